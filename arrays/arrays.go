@@ -0,0 +1,149 @@
+// Package arrays provides generic helpers for slices, complementing the standard slices package.
+package arrays
+
+import "slices"
+
+// Map returns the results of fn applied to each element of s.
+func Map[T, U any](s []T, fn func(T) U) []U {
+	out := make([]U, len(s))
+	for i, v := range s {
+		out[i] = fn(v)
+	}
+
+	return out
+}
+
+// MapWithIndex is Map with the index of each element.
+func MapWithIndex[T, U any](s []T, fn func(int, T) U) []U {
+	out := make([]U, len(s))
+	for i, v := range s {
+		out[i] = fn(i, v)
+	}
+
+	return out
+}
+
+// Filter returns the elements of s for which fn is true, in a new slice.
+func Filter[T any](s []T, fn func(T) bool) []T {
+	var out []T
+
+	for _, v := range s {
+		if fn(v) {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// FilterWithIndex is Filter with the index of each element.
+func FilterWithIndex[T any](s []T, fn func(int, T) bool) []T {
+	var out []T
+
+	for i, v := range s {
+		if fn(i, v) {
+			out = append(out, v)
+		}
+	}
+
+	return out
+}
+
+// Reduce folds the elements of s into an accumulator starting from init.
+func Reduce[T, A any](s []T, init A, fn func(A, T) A) A {
+	acc := init
+	for _, v := range s {
+		acc = fn(acc, v)
+	}
+
+	return acc
+}
+
+// ReduceWithIndex is Reduce with the index of each element.
+func ReduceWithIndex[T, A any](s []T, init A, fn func(A, int, T) A) A {
+	acc := init
+	for i, v := range s {
+		acc = fn(acc, i, v)
+	}
+
+	return acc
+}
+
+// Find returns the first element of s for which fn is true, ok is false if there is none.
+func Find[T any](s []T, fn func(T) bool) (T, bool) {
+	for _, v := range s {
+		if fn(v) {
+			return v, true
+		}
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// FindWithIndex is Find with the index of each element.
+func FindWithIndex[T any](s []T, fn func(int, T) bool) (T, bool) {
+	for i, v := range s {
+		if fn(i, v) {
+			return v, true
+		}
+	}
+
+	var zero T
+
+	return zero, false
+}
+
+// Any reports whether fn is true for at least one element of s.
+func Any[T any](s []T, fn func(T) bool) bool {
+	return slices.ContainsFunc(s, fn)
+}
+
+// AnyWithIndex is Any with the index of each element.
+func AnyWithIndex[T any](s []T, fn func(int, T) bool) bool {
+	return IndexFuncWithIndex(s, fn) >= 0
+}
+
+// All reports whether fn is true for every element of s, true if s is empty.
+func All[T any](s []T, fn func(T) bool) bool {
+	for _, v := range s {
+		if !fn(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AllWithIndex is All with the index of each element.
+func AllWithIndex[T any](s []T, fn func(int, T) bool) bool {
+	for i, v := range s {
+		if !fn(i, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Contains reports whether v is an element of s.
+func Contains[T comparable](s []T, v T) bool {
+	return slices.Contains(s, v)
+}
+
+// IndexFunc returns the index of the first element of s for which fn is true, or -1.
+func IndexFunc[T any](s []T, fn func(T) bool) int {
+	return slices.IndexFunc(s, fn)
+}
+
+// IndexFuncWithIndex is IndexFunc with the index of each element.
+func IndexFuncWithIndex[T any](s []T, fn func(int, T) bool) int {
+	for i, v := range s {
+		if fn(i, v) {
+			return i
+		}
+	}
+
+	return -1
+}
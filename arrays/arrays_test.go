@@ -0,0 +1,81 @@
+package arrays_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/arrays"
+)
+
+var (
+	ints  = []int{1, 2, 3, 4, 5}
+	even  = func(v int) bool { return v%2 == 0 }
+	small = func(v int) bool { return v < 10 }
+)
+
+func TestMap(t *testing.T) {
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, arrays.Map(ints, strconv.Itoa))
+	assert.Equal(t, []string{}, arrays.Map(nil, strconv.Itoa))
+	assert.Equal(t, []string{"0:a", "1:b"}, arrays.MapWithIndex([]string{"a", "b"}, func(i int, s string) string {
+		return strconv.Itoa(i) + ":" + s
+	}))
+}
+
+func TestFilter(t *testing.T) {
+	evens := arrays.Filter(ints, even)
+	assert.Equal(t, []int{2, 4}, evens)
+
+	evens[0] = 100
+	assert.Equal(t, 2, ints[1], "filter returns a new slice")
+
+	assert.Nil(t, arrays.Filter(ints, func(int) bool { return false }))
+	assert.Equal(t, []int{1, 3, 5}, arrays.FilterWithIndex(ints, func(i, _ int) bool { return i%2 == 0 }))
+}
+
+func TestReduce(t *testing.T) {
+	assert.Equal(t, 15, arrays.Reduce(ints, 0, func(acc, v int) int { return acc + v }))
+	assert.Equal(t, "12345", arrays.Reduce(ints, "", func(acc string, v int) string { return acc + strconv.Itoa(v) }))
+	assert.Equal(t, 40, arrays.ReduceWithIndex(ints, 0, func(acc, i, v int) int { return acc + i*v }))
+	assert.Equal(t, 7, arrays.Reduce(nil, 7, func(acc, v int) int { return acc + v }))
+}
+
+func TestFind(t *testing.T) {
+	v, ok := arrays.Find(ints, even)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+
+	_, ok = arrays.Find(ints, func(v int) bool { return v > 5 })
+	assert.False(t, ok)
+
+	v, ok = arrays.FindWithIndex(ints, func(i, _ int) bool { return i == 3 })
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+
+	_, ok = arrays.FindWithIndex(ints, func(i, _ int) bool { return i == 5 })
+	assert.False(t, ok)
+}
+
+func TestAnyAll(t *testing.T) {
+	assert.True(t, arrays.Any(ints, even))
+	assert.False(t, arrays.Any(ints, func(v int) bool { return v > 5 }))
+	assert.False(t, arrays.Any(nil, even))
+	assert.True(t, arrays.AnyWithIndex(ints, func(i, v int) bool { return i == v-1 }))
+
+	assert.True(t, arrays.All(ints, small))
+	assert.False(t, arrays.All(ints, even))
+	assert.True(t, arrays.All(nil, even))
+	assert.False(t, arrays.AllWithIndex(ints, func(i, _ int) bool { return i < 4 }))
+	assert.True(t, arrays.AllWithIndex(ints, func(i, v int) bool { return i == v-1 }))
+}
+
+func TestContainsIndex(t *testing.T) {
+	assert.True(t, arrays.Contains([]string{"a", "b"}, "b"))
+	assert.False(t, arrays.Contains([]string{"a", "b"}, "c"))
+
+	assert.Equal(t, 1, arrays.IndexFunc(ints, even))
+	assert.Equal(t, -1, arrays.IndexFunc(ints, func(v int) bool { return v > 5 }))
+	assert.Equal(t, 3, arrays.IndexFuncWithIndex(ints, func(i, v int) bool { return i > 2 && even(v) }))
+	assert.Equal(t, -1, arrays.IndexFuncWithIndex(ints, func(i, _ int) bool { return i > 10 }))
+}
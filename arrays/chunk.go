@@ -0,0 +1,112 @@
+package arrays
+
+import "iter"
+
+// Chunk splits s into consecutive chunks of n elements, the last one possibly shorter.  The chunks share the
+// backing array of s (capped so appending to one doesn't overwrite the next) and only the outer slice is allocated.
+// Panics if n < 1.
+func Chunk[T any](s []T, n int) [][]T {
+	if n < 1 {
+		panic("arrays: chunk size must be positive")
+	}
+
+	out := make([][]T, 0, (len(s)+n-1)/n)
+	for i := 0; i < len(s); i += n {
+		end := min(i+n, len(s))
+		out = append(out, s[i:end:end])
+	}
+
+	return out
+}
+
+// Chunks is Chunk as an iterator, without allocations.
+func Chunks[T any](s []T, n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("arrays: chunk size must be positive")
+	}
+
+	return func(yield func([]T) bool) {
+		for i := 0; i < len(s); i += n {
+			end := min(i+n, len(s))
+			if !yield(s[i:end:end]) {
+				return
+			}
+		}
+	}
+}
+
+// Batched calls fn with consecutive batches of up to n elements of s, e.g. to page database writes or API calls,
+// stopping at the first error.  Panics if n < 1.
+func Batched[T any](s []T, n int, fn func(batch []T) error) error {
+	for batch := range Chunks(s, n) {
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BatchedSeq calls fn with batches of up to n elements of seq, stopping at the first error.  The batch slice is
+// reused between calls, fn must copy it to retain it.  Panics if n < 1.
+func BatchedSeq[T any](seq iter.Seq[T], n int, fn func(batch []T) error) error {
+	if n < 1 {
+		panic("arrays: batch size must be positive")
+	}
+
+	batch := make([]T, 0, n)
+
+	for v := range seq {
+		batch = append(batch, v)
+		if len(batch) < n {
+			continue
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		clear(batch)
+		batch = batch[:0]
+	}
+
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+
+	return nil
+}
+
+// Window returns the sliding windows of n consecutive elements of s, len(s)-n+1 of them, none if s is shorter than
+// n.  The windows share the backing array of s.  Panics if n < 1.
+func Window[T any](s []T, n int) [][]T {
+	if n < 1 {
+		panic("arrays: window size must be positive")
+	}
+
+	if len(s) < n {
+		return nil
+	}
+
+	out := make([][]T, 0, len(s)-n+1)
+	for i := 0; i+n <= len(s); i++ {
+		out = append(out, s[i:i+n:i+n])
+	}
+
+	return out
+}
+
+// Windows is Window as an iterator, without allocations.
+func Windows[T any](s []T, n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("arrays: window size must be positive")
+	}
+
+	return func(yield func([]T) bool) {
+		for i := 0; i+n <= len(s); i++ {
+			if !yield(s[i : i+n : i+n]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package arrays_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/arrays"
+)
+
+func TestChunk(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+
+	chunks := arrays.Chunk(s, 2)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, chunks)
+	assert.Equal(t, [][]int{{1, 2, 3, 4, 5}}, arrays.Chunk(s, 10))
+	assert.Empty(t, arrays.Chunk([]int{}, 3))
+
+	chunks[0] = append(chunks[0], 99)
+	assert.Equal(t, 3, s[2], "appending to a chunk doesn't overwrite the next")
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {4, 5}}, slices.Collect(arrays.Chunks(s, 3)))
+
+	for c := range arrays.Chunks(s, 2) {
+		assert.Equal(t, []int{1, 2}, c)
+
+		break
+	}
+
+	assert.Panics(t, func() { arrays.Chunk(s, 0) })
+	assert.Panics(t, func() { arrays.Chunks(s, 0) })
+}
+
+func TestBatched(t *testing.T) {
+	var got [][]int
+
+	require.NoError(t, arrays.Batched([]int{1, 2, 3, 4, 5}, 2, func(batch []int) error {
+		got = append(got, batch)
+
+		return nil
+	}))
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, got)
+
+	errStop := errors.New("stop")
+	calls := 0
+
+	err := arrays.Batched([]int{1, 2, 3, 4, 5}, 2, func([]int) error {
+		calls++
+
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBatchedSeq(t *testing.T) {
+	var got [][]int
+
+	require.NoError(t, arrays.BatchedSeq(slices.Values([]int{1, 2, 3, 4, 5}), 2, func(batch []int) error {
+		got = append(got, slices.Clone(batch))
+
+		return nil
+	}))
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, got)
+
+	got = nil
+
+	require.NoError(t, arrays.BatchedSeq(slices.Values([]int{1, 2}), 2, func(batch []int) error {
+		got = append(got, slices.Clone(batch))
+
+		return nil
+	}))
+	assert.Equal(t, [][]int{{1, 2}}, got, "no empty trailing batch")
+
+	errStop := errors.New("stop")
+	require.ErrorIs(t, arrays.BatchedSeq(slices.Values([]int{1}), 2, func([]int) error { return errStop }), errStop)
+	assert.Panics(t, func() { _ = arrays.BatchedSeq(slices.Values([]int{1}), 0, nil) })
+}
+
+func TestWindow(t *testing.T) {
+	s := []int{1, 2, 3, 4}
+
+	assert.Equal(t, [][]int{{1, 2}, {2, 3}, {3, 4}}, arrays.Window(s, 2))
+	assert.Equal(t, [][]int{{1, 2, 3, 4}}, arrays.Window(s, 4))
+	assert.Nil(t, arrays.Window(s, 5))
+	assert.Equal(t, [][]int{{1, 2, 3}, {2, 3, 4}}, slices.Collect(arrays.Windows(s, 3)))
+	assert.Empty(t, slices.Collect(arrays.Windows(s, 5)))
+
+	sums := []int{}
+	for w := range arrays.Windows(s, 2) {
+		sums = append(sums, w[0]+w[1])
+	}
+
+	assert.Equal(t, []int{3, 5, 7}, sums)
+	assert.Panics(t, func() { arrays.Window(s, 0) })
+	assert.Panics(t, func() { arrays.Windows(s, -1) })
+}
+
+var benchInts = func() []int {
+	s := make([]int, 10_000)
+	for i := range s {
+		s[i] = i
+	}
+
+	return s
+}()
+
+func BenchmarkChunk(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		_ = arrays.Chunk(benchInts, 100)
+	}
+}
+
+func BenchmarkChunks(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		for c := range arrays.Chunks(benchInts, 100) {
+			_ = c
+		}
+	}
+}
+
+func BenchmarkBatchedSeq(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		_ = arrays.BatchedSeq(slices.Values(benchInts), 100, func([]int) error { return nil })
+	}
+}
+
+func BenchmarkWindows(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		sum := 0
+		for w := range arrays.Windows(benchInts, 10) {
+			sum += w[0]
+		}
+
+		_ = sum
+	}
+}
@@ -0,0 +1,45 @@
+package arrays
+
+// Unique returns the distinct elements of s in first seen order, in a new slice.
+func Unique[T comparable](s []T) []T {
+	return UniqueBy(s, func(v T) T { return v })
+}
+
+// UniqueBy returns the elements of s with distinct keys in first seen order, in a new slice.
+func UniqueBy[T any, K comparable](s []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(s))
+	out := make([]T, 0, len(s))
+
+	for _, v := range s {
+		k := key(v)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+
+		seen[k] = struct{}{}
+		out = append(out, v)
+	}
+
+	return out
+}
+
+// GroupBy returns the elements of s grouped by key, in their order within each group.
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	out := make(map[K][]T)
+	for _, v := range s {
+		k := key(v)
+		out[k] = append(out[k], v)
+	}
+
+	return out
+}
+
+// IndexBy returns the elements of s by key, the last element wins for duplicate keys.
+func IndexBy[T any, K comparable](s []T, key func(T) K) map[K]T {
+	out := make(map[K]T, len(s))
+	for _, v := range s {
+		out[key(v)] = v
+	}
+
+	return out
+}
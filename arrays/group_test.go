@@ -0,0 +1,39 @@
+package arrays_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/arrays"
+)
+
+type user struct {
+	ID   int
+	Team string
+}
+
+var users = []user{{1, "ops"}, {2, "dev"}, {3, "ops"}, {1, "qa"}}
+
+func TestUnique(t *testing.T) {
+	assert.Equal(t, []string{"b", "a", "c"}, arrays.Unique([]string{"b", "a", "b", "c", "a"}))
+	assert.Empty(t, arrays.Unique([]int(nil)))
+
+	assert.Equal(t, []user{{1, "ops"}, {2, "dev"}, {3, "ops"}}, arrays.UniqueBy(users, func(u user) int { return u.ID }))
+	assert.Equal(t, []string{"Go", "rust"}, arrays.UniqueBy([]string{"Go", "go", "rust", "GO"}, strings.ToLower))
+}
+
+func TestGroupBy(t *testing.T) {
+	assert.Equal(t, map[string][]user{
+		"ops": {{1, "ops"}, {3, "ops"}},
+		"dev": {{2, "dev"}},
+		"qa":  {{1, "qa"}},
+	}, arrays.GroupBy(users, func(u user) string { return u.Team }))
+	assert.Empty(t, arrays.GroupBy([]user(nil), func(u user) string { return u.Team }))
+}
+
+func TestIndexBy(t *testing.T) {
+	assert.Equal(t, map[int]user{1: {1, "qa"}, 2: {2, "dev"}, 3: {3, "ops"}},
+		arrays.IndexBy(users, func(u user) int { return u.ID }))
+}
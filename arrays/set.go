@@ -0,0 +1,184 @@
+package arrays
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Set is a set of comparable values, ranged over as a map.  Create it with NewSet, FromSlice or make.  It is
+// encoded in JSON as an array, sorted by encoding so the output is stable.
+type Set[T comparable] map[T]struct{}
+
+// NewSet returns a Set of values.
+func NewSet[T comparable](values ...T) Set[T] {
+	return FromSlice(values)
+}
+
+// FromSlice returns a Set of the elements of s.
+func FromSlice[T comparable](s []T) Set[T] {
+	out := make(Set[T], len(s))
+	out.Add(s...)
+
+	return out
+}
+
+// Add adds values to s.
+func (s Set[T]) Add(values ...T) {
+	for _, v := range values {
+		s[v] = struct{}{}
+	}
+}
+
+// Remove removes values from s.
+func (s Set[T]) Remove(values ...T) {
+	for _, v := range values {
+		delete(s, v)
+	}
+}
+
+// Has reports whether v is in s.
+func (s Set[T]) Has(v T) bool {
+	_, ok := s[v]
+
+	return ok
+}
+
+// Len returns the number of values in s.
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+// Clone returns a copy of s.
+func (s Set[T]) Clone() Set[T] {
+	out := make(Set[T], len(s))
+	for v := range s {
+		out[v] = struct{}{}
+	}
+
+	return out
+}
+
+// Union returns the values in s or o.
+func (s Set[T]) Union(o Set[T]) Set[T] {
+	out := make(Set[T], max(len(s), len(o)))
+	for v := range s {
+		out[v] = struct{}{}
+	}
+
+	for v := range o {
+		out[v] = struct{}{}
+	}
+
+	return out
+}
+
+// Intersection returns the values in both s and o.
+func (s Set[T]) Intersection(o Set[T]) Set[T] {
+	small, large := s, o
+	if len(small) > len(large) {
+		small, large = large, small
+	}
+
+	out := make(Set[T])
+
+	for v := range small {
+		if large.Has(v) {
+			out[v] = struct{}{}
+		}
+	}
+
+	return out
+}
+
+// Difference returns the values in s but not in o.
+func (s Set[T]) Difference(o Set[T]) Set[T] {
+	out := make(Set[T])
+
+	for v := range s {
+		if !o.Has(v) {
+			out[v] = struct{}{}
+		}
+	}
+
+	return out
+}
+
+// IsSubset reports whether every value of s is in o.
+func (s Set[T]) IsSubset(o Set[T]) bool {
+	if len(s) > len(o) {
+		return false
+	}
+
+	for v := range s {
+		if !o.Has(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Equal reports whether s and o have the same values.
+func (s Set[T]) Equal(o Set[T]) bool {
+	return len(s) == len(o) && s.IsSubset(o)
+}
+
+// ToSlice returns the values of s in no particular order.
+func (s Set[T]) ToSlice() []T {
+	out := make([]T, 0, len(s))
+	for v := range s {
+		out = append(out, v)
+	}
+
+	return out
+}
+
+// ToSortedSlice returns the values of s ordered by cmp, e.g. cmp.Compare for ordered types.
+func (s Set[T]) ToSortedSlice(cmp func(a, b T) int) []T {
+	out := s.ToSlice()
+	slices.SortFunc(out, cmp)
+
+	return out
+}
+
+// MarshalJSON encodes s as an array, sorted by the encoding of the values.
+func (s Set[T]) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+
+	values := make([][]byte, 0, len(s))
+
+	for v := range s {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("set: %w", err)
+		}
+
+		values = append(values, b)
+	}
+
+	slices.SortFunc(values, bytes.Compare)
+
+	return append(append([]byte("["), bytes.Join(values, []byte(","))...), ']'), nil
+}
+
+// UnmarshalJSON decodes an array into s, replacing its values.
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var values []T
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+
+	if values == nil {
+		*s = nil
+
+		return nil
+	}
+
+	*s = FromSlice(values)
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package arrays_test
+
+import (
+	"cmp"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/arrays"
+)
+
+func TestSet(t *testing.T) {
+	s := arrays.NewSet("a", "b")
+	s.Add("c", "a")
+	s.Remove("b", "x")
+
+	assert.True(t, s.Has("a"))
+	assert.False(t, s.Has("b"))
+	assert.Equal(t, 2, s.Len())
+	assert.ElementsMatch(t, []string{"a", "c"}, s.ToSlice())
+	assert.Equal(t, []string{"c", "a"}, s.ToSortedSlice(func(a, b string) int { return cmp.Compare(b, a) }))
+
+	c := s.Clone()
+	c.Add("z")
+	assert.False(t, s.Has("z"), "clones are independent")
+
+	for v := range s {
+		assert.Contains(t, []string{"a", "c"}, v)
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := arrays.FromSlice([]int{1, 2, 3, 4})
+	b := arrays.NewSet(3, 4, 5)
+
+	sorted := func(s arrays.Set[int]) []int { return s.ToSortedSlice(cmp.Compare[int]) }
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, sorted(a.Union(b)))
+	assert.Equal(t, []int{3, 4}, sorted(a.Intersection(b)))
+	assert.Equal(t, []int{3, 4}, sorted(b.Intersection(a)))
+	assert.Equal(t, []int{1, 2}, sorted(a.Difference(b)))
+	assert.Equal(t, []int{5}, sorted(b.Difference(a)))
+
+	assert.True(t, arrays.NewSet(3, 4).IsSubset(a))
+	assert.False(t, b.IsSubset(a))
+	assert.True(t, arrays.NewSet[int]().IsSubset(a))
+	assert.True(t, a.Equal(arrays.NewSet(4, 3, 2, 1)))
+	assert.False(t, a.Equal(b))
+}
+
+func TestSetJSON(t *testing.T) {
+	var v struct {
+		Tags  arrays.Set[string] `json:"tags"`
+		Empty arrays.Set[int]    `json:"empty"`
+		Nil   arrays.Set[int]    `json:"nil"`
+	}
+
+	v.Tags = arrays.NewSet("b", "c", "a")
+	v.Empty = arrays.NewSet[int]()
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tags":["a","b","c"],"empty":[],"nil":null}`, string(b))
+	assert.Contains(t, string(b), `["a","b","c"]`, "sorted")
+
+	v.Tags = nil
+
+	require.NoError(t, json.Unmarshal([]byte(`{"tags":["x","y","x"],"empty":[],"nil":null}`), &v))
+	assert.True(t, v.Tags.Equal(arrays.NewSet("x", "y")))
+	assert.NotNil(t, v.Empty)
+	assert.Nil(t, v.Nil)
+
+	require.Error(t, json.Unmarshal([]byte(`{"tags":[1]}`), &v))
+}
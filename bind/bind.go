@@ -0,0 +1,216 @@
+// Package bind composes parameter binding, JSON body decoding and struct validation into a single request binding
+// step with a single error shape.
+package bind
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/params"
+	"github.com/bir/iken/validation"
+)
+
+// BodyField is the key of body decoding failures that can not be attributed to a field.
+const BodyField = "body"
+
+// Binder binds requests, see Request.
+type Binder struct {
+	// Validator validates the bound struct, defaults to validation.DefaultValidator.
+	Validator *validation.Validator
+	// DisallowUnknownFields rejects bodies with fields not present in the target.
+	DisallowUnknownFields bool
+}
+
+// binders populate the parameter fields, paramTags are the corresponding struct tags.
+var (
+	binders = []func(*http.Request, any) error{
+		params.Bind, params.BindQuery, params.BindPath, params.BindHeader, params.BindForm,
+	}
+	paramTags = []string{params.TagName, params.QueryTag, params.PathTag, params.HeaderTag, params.FormTag}
+)
+
+// Default is used by Request.
+var Default = &Binder{}
+
+// Request binds r to dst with Default, see Binder.Request.
+func Request(r *http.Request, dst any) error {
+	return Default.Request(r, dst)
+}
+
+// Request decodes the JSON body of r into dst (an empty body is skipped), binds the fields tagged for parameters (see
+// params.Bind, params.BindQuery, params.BindPath, params.BindHeader and params.BindForm), then validates dst.  Form
+// bodies are bound by params.BindForm rather than decoded as JSON.  All failures are
+// aggregated into a single *validation.Errors coded as errs.InvalidArgument, which httputil.ErrorHandler returns as a
+// 400.  Rules of fields that failed to parse
+// are not reported, the parse failure is.
+//
+// Programming errors (invalid targets, invalid rules) and request read failures are returned as is.
+func (b *Binder) Request(r *http.Request, dst any) error {
+	if val := reflect.ValueOf(dst); val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return params.ErrInvalidTarget
+	}
+
+	var ee validation.Errors
+
+	if err := b.decodeBody(r, dst, &ee); err != nil {
+		return err
+	}
+
+	for _, bind := range binders {
+		if err := bind(r, dst); err != nil && !merge(&ee, err) {
+			return fmt.Errorf("bind params: %w", err)
+		}
+	}
+
+	v := b.Validator
+	if v == nil {
+		v = validation.DefaultValidator
+	}
+
+	var failures *validation.Errors
+
+	err := v.StructCtx(r.Context(), dst)
+
+	switch {
+	case errors.As(err, &failures):
+		names := paramNames(dst)
+
+		for field, msgs := range *failures {
+			if name, ok := names[field]; ok {
+				field = name
+			}
+
+			if _, failed := ee[field]; !failed {
+				for _, msg := range msgs {
+					ee.Add(field, msg)
+				}
+			}
+		}
+	case err != nil:
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	if len(ee) == 0 {
+		return nil
+	}
+
+	return errs.WithCode(&ee, errs.InvalidArgument)
+}
+
+func (b *Binder) decodeBody(r *http.Request, dst any, ee *validation.Errors) error {
+	if r.Body == nil || r.Body == http.NoBody || params.IsForm(r) {
+		return nil
+	}
+
+	d := json.NewDecoder(r.Body)
+	if b.DisallowUnknownFields {
+		d.DisallowUnknownFields()
+	}
+
+	err := d.Decode(dst)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var (
+		syntaxErr     *json.SyntaxError
+		typeErr       *json.UnmarshalTypeError
+		failures      *validation.Errors
+		validationErr validation.Error
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		ee.Add(BodyField, validation.Error{Message: "must be valid JSON", Source: err})
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = BodyField
+		}
+
+		ee.Add(field, validation.Error{Message: "must be " + jsonType(typeErr.Type.Kind()), Source: err})
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+		ee.Add(field, validation.Error{Message: "is not allowed", Source: err})
+	case errors.As(err, &failures):
+		// Reported by custom unmarshalers
+		merge(ee, failures)
+	case errors.As(err, &validationErr):
+		ee.Add(BodyField, validationErr)
+	default:
+		return fmt.Errorf("decode body: %w", err)
+	}
+
+	return nil
+}
+
+// unknownFieldPrefix prefixes the encoding/json error for unknown fields, which has no exported type.
+const unknownFieldPrefix = "json: unknown field "
+
+// jsonType describes the expected JSON type of a Go kind.
+func jsonType(kind reflect.Kind) string {
+	switch kind { //nolint:exhaustive
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	}
+
+	return "a valid " + kind.String()
+}
+
+// merge adds the parameter failures to ee, reporting false if err is not a validation failure.
+func merge(ee *validation.Errors, err error) bool {
+	var failures *validation.Errors
+	if !errors.As(err, &failures) {
+		return false
+	}
+
+	for field, msgs := range *failures {
+		for _, msg := range msgs {
+			ee.Add(field, msg)
+		}
+	}
+
+	return true
+}
+
+// paramNames maps the Go field names of parameter fields without a json name to the parameter name, so validation
+// failures of parameters are reported under the same key as parse failures.
+func paramNames(dst any) map[string]string {
+	t := reflect.TypeOf(dst).Elem()
+	out := map[string]string{}
+
+	for _, sf := range reflect.VisibleFields(t) {
+		jsonName, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if jsonName != "" && jsonName != "-" {
+			continue
+		}
+
+		for _, tag := range paramTags {
+			if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+				out[sf.Name] = name
+
+				break
+			}
+		}
+	}
+
+	return out
+}
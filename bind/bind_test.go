@@ -0,0 +1,168 @@
+package bind_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/bind"
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/params"
+	"github.com/bir/iken/validation"
+)
+
+type CreateOrder struct {
+	Tenant   string `json:"-" param:"X-Tenant" validate:"required"`
+	DryRun   bool   `json:"-" param:"dry_run"`
+	Email    string `json:"email" validate:"required,email"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+func TestRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		tenant string
+		body   string
+		want   map[string][]string
+	}{
+		{"valid", "/?dry_run=true", "acme", `{"email":"bob@example.com","quantity":1}`, nil},
+		{"validation", "/", "", `{"email":"bob","quantity":0}`, map[string][]string{
+			"X-Tenant": {"is required"},
+			"email":    {"must be a valid email address"},
+			"quantity": {"must be at least 1"},
+		}},
+		{
+			"parse and validation", "/?dry_run=maybe", "acme", `{"email":"bob@example.com","quantity":"many"}`,
+			map[string][]string{
+				"dry_run":  {"must be a boolean"},
+				"quantity": {"must be an integer"},
+			},
+		},
+		{"syntax", "/", "acme", `{"email":`, map[string][]string{
+			"body":     {"must be valid JSON"},
+			"email":    {"is required"},
+			"quantity": {"must be at least 1"},
+		}},
+		{"empty body", "/", "acme", ``, map[string][]string{
+			"email":    {"is required"},
+			"quantity": {"must be at least 1"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			r.Header.Set("X-Tenant", tt.tenant)
+
+			var dst CreateOrder
+
+			err := bind.Request(r, &dst)
+			if tt.want == nil {
+				require.NoError(t, err)
+				assert.Equal(t, CreateOrder{Tenant: "acme", DryRun: true, Email: "bob@example.com", Quantity: 1}, dst)
+
+				return
+			}
+
+			var ee *validation.Errors
+
+			require.ErrorAs(t, err, &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+			assert.Equal(t, http.StatusBadRequest, errs.Status(err))
+		})
+	}
+}
+
+func TestRequestStrict(t *testing.T) {
+	b := &bind.Binder{DisallowUnknownFields: true}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"email":"bob@example.com","quantity":1,"qty":2}`))
+	r.Header.Set("X-Tenant", "acme")
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, b.Request(r, &CreateOrder{}), &ee)
+	assert.Equal(t, map[string][]string{"qty": {"is not allowed"}}, ee.Fields())
+}
+
+func TestRequestErrors(t *testing.T) {
+	v := validation.NewValidator().RegisterCtx("fail", "x", func(context.Context, any, string) (bool, error) {
+		return false, assert.AnError
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	assert.ErrorIs(t, (&bind.Binder{Validator: v}).Request(r, &struct {
+		A string `validate:"fail"`
+	}{}), assert.AnError)
+	assert.ErrorIs(t, bind.Request(r, CreateOrder{}), params.ErrInvalidTarget)
+}
+
+func TestRequestQuery(t *testing.T) {
+	type Search struct {
+		Tenant string `param:"X-Tenant"`
+		Limit  int    `query:"limit" validate:"max=10"`
+		Term   string `json:"term" validate:"required"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/?limit=20", strings.NewReader(`{"term":"shoes"}`))
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, bind.Request(r, &Search{}), &ee)
+	assert.Equal(t, map[string][]string{"limit": {"must be at most 10"}}, ee.Fields())
+
+	r = httptest.NewRequest(http.MethodPost, "/?limit=two", strings.NewReader(`{"term":"shoes"}`))
+	require.ErrorAs(t, bind.Request(r, &Search{}), &ee)
+	assert.Equal(t, map[string][]string{"limit": {"must be an integer"}}, ee.Fields())
+}
+
+func TestRequestHeader(t *testing.T) {
+	type Versioned struct {
+		Version int    `header:"X-Client-Version" validate:"min=2"`
+		Term    string `json:"term"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"term":"shoes"}`))
+	r.Header.Set("X-Client-Version", "3")
+
+	var v Versioned
+
+	require.NoError(t, bind.Request(r, &v))
+	assert.Equal(t, Versioned{Version: 3, Term: "shoes"}, v)
+
+	var ee *validation.Errors
+
+	r.Header.Set("X-Client-Version", "1")
+	require.ErrorAs(t, bind.Request(r, &Versioned{}), &ee)
+	assert.Equal(t, map[string][]string{"X-Client-Version": {"must be at least 2"}}, ee.Fields())
+}
+
+func TestRequestForm(t *testing.T) {
+	type Signup struct {
+		Name string `form:"name" validate:"required"`
+		Age  int    `form:"age" validate:"min=18"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("name=bob&age=21"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var s Signup
+
+	require.NoError(t, bind.Request(r, &s))
+	assert.Equal(t, Signup{Name: "bob", Age: 21}, s)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("age=12"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, bind.Request(r, &Signup{}), &ee)
+	assert.Equal(t, map[string][]string{"name": {"is required"}, "age": {"must be at least 18"}}, ee.Fields())
+}
@@ -0,0 +1,118 @@
+package cache_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestRefreshAhead(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{
+		TTL: time.Minute, RefreshAhead: 10 * time.Second, Resolution: -1, Clock: clk.Now,
+	})
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	loader := func(context.Context, string) (int, error) {
+		n := calls.Add(1)
+		if n > 1 {
+			<-release
+		}
+
+		return int(n), nil
+	}
+
+	v, err := c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	clk.Add(49 * time.Second)
+
+	v, _ = c.GetOrLoad(context.Background(), "a", loader)
+	assert.Equal(t, 1, v)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load(), "not due yet")
+
+	clk.Add(time.Second)
+
+	for range 10 {
+		v, err = c.GetOrLoad(context.Background(), "a", loader)
+		require.NoError(t, err)
+		assert.Equal(t, 1, v, "served while refreshing")
+	}
+
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		v, ok := c.Get("a")
+
+		return ok && v == 2
+	}, time.Second, time.Millisecond, "refreshed before expiry")
+	assert.Equal(t, int32(2), calls.Load(), "one refresh per key")
+
+	clk.Add(15 * time.Second)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok, "never missed")
+	assert.Equal(t, 2, v)
+}
+
+func TestRefreshAheadMaxRefreshes(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{
+		TTL: time.Minute, RefreshAhead: time.Minute, MaxRefreshes: 2, Resolution: -1, Clock: clk.Now,
+	})
+
+	for i := range 10 {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	var (
+		running, peak atomic.Int32
+		wg            sync.WaitGroup
+	)
+
+	release := make(chan struct{})
+	loader := func(context.Context, string) (int, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+
+		<-release
+
+		return 0, nil
+	}
+
+	for i := range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := c.GetOrLoad(context.Background(), strconv.Itoa(i), loader)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	assert.Equal(t, int32(2), peak.Load(), "bounded by MaxRefreshes")
+}
@@ -0,0 +1,73 @@
+package cache
+
+// Reason is the cause of an entry removal, see Options.OnEvict.
+type Reason int
+
+const (
+	// Expired entries reached their TTL (and StaleTTL).
+	Expired Reason = iota
+	// Capacity entries were evicted by MaxEntries or MaxBytes, or were too large to cache.
+	Capacity
+	// Replaced entries were overwritten by Set or a load.
+	Replaced
+	// Deleted entries were removed by Delete or Clear.
+	Deleted
+)
+
+// String returns the reason name.
+func (r Reason) String() string {
+	switch r {
+	case Expired:
+		return "expired"
+	case Capacity:
+		return "capacity"
+	case Replaced:
+		return "replaced"
+	case Deleted:
+		return "deleted"
+	}
+
+	return "unknown"
+}
+
+// EvictFunc is called with removed entries and the cause.
+type EvictFunc[K comparable, V any] func(k K, v V, reason Reason)
+
+type eviction[K comparable, V any] struct {
+	key    K
+	value  V
+	reason Reason
+}
+
+// evicted records a removal for the callbacks, called by unlock.  c.mu must be held.
+func (c *TTL[K, V]) evicted(k K, v V, reason Reason) {
+	switch reason {
+	case Expired:
+		c.stats.Expirations++
+	case Capacity:
+		c.stats.Evictions++
+	case Replaced, Deleted:
+	}
+
+	if c.opts.OnEvict != nil || c.opts.OnExpire != nil && reason == Expired {
+		c.pending = append(c.pending, eviction[K, V]{k, v, reason})
+	}
+}
+
+// unlock releases c.mu and calls the callbacks of the removals made while holding it.
+func (c *TTL[K, V]) unlock() {
+	pending := c.pending
+	c.pending = nil
+
+	c.mu.Unlock()
+
+	for _, e := range pending {
+		if e.reason == Expired && c.opts.OnExpire != nil {
+			c.opts.OnExpire(e.key, e.value, e.reason)
+		}
+
+		if c.opts.OnEvict != nil {
+			c.opts.OnEvict(e.key, e.value, e.reason)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package cache_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/cache"
+)
+
+type evictLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *evictLog) record(prefix string) cache.EvictFunc[string, int] {
+	return func(k string, v int, reason cache.Reason) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.events = append(l.events, fmt.Sprintf("%s %s=%d %s", prefix, k, v, reason))
+	}
+}
+
+func (l *evictLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := l.events
+	l.events = nil
+
+	return out
+}
+
+func TestEvictCallbacks(t *testing.T) {
+	clk := newClock()
+
+	var log evictLog
+
+	c := cache.NewTTL(cache.Options[string, int]{
+		TTL:        time.Minute,
+		Resolution: -1,
+		Clock:      clk.Now,
+		MaxEntries: 2,
+		MaxBytes:   100,
+		Size:       func(_ string, v int) int64 { return int64(v) },
+		OnEvict:    log.record("evict"),
+		OnExpire:   log.record("expire"),
+	})
+
+	c.Set("a", 1)
+	c.Set("a", 2)
+	assert.Equal(t, []string{"evict a=1 replaced"}, log.take())
+
+	c.Set("b", 3)
+	c.Set("c", 4)
+	assert.Equal(t, []string{"evict a=2 capacity"}, log.take())
+
+	c.Set("big", 101)
+	assert.Equal(t, []string{"evict big=101 capacity"}, log.take())
+
+	c.Delete("b")
+	c.Delete("missing")
+	assert.Equal(t, []string{"evict b=3 deleted"}, log.take())
+
+	clk.Add(time.Minute)
+
+	_, ok := c.Get("c")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"expire c=4 expired", "evict c=4 expired"}, log.take())
+
+	c.Set("d", 5)
+	c.Set("e", 6)
+	c.Clear()
+	assert.ElementsMatch(t, []string{"evict d=5 deleted", "evict e=6 deleted"}, log.take())
+
+	c.Set("f", 7)
+	clk.Add(time.Minute)
+	c.DeleteExpired()
+	assert.Equal(t, []string{"expire f=7 expired", "evict f=7 expired"}, log.take())
+}
+
+func TestEvictCallbackReentrant(t *testing.T) {
+	var c *cache.TTL[string, int]
+
+	c = cache.NewTTL(cache.Options[string, int]{
+		Resolution: -1,
+		OnEvict: func(k string, _ int, _ cache.Reason) {
+			c.Set("evicted", len(k)) // callbacks may use the cache
+		},
+	})
+
+	c.Set("abc", 1)
+	c.Delete("abc")
+
+	v, ok := c.Get("evicted")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+}
+
+func TestReasonString(t *testing.T) {
+	assert.Equal(t, "unknown", cache.Reason(99).String())
+}
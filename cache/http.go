@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/httputil"
+)
+
+const (
+	// XCacheHeader reports whether a response was served by ResponseCache: HIT, MISS or BYPASS.
+	XCacheHeader = "X-Cache"
+	// LogCache is the log field of the X-Cache value, added to the request logger (see httplog.RequestLogger).
+	LogCache = "http.cache"
+	// DefaultMaxResponseSize is the largest body cached by ResponseCache when MaxSize is 0.
+	DefaultMaxResponseSize = 1 << 20
+)
+
+// X-Cache values.
+const (
+	CacheHit    = "HIT"
+	CacheMiss   = "MISS"
+	CacheBypass = "BYPASS"
+)
+
+// Response is an HTTP response cached by ResponseCache.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	Stored time.Time
+}
+
+// ResponseCache caches GET and HEAD responses, keyed by method, host, URL and the Vary request headers.
+//
+// Responses with a Cache-Control s-maxage or max-age are cached for that long, others for the cache TTL.
+// Responses with Cache-Control no-store, no-cache or private, Set-Cookie, Vary: * or a status other than 200, 203,
+// 204, 301, 404 or 410 are not cached.  Requests with Cache-Control no-cache or no-store, or a Cookie, skip the cache.
+// Responses to requests with Authorization are only cached if marked public, s-maxage or must-revalidate (RFC 9111
+// section 3.5), unless Cookie or Authorization are listed in Vary.  Response Vary headers are not interpreted, list
+// them in Vary.  Responses are tagged by Tags, see InvalidateTag.
+type ResponseCache struct {
+	Cache *TTL[string, *Response]
+	// Vary lists the request headers selecting different responses, e.g. Accept-Language.
+	Vary []string
+	// MaxSize is the largest body cached, defaults to DefaultMaxResponseSize.
+	MaxSize int
+	// Tags returns the tags of a response to cache, e.g. "user:42" for InvalidateTag.
+	Tags func(r *http.Request, header http.Header) []string
+}
+
+// NewResponseCache returns a ResponseCache backed by a TTL cache configured by opts.
+func NewResponseCache(opts Options[string, *Response]) *ResponseCache {
+	return &ResponseCache{Cache: NewTTL(opts)}
+}
+
+// InvalidateTag deletes the responses with any of tags, returning the number deleted.
+func (c *ResponseCache) InvalidateTag(tags ...string) int {
+	return c.Cache.InvalidateTag(tags...)
+}
+
+// Middleware returns next with response caching, for use in a chain.Chain.
+func (c *ResponseCache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if d := directives(r.Header); d.has("no-cache") || d.has("no-store") ||
+			(r.Header.Get("Cookie") != "" && !c.varies("Cookie")) {
+			logCache(r, CacheBypass)
+			w.Header().Set(XCacheHeader, CacheBypass)
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		key := c.key(r)
+
+		if res, ok := c.Cache.Get(key); ok {
+			logCache(r, CacheHit)
+			c.write(w, r, res)
+
+			return
+		}
+
+		logCache(r, CacheMiss)
+		w.Header().Set(XCacheHeader, CacheMiss)
+
+		c.serve(w, r, next, key)
+	})
+}
+
+// serve calls next, caching the response if allowed.
+func (c *ResponseCache) serve(w http.ResponseWriter, r *http.Request, next http.Handler, key string) {
+	var body bytes.Buffer
+
+	ww := httputil.WrapWriter(w)
+	ww.Tee(&body)
+
+	next.ServeHTTP(ww, r)
+
+	maxSize := c.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxResponseSize
+	}
+
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	ttl, ok := c.ttl(status, w.Header())
+	if !ok || body.Len() > maxSize || !c.shared(r, w.Header()) {
+		return
+	}
+
+	header := w.Header().Clone()
+	header.Del(XCacheHeader)
+
+	res := &Response{Status: status, Header: header, Body: body.Bytes(), Stored: c.Cache.opts.Clock()}
+
+	c.Cache.SetWithTTL(key, res, ttl)
+
+	if c.Tags != nil {
+		c.Cache.Tag(key, c.Tags(r, header)...)
+	}
+}
+
+// write serves a cached response.
+func (c *ResponseCache) write(w http.ResponseWriter, r *http.Request, res *Response) {
+	h := w.Header()
+	for k, v := range res.Header {
+		h[k] = v
+	}
+
+	h.Set(XCacheHeader, CacheHit)
+	h.Set("Age", strconv.Itoa(int(c.Cache.opts.Clock().Sub(res.Stored).Seconds())))
+
+	w.WriteHeader(res.Status)
+
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(res.Body)
+	}
+}
+
+// key returns the cache key of r.
+func (c *ResponseCache) key(r *http.Request) string {
+	var b strings.Builder
+
+	b.WriteString(r.Method + " " + r.Host + r.URL.RequestURI())
+
+	for _, h := range c.Vary {
+		b.WriteString("\n" + h + ": " + strings.Join(r.Header.Values(h), ","))
+	}
+
+	return b.String()
+}
+
+// varies reports whether the request header name is listed in Vary.
+func (c *ResponseCache) varies(name string) bool {
+	for _, h := range c.Vary {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shared reports whether the response to an authorized request may be cached for other requests.
+func (c *ResponseCache) shared(r *http.Request, header http.Header) bool {
+	if r.Header.Get("Authorization") == "" || c.varies("Authorization") {
+		return true
+	}
+
+	d := directives(header)
+
+	return d.has("public") || d.has("s-maxage") || d.has("must-revalidate")
+}
+
+// ttl returns the lifetime of a response, ok is false if it must not be cached.
+func (c *ResponseCache) ttl(status int, header http.Header) (time.Duration, bool) {
+	switch status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMovedPermanently,
+		http.StatusNotFound, http.StatusGone:
+	default:
+		return 0, false
+	}
+
+	d := directives(header)
+	if d.has("no-store") || d.has("no-cache") || d.has("private") || header.Get("Set-Cookie") != "" ||
+		header.Get("Vary") == "*" {
+		return 0, false
+	}
+
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if v, found := d[name]; found {
+			seconds, err := strconv.Atoi(strings.Trim(v, `"`))
+			if err != nil || seconds <= 0 {
+				return 0, false
+			}
+
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+
+	return c.Cache.opts.TTL, true
+}
+
+// cacheControl maps Cache-Control directive names to their values.
+type cacheControl map[string]string
+
+func (c cacheControl) has(name string) bool {
+	_, ok := c[name]
+
+	return ok
+}
+
+// directives returns the Cache-Control directives of header.
+func directives(header http.Header) cacheControl {
+	out := cacheControl{}
+
+	for _, v := range header.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(d), "=")
+			out[strings.ToLower(name)] = value
+		}
+	}
+
+	return out
+}
+
+// logCache adds the cache result to the request logger.
+func logCache(r *http.Request, result string) {
+	zerolog.Ctx(r.Context()).UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Str(LogCache, result)
+	})
+}
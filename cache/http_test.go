@@ -0,0 +1,185 @@
+package cache_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestResponseCache(t *testing.T) {
+	clk := newClock()
+	c := cache.NewResponseCache(cache.Options[string, *cache.Response]{
+		TTL: time.Minute, Resolution: -1, Clock: clk.Now,
+	})
+	c.Vary = []string{"Accept-Language"}
+	c.Tags = func(r *http.Request, _ http.Header) []string { return []string{"path:" + r.URL.Path} }
+
+	calls := 0
+	h := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.Header().Set("Content-Type", "text/plain")
+
+		switch r.URL.Path {
+		case "/max-age":
+			w.Header().Set("Cache-Control", "public, max-age=10, s-maxage=30")
+		case "/private":
+			w.Header().Set("Cache-Control", "private")
+		case "/error":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		_, _ = w.Write([]byte(r.URL.Path + " " + r.Header.Get("Accept-Language")))
+	}))
+
+	serve := func(method, target string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		for i := 0; i < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	w := serve(http.MethodGet, "/a")
+	assert.Equal(t, cache.CacheMiss, w.Header().Get(cache.XCacheHeader))
+
+	clk.Add(5 * time.Second)
+
+	w = serve(http.MethodGet, "/a")
+	assert.Equal(t, cache.CacheHit, w.Header().Get(cache.XCacheHeader))
+	assert.Equal(t, "5", w.Header().Get("Age"))
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "/a ", w.Body.String())
+	assert.Equal(t, 1, calls)
+
+	w = serve(http.MethodGet, "/a", "Accept-Language", "fr")
+	assert.Equal(t, cache.CacheMiss, w.Header().Get(cache.XCacheHeader), "varied header")
+	assert.Equal(t, "/a fr", w.Body.String())
+
+	w = serve(http.MethodGet, "/a", "Cache-Control", "no-cache")
+	assert.Equal(t, cache.CacheBypass, w.Header().Get(cache.XCacheHeader))
+	assert.Equal(t, 3, calls)
+
+	w = serve(http.MethodHead, "/a")
+	assert.Equal(t, cache.CacheMiss, w.Header().Get(cache.XCacheHeader))
+	w = serve(http.MethodHead, "/a")
+	assert.Equal(t, cache.CacheHit, w.Header().Get(cache.XCacheHeader))
+	assert.Empty(t, w.Body.String())
+
+	for _, path := range []string{"/private", "/error"} {
+		serve(http.MethodGet, path)
+		w = serve(http.MethodGet, path)
+		assert.Equal(t, cache.CacheMiss, w.Header().Get(cache.XCacheHeader), path)
+	}
+
+	w = serve(http.MethodPost, "/a")
+	assert.Empty(t, w.Header().Get(cache.XCacheHeader))
+
+	serve(http.MethodGet, "/max-age")
+	clk.Add(29 * time.Second)
+	assert.Equal(t, cache.CacheHit, serve(http.MethodGet, "/max-age").Header().Get(cache.XCacheHeader), "s-maxage")
+	clk.Add(time.Second)
+	assert.Equal(t, cache.CacheMiss, serve(http.MethodGet, "/max-age").Header().Get(cache.XCacheHeader))
+
+	assert.Equal(t, 3, c.InvalidateTag("path:/a"), "GET, GET fr and HEAD")
+	assert.Equal(t, cache.CacheMiss, serve(http.MethodGet, "/a").Header().Get(cache.XCacheHeader))
+}
+
+func TestResponseCacheCredentials(t *testing.T) {
+	c := cache.NewResponseCache(cache.Options[string, *cache.Response]{TTL: time.Minute, Resolution: -1})
+	h := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/max-age":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/public":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		}
+
+		_, _ = w.Write([]byte(r.Header.Get("Authorization") + r.Header.Get("Cookie")))
+	}))
+
+	serve := func(path, header, value string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set(header, value)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	for _, path := range []string{"/me", "/max-age"} {
+		serve(path, "Authorization", "Bearer a")
+		w := serve(path, "Authorization", "Bearer b")
+		assert.Equal(t, cache.CacheMiss, w.Header().Get(cache.XCacheHeader), path)
+		assert.Equal(t, "Bearer b", w.Body.String(), path)
+	}
+
+	serve("/public", "Authorization", "Bearer a")
+	w := serve("/public", "Authorization", "Bearer b")
+	assert.Equal(t, cache.CacheHit, w.Header().Get(cache.XCacheHeader), "public")
+	assert.Equal(t, "Bearer a", w.Body.String(), "public")
+
+	serve("/session", "Cookie", "session=a")
+	w = serve("/session", "Cookie", "session=b")
+	assert.Equal(t, cache.CacheBypass, w.Header().Get(cache.XCacheHeader))
+	assert.Equal(t, "session=b", w.Body.String())
+
+	c.Vary = []string{"Authorization"}
+
+	serve("/varied", "Authorization", "Bearer a")
+	assert.Equal(t, "Bearer b", serve("/varied", "Authorization", "Bearer b").Body.String())
+	assert.Equal(t, cache.CacheHit, serve("/varied", "Authorization", "Bearer a").Header().Get(cache.XCacheHeader))
+}
+
+func TestResponseCacheMaxSize(t *testing.T) {
+	c := cache.NewResponseCache(cache.Options[string, *cache.Response]{TTL: time.Minute, Resolution: -1})
+	c.MaxSize = 4
+
+	h := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+
+	for _, tt := range []struct {
+		path string
+		want string
+	}{{"/abc", cache.CacheHit}, {"/abcd", cache.CacheMiss}} {
+		var w *httptest.ResponseRecorder
+
+		for range 2 {
+			w = httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		}
+
+		assert.Equal(t, tt.want, w.Header().Get(cache.XCacheHeader), tt.path)
+		assert.Equal(t, tt.path, w.Body.String())
+	}
+}
+
+func TestResponseCacheLog(t *testing.T) {
+	c := cache.NewResponseCache(cache.Options[string, *cache.Response]{TTL: time.Minute, Resolution: -1})
+	h := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		zerolog.Ctx(r.Context()).Info().Msg("served")
+	}))
+
+	var buf bytes.Buffer
+
+	log := zerolog.New(&buf)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r = r.WithContext(log.WithContext(r.Context()))
+
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	require.JSONEq(t, `{"level":"info","http.cache":"MISS","message":"served"}`, buf.String())
+}
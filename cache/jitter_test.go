@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestJitter(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Minute, Jitter: 0.5, Resolution: -1, Clock: clk.Now})
+
+	ctx := context.Background()
+	loader := func(_ context.Context, k string) (int, error) { return len(k), nil }
+
+	for i := range 100 {
+		k := strconv.Itoa(i)
+
+		switch i % 3 {
+		case 0:
+			c.Set(k, i)
+		case 1:
+			c.SetWithTTL(k, i, time.Minute)
+		default:
+			_, err := c.GetOrLoad(ctx, k, loader)
+			require.NoError(t, err)
+		}
+	}
+
+	clk.Add(30*time.Second - time.Nanosecond)
+	assert.Equal(t, 100, len(c.Keys()), "shortened by at most half")
+
+	clk.Add(15 * time.Second)
+
+	n := len(c.Keys())
+	assert.Positive(t, n, "spread over the jitter window")
+	assert.Less(t, n, 100)
+
+	clk.Add(15 * time.Second)
+	assert.Zero(t, len(c.Keys()))
+}
+
+func TestJitterDisabled(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Minute, Resolution: -1, Clock: clk.Now})
+
+	for i := range 10 {
+		c.Set(strconv.Itoa(i), i)
+	}
+
+	clk.Add(time.Minute - time.Nanosecond)
+	assert.Equal(t, 10, len(c.Keys()))
+
+	clk.Add(time.Nanosecond)
+	assert.Zero(t, len(c.Keys()))
+}
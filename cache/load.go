@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrLoaderPanic is returned by GetOrLoad when the loader panics.
+	ErrLoaderPanic = errors.New("cache loader panic")
+	// ErrNotFound is returned (or wrapped) by loaders for missing values, cached for Options.NotFoundTTL.
+	ErrNotFound = errors.New("not found")
+)
+
+// call is an in-flight load shared by concurrent GetOrLoad misses of a key.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// invalidated is set when the key is set or deleted during the load, the result is then not cached.
+	invalidated bool
+}
+
+// failure is a cached loader error, see Options.ErrorTTL and Options.NotFoundTTL.
+type failure struct {
+	err     error
+	expires time.Time
+}
+
+// GetOrLoad returns the cached value of k, or loads it with loader and caches it with the default TTL.  Concurrent
+// misses for the same key share one loader call.
+//
+// The loader runs with the values of the ctx of the first caller but is not canceled with it, each caller stops
+// waiting when its own ctx is done while the load completes for the others.  Loader errors are returned to all
+// waiting callers, and cached for ErrorTTL if set.  Errors wrapping ErrNotFound are cached for NotFoundTTL
+// instead, and are not counted as load errors.
+//
+// Stale entries (see StaleTTL) are returned immediately, refreshing them in the background.  Refresh errors keep the
+// stale entry, and are cached for ErrorTTL to delay the next refresh.  Entries within RefreshAhead of becoming stale
+// are refreshed the same way.  Keys set, deleted or invalidated during a load keep that newer state, the loaded result
+// is only returned to the waiting callers.
+func (c *TTL[K, V]) GetOrLoad(ctx context.Context, k K, loader LoaderFunc[K, V]) (V, error) {
+	var zero V
+
+	c.mu.Lock()
+
+	now := c.opts.Clock()
+
+	if e := c.lookup(k, now); e != nil {
+		c.stats.Hits++
+
+		if !e.fresh(now) || e.due(now, c.opts.RefreshAhead) {
+			c.refresh(ctx, k, loader, now)
+		}
+
+		c.unlock()
+
+		return e.value, nil
+	}
+
+	c.stats.Misses++
+
+	if f, ok := c.failures[k]; ok && now.Before(f.expires) {
+		c.unlock()
+
+		return zero, f.err
+	}
+
+	if err := ctx.Err(); err != nil {
+		c.unlock()
+
+		return zero, err //nolint:wrapcheck
+	}
+
+	cl, ok := c.calls[k]
+	if !ok {
+		cl = &call[V]{done: make(chan struct{})}
+		c.calls[k] = cl
+
+		go c.load(context.WithoutCancel(ctx), k, loader, cl, false)
+	}
+
+	c.unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		return zero, ctx.Err() //nolint:wrapcheck
+	}
+}
+
+// refresh starts a background load of k unless one is in flight, MaxRefreshes are running or a refresh recently
+// failed.  c.mu must be held.
+func (c *TTL[K, V]) refresh(ctx context.Context, k K, loader LoaderFunc[K, V], now time.Time) {
+	if _, ok := c.calls[k]; ok || c.refreshing >= c.opts.MaxRefreshes {
+		return
+	}
+
+	if f, ok := c.failures[k]; ok && now.Before(f.expires) {
+		return
+	}
+
+	cl := &call[V]{done: make(chan struct{})}
+	c.calls[k] = cl
+	c.refreshing++
+
+	go c.load(context.WithoutCancel(ctx), k, loader, cl, true)
+}
+
+func (c *TTL[K, V]) load(ctx context.Context, k K, loader LoaderFunc[K, V], cl *call[V], refresh bool) {
+	defer close(cl.done)
+
+	defer func() {
+		if r := recover(); r != nil {
+			cl.err = fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+		}
+
+		c.mu.Lock()
+		defer c.unlock()
+
+		delete(c.calls, k)
+
+		c.stats.Loads++
+
+		if refresh {
+			c.refreshing--
+		}
+
+		now := c.opts.Clock()
+
+		switch {
+		case cl.invalidated:
+		case cl.err == nil:
+			c.set(k, cl.value, c.opts.TTL, now)
+		case c.opts.NotFoundTTL > 0 && errors.Is(cl.err, ErrNotFound):
+			c.notFound(k, cl.err, now)
+		case c.opts.ErrorTTL > 0:
+			c.failures[k] = failure{err: cl.err, expires: now.Add(c.opts.ErrorTTL)}
+
+			fallthrough
+		default:
+			c.stats.LoadErrors++
+		}
+	}()
+
+	cl.value, cl.err = loader(ctx, k)
+}
+
+// invalidateLoad keeps the in-flight load of k, if any, from caching its older result.  c.mu must be held.
+func (c *TTL[K, V]) invalidateLoad(k K) {
+	if cl, ok := c.calls[k]; ok {
+		cl.invalidated = true
+	}
+}
+
+// SetNotFound caches k as missing for NotFoundTTL, e.g. after deleting the value from the source.  GetOrLoad then
+// returns ErrNotFound without loading, until the key is set or NotFoundTTL passes.
+func (c *TTL[K, V]) SetNotFound(k K) {
+	if c.opts.NotFoundTTL <= 0 {
+		c.Delete(k)
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.notFound(k, ErrNotFound, c.opts.Clock())
+}
+
+// notFound removes k and caches err for NotFoundTTL.  c.mu must be held.
+func (c *TTL[K, V]) notFound(k K, err error, now time.Time) {
+	if e, ok := c.items[k]; ok {
+		c.remove(e, Deleted)
+	}
+
+	c.failures[k] = failure{err: err, expires: now.Add(c.opts.NotFoundTTL)}
+}
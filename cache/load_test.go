@@ -0,0 +1,188 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+type ctxKey struct{}
+
+func TestGetOrLoadSingleflight(t *testing.T) {
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Minute, Resolution: -1})
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	loader := func(ctx context.Context, _ string) (int, error) {
+		calls.Add(1)
+		<-release
+
+		assert.Equal(t, "value", ctx.Value(ctxKey{}), "context values propagate")
+
+		return 42, nil
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	var wg sync.WaitGroup
+
+	results := make([]int, 100)
+
+	for i := range results {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v, err := c.GetOrLoad(ctx, "a", loader)
+			assert.NoError(t, err)
+
+			results[i] = v
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	for _, v := range results {
+		assert.Equal(t, 42, v)
+	}
+}
+
+func TestGetOrLoadCanceledWaiter(t *testing.T) {
+	c := cache.NewTTL(cache.Options[string, int]{Resolution: -1})
+
+	release := make(chan struct{})
+	loader := func(ctx context.Context, _ string) (int, error) {
+		<-release
+
+		return 1, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+
+	go func() {
+		_, err := c.GetOrLoad(ctx, "a", loader)
+		done <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		_, ok := c.Get("a")
+
+		return ok
+	}, time.Second, time.Millisecond, "load completes after the caller gave up")
+}
+
+func TestGetOrLoadErrors(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{Resolution: -1, ErrorTTL: time.Second, Clock: clk.Now})
+
+	boom := errors.New("boom")
+	calls := 0
+	loader := func(context.Context, string) (int, error) {
+		calls++
+
+		return 0, boom
+	}
+
+	_, err := c.GetOrLoad(context.Background(), "a", loader)
+	require.ErrorIs(t, err, boom)
+
+	_, err = c.GetOrLoad(context.Background(), "a", loader)
+	require.ErrorIs(t, err, boom)
+	assert.Equal(t, 1, calls, "error cached")
+
+	clk.Add(time.Second)
+
+	_, err = c.GetOrLoad(context.Background(), "a", loader)
+	require.ErrorIs(t, err, boom)
+	assert.Equal(t, 2, calls, "error expired")
+
+	c.Set("a", 1)
+
+	v, err := c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "Set replaces the cached error")
+
+	_, err = c.GetOrLoad(context.Background(), "p", func(context.Context, string) (int, error) { panic("oops") })
+	require.ErrorIs(t, err, cache.ErrLoaderPanic)
+	assert.ErrorContains(t, err, "oops")
+}
+
+func TestGetOrLoadInvalidated(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Minute, StaleTTL: time.Hour, Resolution: -1, Clock: clk.Now})
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	loader := func(context.Context, string) (int, error) {
+		started <- struct{}{}
+		<-release
+
+		return 1, nil
+	}
+
+	done := make(chan int)
+
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), "a", loader)
+		done <- v
+	}()
+
+	<-started
+	c.Delete("a")
+	release <- struct{}{}
+	assert.Equal(t, 1, <-done, "waiters get the loaded value")
+
+	_, ok := c.Get("a")
+	assert.False(t, ok, "delete during the load is kept")
+
+	go func() {
+		v, _ := c.GetOrLoad(context.Background(), "b", loader)
+		done <- v
+	}()
+
+	<-started
+	c.Set("b", 2)
+	release <- struct{}{}
+	<-done
+
+	v, _ := c.Get("b")
+	assert.Equal(t, 2, v, "set during the load is kept")
+
+	c.Set("c", 2)
+	clk.Add(time.Minute)
+
+	v, err := c.GetOrLoad(context.Background(), "c", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "stale value served")
+
+	<-started
+	c.Set("c", 3)
+	release <- struct{}{}
+
+	time.Sleep(10 * time.Millisecond)
+
+	v, _ = c.Get("c")
+	assert.Equal(t, 3, v, "set during the refresh is kept")
+}
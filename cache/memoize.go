@@ -0,0 +1,32 @@
+package cache
+
+import "context"
+
+// Memoize returns fn caching its results by argument in a TTL cache configured by opts, e.g. for an expensive
+// lookup:
+//
+//	var getUser = cache.Memoize(db.GetUser, cache.Options[int, User]{TTL: time.Minute})
+//
+// Concurrent calls with the same argument share one call of fn, and errors are returned without being cached unless
+// ErrorTTL or NotFoundTTL is set, see TTL.GetOrLoad.  Use a struct argument for functions of several arguments.
+func Memoize[A comparable, R any](fn func(ctx context.Context, a A) (R, error), opts Options[A, R],
+) func(ctx context.Context, a A) (R, error) {
+	c := NewTTL(opts)
+
+	return func(ctx context.Context, a A) (R, error) {
+		return c.GetOrLoad(ctx, a, fn)
+	}
+}
+
+// MemoizeKey is Memoize for arguments that are not comparable or only partly identify the result, caching by key(a).
+func MemoizeKey[A any, K comparable, R any](fn func(ctx context.Context, a A) (R, error), key func(a A) K,
+	opts Options[K, R],
+) func(ctx context.Context, a A) (R, error) {
+	c := NewTTL(opts)
+
+	return func(ctx context.Context, a A) (R, error) {
+		return c.GetOrLoad(ctx, key(a), func(ctx context.Context, _ K) (R, error) {
+			return fn(ctx, a)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func ExampleMemoize() {
+	square := cache.Memoize(func(_ context.Context, n int) (int, error) {
+		fmt.Println("computing", n)
+
+		return n * n, nil
+	}, cache.Options[int, int]{TTL: time.Minute, Resolution: -1})
+
+	for range 2 {
+		v, _ := square(context.Background(), 3)
+		fmt.Println(v)
+	}
+	// Output:
+	// computing 3
+	// 9
+	// 9
+}
+
+func TestMemoize(t *testing.T) {
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	fn := cache.Memoize(func(_ context.Context, id int) (user, error) {
+		calls.Add(1)
+		<-release
+
+		if id < 0 {
+			return user{}, errors.New("boom")
+		}
+
+		return user{ID: id}, nil
+	}, cache.Options[int, user]{TTL: time.Minute, Resolution: -1})
+
+	var wg sync.WaitGroup
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			u, err := fn(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, u.ID)
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load(), "singleflight")
+
+	for range 2 {
+		_, err := fn(context.Background(), -1)
+		require.EqualError(t, err, "boom")
+	}
+
+	assert.Equal(t, int32(3), calls.Load(), "errors are not cached")
+}
+
+func TestMemoizeKey(t *testing.T) {
+	type query struct {
+		Tags []string
+	}
+
+	calls := 0
+	fn := cache.MemoizeKey(func(_ context.Context, q query) (string, error) {
+		calls++
+
+		return strings.Join(q.Tags, "+"), nil
+	}, func(q query) string { return strings.Join(q.Tags, ",") }, cache.Options[string, string]{Resolution: -1})
+
+	for range 2 {
+		v, err := fn(context.Background(), query{Tags: []string{"a", "b"}})
+		require.NoError(t, err)
+		assert.Equal(t, "a+b", v)
+	}
+
+	v, err := fn(context.Background(), query{Tags: []string{"c"}})
+	require.NoError(t, err)
+	assert.Equal(t, "c", v)
+	assert.Equal(t, 2, calls)
+}
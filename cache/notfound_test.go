@@ -0,0 +1,120 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestNotFound(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{
+		TTL: time.Hour, NotFoundTTL: time.Minute, Resolution: -1, Clock: clk.Now,
+	})
+
+	ctx := context.Background()
+	calls := 0
+	db := map[string]int{}
+	loader := func(_ context.Context, k string) (int, error) {
+		calls++
+
+		v, ok := db[k]
+		if !ok {
+			return 0, fmt.Errorf("user %s: %w", k, cache.ErrNotFound)
+		}
+
+		return v, nil
+	}
+
+	for range 3 {
+		_, err := c.GetOrLoad(ctx, "a", loader)
+		require.ErrorIs(t, err, cache.ErrNotFound)
+		require.EqualError(t, err, "user a: not found")
+	}
+
+	assert.Equal(t, 1, calls, "cached")
+	assert.Equal(t, uint64(0), c.Stats().LoadErrors)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+
+	db["a"] = 1
+	clk.Add(time.Minute)
+
+	v, err := c.GetOrLoad(ctx, "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "loaded again after NotFoundTTL")
+	assert.Equal(t, 2, calls)
+
+	c.SetNotFound("a")
+
+	_, err = c.GetOrLoad(ctx, "a", loader)
+	require.ErrorIs(t, err, cache.ErrNotFound)
+	assert.Equal(t, 2, calls)
+
+	c.Set("a", 2)
+
+	v, err = c.GetOrLoad(ctx, "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "set values replace the negative entry")
+}
+
+func TestNotFoundDisabled(t *testing.T) {
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Hour, Resolution: -1})
+
+	calls := 0
+	loader := func(context.Context, string) (int, error) {
+		calls++
+
+		return 0, cache.ErrNotFound
+	}
+
+	for range 2 {
+		_, err := c.GetOrLoad(context.Background(), "a", loader)
+		require.ErrorIs(t, err, cache.ErrNotFound)
+	}
+
+	assert.Equal(t, 2, calls, "not cached without NotFoundTTL")
+
+	c.Set("a", 1)
+	c.SetNotFound("a")
+
+	_, ok := c.Get("a")
+	assert.False(t, ok, "deleted")
+}
+
+func TestNotFoundRefresh(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{
+		TTL: time.Minute, StaleTTL: time.Hour, NotFoundTTL: time.Minute, Resolution: -1, Clock: clk.Now,
+	})
+
+	c.Set("a", 1)
+	clk.Add(2 * time.Minute)
+
+	done := make(chan struct{})
+	loader := func(context.Context, string) (int, error) {
+		defer close(done)
+
+		return 0, cache.ErrNotFound
+	}
+
+	v, err := c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "stale")
+
+	<-done
+
+	assert.Eventually(t, func() bool {
+		_, err = c.GetOrLoad(context.Background(), "a", loader)
+
+		return err != nil
+	}, time.Second, time.Millisecond, "removed when the refresh finds it missing")
+	require.ErrorIs(t, err, cache.ErrNotFound)
+}
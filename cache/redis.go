@@ -0,0 +1,435 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRedisProtocol is returned for malformed Redis replies.
+	ErrRedisProtocol = errors.New("redis protocol error")
+	// ErrRedisURL is returned by ParseRedisURL for invalid URLs.
+	ErrRedisURL = errors.New("invalid redis url")
+)
+
+// RedisError is an error reply of the Redis server.
+type RedisError string
+
+// Error returns the server message.
+func (e RedisError) Error() string { return "redis: " + string(e) }
+
+const (
+	// DefaultRedisPoolSize is the number of idle connections kept by Redis when PoolSize is 0.
+	DefaultRedisPoolSize = 10
+	// DefaultRedisTimeout bounds the dial and I/O of each command when Redis.Timeout is 0.
+	DefaultRedisTimeout = 5 * time.Second
+	// DefaultRedisMaxBulk is the largest bulk reply, and array reply length, accepted when Redis.MaxBulk is 0, the
+	// default proto-max-bulk-len of Redis.
+	DefaultRedisMaxBulk = 512 << 20
+)
+
+// Redis is a minimal Redis client implementing Store, using the RESP protocol over pooled TCP connections.
+type Redis struct {
+	// Addr is the host:port of the server.
+	Addr string
+	// Username and Password authenticate connections if Password is set.
+	Username, Password string
+	// DB is the selected database.
+	DB int
+	// PoolSize is the number of idle connections kept, defaults to DefaultRedisPoolSize.
+	PoolSize int
+	// Dialer dials connections, the zero value uses net.Dialer defaults and Timeout.
+	Dialer net.Dialer
+	// Timeout bounds the dial and I/O of each command unless the ctx deadline is sooner, defaults to
+	// DefaultRedisTimeout, negative disables it.  Subscribe waits for messages without a timeout.
+	Timeout time.Duration
+	// MaxBulk is the largest bulk reply, and array reply length, accepted, defaults to DefaultRedisMaxBulk.  Larger
+	// replies fail with ErrRedisProtocol.
+	MaxBulk int
+
+	pool     chan *redisConn
+	poolOnce sync.Once
+}
+
+// NewRedis returns a client of the server at addr.
+func NewRedis(addr string) *Redis {
+	return &Redis{Addr: addr}
+}
+
+// ParseRedisURL returns a client configured by a redis://[user:password@]host[:port][/db] URL.
+func ParseRedisURL(s string) (*Redis, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", ErrRedisURL, s)
+	}
+
+	r := NewRedis(u.Host)
+	if u.Port() == "" {
+		r.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	if u.User != nil {
+		r.Username = u.User.Username()
+		r.Password, _ = u.User.Password()
+	}
+
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if r.DB, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrRedisURL, s)
+		}
+	}
+
+	return r, nil
+}
+
+// Get returns the value of key.
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := r.Do(ctx, "GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+
+	b, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: GET reply %T", ErrRedisProtocol, reply)
+	}
+
+	return b, true, nil
+}
+
+// Set sets the value of key expiring after ttl.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+
+	_, err := r.Do(ctx, args...)
+
+	return err
+}
+
+// Delete deletes key.
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	_, err := r.Do(ctx, "DEL", key)
+
+	return err
+}
+
+// Publish sends message to the subscribers of channel.
+func (r *Redis) Publish(ctx context.Context, channel, message string) error {
+	_, err := r.Do(ctx, "PUBLISH", channel, message)
+
+	return err
+}
+
+// Subscribe calls fn with the messages published to channel on a dedicated connection, until ctx is done or the
+// connection fails.  Returns nil when ctx is done.
+func (r *Redis) Subscribe(ctx context.Context, channel string, fn func(message string)) error {
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Time{})
+
+	if err = conn.send("SUBSCRIBE", channel); err != nil {
+		return err
+	}
+
+	for {
+		reply, err := conn.receive()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		msg, ok := reply.([]any)
+		if !ok || len(msg) != 3 {
+			return fmt.Errorf("%w: SUBSCRIBE reply %v", ErrRedisProtocol, reply)
+		}
+
+		if kind, _ := msg[0].([]byte); string(kind) != "message" {
+			continue
+		}
+
+		if b, ok := msg[2].([]byte); ok {
+			fn(string(b))
+		}
+	}
+}
+
+// Do sends a command and returns the reply: nil, string (status), int64, []byte (bulk) or []any (array).  Error
+// replies are returned as RedisError.
+func (r *Redis) Do(ctx context.Context, args ...string) (any, error) {
+	conn, err := r.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(ctx, args...)
+
+	var redisErr RedisError
+	if conn.broken || (err != nil && !errors.As(err, &redisErr)) {
+		_ = conn.Close()
+
+		return reply, err
+	}
+
+	r.put(conn)
+
+	return reply, err
+}
+
+// Close closes the idle connections.
+func (r *Redis) Close() error {
+	r.initPool()
+
+	for {
+		select {
+		case conn := <-r.pool:
+			_ = conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (r *Redis) initPool() {
+	r.poolOnce.Do(func() {
+		size := r.PoolSize
+		if size <= 0 {
+			size = DefaultRedisPoolSize
+		}
+
+		r.pool = make(chan *redisConn, size)
+	})
+}
+
+func (r *Redis) get(ctx context.Context) (*redisConn, error) {
+	r.initPool()
+
+	select {
+	case conn := <-r.pool:
+		return conn, nil
+	default:
+	}
+
+	return r.dial(ctx)
+}
+
+func (r *Redis) put(conn *redisConn) {
+	select {
+	case r.pool <- conn:
+	default:
+		_ = conn.Close()
+	}
+}
+
+// dial opens an authenticated connection to the selected DB.
+func (r *Redis) dial(ctx context.Context) (*redisConn, error) {
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = DefaultRedisTimeout
+	}
+
+	maxBulk := r.MaxBulk
+	if maxBulk <= 0 {
+		maxBulk = DefaultRedisMaxBulk
+	}
+
+	dialer := r.Dialer
+	if dialer.Timeout == 0 && timeout > 0 {
+		dialer.Timeout = timeout
+	}
+
+	c, err := dialer.DialContext(ctx, "tcp", r.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis dial: %w", err)
+	}
+
+	conn := &redisConn{Conn: c, r: bufio.NewReader(c), timeout: timeout, maxBulk: maxBulk}
+
+	if r.Password != "" {
+		args := []string{"AUTH", r.Password}
+		if r.Username != "" {
+			args = []string{"AUTH", r.Username, r.Password}
+		}
+
+		if _, err = conn.do(ctx, args...); err != nil {
+			_ = conn.Close()
+
+			return nil, err
+		}
+	}
+
+	if r.DB != 0 {
+		if _, err = conn.do(ctx, "SELECT", strconv.Itoa(r.DB)); err != nil {
+			_ = conn.Close()
+
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+type redisConn struct {
+	net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+	maxBulk int
+
+	// broken is set once a canceled context may have cut a command short, the connection must not be reused.
+	broken bool
+}
+
+// do sends a command and reads its reply within the timeout or the sooner ctx deadline.  Canceling ctx unblocks the
+// call by expiring the connection deadline, which leaves the connection unusable.
+func (c *redisConn) do(ctx context.Context, args ...string) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if limit := time.Now().Add(c.timeout); c.timeout > 0 && (!ok || limit.Before(deadline)) {
+		deadline = limit
+	}
+
+	_ = c.SetDeadline(deadline)
+
+	stop := context.AfterFunc(ctx, func() { _ = c.SetDeadline(time.Now()) })
+
+	err := c.send(args...)
+
+	var reply any
+	if err == nil {
+		reply, err = c.receive()
+	}
+
+	if !stop() {
+		c.broken = true
+
+		if err != nil {
+			err = fmt.Errorf("%w: %w", context.Cause(ctx), err)
+		}
+	}
+
+	return reply, err
+}
+
+func (c *redisConn) send(args ...string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+
+	if _, err := io.WriteString(c.Conn, b.String()); err != nil {
+		return fmt.Errorf("redis write: %w", err)
+	}
+
+	return nil
+}
+
+func (c *redisConn) receive() (any, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis read: %w", err)
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, ErrRedisProtocol
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrRedisProtocol, line)
+		}
+
+		return n, nil
+	case '$':
+		return c.bulk(line)
+	case '*':
+		return c.array(line)
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrRedisProtocol, line)
+}
+
+func (c *redisConn) bulk(line string) (any, error) {
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrRedisProtocol, line)
+	}
+
+	if n < 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	if n > c.maxBulk {
+		return nil, fmt.Errorf("%w: bulk length %d exceeds %d", ErrRedisProtocol, n, c.maxBulk)
+	}
+
+	b := make([]byte, n+2)
+	if _, err = io.ReadFull(c.r, b); err != nil {
+		return nil, fmt.Errorf("redis read: %w", err)
+	}
+
+	return b[:n], nil
+}
+
+func (c *redisConn) array(line string) (any, error) {
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrRedisProtocol, line)
+	}
+
+	if n < 0 {
+		return nil, nil //nolint:nilnil
+	}
+
+	if n > c.maxBulk {
+		return nil, fmt.Errorf("%w: array length %d exceeds %d", ErrRedisProtocol, n, c.maxBulk)
+	}
+
+	out := make([]any, n)
+
+	for i := range out {
+		v, err := c.receive()
+
+		var redisErr RedisError
+		if err != nil && !errors.As(err, &redisErr) {
+			return nil, err
+		}
+
+		if err != nil {
+			v = err
+		}
+
+		out[i] = v
+	}
+
+	return out, nil
+}
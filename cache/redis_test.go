@@ -0,0 +1,439 @@
+package cache_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+// redisServer is a minimal in-memory RESP server for the commands used by the cache package.
+type redisServer struct {
+	addr     string
+	password string
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	subs    map[string][]net.Conn
+	sets    map[string]map[string]bool
+	conns   int
+}
+
+func newRedisServer(t *testing.T, password string) *redisServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	s := &redisServer{
+		addr:     l.Addr().String(),
+		password: password,
+		values:   map[string]string{},
+		expires:  map[string]time.Time{},
+		subs:     map[string][]net.Conn{},
+		sets:     map[string]map[string]bool{},
+	}
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			s.mu.Lock()
+			s.conns++
+			s.mu.Unlock()
+
+			go s.serve(c)
+		}
+	}()
+
+	return s
+}
+
+func (s *redisServer) serve(c net.Conn) {
+	defer c.Close()
+
+	r := bufio.NewReader(c)
+	authed := s.password == ""
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+
+		switch {
+		case cmd == "AUTH":
+			authed = args[len(args)-1] == s.password
+			if !authed {
+				fmt.Fprint(c, "-WRONGPASS invalid password\r\n")
+
+				continue
+			}
+
+			fmt.Fprint(c, "+OK\r\n")
+		case !authed:
+			fmt.Fprint(c, "-NOAUTH Authentication required.\r\n")
+		case cmd == "SUBSCRIBE":
+			s.mu.Lock()
+			s.subs[args[1]] = append(s.subs[args[1]], c)
+			s.mu.Unlock()
+
+			fmt.Fprintf(c, "*3\r\n$9\r\nsubscribe\r\n%s:1\r\n", bulk(args[1]))
+		default:
+			_, _ = io.WriteString(c, s.exec(cmd, args[1:]))
+		}
+	}
+}
+
+func (s *redisServer) exec(cmd string, args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch cmd {
+	case "SELECT", "PING":
+		return "+OK\r\n"
+	case "GET":
+		v, ok := s.values[args[0]]
+		if exp, found := s.expires[args[0]]; found && time.Now().After(exp) {
+			ok = false
+		}
+
+		if !ok {
+			return "$-1\r\n"
+		}
+
+		return bulk(v)
+	case "SET":
+		s.values[args[0]] = args[1]
+		delete(s.expires, args[0])
+
+		if len(args) == 4 && strings.EqualFold(args[2], "PX") {
+			ms, _ := strconv.Atoi(args[3])
+			s.expires[args[0]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+
+		return "+OK\r\n"
+	case "DEL":
+		n := 0
+
+		for _, k := range args {
+			if _, ok := s.values[k]; ok || s.sets[k] != nil {
+				n++
+			}
+
+			delete(s.values, k)
+			delete(s.sets, k)
+		}
+
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SADD":
+		if s.sets[args[0]] == nil {
+			s.sets[args[0]] = map[string]bool{}
+		}
+
+		for _, m := range args[1:] {
+			s.sets[args[0]][m] = true
+		}
+
+		return ":1\r\n"
+	case "RENAME":
+		set, ok := s.sets[args[0]]
+		if !ok {
+			return "-ERR no such key\r\n"
+		}
+
+		delete(s.sets, args[0])
+		s.sets[args[1]] = set
+
+		return "+OK\r\n"
+	case "SMEMBERS":
+		out := fmt.Sprintf("*%d\r\n", len(s.sets[args[0]]))
+		for m := range s.sets[args[0]] {
+			out += bulk(m)
+		}
+
+		return out
+	case "PEXPIRE", "PERSIST":
+		return ":1\r\n"
+	case "PUBLISH":
+		for _, c := range s.subs[args[0]] {
+			fmt.Fprintf(c, "*3\r\n$7\r\nmessage\r\n%s%s", bulk(args[0]), bulk(args[1]))
+		}
+
+		return fmt.Sprintf(":%d\r\n", len(s.subs[args[0]]))
+	}
+
+	return "-ERR unknown command '" + cmd + "'\r\n"
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+// subscribers returns the number of subscriptions to channel.
+func (s *redisServer) subscribers(channel string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.subs[channel])
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+
+	for i := range args {
+		if line, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+
+		size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+
+		b := make([]byte, size+2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(b[:size])
+	}
+
+	return args, nil
+}
+
+func TestRedis(t *testing.T) {
+	s := newRedisServer(t, "secret")
+
+	r, err := cache.ParseRedisURL("redis://:secret@" + s.addr + "/2")
+	require.NoError(t, err)
+
+	defer r.Close()
+
+	ctx := context.Background()
+
+	_, ok, err := r.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, r.Set(ctx, "a", []byte("1\r\n2"), 0))
+	require.NoError(t, r.Set(ctx, "b", []byte("x"), time.Millisecond))
+
+	v, ok, err := r.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1\r\n2"), v)
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok, err = r.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.False(t, ok, "expired")
+
+	require.NoError(t, r.Delete(ctx, "a"))
+
+	_, ok, _ = r.Get(ctx, "a")
+	assert.False(t, ok)
+
+	_, err = r.Do(ctx, "NOPE")
+
+	var redisErr cache.RedisError
+
+	require.ErrorAs(t, err, &redisErr)
+	require.EqualError(t, err, "redis: ERR unknown command 'NOPE'")
+
+	_, err = r.Do(ctx, "PING")
+	require.NoError(t, err)
+
+	s.mu.Lock()
+	assert.Equal(t, 1, s.conns, "connection reused after error replies")
+	s.mu.Unlock()
+
+	bad := cache.NewRedis(s.addr)
+	_, _, err = bad.Get(ctx, "a")
+	require.EqualError(t, err, "redis: NOAUTH Authentication required.")
+
+	bad = cache.NewRedis(s.addr)
+	bad.Password = "wrong"
+	_, _, err = bad.Get(ctx, "a")
+	require.EqualError(t, err, "redis: WRONGPASS invalid password")
+
+	_, _, err = cache.NewRedis("127.0.0.1:1").Get(ctx, "a")
+	require.ErrorContains(t, err, "redis dial")
+}
+
+func TestRedisCancel(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer l.Close()
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() { _, _ = io.Copy(io.Discard, c) }()
+		}
+	}()
+
+	r := cache.NewRedis(l.Addr().String())
+	defer r.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, _, err = r.Get(ctx, "a")
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRedisLimits(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer l.Close()
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				r := bufio.NewReader(c)
+
+				for {
+					args, err := readCommand(r)
+					if err != nil {
+						return
+					}
+
+					if args[0] == "GET" {
+						_, _ = io.WriteString(c, "$1000000\r\n")
+					}
+				}
+			}()
+		}
+	}()
+
+	r := cache.NewRedis(l.Addr().String())
+	r.Timeout = 50 * time.Millisecond
+	r.MaxBulk = 1024
+
+	defer r.Close()
+
+	start := time.Now()
+	_, err = r.Do(context.Background(), "PING")
+
+	var netErr net.Error
+
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+	assert.Less(t, time.Since(start), time.Second, "the Timeout bounds commands without a ctx deadline")
+
+	_, _, err = r.Get(context.Background(), "a")
+	require.ErrorIs(t, err, cache.ErrRedisProtocol)
+	assert.ErrorContains(t, err, "bulk length 1000000 exceeds 1024")
+}
+
+func TestParseRedisURL(t *testing.T) {
+	r, err := cache.ParseRedisURL("redis://user:pw@cache/3")
+	require.NoError(t, err)
+	assert.Equal(t, "cache:6379", r.Addr)
+	assert.Equal(t, "user", r.Username)
+	assert.Equal(t, "pw", r.Password)
+	assert.Equal(t, 3, r.DB)
+
+	for _, s := range []string{"http://cache", "redis://", "redis://cache/x"} {
+		_, err = cache.ParseRedisURL(s)
+		assert.ErrorIs(t, err, cache.ErrRedisURL, s)
+	}
+}
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestRemote(t *testing.T) {
+	s := newRedisServer(t, "")
+	ctx := context.Background()
+
+	var c cache.Loading[int, user] = cache.NewRemote[int, user](cache.NewRedis(s.addr), "user:", time.Minute)
+
+	calls := 0
+	loader := func(_ context.Context, id int) (user, error) {
+		calls++
+
+		if id < 0 {
+			return user{}, errors.New("boom")
+		}
+
+		return user{ID: id, Name: "bob"}, nil
+	}
+
+	u, err := c.GetOrLoad(ctx, 1, loader)
+	require.NoError(t, err)
+	assert.Equal(t, user{1, "bob"}, u)
+
+	u, err = c.GetOrLoad(ctx, 1, loader)
+	require.NoError(t, err)
+	assert.Equal(t, user{1, "bob"}, u)
+	assert.Equal(t, 1, calls)
+
+	s.mu.Lock()
+	assert.JSONEq(t, `{"id":1,"name":"bob"}`, s.values["user:1"])
+	s.values["user:2"] = "not json"
+	s.mu.Unlock()
+
+	remote := c.(*cache.Remote[int, user])
+
+	_, _, err = remote.Get(ctx, 2)
+	require.ErrorContains(t, err, "cache decode user:2")
+
+	_, err = c.GetOrLoad(ctx, -1, loader)
+	require.EqualError(t, err, "boom")
+
+	require.NoError(t, remote.Delete(ctx, 1))
+
+	_, ok, err := remote.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	var local cache.Loading[int, user] = cache.NewTTL(cache.Options[int, user]{Resolution: -1})
+
+	u, err = local.GetOrLoad(ctx, 3, loader)
+	require.NoError(t, err)
+	assert.Equal(t, 3, u.ID, "same call site with an in-process cache")
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"time"
+)
+
+// DefaultShards is the number of shards used by NewSharded when shards is 0.
+const DefaultShards = 16
+
+// Sharded is a TTL cache split into shards by key hash, each with its own lock, to reduce contention under
+// concurrent access.  Eviction is per shard, MaxEntries, MaxBytes and MaxRefreshes are divided between the shards.
+type Sharded[K comparable, V any] struct {
+	shards []*TTL[K, V]
+	seed   maphash.Seed
+}
+
+// NewSharded creates a new thread safe cache with shards TTL caches configured by opts.  Call Close to stop the
+// expiration sweepers.
+func NewSharded[K comparable, V any](shards int, opts Options[K, V]) *Sharded[K, V] {
+	if shards <= 0 {
+		shards = DefaultShards
+	}
+
+	opts.MaxEntries = perShard(opts.MaxEntries, shards)
+	opts.MaxBytes = perShard(opts.MaxBytes, int64(shards))
+	opts.MaxRefreshes = perShard(opts.MaxRefreshes, shards)
+
+	c := &Sharded[K, V]{shards: make([]*TTL[K, V], shards), seed: maphash.MakeSeed()}
+	for i := range c.shards {
+		c.shards[i] = NewTTL(opts)
+	}
+
+	return c
+}
+
+func perShard[T int | int64](limit, shards T) T {
+	if limit <= 0 {
+		return limit
+	}
+
+	return max((limit+shards-1)/shards, 1)
+}
+
+// shard returns the shard of k.  Strings and integers are hashed directly, other keys by their fmt representation.
+func (c *Sharded[K, V]) shard(k K) *TTL[K, V] {
+	var h uint64
+
+	switch k := any(k).(type) {
+	case string:
+		h = maphash.String(c.seed, k)
+	case int:
+		h = mix(uint64(k)) //nolint:gosec
+	case int64:
+		h = mix(uint64(k)) //nolint:gosec
+	case uint64:
+		h = mix(k)
+	case int32:
+		h = mix(uint64(k)) //nolint:gosec
+	case uint32:
+		h = mix(uint64(k))
+	default:
+		h = maphash.String(c.seed, fmt.Sprint(k))
+	}
+
+	return c.shards[h%uint64(len(c.shards))]
+}
+
+// mix is the splitmix64 finalizer, spreading sequential integers across shards.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
+
+// Get gets an item from the cache, see TTL.Get.
+func (c *Sharded[K, V]) Get(k K) (V, bool) {
+	return c.shard(k).Get(k)
+}
+
+// Set sets any item to the cache with the default TTL, see TTL.Set.
+func (c *Sharded[K, V]) Set(k K, v V) {
+	c.shard(k).Set(k, v)
+}
+
+// SetWithTTL sets any item to the cache expiring after ttl, see TTL.SetWithTTL.
+func (c *Sharded[K, V]) SetWithTTL(k K, v V, ttl time.Duration) {
+	c.shard(k).SetWithTTL(k, v, ttl)
+}
+
+// GetOrLoad returns the cached value of k, or loads it with loader, see TTL.GetOrLoad.
+func (c *Sharded[K, V]) GetOrLoad(ctx context.Context, k K, loader LoaderFunc[K, V]) (V, error) {
+	return c.shard(k).GetOrLoad(ctx, k, loader)
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Sharded[K, V]) Delete(k K) {
+	c.shard(k).Delete(k)
+}
+
+// SetNotFound caches k as missing, see TTL.SetNotFound.
+func (c *Sharded[K, V]) SetNotFound(k K) {
+	c.shard(k).SetNotFound(k)
+}
+
+// Keys returns the keys of unexpired items, the order is indeterminate.
+func (c *Sharded[K, V]) Keys() []K {
+	var out []K
+
+	for _, s := range c.shards {
+		out = append(out, s.Keys()...)
+	}
+
+	return out
+}
+
+// Len returns the number of items, including expired items not yet swept.
+func (c *Sharded[K, V]) Len() int {
+	n := 0
+
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+
+	return n
+}
+
+// Clear resets the cache.
+func (c *Sharded[K, V]) Clear() {
+	for _, s := range c.shards {
+		s.Clear()
+	}
+}
+
+// DeleteExpired removes the expired items of all shards.
+func (c *Sharded[K, V]) DeleteExpired() {
+	for _, s := range c.shards {
+		s.DeleteExpired()
+	}
+}
+
+// Close stops the expiration sweepers, the cache remains usable.
+func (c *Sharded[K, V]) Close() {
+	for _, s := range c.shards {
+		s.Close()
+	}
+}
+
+// Stats returns the sum of the shard counters.
+func (c *Sharded[K, V]) Stats() Stats {
+	var out Stats
+
+	for _, s := range c.shards {
+		st := s.Stats()
+		out.Hits += st.Hits
+		out.Misses += st.Misses
+		out.Loads += st.Loads
+		out.LoadErrors += st.LoadErrors
+		out.Evictions += st.Evictions
+		out.Expirations += st.Expirations
+		out.Entries += st.Entries
+		out.Bytes += st.Bytes
+	}
+
+	return out
+}
@@ -0,0 +1,125 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+// Type assertions
+var (
+	_ cache.Cache[string, string] = cache.NewSharded(0, cache.Options[string, string]{})
+	_ cache.StatsProvider         = cache.NewSharded(0, cache.Options[string, string]{})
+)
+
+type point struct{ x, y int }
+
+func TestSharded(t *testing.T) {
+	clk := newClock()
+	c := cache.NewSharded(4, cache.Options[int, int]{TTL: time.Minute, Resolution: -1, Clock: clk.Now})
+
+	for i := range 100 {
+		c.Set(i, i*10)
+	}
+
+	assert.Equal(t, 100, c.Len())
+
+	v, ok := c.Get(42)
+	assert.True(t, ok)
+	assert.Equal(t, 420, v)
+
+	kk := c.Keys()
+	sort.Ints(kk)
+	assert.Len(t, kk, 100)
+	assert.Equal(t, 99, kk[99])
+
+	c.Delete(42)
+	_, ok = c.Get(42)
+	assert.False(t, ok)
+
+	v, err := c.GetOrLoad(context.Background(), 42, func(_ context.Context, k int) (int, error) { return k, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 42, v)
+
+	c.SetWithTTL(1000, 1, time.Hour)
+	clk.Add(time.Minute)
+	c.DeleteExpired()
+	assert.Equal(t, []int{1000}, c.Keys())
+
+	s := c.Stats()
+	assert.Equal(t, 1, s.Entries)
+	assert.Equal(t, uint64(100), s.Expirations)
+
+	c.Clear()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestShardedKeys(t *testing.T) {
+	strings := cache.NewSharded(8, cache.Options[string, int]{Resolution: -1, MaxEntries: 80})
+	points := cache.NewSharded(8, cache.Options[point, int]{Resolution: -1})
+
+	for i := range 1000 {
+		strings.Set(fmt.Sprint(i), i)
+		points.Set(point{i, -i}, i)
+	}
+
+	assert.LessOrEqual(t, strings.Len(), 80, "MaxEntries divided between shards")
+	assert.Greater(t, strings.Len(), 40, "keys spread across shards")
+
+	v, ok := points.Get(point{7, -7})
+	assert.True(t, ok)
+	assert.Equal(t, 7, v)
+}
+
+// Benchmarks compare the single lock TTL cache with the Sharded cache under 100+ concurrent goroutines:
+//
+//	go test -bench Concurrent -cpu 8 ./cache
+
+type benchCache interface {
+	Get(k int) (int, bool)
+	Set(k, v int)
+}
+
+func benchmarkConcurrent(b *testing.B, c benchCache) {
+	b.Helper()
+
+	for i := range 1024 {
+		c.Set(i, i)
+	}
+
+	procs := runtime.GOMAXPROCS(0)
+	b.SetParallelism((128 + procs - 1) / procs) // at least 128 goroutines
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63())) //nolint:gosec
+
+		for pb.Next() {
+			k := r.Intn(1024)
+			if k%10 == 0 {
+				c.Set(k, k)
+			} else {
+				c.Get(k)
+			}
+		}
+	})
+}
+
+func BenchmarkConcurrentTTL(b *testing.B) {
+	c := cache.NewTTL(cache.Options[int, int]{Resolution: -1})
+	benchmarkConcurrent(b, c)
+}
+
+func BenchmarkConcurrentSharded(b *testing.B) {
+	c := cache.NewSharded(0, cache.Options[int, int]{Resolution: -1})
+	benchmarkConcurrent(b, c)
+}
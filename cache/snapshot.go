@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotVersion is the format version written by Save.
+const snapshotVersion = 1
+
+// ErrSnapshotVersion is returned by Load for snapshots of an unknown format.
+var ErrSnapshotVersion = errors.New("unsupported cache snapshot version")
+
+// snapshotEntry is an entry encoded by Save, with the absolute expiry times so the time between Save and Load counts
+// against the TTLs.
+type snapshotEntry[K comparable, V any] struct {
+	Key     K
+	Value   V
+	Expires time.Time
+	Stale   time.Time
+	Tags    []string
+}
+
+// Save writes the unexpired entries to w with encoding/gob, keys and values must be gob encodable.  Restore them
+// with Load, e.g. on startup after saving on shutdown.
+func (c *TTL[K, V]) Save(w io.Writer) error {
+	return writeSnapshot(w, c.snapshot(nil))
+}
+
+// Load adds the entries saved by Save that have not expired since, replacing existing entries.  Returns the number
+// of entries loaded.
+func (c *TTL[K, V]) Load(r io.Reader) (int, error) {
+	entries, err := readSnapshot[K, V](r)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.restore(entries), nil
+}
+
+// Save writes the unexpired entries of all shards to w, see TTL.Save.
+func (c *Sharded[K, V]) Save(w io.Writer) error {
+	var entries []snapshotEntry[K, V]
+
+	for _, s := range c.shards {
+		entries = s.snapshot(entries)
+	}
+
+	return writeSnapshot(w, entries)
+}
+
+// Load adds the entries saved by Save or TTL.Save, see TTL.Load.  The number of shards may differ from the saved
+// cache.
+func (c *Sharded[K, V]) Load(r io.Reader) (int, error) {
+	entries, err := readSnapshot[K, V](r)
+	if err != nil {
+		return 0, err
+	}
+
+	byShard := make(map[*TTL[K, V]][]snapshotEntry[K, V], len(c.shards))
+	for _, e := range entries {
+		s := c.shard(e.Key)
+		byShard[s] = append(byShard[s], e)
+	}
+
+	n := 0
+
+	for s, entries := range byShard {
+		s.mu.Lock()
+		n += s.restore(entries)
+		s.unlock()
+	}
+
+	return n, nil
+}
+
+// snapshot appends the unexpired entries to out, least recently used first so loading into a smaller cache keeps
+// the most recently used entries.
+func (c *TTL[K, V]) snapshot(out []snapshotEntry[K, V]) []snapshotEntry[K, V] {
+	c.mu.Lock()
+	defer c.unlock()
+
+	now := c.opts.Clock()
+
+	for el := c.lru.Back(); el != nil; el = el.Prev() {
+		e := el.Value.(*entry[K, V]) //nolint:forcetypeassert
+		if !e.expired(now) {
+			out = append(out, snapshotEntry[K, V]{e.key, e.value, e.expires, e.stale, e.tags})
+		}
+	}
+
+	return out
+}
+
+// restore sets the unexpired entries, returning the number kept.  c.mu must be held.
+func (c *TTL[K, V]) restore(entries []snapshotEntry[K, V]) int {
+	now := c.opts.Clock()
+	n := 0
+
+	for _, s := range entries {
+		if !s.Expires.IsZero() && !now.Before(s.Expires) {
+			continue
+		}
+
+		c.set(s.Key, s.Value, 0, now)
+
+		if e, ok := c.items[s.Key]; ok {
+			e.expires, e.stale = s.Expires, s.Stale
+			c.tag(s.Key, s.Tags)
+
+			n++
+		}
+	}
+
+	return n
+}
+
+func writeSnapshot[K comparable, V any](w io.Writer, entries []snapshotEntry[K, V]) error {
+	enc := gob.NewEncoder(w)
+
+	if err := enc.Encode(snapshotVersion); err != nil {
+		return fmt.Errorf("cache snapshot: %w", err)
+	}
+
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("cache snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func readSnapshot[K comparable, V any](r io.Reader) ([]snapshotEntry[K, V], error) {
+	dec := gob.NewDecoder(r)
+
+	var version int
+	if err := dec.Decode(&version); err != nil {
+		return nil, fmt.Errorf("cache snapshot: %w", err)
+	}
+
+	if version != snapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrSnapshotVersion, version)
+	}
+
+	var entries []snapshotEntry[K, V]
+	if err := dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("cache snapshot: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,92 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestSnapshot(t *testing.T) {
+	clk := newClock()
+	opts := cache.Options[string, user]{TTL: time.Minute, Resolution: -1, Clock: clk.Now}
+
+	c := cache.NewTTL(opts)
+	c.Set("a", user{ID: 1, Name: "a"})
+	c.SetWithTTL("b", user{ID: 2}, time.Hour)
+	c.SetWithTTL("forever", user{ID: 3}, 0)
+	c.SetWithTags("tagged", user{ID: 4}, "team")
+	c.SetWithTTL("expired", user{ID: 5}, time.Second)
+
+	clk.Add(time.Second)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Save(&buf))
+
+	clk.Add(30 * time.Second) // restart
+
+	restored := cache.NewTTL(opts)
+	n, err := restored.Load(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, []string{"a", "b", "forever", "tagged"}, sortedKeys(restored))
+
+	u, ok := restored.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, user{ID: 1, Name: "a"}, u)
+
+	clk.Add(30 * time.Second)
+	assert.Equal(t, []string{"b", "forever"}, sortedKeys(restored), "remaining TTLs kept")
+
+	restored.SetWithTags("tagged", user{ID: 4}, "team")
+	assert.Equal(t, 1, restored.InvalidateTag("team"))
+
+	clk.Add(-time.Minute)
+
+	small := cache.NewTTL(cache.Options[string, user]{Resolution: -1, Clock: clk.Now, MaxEntries: 2})
+	_, err = small.Load(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"forever", "tagged"}, sortedKeys(small), "most recently used kept")
+	assert.Equal(t, 1, small.InvalidateTag("team"), "tags restored")
+}
+
+func TestShardedSnapshot(t *testing.T) {
+	c := cache.NewSharded(4, cache.Options[int, int]{Resolution: -1})
+	for i := range 50 {
+		c.Set(i, i)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, c.Save(&buf))
+
+	restored := cache.NewSharded(3, cache.Options[int, int]{Resolution: -1})
+	n, err := restored.Load(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 50, n)
+
+	v, ok := restored.Get(42)
+	assert.True(t, ok)
+	assert.Equal(t, 42, v)
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	c := cache.NewTTL(cache.Options[string, int]{Resolution: -1})
+
+	_, err := c.Load(bytes.NewReader([]byte("garbage")))
+	require.ErrorContains(t, err, "cache snapshot")
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(99))
+
+	_, err = c.Load(&buf)
+	require.ErrorIs(t, err, cache.ErrSnapshotVersion)
+
+	bad := cache.NewTTL(cache.Options[string, any]{Resolution: -1})
+	bad.Set("unregistered", user{})
+	require.ErrorContains(t, bad.Save(&bytes.Buffer{}), "cache snapshot")
+}
@@ -0,0 +1,133 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{
+		TTL: time.Minute, StaleTTL: time.Hour, ErrorTTL: time.Second, Resolution: -1, Clock: clk.Now,
+	})
+
+	var (
+		calls atomic.Int32
+		fail  atomic.Bool
+	)
+
+	release := make(chan struct{}, 10)
+	loader := func(context.Context, string) (int, error) {
+		n := calls.Add(1)
+		<-release
+
+		if fail.Load() {
+			return 0, errors.New("boom")
+		}
+
+		return int(n), nil
+	}
+
+	release <- struct{}{}
+
+	v, err := c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	clk.Add(time.Minute)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok, "Get ignores stale entries")
+
+	v, err = c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "stale value served")
+
+	v, _ = c.GetOrLoad(context.Background(), "a", loader)
+	assert.Equal(t, 1, v)
+
+	release <- struct{}{}
+
+	assert.Eventually(t, func() bool {
+		v, ok := c.Get("a")
+
+		return ok && v == 2
+	}, time.Second, time.Millisecond, "refreshed in the background")
+	assert.Equal(t, int32(2), calls.Load(), "one refresh per key")
+
+	clk.Add(time.Minute)
+	fail.Store(true)
+	release <- struct{}{}
+
+	v, _ = c.GetOrLoad(context.Background(), "a", loader)
+	assert.Equal(t, 2, v)
+	assert.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, time.Millisecond)
+
+	time.Sleep(10 * time.Millisecond)
+
+	v, err = c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "failed refresh keeps the stale value")
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load(), "failed refresh delays the next refresh")
+
+	clk.Add(time.Hour)
+
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len(), "removed after StaleTTL")
+}
+
+func TestStaleMaxRefreshes(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[int, int]{
+		TTL: time.Minute, StaleTTL: time.Hour, MaxRefreshes: 2, Resolution: -1, Clock: clk.Now,
+	})
+
+	for i := range 5 {
+		c.Set(i, i)
+	}
+
+	clk.Add(time.Minute)
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	loader := func(_ context.Context, k int) (int, error) {
+		calls.Add(1)
+		<-release
+
+		return k * 10, nil
+	}
+
+	for i := range 5 {
+		v, err := c.GetOrLoad(context.Background(), i, loader)
+		require.NoError(t, err)
+		assert.Equal(t, i, v)
+	}
+
+	close(release)
+
+	assert.Eventually(t, func() bool { return len(freshKeys(c, 5)) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func freshKeys(c *cache.TTL[int, int], n int) []int {
+	var out []int
+
+	for i := range n {
+		if _, ok := c.Get(i); ok {
+			out = append(out, i)
+		}
+	}
+
+	return out
+}
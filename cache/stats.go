@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/bir/iken/httputil"
+)
+
+// Stats are the counters of a cache since it was created.
+type Stats struct {
+	// Hits are lookups returning a value, including stale values returned by GetOrLoad.
+	Hits uint64 `json:"hits"`
+	// Misses are lookups not returning a cached value.
+	Misses uint64 `json:"misses"`
+	// Loads are the loader calls of GetOrLoad, including background refreshes.
+	Loads uint64 `json:"loads"`
+	// LoadErrors are the loader calls returning an error.
+	LoadErrors uint64 `json:"loadErrors"`
+	// Evictions are the entries removed by MaxEntries or MaxBytes.
+	Evictions uint64 `json:"evictions"`
+	// Expirations are the expired entries removed.
+	Expirations uint64 `json:"expirations"`
+	// Entries is the current number of entries.
+	Entries int `json:"entries"`
+	// Bytes is the current total Size of the entries.
+	Bytes int64 `json:"bytes"`
+}
+
+// HitRatio returns Hits / (Hits + Misses), 0 without lookups.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// StatsProvider is a cache reporting Stats.
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// Stats returns the current counters.
+func (c *TTL[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.unlock()
+
+	s := c.stats
+	s.Entries = len(c.items)
+	s.Bytes = c.bytes
+
+	return s
+}
+
+// MetricsPrefix prefixes the metric names written by WriteMetrics.
+var MetricsPrefix = "cache_"
+
+// WriteMetrics writes the Stats of caches in the Prometheus text exposition format, labeled by the map key, e.g.
+// `cache_hits_total{cache="users"} 42`.
+func WriteMetrics(w io.Writer, caches map[string]StatsProvider) error {
+	names := make([]string, 0, len(caches))
+	for name := range caches {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	stats := make([]Stats, len(names))
+	for i, name := range names {
+		stats[i] = caches[name].Stats()
+	}
+
+	metrics := []struct {
+		name, kind, help string
+		value            func(s Stats) any
+	}{
+		{"hits_total", "counter", "Cache lookups returning a value.", func(s Stats) any { return s.Hits }},
+		{"misses_total", "counter", "Cache lookups not returning a value.", func(s Stats) any { return s.Misses }},
+		{"loads_total", "counter", "Cache loader calls.", func(s Stats) any { return s.Loads }},
+		{"load_errors_total", "counter", "Cache loader calls failing.", func(s Stats) any { return s.LoadErrors }},
+		{"evictions_total", "counter", "Cache entries evicted for capacity.", func(s Stats) any { return s.Evictions }},
+		{"expirations_total", "counter", "Cache entries expired.", func(s Stats) any { return s.Expirations }},
+		{"entries", "gauge", "Cache entries.", func(s Stats) any { return s.Entries }},
+		{"bytes", "gauge", "Estimated size of cache entries.", func(s Stats) any { return s.Bytes }},
+	}
+
+	var out httputil.Metrics
+
+	for _, m := range metrics {
+		name := MetricsPrefix + m.name
+		out.Family(name, m.kind, m.help)
+
+		for i, cache := range names {
+			out.Sample(name, m.value(stats[i]), "cache", cache)
+		}
+	}
+
+	_, err := out.WriteTo(w)
+
+	return err //nolint:wrapcheck
+}
+
+// MetricsHandler serves WriteMetrics of caches, for scraping by Prometheus.
+func MetricsHandler(caches map[string]StatsProvider) http.HandlerFunc {
+	return httputil.MetricsHandler(func(w io.Writer) error { return WriteMetrics(w, caches) })
+}
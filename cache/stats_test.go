@@ -0,0 +1,74 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestStats(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, string]{
+		TTL:        time.Minute,
+		Resolution: -1,
+		Clock:      clk.Now,
+		MaxEntries: 2,
+		Size:       func(k, v string) int64 { return int64(len(k) + len(v)) },
+	})
+
+	c.Set("a", "1")
+	c.Get("a")
+	c.Get("b")
+
+	_, err := c.GetOrLoad(context.Background(), "b", func(context.Context, string) (string, error) { return "22", nil })
+	require.NoError(t, err)
+
+	_, err = c.GetOrLoad(context.Background(), "c", func(context.Context, string) (string, error) {
+		return "", errors.New("boom")
+	})
+	require.Error(t, err)
+
+	c.Set("d", "4") // evicts a
+
+	clk.Add(time.Minute)
+	c.Get("b") // expired
+
+	assert.Equal(t, cache.Stats{
+		Hits:        1,
+		Misses:      4,
+		Loads:       2,
+		LoadErrors:  1,
+		Evictions:   1,
+		Expirations: 1,
+		Entries:     1,
+		Bytes:       2,
+	}, c.Stats())
+	assert.InDelta(t, 0.2, c.Stats().HitRatio(), 0.001)
+	assert.Zero(t, cache.Stats{}.HitRatio())
+}
+
+func TestMetricsHandler(t *testing.T) {
+	users := cache.NewTTL(cache.Options[int, string]{Resolution: -1})
+	users.Set(1, "bob")
+	users.Get(1)
+
+	w := httptest.NewRecorder()
+	cache.MetricsHandler(map[string]cache.StatsProvider{
+		"users":   users,
+		"catalog": cache.NewTTL(cache.Options[string, int]{Resolution: -1}),
+	})(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), "# TYPE cache_hits_total counter\n"+
+		"cache_hits_total{cache=\"catalog\"} 0\n"+
+		"cache_hits_total{cache=\"users\"} 1\n")
+	assert.Contains(t, w.Body.String(), "cache_entries{cache=\"users\"} 1\n")
+}
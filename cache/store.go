@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Store is a cache of binary values shared between processes, e.g. Redis.
+type Store interface {
+	// Get returns the value of key, ok is false if it is missing or expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set sets the value of key expiring after ttl, ttl <= 0 never expires.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete deletes key, missing keys are ignored.
+	Delete(ctx context.Context, key string) error
+}
+
+// TagStore is a Store indexing keys by tag, implemented by Redis with sets.
+type TagStore interface {
+	Store
+	// AddTags adds key to the sets of tags, kept for at least ttl, ttl <= 0 keeps them until invalidated.
+	AddTags(ctx context.Context, key string, ttl time.Duration, tags ...string) error
+	// InvalidateTags deletes the sets of tags and their keys, returning the deleted keys.
+	InvalidateTags(ctx context.Context, tags ...string) ([]string, error)
+}
+
+// ErrTagsUnsupported is returned by Remote tag methods if the Store is not a TagStore.
+var ErrTagsUnsupported = errors.New("cache store does not support tags")
+
+// Codec encodes the values of a Remote cache.
+type Codec[V any] interface {
+	Marshal(v V) ([]byte, error)
+	Unmarshal(data []byte, v *V) error
+}
+
+// JSONCodec encodes values with encoding/json.
+type JSONCodec[V any] struct{}
+
+// Marshal returns the JSON encoding of v.
+func (JSONCodec[V]) Marshal(v V) ([]byte, error) {
+	return json.Marshal(v) //nolint:wrapcheck
+}
+
+// Unmarshal decodes the JSON data into v.
+func (JSONCodec[V]) Unmarshal(data []byte, v *V) error {
+	return json.Unmarshal(data, v) //nolint:wrapcheck
+}
+
+// Loading is the context aware cache contract implemented by TTL, Sharded and Remote, so call sites can switch
+// between in-process and shared caches by configuration.
+type Loading[K comparable, V any] interface {
+	GetOrLoad(ctx context.Context, k K, loader LoaderFunc[K, V]) (V, error)
+}
+
+// Remote is a typed cache stored in a Store, values are encoded with Codec under Prefix and the key formatted with
+// fmt.Sprint.
+type Remote[K comparable, V any] struct {
+	Store  Store
+	Codec  Codec[V]
+	Prefix string
+	// TTL is the lifetime of values set by Set and GetOrLoad, <= 0 never expires.
+	TTL time.Duration
+	// Jitter shortens each TTL by a random fraction up to Jitter, see Options.Jitter.
+	Jitter float64
+}
+
+// NewRemote creates a typed cache of JSON values stored under prefix.
+func NewRemote[K comparable, V any](store Store, prefix string, ttl time.Duration) *Remote[K, V] {
+	return &Remote[K, V]{Store: store, Codec: JSONCodec[V]{}, Prefix: prefix, TTL: ttl}
+}
+
+// Key returns the Store key of k.
+func (c *Remote[K, V]) Key(k K) string {
+	return c.Prefix + fmt.Sprint(k)
+}
+
+// Get gets an item from the store, ok is false if it is missing.
+func (c *Remote[K, V]) Get(ctx context.Context, k K) (v V, ok bool, err error) { //nolint:nonamedreturns
+	data, ok, err := c.Store.Get(ctx, c.Key(k))
+	if err != nil || !ok {
+		return v, false, err //nolint:wrapcheck
+	}
+
+	if err = c.Codec.Unmarshal(data, &v); err != nil {
+		return v, false, fmt.Errorf("cache decode %s: %w", c.Key(k), err)
+	}
+
+	return v, true, nil
+}
+
+// Set sets an item to the store with the default TTL.
+func (c *Remote[K, V]) Set(ctx context.Context, k K, v V) error {
+	return c.SetWithTTL(ctx, k, v, c.TTL)
+}
+
+// SetWithTTL sets an item to the store expiring after ttl.
+func (c *Remote[K, V]) SetWithTTL(ctx context.Context, k K, v V, ttl time.Duration) error {
+	data, err := c.Codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cache encode %s: %w", c.Key(k), err)
+	}
+
+	return c.Store.Set(ctx, c.Key(k), data, jitter(ttl, c.Jitter)) //nolint:wrapcheck
+}
+
+// Delete deletes the item with provided key from the store.
+func (c *Remote[K, V]) Delete(ctx context.Context, k K) error {
+	return c.Store.Delete(ctx, c.Key(k)) //nolint:wrapcheck
+}
+
+// GetOrLoad returns the stored value of k, or loads it with loader and stores it with the default TTL.  Store errors
+// are returned, loader errors are returned and not stored.
+func (c *Remote[K, V]) GetOrLoad(ctx context.Context, k K, loader LoaderFunc[K, V]) (V, error) {
+	v, ok, err := c.Get(ctx, k)
+	if err != nil || ok {
+		return v, err
+	}
+
+	if v, err = loader(ctx, k); err != nil {
+		return v, err
+	}
+
+	return v, c.Set(ctx, k, v)
+}
@@ -0,0 +1,237 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SetWithTags sets any item to the cache with the default TTL and tags, e.g. "user:42", see InvalidateTag.
+func (c *TTL[K, V]) SetWithTags(k K, v V, tags ...string) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.set(k, v, c.opts.TTL, c.opts.Clock())
+	c.tag(k, tags)
+}
+
+// Tag adds tags to the item of k, e.g. after GetOrLoad.  Returns false if k is not cached.
+func (c *TTL[K, V]) Tag(k K, tags ...string) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.tag(k, tags)
+}
+
+func (c *TTL[K, V]) tag(k K, tags []string) bool {
+	e, found := c.items[k]
+	if !found {
+		return false
+	}
+
+	for _, tag := range tags {
+		keys := c.tags[tag]
+		if keys == nil {
+			keys = make(map[K]struct{})
+			c.tags[tag] = keys
+		}
+
+		if _, tagged := keys[k]; !tagged {
+			keys[k] = struct{}{}
+			e.tags = append(e.tags, tag)
+		}
+	}
+
+	return true
+}
+
+// InvalidateTag deletes the items with any of tags, returning the number deleted.
+func (c *TTL[K, V]) InvalidateTag(tags ...string) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	n := 0
+
+	for _, tag := range tags {
+		for k := range c.tags[tag] {
+			if e, found := c.items[k]; found {
+				c.remove(e, Deleted)
+
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// SetWithTags sets any item to the cache with the default TTL and tags, see TTL.SetWithTags.
+func (c *Sharded[K, V]) SetWithTags(k K, v V, tags ...string) {
+	c.shard(k).SetWithTags(k, v, tags...)
+}
+
+// Tag adds tags to the item of k, see TTL.Tag.
+func (c *Sharded[K, V]) Tag(k K, tags ...string) bool {
+	return c.shard(k).Tag(k, tags...)
+}
+
+// InvalidateTag deletes the items with any of tags from all shards, returning the number deleted.
+func (c *Sharded[K, V]) InvalidateTag(tags ...string) int {
+	n := 0
+
+	for _, s := range c.shards {
+		n += s.InvalidateTag(tags...)
+	}
+
+	return n
+}
+
+// SetWithTags sets an item to the store with the default TTL and tags, see InvalidateTag.
+func (c *Remote[K, V]) SetWithTags(ctx context.Context, k K, v V, tags ...string) error {
+	ts, ok := c.Store.(TagStore)
+	if !ok {
+		return ErrTagsUnsupported
+	}
+
+	if err := c.Set(ctx, k, v); err != nil {
+		return err
+	}
+
+	return ts.AddTags(ctx, c.Key(k), c.TTL, c.tagKeys(tags)...) //nolint:wrapcheck
+}
+
+// InvalidateTag deletes the items with any of tags from the store, returning the number deleted.
+func (c *Remote[K, V]) InvalidateTag(ctx context.Context, tags ...string) (int, error) {
+	keys, err := c.invalidateTags(ctx, tags)
+
+	return len(keys), err
+}
+
+func (c *Remote[K, V]) invalidateTags(ctx context.Context, tags []string) ([]string, error) {
+	ts, ok := c.Store.(TagStore)
+	if !ok {
+		return nil, ErrTagsUnsupported
+	}
+
+	return ts.InvalidateTags(ctx, c.tagKeys(tags)...) //nolint:wrapcheck
+}
+
+// tagKeys returns the store keys of the tag sets.
+func (c *Remote[K, V]) tagKeys(tags []string) []string {
+	out := make([]string, len(tags))
+	for i, tag := range tags {
+		out[i] = c.Prefix + "tag:" + tag
+	}
+
+	return out
+}
+
+// SetWithTags sets an item with tags to both tiers with the default TTL, the remote write is always write through
+// (after the queued WriteBehind writes).
+func (c *Tiered[K, V]) SetWithTags(ctx context.Context, k K, v V, tags ...string) error {
+	c.setLocal(k, v, c.Local.opts.TTL)
+	c.Local.Tag(k, tags...)
+
+	return c.remote(ctx, func(ctx context.Context) error {
+		if err := c.Remote.SetWithTags(ctx, k, v, tags...); err != nil {
+			return err
+		}
+
+		return c.invalidate(ctx, c.Remote.Key(k))
+	})
+}
+
+// InvalidateTag deletes the items with any of tags from both tiers, other instances are then notified of the
+// deleted keys.  Returns the number of remote items deleted.
+func (c *Tiered[K, V]) InvalidateTag(ctx context.Context, tags ...string) (int, error) {
+	c.Local.InvalidateTag(tags...)
+
+	var keys []string
+
+	err := c.remote(ctx, func(ctx context.Context) error {
+		var err error
+
+		keys, err = c.Remote.invalidateTags(ctx, tags)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		c.dropLocal(key)
+	}
+
+	return len(keys), c.invalidate(ctx, keys...)
+}
+
+// AddTags adds key to the sets of tags.  The set TTLs only grow (PEXPIRE NX and GT), which requires Redis 7 or later.
+func (r *Redis) AddTags(ctx context.Context, key string, ttl time.Duration, tags ...string) error {
+	for _, tag := range tags {
+		if _, err := r.Do(ctx, "SADD", tag, key); err != nil {
+			return fmt.Errorf("tag %s: %w", tag, err)
+		}
+
+		var err error
+
+		if ttl > 0 {
+			ms := strconv.FormatInt(max(ttl.Milliseconds(), 1), 10)
+			if _, err = r.Do(ctx, "PEXPIRE", tag, ms, "NX"); err == nil {
+				_, err = r.Do(ctx, "PEXPIRE", tag, ms, "GT")
+			}
+		} else {
+			_, err = r.Do(ctx, "PERSIST", tag)
+		}
+
+		if err != nil {
+			return fmt.Errorf("tag %s: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// InvalidateTags deletes the sets of tags and their keys.  Each set is first renamed to a temporary key, so keys
+// tagged while it is read join a new set rather than being dropped unseen.
+func (r *Redis) InvalidateTags(ctx context.Context, tags ...string) ([]string, error) {
+	var keys []string
+
+	for _, tag := range tags {
+		tmp := tag + ":invalidating:" + uuid.NewString()
+
+		var redisErr RedisError
+		if _, err := r.Do(ctx, "RENAME", tag, tmp); errors.As(err, &redisErr) &&
+			strings.HasPrefix(string(redisErr), "ERR no such key") {
+			continue
+		} else if err != nil {
+			return keys, fmt.Errorf("tag %s: %w", tag, err)
+		}
+
+		reply, err := r.Do(ctx, "SMEMBERS", tmp)
+		if err != nil {
+			return keys, fmt.Errorf("tag %s: %w", tag, err)
+		}
+
+		members, _ := reply.([]any)
+		args := []string{"DEL", tmp}
+
+		for _, m := range members {
+			if b, ok := m.([]byte); ok {
+				args = append(args, string(b))
+				keys = append(keys, string(b))
+			}
+		}
+
+		if _, err = r.Do(ctx, args...); err != nil {
+			return keys, fmt.Errorf("tag %s: %w", tag, err)
+		}
+	}
+
+	return keys, nil
+}
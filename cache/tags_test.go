@@ -0,0 +1,128 @@
+package cache_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func TestTags(t *testing.T) {
+	c := cache.NewTTL(cache.Options[string, int]{Resolution: -1})
+
+	c.SetWithTags("user:42:profile", 1, "user:42")
+	c.SetWithTags("user:42:orders", 2, "user:42", "orders")
+	c.SetWithTags("user:7:orders", 3, "user:7", "orders")
+	c.Set("catalog", 4)
+	assert.True(t, c.Tag("catalog", "catalog", "catalog"))
+	assert.False(t, c.Tag("missing", "catalog"))
+
+	assert.Equal(t, 2, c.InvalidateTag("user:42"))
+	assert.Equal(t, []string{"catalog", "user:7:orders"}, sortedKeys(c))
+
+	assert.Equal(t, 1, c.InvalidateTag("orders"))
+	assert.Equal(t, 0, c.InvalidateTag("orders", "unknown"))
+
+	c.Set("catalog", 5) // replacing drops the tags
+	assert.Equal(t, 0, c.InvalidateTag("catalog"))
+	assert.Equal(t, []string{"catalog"}, sortedKeys(c))
+}
+
+func TestShardedTags(t *testing.T) {
+	c := cache.NewSharded(4, cache.Options[int, int]{Resolution: -1})
+
+	for i := range 20 {
+		c.SetWithTags(i, i, "all")
+	}
+
+	c.Set(100, 100)
+	assert.True(t, c.Tag(100, "other"))
+	assert.Equal(t, 20, c.InvalidateTag("all"))
+	assert.Equal(t, []int{100}, c.Keys())
+}
+
+func TestRemoteTags(t *testing.T) {
+	s := newRedisServer(t, "")
+	ctx := context.Background()
+
+	r := cache.NewRemote[int, user](cache.NewRedis(s.addr), "user:", time.Hour)
+
+	require.NoError(t, r.SetWithTags(ctx, 1, user{ID: 1}, "team:a"))
+	require.NoError(t, r.SetWithTags(ctx, 2, user{ID: 2}, "team:a", "admins"))
+	require.NoError(t, r.SetWithTags(ctx, 3, user{ID: 3}, "team:b"))
+
+	n, err := r.InvalidateTag(ctx, "team:a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.values))
+	for k := range s.values {
+		keys = append(keys, k)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(keys)
+	assert.Equal(t, []string{"user:3"}, keys)
+
+	s.mu.Lock()
+	sets := make([]string, 0, len(s.sets))
+	for k := range s.sets {
+		sets = append(sets, k)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(sets)
+	assert.Equal(t, []string{"user:tag:admins", "user:tag:team:b"}, sets, "renamed set deleted")
+
+	n, err = r.InvalidateTag(ctx, "team:a")
+	require.NoError(t, err)
+	assert.Zero(t, n, "missing tag")
+
+	_, err = cache.NewRemote[int, user](storeOnly{}, "", 0).InvalidateTag(ctx, "a")
+	require.ErrorIs(t, err, cache.ErrTagsUnsupported)
+	require.ErrorIs(t, cache.NewRemote[int, user](storeOnly{}, "", 0).SetWithTags(ctx, 1, user{}), cache.ErrTagsUnsupported)
+}
+
+// storeOnly is a Store without tag support.
+type storeOnly struct{}
+
+func (storeOnly) Get(context.Context, string) ([]byte, bool, error)        { return nil, false, nil }
+func (storeOnly) Set(context.Context, string, []byte, time.Duration) error { return nil }
+func (storeOnly) Delete(context.Context, string) error                     { return nil }
+
+func TestTieredTags(t *testing.T) {
+	s := newRedisServer(t, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := newTiered(t, ctx, s, cache.WriteThrough)
+	b := newTiered(t, ctx, s, cache.WriteThrough)
+
+	defer a.Close()
+	defer b.Close()
+
+	require.NoError(t, a.SetWithTags(ctx, 1, user{ID: 1}, "team:a"))
+	require.NoError(t, a.SetWithTags(ctx, 2, user{ID: 2}, "team:a"))
+	require.NoError(t, a.Set(ctx, 3, user{ID: 3}))
+
+	for id := range 3 {
+		_, ok, err := b.Get(ctx, id+1)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	n, err := b.InvalidateTag(ctx, "team:a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Eventually(t, func() bool { return a.Local.Len() == 1 }, time.Second, time.Millisecond,
+		"remote tag invalidation broadcast to other instances")
+	assert.Equal(t, []int{3}, b.Local.Keys(), "local copies without local tags dropped")
+}
@@ -0,0 +1,270 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WriteMode selects how Tiered writes the remote store.
+type WriteMode int
+
+const (
+	// WriteThrough writes the remote store before Set returns.
+	WriteThrough WriteMode = iota
+	// WriteBehind queues remote writes, Set returns after the local write.  Deletes and tag writes go through the
+	// same queue, waiting for the queued writes, so the remote store sees the calls in order.
+	WriteBehind
+)
+
+// DefaultWriteBehindQueue is the number of queued remote writes used when Tiered.QueueSize is 0.
+const DefaultWriteBehindQueue = 1024
+
+// Broadcaster sends messages between instances, implemented by Redis with PUBLISH and SUBSCRIBE.
+type Broadcaster interface {
+	Publish(ctx context.Context, channel, message string) error
+	// Subscribe calls fn with the messages of channel until ctx is done.
+	Subscribe(ctx context.Context, channel string, fn func(message string)) error
+}
+
+// Tiered is a small in-process cache in front of a Remote cache.  Writes and deletes are broadcast, if Broadcaster is
+// set, so the other instances running Listen drop their local copies.
+type Tiered[K comparable, V any] struct {
+	Local  *TTL[K, V]
+	Remote *Remote[K, V]
+	// Mode is the remote write mode of Set.
+	Mode WriteMode
+	// QueueSize is the number of queued WriteBehind writes, defaults to DefaultWriteBehindQueue.  Set blocks while
+	// the queue is full.
+	QueueSize int
+	// Broadcaster sends invalidations on Channel, nil disables them.
+	Broadcaster Broadcaster
+	// Channel is the invalidation channel, defaults to the remote prefix + "invalidate".
+	Channel string
+	// OnError is called with the errors of WriteBehind writes, which are otherwise dropped.
+	OnError func(err error)
+
+	id    string
+	index sync.Map // remote key => K, of local entries
+
+	mu    sync.Mutex
+	queue chan write
+	wg    sync.WaitGroup
+}
+
+// write is a queued remote write, done receives its error if the caller waits for it.
+type write struct {
+	ctx  context.Context //nolint:containedctx
+	fn   func(ctx context.Context) error
+	done chan error
+}
+
+// NewTiered creates a local TTL cache configured by opts in front of remote.  Call Close to flush write behind
+// writes and stop the local sweeper.
+func NewTiered[K comparable, V any](opts Options[K, V], remote *Remote[K, V]) *Tiered[K, V] {
+	c := &Tiered[K, V]{Remote: remote, Channel: remote.Prefix + "invalidate", id: uuid.NewString()}
+
+	onEvict := opts.OnEvict
+	opts.OnEvict = func(k K, v V, reason Reason) {
+		if reason != Replaced {
+			c.index.Delete(remote.Key(k))
+		}
+
+		if onEvict != nil {
+			onEvict(k, v, reason)
+		}
+	}
+
+	c.Local = NewTTL(opts)
+
+	return c
+}
+
+// Get returns the local value of k, or the remote value which is then cached locally.
+func (c *Tiered[K, V]) Get(ctx context.Context, k K) (V, bool, error) {
+	if v, ok := c.Local.Get(k); ok {
+		return v, true, nil
+	}
+
+	v, ok, err := c.Remote.Get(ctx, k)
+	if err != nil || !ok {
+		return v, false, err
+	}
+
+	c.setLocal(k, v, c.Local.opts.TTL)
+
+	return v, true, nil
+}
+
+// GetOrLoad returns the local value of k, or the remote value, or loads it with loader and writes it to both tiers.
+// Concurrent local misses share one remote read, see TTL.GetOrLoad.
+func (c *Tiered[K, V]) GetOrLoad(ctx context.Context, k K, loader LoaderFunc[K, V]) (V, error) {
+	v, err := c.Local.GetOrLoad(ctx, k, func(ctx context.Context, k K) (V, error) {
+		return c.Remote.GetOrLoad(ctx, k, loader)
+	})
+	if err == nil {
+		c.index.Store(c.Remote.Key(k), k)
+	}
+
+	return v, err
+}
+
+// Set sets an item to both tiers with the default TTL.
+func (c *Tiered[K, V]) Set(ctx context.Context, k K, v V) error {
+	return c.SetWithTTL(ctx, k, v, c.Remote.TTL)
+}
+
+// SetWithTTL sets an item to both tiers, the local entry expires after the smaller of ttl and the local TTL.  The
+// remote write follows Mode, other instances are then notified.
+func (c *Tiered[K, V]) SetWithTTL(ctx context.Context, k K, v V, ttl time.Duration) error {
+	localTTL := c.Local.opts.TTL
+	if ttl > 0 && (localTTL <= 0 || ttl < localTTL) {
+		localTTL = ttl
+	}
+
+	c.setLocal(k, v, localTTL)
+
+	set := func(ctx context.Context) error {
+		if err := c.Remote.SetWithTTL(ctx, k, v, ttl); err != nil {
+			return err
+		}
+
+		return c.invalidate(ctx, c.Remote.Key(k))
+	}
+
+	if c.Mode == WriteBehind {
+		return c.enqueue(ctx, write{ctx: context.Background(), fn: set})
+	}
+
+	return set(ctx)
+}
+
+// Delete deletes the item from both tiers, other instances are then notified.
+func (c *Tiered[K, V]) Delete(ctx context.Context, k K) error {
+	c.Local.Delete(k)
+
+	return c.remote(ctx, func(ctx context.Context) error {
+		if err := c.Remote.Delete(ctx, k); err != nil {
+			return err
+		}
+
+		return c.invalidate(ctx, c.Remote.Key(k))
+	})
+}
+
+// Listen drops the local entries invalidated by other instances until ctx is done.
+func (c *Tiered[K, V]) Listen(ctx context.Context) error {
+	if c.Broadcaster == nil {
+		return nil
+	}
+
+	return c.Broadcaster.Subscribe(ctx, c.Channel, func(message string) { //nolint:wrapcheck
+		id, keys, ok := strings.Cut(message, "\n")
+		if !ok || id == c.id {
+			return
+		}
+
+		for _, key := range strings.Split(keys, "\n") {
+			c.dropLocal(key)
+		}
+	})
+}
+
+// Close flushes the write behind queue and stops the local sweeper, the cache must not be written afterwards.
+func (c *Tiered[K, V]) Close() {
+	c.mu.Lock()
+	if c.queue != nil {
+		close(c.queue)
+	}
+	c.mu.Unlock()
+
+	c.wg.Wait()
+	c.Local.Close()
+}
+
+func (c *Tiered[K, V]) setLocal(k K, v V, ttl time.Duration) {
+	c.index.Store(c.Remote.Key(k), k)
+	c.Local.SetWithTTL(k, v, ttl)
+}
+
+func (c *Tiered[K, V]) dropLocal(key string) {
+	if k, ok := c.index.Load(key); ok {
+		c.Local.Delete(k.(K)) //nolint:forcetypeassert
+	}
+}
+
+// invalidate broadcasts the remote keys, as the instance id and keys on separate lines.
+func (c *Tiered[K, V]) invalidate(ctx context.Context, keys ...string) error {
+	if c.Broadcaster == nil || len(keys) == 0 {
+		return nil
+	}
+
+	return c.Broadcaster.Publish(ctx, c.Channel, c.id+"\n"+strings.Join(keys, "\n")) //nolint:wrapcheck
+}
+
+// remote runs fn, with WriteBehind after the queued writes.
+func (c *Tiered[K, V]) remote(ctx context.Context, fn func(ctx context.Context) error) error {
+	if c.Mode != WriteBehind {
+		return fn(ctx)
+	}
+
+	done := make(chan error, 1)
+	if err := c.enqueue(ctx, write{ctx: ctx, fn: fn, done: done}); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	}
+}
+
+// enqueue queues w, blocking while the queue is full.
+func (c *Tiered[K, V]) enqueue(ctx context.Context, w write) error {
+	select {
+	case c.writer() <- w:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck
+	}
+}
+
+// writer returns the write behind queue, starting the writer on first use.
+func (c *Tiered[K, V]) writer() chan<- write {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.queue != nil {
+		return c.queue
+	}
+
+	size := c.QueueSize
+	if size <= 0 {
+		size = DefaultWriteBehindQueue
+	}
+
+	c.queue = make(chan write, size)
+	c.wg.Add(1)
+
+	go func() {
+		defer c.wg.Done()
+
+		for w := range c.queue {
+			err := w.fn(w.ctx)
+
+			switch {
+			case w.done != nil:
+				w.done <- err
+			case err != nil && c.OnError != nil:
+				c.OnError(err)
+			}
+		}
+	}()
+
+	return c.queue
+}
@@ -0,0 +1,220 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+func newTiered(t *testing.T, ctx context.Context, s *redisServer, mode cache.WriteMode) *cache.Tiered[int, user] {
+	t.Helper()
+
+	r := cache.NewRedis(s.addr)
+	c := cache.NewTiered(cache.Options[int, user]{TTL: time.Hour, Resolution: -1},
+		cache.NewRemote[int, user](r, "user:", time.Hour))
+	c.Mode = mode
+	c.Broadcaster = r
+
+	want := s.subscribers(c.Channel) + 1
+
+	go func() { _ = c.Listen(ctx) }()
+
+	require.Eventually(t, func() bool { return s.subscribers(c.Channel) == want }, time.Second, time.Millisecond)
+
+	return c
+}
+
+func TestTiered(t *testing.T) {
+	s := newRedisServer(t, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := newTiered(t, ctx, s, cache.WriteThrough)
+	b := newTiered(t, ctx, s, cache.WriteThrough)
+
+	defer a.Close()
+	defer b.Close()
+
+	calls := 0
+	loader := func(_ context.Context, id int) (user, error) {
+		calls++
+
+		return user{ID: id, Name: "loaded"}, nil
+	}
+
+	u, err := a.GetOrLoad(ctx, 1, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", u.Name)
+
+	u, err = b.GetOrLoad(ctx, 1, loader)
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", u.Name, "read from the remote tier")
+	assert.Equal(t, 1, calls)
+
+	_, ok := b.Local.Get(1)
+	assert.True(t, ok, "cached locally")
+
+	require.NoError(t, a.Set(ctx, 1, user{ID: 1, Name: "updated"}))
+
+	assert.Eventually(t, func() bool {
+		_, ok := b.Local.Get(1)
+
+		return !ok
+	}, time.Second, time.Millisecond, "invalidated by the broadcast")
+
+	u, ok, err = b.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "updated", u.Name)
+
+	_, ok = a.Local.Get(1)
+	assert.True(t, ok, "own broadcasts are ignored")
+
+	require.NoError(t, a.Delete(ctx, 1))
+
+	assert.Eventually(t, func() bool {
+		_, ok := b.Local.Get(1)
+
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	_, ok, err = b.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestTieredWriteBehind(t *testing.T) {
+	s := newRedisServer(t, "")
+	ctx := context.Background()
+
+	c := cache.NewTiered(cache.Options[int, user]{TTL: time.Minute, Resolution: -1},
+		cache.NewRemote[int, user](cache.NewRedis(s.addr), "user:", time.Hour))
+	c.Mode = cache.WriteBehind
+
+	var errs []error
+
+	c.OnError = func(err error) { errs = append(errs, err) }
+
+	for i := range 10 {
+		require.NoError(t, c.Set(ctx, i, user{ID: i}))
+	}
+
+	u, ok, err := c.Get(ctx, 9)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 9, u.ID)
+
+	c.Close()
+
+	s.mu.Lock()
+	assert.Len(t, s.values, 10, "flushed by Close")
+	s.mu.Unlock()
+	assert.Empty(t, errs)
+
+	c = cache.NewTiered(cache.Options[int, user]{Resolution: -1},
+		cache.NewRemote[int, user](cache.NewRedis("127.0.0.1:1"), "user:", time.Hour))
+	c.Mode = cache.WriteBehind
+	c.OnError = func(err error) { errs = append(errs, err) }
+
+	require.NoError(t, c.Set(ctx, 1, user{ID: 1}))
+	c.Close()
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0], "redis dial")
+}
+
+// gateStore is a Store holding its first Set until gate is closed.
+type gateStore struct {
+	gate chan struct{}
+
+	mu     sync.Mutex
+	once   sync.Once
+	values map[string]string
+}
+
+func (s *gateStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, ok := s.values[key]
+
+	return []byte(v), ok, nil
+}
+
+func (s *gateStore) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	s.once.Do(func() { <-s.gate })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = string(value)
+
+	return nil
+}
+
+func (s *gateStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key)
+
+	return nil
+}
+
+func TestTieredWriteBehindOrder(t *testing.T) {
+	ctx := context.Background()
+	s := &gateStore{gate: make(chan struct{}), values: map[string]string{}}
+
+	c := cache.NewTiered(cache.Options[int, user]{TTL: time.Minute, Resolution: -1},
+		cache.NewRemote[int, user](s, "user:", time.Hour))
+	c.Mode = cache.WriteBehind
+	c.QueueSize = 1
+
+	time.AfterFunc(50*time.Millisecond, func() { close(s.gate) })
+
+	for i := range 3 {
+		require.NoError(t, c.Set(ctx, 1, user{ID: i}))
+	}
+
+	require.NoError(t, c.Set(ctx, 2, user{ID: 2}))
+	require.NoError(t, c.Delete(ctx, 2))
+
+	s.mu.Lock()
+	assert.NotContains(t, s.values, "user:2", "deleted after the queued set")
+	s.mu.Unlock()
+
+	c.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assert.JSONEq(t, `{"id":2,"name":""}`, s.values["user:1"], "a full queue keeps the write order")
+	assert.NotContains(t, s.values, "user:2")
+}
+
+func TestRedisSubscribeCancel(t *testing.T) {
+	s := newRedisServer(t, "")
+	r := cache.NewRedis(s.addr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	var got []string
+
+	go func() { done <- r.Subscribe(ctx, "ch", func(m string) { got = append(got, m) }) }()
+
+	require.Eventually(t, func() bool { return s.subscribers("ch") == 1 }, time.Second, time.Millisecond)
+	require.NoError(t, r.Publish(context.Background(), "ch", "hello"))
+	require.NoError(t, r.Publish(context.Background(), "other", "ignored"))
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"hello"}, got)
+}
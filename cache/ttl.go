@@ -0,0 +1,362 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultResolution is the expiration sweep interval used when Options.Resolution is 0.
+	DefaultResolution = time.Minute
+	// DefaultMaxRefreshes is the number of concurrent background refreshes used when Options.MaxRefreshes is 0.
+	DefaultMaxRefreshes = 16
+)
+
+// Options configures a TTL cache.
+type Options[K comparable, V any] struct {
+	// TTL is the lifetime of entries set by Set and GetOrLoad, <= 0 never expires.
+	TTL time.Duration
+	// Resolution is the interval of the sweeper removing expired entries, defaults to DefaultResolution.  Negative
+	// disables the sweeper, expired entries are then removed when accessed.  Expired entries are never returned.
+	Resolution time.Duration
+	// Clock returns the current time, defaults to time.Now.
+	Clock func() time.Time
+	// MaxEntries evicts the least recently used entries above this many entries, 0 is unlimited.
+	MaxEntries int
+	// MaxBytes evicts the least recently used entries above this total Size, 0 is unlimited.
+	MaxBytes int64
+	// Size returns the estimated size of an entry in bytes for MaxBytes, MaxBytes is ignored without Size.
+	Size func(k K, v V) int64
+	// ErrorTTL caches loader errors, GetOrLoad returns the cached error for this long instead of loading again.  0
+	// does not cache errors.
+	ErrorTTL time.Duration
+	// NotFoundTTL caches loader errors wrapping ErrNotFound for this long, usually shorter than TTL so new values
+	// appear promptly, while lookups of missing keys stop reaching the loader.  0 handles them as other errors.
+	NotFoundTTL time.Duration
+	// StaleTTL keeps entries for this long after they expire, GetOrLoad returns stale entries immediately while
+	// refreshing them in the background.  Get does not return stale entries.
+	StaleTTL time.Duration
+	// MaxRefreshes bounds the concurrent background refreshes, defaults to DefaultMaxRefreshes.  Stale entries are
+	// returned without a refresh while the limit is reached.
+	MaxRefreshes int
+	// RefreshAhead refreshes entries returned by GetOrLoad within this long before they become stale, in the
+	// background like stale entries, so frequently used keys are reloaded before they expire.  0 disables it.
+	RefreshAhead time.Duration
+	// OnEvict is called with every removed entry, e.g. to release resources held by values.  Callbacks run after the
+	// cache lock is released, in the calling goroutine (the sweeper for expirations it removes).
+	OnEvict EvictFunc[K, V]
+	// OnExpire is called with expired entries only, before OnEvict.
+	OnExpire EvictFunc[K, V]
+	// Jitter shortens each TTL by a random fraction up to Jitter (0 to 1, e.g. 0.1 for up to 10%), so entries
+	// written together do not expire together.
+	Jitter float64
+}
+
+// LoaderFunc loads the value of a key missing from the cache.
+type LoaderFunc[K comparable, V any] func(ctx context.Context, k K) (V, error)
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time // removal, zero never expires
+	stale   time.Time // end of freshness, zero never stale
+	size    int64
+	elem    *list.Element
+	tags    []string
+}
+
+func (e *entry[K, V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+func (e *entry[K, V]) fresh(now time.Time) bool {
+	return e.stale.IsZero() || now.Before(e.stale)
+}
+
+// due reports whether a fresh entry is within ahead of becoming stale.
+func (e *entry[K, V]) due(now time.Time, ahead time.Duration) bool {
+	return ahead > 0 && !e.stale.IsZero() && !now.Before(e.stale.Add(-ahead))
+}
+
+// TTL is a thread safe cache with per entry expiration, optionally bounded by MaxEntries and MaxBytes with least
+// recently used eviction.
+type TTL[K comparable, V any] struct {
+	opts  Options[K, V]
+	items map[K]*entry[K, V]
+	lru   *list.List // front is most recently used
+	bytes int64
+	mu    sync.Mutex
+
+	calls      map[K]*call[V]
+	failures   map[K]failure
+	refreshing int
+	pending    []eviction[K, V]
+	stats      Stats
+	tags       map[string]map[K]struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewTTL creates a new thread safe cache with expiring entries.  Call Close to stop the expiration sweeper.
+func NewTTL[K comparable, V any](opts Options[K, V]) *TTL[K, V] {
+	if opts.Resolution == 0 {
+		opts.Resolution = DefaultResolution
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = time.Now
+	}
+
+	if opts.MaxRefreshes == 0 {
+		opts.MaxRefreshes = DefaultMaxRefreshes
+	}
+
+	c := &TTL[K, V]{
+		opts:     opts,
+		items:    make(map[K]*entry[K, V]),
+		lru:      list.New(),
+		calls:    make(map[K]*call[V]),
+		failures: make(map[K]failure),
+		tags:     make(map[string]map[K]struct{}),
+		stop:     make(chan struct{}),
+	}
+
+	if opts.Resolution > 0 {
+		go c.sweeper(opts.Resolution)
+	}
+
+	return c
+}
+
+// Get gets an item from the cache.
+// Returns the item or zero value, and a bool indicating whether the key was found and not expired or stale.
+func (c *TTL[K, V]) Get(k K) (V, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.get(k, c.opts.Clock())
+}
+
+func (c *TTL[K, V]) get(k K, now time.Time) (V, bool) {
+	if e := c.lookup(k, now); e != nil && e.fresh(now) {
+		c.stats.Hits++
+
+		return e.value, true
+	}
+
+	c.stats.Misses++
+
+	var zero V
+
+	return zero, false
+}
+
+// lookup returns the unexpired, possibly stale, entry of k, marking it recently used.
+func (c *TTL[K, V]) lookup(k K, now time.Time) *entry[K, V] {
+	e, found := c.items[k]
+	if !found {
+		return nil
+	}
+
+	if e.expired(now) {
+		c.remove(e, Expired)
+
+		return nil
+	}
+
+	c.lru.MoveToFront(e.elem)
+
+	return e
+}
+
+// Set sets any item to the cache with the default TTL, replacing any existing item.
+func (c *TTL[K, V]) Set(k K, v V) {
+	c.SetWithTTL(k, v, c.opts.TTL)
+}
+
+// SetWithTTL sets any item to the cache expiring after ttl, replacing any existing item.  ttl <= 0 never expires.
+// Items larger than MaxBytes are not cached.
+func (c *TTL[K, V]) SetWithTTL(k K, v V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.set(k, v, ttl, c.opts.Clock())
+}
+
+func (c *TTL[K, V]) set(k K, v V, ttl time.Duration, now time.Time) {
+	delete(c.failures, k)
+	c.invalidateLoad(k)
+
+	if old, found := c.items[k]; found {
+		c.remove(old, Replaced)
+	}
+
+	e := &entry[K, V]{key: k, value: v}
+	if ttl > 0 {
+		ttl = jitter(ttl, c.opts.Jitter)
+		e.stale = now.Add(ttl)
+		e.expires = e.stale.Add(max(c.opts.StaleTTL, 0))
+	}
+
+	if c.opts.Size != nil {
+		e.size = c.opts.Size(k, v)
+		if c.opts.MaxBytes > 0 && e.size > c.opts.MaxBytes {
+			c.evicted(k, v, Capacity)
+
+			return
+		}
+	}
+
+	e.elem = c.lru.PushFront(e)
+	c.items[k] = e
+	c.bytes += e.size
+
+	c.evict()
+}
+
+// jitter returns ttl shortened by a random fraction up to fraction, at least 1ns.
+func jitter(ttl time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 || ttl <= 0 {
+		return ttl
+	}
+
+	return max(ttl-time.Duration(float64(ttl)*min(fraction, 1)*rand.Float64()), 1) //nolint:gosec
+}
+
+// evict removes the least recently used entries until the limits are met.
+func (c *TTL[K, V]) evict() {
+	for c.lru.Len() > 0 && (c.opts.MaxEntries > 0 && c.lru.Len() > c.opts.MaxEntries ||
+		c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes) {
+		c.remove(c.lru.Back().Value.(*entry[K, V]), Capacity) //nolint:forcetypeassert
+	}
+}
+
+func (c *TTL[K, V]) remove(e *entry[K, V], reason Reason) {
+	c.lru.Remove(e.elem)
+	delete(c.items, e.key)
+	c.bytes -= e.size
+
+	if reason == Deleted {
+		c.invalidateLoad(e.key)
+	}
+
+	for _, tag := range e.tags {
+		delete(c.tags[tag], e.key)
+
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+
+	c.evicted(e.key, e.value, reason)
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *TTL[K, V]) Delete(k K) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	delete(c.failures, k)
+	c.invalidateLoad(k)
+
+	if e, found := c.items[k]; found {
+		c.remove(e, Deleted)
+	}
+}
+
+// Keys returns the keys of unexpired items, including stale items, the order is indeterminate.
+func (c *TTL[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.unlock()
+
+	now := c.opts.Clock()
+
+	var out []K
+
+	for k, e := range c.items {
+		if !e.expired(now) {
+			out = append(out, k)
+		}
+	}
+
+	return out
+}
+
+// Len returns the number of items, including expired items not yet swept.
+func (c *TTL[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	return len(c.items)
+}
+
+// Bytes returns the total Size of the items, 0 without Size.
+func (c *TTL[K, V]) Bytes() int64 {
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.bytes
+}
+
+// Clear resets the cache.
+func (c *TTL[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for _, e := range c.items {
+		c.evicted(e.key, e.value, Deleted)
+	}
+
+	for k := range c.calls {
+		c.invalidateLoad(k)
+	}
+
+	c.items = make(map[K]*entry[K, V])
+	c.lru.Init()
+	c.bytes = 0
+	c.tags = make(map[string]map[K]struct{})
+	c.failures = make(map[K]failure)
+}
+
+// Close stops the expiration sweeper, the cache remains usable.
+func (c *TTL[K, V]) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// DeleteExpired removes the expired items, called by the sweeper every Resolution.
+func (c *TTL[K, V]) DeleteExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	now := c.opts.Clock()
+
+	for _, e := range c.items {
+		if e.expired(now) {
+			c.remove(e, Expired)
+		}
+	}
+
+	for k, f := range c.failures {
+		if !now.Before(f.expires) {
+			delete(c.failures, k)
+		}
+	}
+}
+
+func (c *TTL[K, V]) sweeper(resolution time.Duration) {
+	ticker := time.NewTicker(resolution)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.DeleteExpired()
+		}
+	}
+}
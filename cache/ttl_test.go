@@ -0,0 +1,206 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/cache"
+)
+
+// Type assertion
+var _ cache.Cache[string, string] = cache.NewTTL(cache.Options[string, string]{})
+
+// clock is a manually advanced time source.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock() *clock { return &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)} }
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func ExampleTTL() {
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Minute})
+	defer c.Close()
+
+	c.Set("a", 1)
+	out, ok := c.Get("a")
+	fmt.Println(out, ok)
+
+	out, err := c.GetOrLoad(context.Background(), "b", func(_ context.Context, k string) (int, error) {
+		return len(k) + 1, nil
+	})
+	fmt.Println(out, err)
+
+	// Output:
+	// 1 true
+	// 2 <nil>
+}
+
+func TestTTL(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Minute, Resolution: -1, Clock: clk.Now})
+
+	c.Set("a", 1)
+	c.SetWithTTL("b", 2, time.Hour)
+	c.SetWithTTL("c", 3, 0)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	clk.Add(time.Minute)
+
+	_, ok = c.Get("a")
+	assert.False(t, ok, "expired")
+
+	kk := c.Keys()
+	sort.Strings(kk)
+	assert.Equal(t, []string{"b", "c"}, kk)
+
+	clk.Add(time.Hour)
+	assert.Equal(t, 2, c.Len(), "not swept")
+	c.DeleteExpired()
+	assert.Equal(t, 1, c.Len())
+
+	v, ok = c.Get("c")
+	assert.True(t, ok, "never expires")
+	assert.Equal(t, 3, v)
+
+	c.Delete("c")
+	assert.Equal(t, 0, c.Len())
+
+	c.Set("d", 4)
+	c.Clear()
+	assert.Nil(t, c.Keys())
+}
+
+func TestTTLSweeper(t *testing.T) {
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Millisecond, Resolution: 5 * time.Millisecond})
+	defer c.Close()
+
+	c.Set("a", 1)
+
+	assert.Eventually(t, func() bool { return c.Len() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestTTLGetOrLoad(t *testing.T) {
+	clk := newClock()
+	c := cache.NewTTL(cache.Options[string, int]{TTL: time.Minute, Resolution: -1, Clock: clk.Now})
+
+	calls := 0
+	loader := func(_ context.Context, k string) (int, error) {
+		calls++
+
+		if k == "bad" {
+			return 0, errors.New("boom")
+		}
+
+		return calls, nil
+	}
+
+	v, err := c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	v, err = c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "cached")
+
+	clk.Add(time.Minute)
+
+	v, err = c.GetOrLoad(context.Background(), "a", loader)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "reloaded after expiry")
+
+	_, err = c.GetOrLoad(context.Background(), "bad", loader)
+	require.EqualError(t, err, "boom")
+
+	_, ok := c.Get("bad")
+	assert.False(t, ok, "errors are not cached")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = c.GetOrLoad(ctx, "b", loader)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 3, calls)
+}
+
+func TestTTLMaxEntries(t *testing.T) {
+	c := cache.NewTTL(cache.Options[int, int]{Resolution: -1, MaxEntries: 3})
+
+	for i := range 3 {
+		c.Set(i, i)
+	}
+
+	_, ok := c.Get(0) // 0 is now most recently used
+	require.True(t, ok)
+
+	c.Set(3, 3)
+
+	kk := c.Keys()
+	sort.Ints(kk)
+	assert.Equal(t, []int{0, 2, 3}, kk, "1 was least recently used")
+
+	c.Set(2, 20) // replacing does not evict
+	assert.Equal(t, 3, c.Len())
+}
+
+func TestTTLMaxBytes(t *testing.T) {
+	c := cache.NewTTL(cache.Options[string, string]{
+		Resolution: -1,
+		MaxBytes:   10,
+		Size:       func(k, v string) int64 { return int64(len(k) + len(v)) },
+	})
+
+	c.Set("a", "1234")
+	c.Set("b", "1234")
+	assert.Equal(t, int64(10), c.Bytes())
+
+	c.Set("c", "1")
+	assert.Equal(t, []string{"b", "c"}, sortedKeys(c))
+	assert.Equal(t, int64(7), c.Bytes())
+
+	c.Set("b", "12")
+	assert.Equal(t, int64(5), c.Bytes(), "replaced size")
+
+	c.Set("big", "12345678")
+	_, ok := c.Get("big")
+	assert.False(t, ok, "larger than MaxBytes")
+	assert.Equal(t, []string{"b", "c"}, sortedKeys(c))
+
+	c.Delete("b")
+	assert.Equal(t, int64(2), c.Bytes())
+
+	c.Clear()
+	assert.Equal(t, int64(0), c.Bytes())
+}
+
+func sortedKeys[V any](c *cache.TTL[string, V]) []string {
+	kk := c.Keys()
+	sort.Strings(kk)
+
+	return kk
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SSMScheme is the value scheme resolved by SSMProvider, e.g. ssm:///prod/db/password.
+	SSMScheme = "ssm"
+	// SecretsManagerScheme is the value scheme resolved by SecretsManagerProvider, e.g. secretsmanager://prod/db or
+	// secretsmanager://prod/db#password for a field of a JSON secret.
+	SecretsManagerScheme = "secretsmanager"
+
+	ssmBatchSize            = 10
+	secretsManagerBatchSize = 20
+)
+
+// SSMAPI is the subset of the AWS Systems Manager Parameter Store API used by SSMProvider, typically an adapter of
+// ssm.Client.GetParameters with WithDecryption.  Parameters that do not exist are omitted from the result.
+type SSMAPI interface {
+	GetParameters(ctx context.Context, names []string) (map[string]string, error)
+}
+
+// SSMProvider resolves ssm:// values from AWS Systems Manager Parameter Store, batching requests by the API limit of
+// 10 names.
+type SSMProvider struct {
+	Client SSMAPI
+}
+
+// Resolve fetches the parameters named by refs.
+func (p SSMProvider) Resolve(ctx context.Context, refs []string) (map[string]string, error) {
+	return batch(refs, ssmBatchSize, func(names []string) (map[string]string, error) {
+		values, err := p.Client.GetParameters(ctx, names)
+		if err != nil {
+			return nil, fmt.Errorf("ssm get parameters: %w", err)
+		}
+
+		return values, nil
+	})
+}
+
+// SecretsManagerAPI is the subset of the AWS Secrets Manager API used by SecretsManagerProvider, typically an adapter
+// of secretsmanager.Client.BatchGetSecretValue returning SecretString by id.  Secrets that do not exist are omitted
+// from the result.
+type SecretsManagerAPI interface {
+	GetSecretValues(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+// SecretsManagerProvider resolves secretsmanager:// values from AWS Secrets Manager, batching requests by the API
+// limit of 20 ids.  A "#field" suffix selects a field of a JSON secret.
+type SecretsManagerProvider struct {
+	Client SecretsManagerAPI
+}
+
+// Resolve fetches the secrets referenced by refs.
+func (p SecretsManagerProvider) Resolve(ctx context.Context, refs []string) (map[string]string, error) {
+	seen := map[string]bool{}
+	ids := make([]string, 0, len(refs))
+
+	for _, ref := range refs {
+		id, _, _ := strings.Cut(ref, "#")
+		if !seen[id] {
+			seen[id] = true
+
+			ids = append(ids, id)
+		}
+	}
+
+	secrets, err := batch(ids, secretsManagerBatchSize, func(ids []string) (map[string]string, error) {
+		values, err := p.Client.GetSecretValues(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("secretsmanager get secret values: %w", err)
+		}
+
+		return values, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(refs))
+
+	for _, ref := range refs {
+		id, field, hasField := strings.Cut(ref, "#")
+
+		secret, ok := secrets[id]
+		if !ok {
+			continue
+		}
+
+		if !hasField {
+			out[ref] = secret
+
+			continue
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+			return nil, fmt.Errorf("secret %s is not a JSON object: %w", id, err)
+		}
+
+		if v, ok := fields[field]; ok {
+			out[ref] = fmt.Sprint(v)
+		}
+	}
+
+	return out, nil
+}
@@ -1,13 +1,13 @@
 package config
 
 import (
+	"context"
+	"encoding"
 	"errors"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
-
-	"github.com/spf13/viper"
+	"time"
 )
 
 var (
@@ -25,6 +25,11 @@ var (
 	ErrInvalidResolver = errors.New("invalid resolver")
 	// ErrInvalidTag is returned when a struct tag is improperly defined, e.g. `env:","`.
 	ErrInvalidTag = errors.New("invalid tag")
+	// Prefix is prepended to every environment variable name, joined by Separator, e.g. "APP" reads APP_PORT for
+	// `env:"PORT"`.  Empty uses the names as is.
+	Prefix = ""
+	// Separator joins Prefix and the names of nested structs to form environment variable names.
+	Separator = "_"
 )
 
 // Resolver is used to map a key to a value.  Examples are custom serialization used for Postgres Connection URL
@@ -40,6 +45,18 @@ const (
 	resolverPos = 2
 )
 
+// tagField is a configuration field parsed from its tag.
+type tagField struct {
+	// names are the tag names of the enclosing structs and the field.
+	names []string
+	// key is the dotted, lower-case viper key.
+	key string
+	// field is the Go field name.
+	field    string
+	resolver Resolver
+	required bool
+}
+
 // parseTag is responsible for parsing struct tags to env config.
 // The struct tag format is:
 //
@@ -52,87 +69,136 @@ const (
 //		    Port      int    `env:"PORT, 3000"`
 //		    DB        string `env:"DB,localhost,pg"`
 //	   }
-func parseTag(tag string) error {
+//
+// path holds the names of the enclosing nested structs, see Load.
+func parseTag(st *loadState, path []string, tag string) (tagField, error) {
 	args := strings.Split(tag, ",")
 
-	key := strings.TrimSpace(args[keyPos])
-	if key == "" {
-		return fmt.Errorf("%w: `%s`", ErrInvalidTag, tag)
+	name := strings.TrimSpace(args[keyPos])
+	if name == "" {
+		return tagField{}, fmt.Errorf("%w: `%s`", ErrInvalidTag, tag)
 	}
 
-	err := viper.BindEnv(key)
-	if err != nil {
-		return fmt.Errorf("binding tag: `%s`: %w", tag, err) // Ignore coverage - unlikely to error
-	}
+	names := append(path[:len(path):len(path)], name)
+	f := tagField{names: names, key: strings.ToLower(strings.Join(names, "."))}
 
 	if len(args) <= 1 {
-		return nil
+		return f, nil
 	}
 
 	def := strings.TrimSpace(args[defaultPos])
 	if def != "" {
-		viper.SetDefault(key, def)
+		st.instance().SetDefault(f.key, def)
 	}
 
 	if len(args) == resolverPos {
-		return nil
+		return f, nil
 	}
 
 	resolver := strings.TrimSpace(args[resolverPos])
 	if resolver != "" {
-		f, ok := Resolvers[resolver]
-		if !ok {
-			return fmt.Errorf("%w: `%v` for field `%v`", ErrInvalidResolver, resolver, key)
-		}
-
-		val, err := f(key)
-		if err != nil {
-			return err
+		var ok bool
+		if f.resolver, ok = Resolvers[resolver]; !ok {
+			return tagField{}, fmt.Errorf("%w: `%v` for field `%v`", ErrInvalidResolver, resolver, f.key)
 		}
-
-		viper.Set(key, val)
 	}
 
-	return nil
+	return f, nil
 }
 
-// Load uses struct tags (see parseTag) and viper to load the configuration into a config object.  The input
-// object must be a pointer to a struct.  See ExampleLoad for simple example.
-func Load(cfg any) error {
-	v := reflect.ValueOf(cfg)
-	if v.Kind() != reflect.Ptr || v.IsZero() {
-		return ErrInvalidConfigObject
+// envName is the environment variable of the key names, joined with Separator and prefixed with Prefix.
+func envName(names []string) string {
+	if Prefix != "" {
+		names = append([]string{Prefix}, names...)
 	}
 
-	viper.SetConfigFile(File)
-	viper.SetConfigType(Type)
-	viper.AutomaticEnv()
-	err := viper.ReadInConfig()
-
-	var pathError *os.PathError
-	if err != nil && !errors.As(err, &pathError) {
-		return fmt.Errorf("error loading config: %w", err)
-	}
+	return strings.Join(names, Separator)
+}
 
-	v = reflect.Indirect(v)
+// isNested reports if fields of type t are nested configuration structs, rather than values decoded from a single
+// variable.
+func isNested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) &&
+		!reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
 
-	for i := 0; i < v.NumField(); i++ {
-		f := v.Type().Field(i)
+// walkFields calls fn for each tagged field of t, recursing into nested structs.  path and index are the names and
+// field indexes of the enclosing structs, the Index of the fields passed to fn is relative to the root struct.
+func walkFields(t reflect.Type, path []string, index []int,
+	fn func(path []string, f reflect.StructField, tag string) error,
+) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		f.Index = append(index[:len(index):len(index)], i)
 
 		tag := f.Tag.Get(TagName)
 		if tag == "" || tag == "-" {
 			continue
 		}
 
-		if err = parseTag(tag); err != nil {
-			return fmt.Errorf("error parsing %s tag on field %s: %w", TagName, f.Name, err)
+		if isNested(f.Type) {
+			name := strings.TrimSpace(strings.Split(tag, ",")[keyPos])
+			if name == "" {
+				return fmt.Errorf("error parsing %s tag on field %s: %w: `%s`", TagName, f.Name, ErrInvalidTag, tag)
+			}
+
+			if err := walkFields(f.Type, append(path[:len(path):len(path)], name), f.Index, fn); err != nil {
+				return err
+			}
+
+			continue
 		}
-	}
 
-	err = viper.Unmarshal(cfg, defaultDecoderConfig)
-	if err != nil {
-		return fmt.Errorf("error Unmarshaling: %w", err)
+		if err := fn(path, f, tag); err != nil {
+			return fmt.Errorf("error parsing %s tag on field %s: %w", TagName, f.Name, err)
+		}
 	}
 
 	return nil
 }
+
+// parseFields parses the tags of the fields of t, recursing into nested structs.
+func parseFields(st *loadState, t reflect.Type, path []string) ([]tagField, error) {
+	var out []tagField
+
+	err := walkFields(t, path, nil, func(path []string, sf reflect.StructField, tag string) error {
+		f, err := parseTag(st, path, tag)
+		if err != nil {
+			return err
+		}
+
+		f.field = sf.Name
+		f.required = sf.Tag.Get(RequiredTag) == "true"
+		out = append(out, f)
+
+		return parseDefault(st, path, sf, tag)
+	})
+
+	return out, err
+}
+
+// Load uses struct tags (see parseTag) and viper to load the configuration into a config object.  The input
+// object must be a pointer to a struct.  See ExampleLoad for simple example.
+//
+// Values are resolved in decreasing precedence: flags (see RegisterFlags), environment variables, File, files given by
+// FlagName, Files, then the tag defaults, see DefaultSources.  Use a Loader for other sources.
+//
+// Struct fields (other than time.Time and encoding.TextUnmarshaler implementations) tagged with a name are nested
+// configuration, their fields are named by joining the names with Separator and prefixed by Prefix, e.g. APP_DB_HOST
+// for the Host field tagged `env:"HOST"` of the field DB tagged `env:"DB"` with Prefix "APP".  In files nested
+// structs are objects, e.g. `db: {host: localhost}`.  Slices are read from comma separated values
+// (APP_CORS_ORIGINS="a,b"), maps from JSON objects or comma separated key=value pairs.
+//
+// Secrets may be read from files, see FileSuffix and FileScheme, or from external systems, see Providers.
+//
+// Fields tagged `required:"true"` must be set, a *RequiredError lists all that are not.  The loaded values are then
+// validated by the `validate` tag rules (see Validation) and Validator, a *ValidationError lists every failing rule
+// with the environment variable and source of the value.
+func Load(cfg any) error {
+	return LoadCtx(context.Background(), cfg)
+}
+
+// LoadCtx is Load, passing ctx to Providers.
+func LoadCtx(ctx context.Context, cfg any) error {
+	return NewLoader(DefaultSources(cfg)...).Load(ctx, cfg)
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConsulWait is the blocking query wait time used by ConsulKV.Watch, the query is repeated until a change.
+var ConsulWait = 5 * time.Minute
+
+// ConsulKV reads the Consul KV store over the HTTP API.
+type ConsulKV struct {
+	// Address of the Consul agent, e.g. http://localhost:8500.
+	Address string
+	// Token is the optional ACL token.
+	Token string
+	// Client is the HTTP client, defaults to http.DefaultClient.
+	Client *http.Client
+
+	mu    sync.Mutex
+	index map[string]string // prefix => X-Consul-Index
+}
+
+type consulPair struct {
+	Key   string
+	Value *string
+}
+
+// List returns the keys under prefix.
+func (c *ConsulKV) List(ctx context.Context, prefix string) (map[string]string, error) {
+	pairs, index, err := c.get(ctx, prefix, "")
+	if err != nil {
+		return nil, err
+	}
+
+	c.setIndex(prefix, index)
+
+	out := make(map[string]string, len(pairs))
+
+	for _, p := range pairs {
+		if p.Value == nil {
+			continue // folder
+		}
+
+		v, err := base64.StdEncoding.DecodeString(*p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("consul key %s: %w", p.Key, err)
+		}
+
+		out[p.Key] = string(v)
+	}
+
+	return out, nil
+}
+
+// Watch blocks until the index of prefix changes, using blocking queries.
+func (c *ConsulKV) Watch(ctx context.Context, prefix string) error {
+	c.mu.Lock()
+	last := c.index[prefix]
+	c.mu.Unlock()
+
+	for {
+		_, index, err := c.get(ctx, prefix, last)
+		if err != nil {
+			return err
+		}
+
+		if index != last {
+			return nil
+		}
+	}
+}
+
+func (c *ConsulKV) setIndex(prefix, index string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index == nil {
+		c.index = map[string]string{}
+	}
+
+	c.index[prefix] = index
+}
+
+func (c *ConsulKV) get(ctx context.Context, prefix, index string) ([]consulPair, string, error) {
+	q := url.Values{"recurse": {"true"}}
+	if index != "" {
+		q.Set("index", index)
+		q.Set("wait", fmt.Sprintf("%ds", int(ConsulWait.Seconds())))
+	}
+
+	u := strings.TrimRight(c.Address, "/") + "/v1/kv/" + strings.TrimLeft(prefix, "/") + "?" + q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("consul request: %w", err)
+	}
+
+	if c.Token != "" {
+		req.Header.Set("X-Consul-Token", c.Token)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("consul request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	index = resp.Header.Get("X-Consul-Index")
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, index, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+
+		return nil, "", fmt.Errorf("consul: status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	var pairs []consulPair
+	if err = json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+		return nil, "", fmt.Errorf("consul response: %w", err)
+	}
+
+	return pairs, index, nil
+}
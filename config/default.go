@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// DefaultTag is the struct tag declaring the default value of a field, applied beneath environment variables and
+// files.  Unlike the default in the env tag it may contain commas, e.g. `env:"ORIGINS" default:"a.com,b.com"`.
+// Defaults are checked to decode into the field type at load, e.g. `default:"5x"` on a time.Duration fails Load.
+var DefaultTag = "default"
+
+// parseDefault applies the DefaultTag of the field f tagged with tag.
+func parseDefault(st *loadState, path []string, f reflect.StructField, tag string) error {
+	def, ok := f.Tag.Lookup(DefaultTag)
+	if !ok {
+		return nil
+	}
+
+	args := strings.Split(tag, ",")
+	if len(args) > defaultPos && strings.TrimSpace(args[defaultPos]) != "" {
+		return fmt.Errorf("%w: default declared by both %s and %s tags", ErrInvalidTag, TagName, DefaultTag)
+	}
+
+	if err := checkDefault(f.Type, def); err != nil {
+		return fmt.Errorf("%w: %s `%s`: %w", ErrInvalidTag, DefaultTag, def, err)
+	}
+
+	names := append(path[:len(path):len(path)], strings.TrimSpace(args[keyPos]))
+	st.instance().SetDefault(strings.Join(names, "."), def)
+
+	return nil
+}
+
+// checkDefault decodes def into a value of type t.
+func checkDefault(t reflect.Type, def string) error {
+	out := reflect.New(t)
+	c := &mapstructure.DecoderConfig{Result: out.Interface(), WeaklyTypedInput: true}
+	defaultDecoderConfig(c)
+
+	d, err := mapstructure.NewDecoder(c)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return d.Decode(def) //nolint:wrapcheck
+}
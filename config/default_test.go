@@ -0,0 +1,78 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type DefaultConfig struct {
+	Timeout  time.Duration `env:"TIMEOUT" default:"90s"`
+	Buffer   int64         `env:"BUFFER" default:"65536"`
+	Origins  []string      `env:"ORIGINS" default:"a.com,b.com"`
+	Debug    bool          `env:"DEBUG" default:"true"`
+	Name     string        `env:"NAME" default:""`
+	Retry    DefaultRetry  `env:"RETRY"`
+	Untagged int           `env:"UNTAGGED"`
+}
+
+type DefaultRetry struct {
+	Backoff time.Duration `env:"BACKOFF" default:"250ms"`
+}
+
+func loadDefaults(t *testing.T, cfg any, env map[string]string) error {
+	t.Helper()
+
+	defaultFile := config.File
+
+	t.Cleanup(func() { config.File = defaultFile })
+
+	config.File = ".envEMPTY"
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	return config.Load(cfg)
+}
+
+func TestDefaultTag(t *testing.T) {
+	var cfg DefaultConfig
+
+	require.NoError(t, loadDefaults(t, &cfg, nil))
+	assert.Equal(t, DefaultConfig{
+		Timeout: 90 * time.Second,
+		Buffer:  65536,
+		Origins: []string{"a.com", "b.com"},
+		Debug:   true,
+		Retry:   DefaultRetry{Backoff: 250 * time.Millisecond},
+	}, cfg)
+
+	cfg = DefaultConfig{}
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{"TIMEOUT": "5s", "ORIGINS": "c.com", "RETRY_BACKOFF": "1s"}))
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"c.com"}, cfg.Origins)
+	assert.Equal(t, time.Second, cfg.Retry.Backoff)
+}
+
+func TestDefaultTagInvalid(t *testing.T) {
+	err := loadDefaults(t, &struct {
+		Timeout time.Duration `env:"TIMEOUT" default:"5x"`
+	}{}, nil)
+	require.ErrorIs(t, err, config.ErrInvalidTag)
+	assert.ErrorContains(t, err, "error parsing env tag on field Timeout: invalid tag: default `5x`")
+
+	err = loadDefaults(t, &struct {
+		Port int `env:"PORT, 80" default:"8080"`
+	}{}, nil)
+	assert.ErrorIs(t, err, config.ErrInvalidTag)
+}
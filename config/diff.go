@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Change is a configuration value changed by a reload, secrets are masked as in Dump.
+type Change struct {
+	// Key is the environment variable name.
+	Key string `json:"key"`
+	Old string `json:"old"`
+	New string `json:"new"`
+	// Secret reports if the values are masked.
+	Secret bool `json:"secret,omitempty"`
+}
+
+// ChangeListener is called with the changes applied by a reload, see Watch.
+type ChangeListener func(ctx context.Context, changes []Change)
+
+var (
+	// LogChanges logs each change applied by Watch to the zerolog logger of the context, at info level.
+	LogChanges = true
+
+	listenersMu sync.RWMutex
+	listeners   []ChangeListener
+)
+
+// AddChangeListener registers fn to be called with the changes of every reload applied by Watch, for auditing.
+func AddChangeListener(fn ChangeListener) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+
+	listeners = append(listeners, fn)
+}
+
+// Diff returns the values that differ between prev and next, pointers to the same config struct type.  Values are
+// masked if either is a secret, a changed secret is reported with both values Redacted.
+func Diff(prev, next any) ([]Change, error) {
+	if reflect.TypeOf(prev) != reflect.TypeOf(next) {
+		return nil, ErrInvalidConfigObject
+	}
+
+	before, err := settings(prev)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := settings(next)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Change
+
+	for i, a := range after {
+		b := before[i]
+		if a.Value == b.Value {
+			continue
+		}
+
+		secret := a.Secret || b.Secret
+		out = append(out, Change{Key: a.Key, Old: mask(b.Value, secret), New: mask(a.Value, secret), Secret: secret})
+	}
+
+	return out, nil
+}
+
+// notifyChanges logs changes and calls the listeners.
+func notifyChanges(ctx context.Context, changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	if LogChanges {
+		l := zerolog.Ctx(ctx)
+		for _, c := range changes {
+			l.Info().Str("key", c.Key).Str("old", c.Old).Str("new", c.New).Bool("secret", c.Secret).Msg("config changed")
+		}
+	}
+
+	listenersMu.RLock()
+	defer listenersMu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(ctx, changes)
+	}
+}
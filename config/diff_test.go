@@ -0,0 +1,56 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+func TestDiff(t *testing.T) {
+	old := DumpConfig{Port: 1, Origins: []string{"a"}, SigningKey: "k1", Password: "pw"}
+	next := DumpConfig{Port: 2, Origins: []string{"a"}, SigningKey: "k2", Password: "pw", URL: "https://x"}
+
+	changes, err := config.Diff(&old, &next)
+	require.NoError(t, err)
+	assert.Equal(t, []config.Change{
+		{Key: "PORT", Old: "1", New: "2"},
+		{Key: "SIGNING", Old: config.Redacted, New: config.Redacted, Secret: true},
+		{Key: "UPSTREAM", Old: "", New: "https://x"},
+	}, changes)
+
+	_, err = config.Diff(&old, &FileConfig{})
+	assert.ErrorIs(t, err, config.ErrInvalidConfigObject)
+}
+
+func TestWatchChangeEvents(t *testing.T) {
+	var logs bytes.Buffer
+
+	path, _, changes := watchFile(t, zerolog.New(&logs).WithContext(context.Background()), "level: info\n")
+
+	events := make(chan []config.Change, 1)
+	config.AddChangeListener(func(_ context.Context, c []config.Change) {
+		select {
+		case events <- c:
+		default:
+		}
+	})
+
+	require.NoError(t, os.WriteFile(path, []byte("level: debug\n"), 0o600))
+
+	_, ok := waitChange(t, changes)
+	require.True(t, ok)
+	assert.Equal(t, []config.Change{{Key: "LEVEL", Old: "info", New: "debug"}}, <-events)
+	assert.JSONEq(t, `{"level":"info","key":"LEVEL","old":"info","new":"debug","secret":false,"message":"config changed"}`,
+		logs.String())
+
+	b, _ := json.Marshal(config.Change{Key: "K", Old: "a", New: "b"})
+	assert.JSONEq(t, `{"key":"K","old":"a","new":"b"}`, string(b))
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	// DotEnv enables loading DotEnvFiles in development, see IsDevelopment.  Unlike File, the files are only read when
+	// enabled and the environment guard passes, so production behavior does not depend on stray files.
+	DotEnv = false
+	// DotEnvFiles are the dotenv files loaded when DotEnv is enabled, in decreasing precedence.  Missing files are
+	// ignored.  Their variables are beneath the real environment variables, e.g. `PORT=1 ./app` overrides PORT in
+	// .env.local.
+	DotEnvFiles = []string{".env.local", ".env.development"}
+	// EnvironmentVar names the variable holding the deployment environment checked by IsDevelopment.
+	EnvironmentVar = "APP_ENV"
+	// DevEnvironments are the values of EnvironmentVar considered development, compared case-insensitively.  An
+	// unset or empty EnvironmentVar is not development, so a missing variable never enables DotEnvFiles.
+	DevEnvironments = []string{"dev", "development", "local", "test"}
+)
+
+// IsDevelopment reports if EnvironmentVar is set to one of DevEnvironments.
+func IsDevelopment() bool {
+	env := strings.ToLower(strings.TrimSpace(os.Getenv(EnvironmentVar)))
+
+	return env != "" && slices.Contains(DevEnvironments, env)
+}
+
+// dotEnvSources are the sources of DotEnvFiles, nil unless DotEnv is enabled in development.
+func dotEnvSources() []Source {
+	if !DotEnv || !IsDevelopment() {
+		return nil
+	}
+
+	out := make([]Source, len(DotEnvFiles))
+	for i, path := range DotEnvFiles {
+		out[i] = &FileSource{Path: path, Type: "env", Optional: true}
+	}
+
+	return out
+}
@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type DotEnvConfig struct {
+	Port  int    `env:"PORT, 3000"`
+	Name  string `env:"NAME"`
+	Local string `env:"LOCAL"`
+}
+
+func withDotEnv(t *testing.T, enabled bool) {
+	t.Helper()
+
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".env.local")
+	dev := filepath.Join(dir, ".env.development")
+
+	require.NoError(t, os.WriteFile(local, []byte("PORT=4000\nLOCAL=yes\n"), 0o600))
+	require.NoError(t, os.WriteFile(dev, []byte("PORT=5000\nNAME=dev\n"), 0o600))
+
+	defaultEnabled, defaultFiles := config.DotEnv, config.DotEnvFiles
+
+	t.Cleanup(func() { config.DotEnv, config.DotEnvFiles = defaultEnabled, defaultFiles })
+
+	config.DotEnv = enabled
+	config.DotEnvFiles = []string{local, dev, filepath.Join(dir, "missing")}
+}
+
+func TestDotEnv(t *testing.T) {
+	withDotEnv(t, true)
+
+	var cfg DotEnvConfig
+
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{"APP_ENV": "local"}))
+	assert.Equal(t, DotEnvConfig{Port: 4000, Name: "dev", Local: "yes"}, cfg)
+
+	cfg = DotEnvConfig{}
+	require.NoError(t, loadDefaults(t, &cfg, nil))
+	assert.Equal(t, DotEnvConfig{Port: 3000}, cfg, "not loaded when APP_ENV is unset")
+
+	cfg = DotEnvConfig{}
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{"PORT": "1", "APP_ENV": "Development"}))
+	assert.Equal(t, DotEnvConfig{Port: 1, Name: "dev", Local: "yes"}, cfg, "real variables take precedence")
+
+	cfg = DotEnvConfig{}
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{"APP_ENV": "production"}))
+	assert.Equal(t, DotEnvConfig{Port: 3000}, cfg, "not loaded outside development")
+}
+
+func TestDotEnvDisabled(t *testing.T) {
+	withDotEnv(t, false)
+
+	var cfg DotEnvConfig
+
+	require.NoError(t, loadDefaults(t, &cfg, nil))
+	assert.Equal(t, DotEnvConfig{Port: 3000}, cfg)
+}
+
+func TestIsDevelopment(t *testing.T) {
+	os.Clearenv()
+	assert.False(t, config.IsDevelopment(), "unset")
+
+	t.Setenv("APP_ENV", " ")
+	assert.False(t, config.IsDevelopment(), "empty")
+
+	t.Setenv("APP_ENV", "test")
+	assert.True(t, config.IsDevelopment())
+
+	t.Setenv("APP_ENV", "prod")
+	assert.False(t, config.IsDevelopment())
+}
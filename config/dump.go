@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bir/iken/httputil"
+)
+
+// SecretTag marks fields masked by Dump, e.g. `env:"SIGNING_KEY" secret:"true"`.
+const SecretTag = "secret"
+
+var (
+	// Redacted replaces the values of secret fields in Dump.
+	Redacted = "[REDACTED]"
+	// SecretNames matches the environment variable names of fields masked by Dump without a SecretTag.
+	SecretNames = regexp.MustCompile(`(?i)(PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|PRIVATE_?KEY|CREDENTIAL)`)
+)
+
+// Setting is a configuration value rendered by Dump.
+type Setting struct {
+	// Key is the environment variable name.
+	Key string `json:"key"`
+	// Value is the effective value, Redacted for secrets.
+	Value string `json:"value"`
+	// Source describes where the value came from, see ValidationError.Sources.
+	Source string `json:"source"`
+	// Secret reports if the value is masked.
+	Secret bool `json:"secret,omitempty"`
+}
+
+// Dump renders the effective configuration of cfg, loaded by Load, with the source of each value.  Secrets are
+// masked: fields tagged `secret:"true"`, fields matching SecretNames, values resolved by Providers and values holding
+// credentials (URLs with passwords, Postgres connection strings).
+func Dump(cfg any) ([]Setting, error) {
+	out, err := settings(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, s := range out {
+		out[i].Value = mask(s.Value, s.Secret)
+	}
+
+	return out, nil
+}
+
+// mask returns Redacted for non-empty secret values.
+func mask(value string, secret bool) string {
+	if secret && value != "" {
+		return Redacted
+	}
+
+	return value
+}
+
+// settings renders the fields of cfg without masking secrets.
+func settings(cfg any) ([]Setting, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsZero() {
+		return nil, ErrInvalidConfigObject
+	}
+
+	v = v.Elem()
+
+	var out []Setting
+
+	err := walkFields(v.Type(), nil, nil, func(path []string, f reflect.StructField, tag string) error {
+		if !f.IsExported() {
+			return nil
+		}
+
+		names := append(path[:len(path):len(path)], strings.TrimSpace(strings.Split(tag, ",")[keyPos]))
+		key := strings.ToLower(strings.Join(names, "."))
+		s := Setting{Key: envName(names), Value: formatValue(v.FieldByIndex(f.Index)), Source: source(key)}
+
+		s.Secret = f.Tag.Get(SecretTag) == "true" || SecretNames.MatchString(s.Key) || s.Source == "provider" ||
+			hasCredentials(s.Value)
+		out = append(out, s)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DumpHandler serves Dump of cfg as JSON, intended for admin endpoints.  cfg must not be modified while serving, for a
+// configuration reloaded by Watch serve Dump(cfg.Load()) instead.
+func DumpHandler(cfg any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		settings, err := Dump(cfg)
+		if err != nil {
+			httputil.ErrorHandler(w, r, err)
+
+			return
+		}
+
+		httputil.JSONWrite(w, r, http.StatusOK, settings)
+	}
+}
+
+// formatValue renders v in the format read by Load.
+func formatValue(v reflect.Value) string {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return ""
+		}
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Ptr:
+		return formatValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatValue(v.Index(i))
+		}
+
+		return strings.Join(items, ",")
+	case reflect.Map:
+		pairs := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			pairs = append(pairs, formatValue(k)+"="+formatValue(v.MapIndex(k)))
+		}
+
+		sort.Strings(pairs)
+
+		return strings.Join(pairs, ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// hasCredentials reports if s is a URL with a password or a key=value connection string with a password.
+func hasCredentials(s string) bool {
+	if strings.Contains(s, "password=") {
+		return true
+	}
+
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return false
+	}
+
+	_, ok := u.User.Password()
+
+	return ok
+}
@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type DumpConfig struct {
+	Port       int               `env:"PORT, 3000"`
+	Timeout    time.Duration     `env:"TIMEOUT"`
+	Origins    []string          `env:"ORIGINS" default:"a.com,b.com"`
+	Labels     map[string]string `env:"LABELS"`
+	SigningKey string            `env:"SIGNING" secret:"true"`
+	Password   string            `env:"DB_PASSWORD"`
+	Empty      string            `env:"API_TOKEN"`
+	URL        string            `env:"UPSTREAM"`
+	Body       config.ByteSize   `env:"BODY" default:"1MiB"`
+}
+
+func TestDump(t *testing.T) {
+	var cfg DumpConfig
+
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{
+		"TIMEOUT":     "90s",
+		"LABELS":      "b=2,a=1",
+		"SIGNING":     "k",
+		"DB_PASSWORD": "pw",
+		"UPSTREAM":    "https://user:pw@example.com",
+	}))
+
+	settings, err := config.Dump(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []config.Setting{
+		{Key: "PORT", Value: "3000", Source: "default"},
+		{Key: "TIMEOUT", Value: "1m30s", Source: "env"},
+		{Key: "ORIGINS", Value: "a.com,b.com", Source: "default"},
+		{Key: "LABELS", Value: "a=1,b=2", Source: "env"},
+		{Key: "SIGNING", Value: config.Redacted, Source: "env", Secret: true},
+		{Key: "DB_PASSWORD", Value: config.Redacted, Source: "env", Secret: true},
+		{Key: "API_TOKEN", Value: "", Source: "unset", Secret: true},
+		{Key: "UPSTREAM", Value: config.Redacted, Source: "env", Secret: true},
+		{Key: "BODY", Value: "1MiB", Source: "default"},
+	}, settings)
+
+	_, err = config.Dump(cfg)
+	assert.ErrorIs(t, err, config.ErrInvalidConfigObject)
+}
+
+func TestDumpHandler(t *testing.T) {
+	var cfg DumpConfig
+
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{"SIGNING": "k"}))
+
+	w := httptest.NewRecorder()
+	config.DumpHandler(&cfg)(w, httptest.NewRequest(http.MethodGet, "/admin/config", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"k"`)
+
+	var settings []config.Setting
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.Equal(t, config.Setting{Key: "SIGNING", Value: config.Redacted, Source: "env", Secret: true}, settings[4])
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EtcdKV reads etcd v3 over the gRPC JSON gateway.
+type EtcdKV struct {
+	// Address of an etcd endpoint, e.g. http://localhost:2379.
+	Address string
+	// Username and Password authenticate if set.
+	Username, Password string
+	// Client is the HTTP client, defaults to http.DefaultClient.
+	Client *http.Client
+
+	mu       sync.Mutex
+	token    string
+	revision map[string]int64 // prefix => revision of the last List
+}
+
+type etcdKV struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type etcdResponse struct {
+	Header struct {
+		Revision string `json:"revision"`
+	} `json:"header"`
+	Kvs    []etcdKV `json:"kvs"`
+	Token  string   `json:"token"`
+	Result *struct {
+		Events []json.RawMessage `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// List returns the keys under prefix.
+func (e *EtcdKV) List(ctx context.Context, prefix string) (map[string]string, error) {
+	var resp etcdResponse
+	if err := e.post(ctx, "/v3/kv/range", e.rangeRequest(prefix), &resp); err != nil {
+		return nil, err
+	}
+
+	rev, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+
+	e.mu.Lock()
+	if e.revision == nil {
+		e.revision = map[string]int64{}
+	}
+
+	e.revision[prefix] = rev
+	e.mu.Unlock()
+
+	out := make(map[string]string, len(resp.Kvs))
+
+	for _, kv := range resp.Kvs {
+		k, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, fmt.Errorf("etcd key: %w", err)
+		}
+
+		v, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("etcd key %s: %w", k, err)
+		}
+
+		out[string(k)] = string(v)
+	}
+
+	return out, nil
+}
+
+// Watch blocks until a key under prefix changes after the revision of the last List.
+func (e *EtcdKV) Watch(ctx context.Context, prefix string) error {
+	e.mu.Lock()
+	rev := e.revision[prefix]
+	e.mu.Unlock()
+
+	create := e.rangeRequest(prefix)
+	create["start_revision"] = strconv.FormatInt(rev+1, 10)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	body, err := e.do(ctx, "/v3/watch", map[string]any{"create_request": create})
+	if err != nil {
+		return err
+	}
+
+	defer body.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(body))
+
+	for {
+		var resp etcdResponse
+		if err = dec.Decode(&resp); err != nil {
+			return fmt.Errorf("etcd watch: %w", err)
+		}
+
+		if resp.Error != nil {
+			return fmt.Errorf("etcd watch: %s", resp.Error.Message) //nolint:err113
+		}
+
+		if resp.Result != nil && len(resp.Result.Events) > 0 {
+			return nil
+		}
+	}
+}
+
+func (e *EtcdKV) rangeRequest(prefix string) map[string]any {
+	return map[string]any{
+		"key":       base64.StdEncoding.EncodeToString([]byte(prefix)),
+		"range_end": base64.StdEncoding.EncodeToString(prefixEnd(prefix)),
+	}
+}
+
+// prefixEnd is the range end matching every key with prefix.
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+
+			return end[:i+1]
+		}
+	}
+
+	return []byte{0}
+}
+
+func (e *EtcdKV) post(ctx context.Context, path string, req any, out *etcdResponse) error {
+	body, err := e.do(ctx, path, req)
+	if err != nil {
+		return err
+	}
+
+	defer body.Close()
+
+	if err = json.NewDecoder(body).Decode(out); err != nil {
+		return fmt.Errorf("etcd response: %w", err)
+	}
+
+	return nil
+}
+
+// do posts req to path, returning the response body of successful requests.
+func (e *EtcdKV) do(ctx context.Context, path string, req any) (io.ReadCloser, error) {
+	token, err := e.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.send(ctx, path, token, req)
+}
+
+func (e *EtcdKV) authenticate(ctx context.Context) (string, error) {
+	if e.Username == "" {
+		return "", nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.token != "" {
+		return e.token, nil
+	}
+
+	body, err := e.send(ctx, "/v3/auth/authenticate", "", map[string]string{"name": e.Username, "password": e.Password})
+	if err != nil {
+		return "", fmt.Errorf("etcd authenticate: %w", err)
+	}
+
+	defer body.Close()
+
+	var resp etcdResponse
+	if err = json.NewDecoder(body).Decode(&resp); err != nil {
+		return "", fmt.Errorf("etcd authenticate: %w", err)
+	}
+
+	e.token = resp.Token
+
+	return e.token, nil
+}
+
+func (e *EtcdKV) send(ctx context.Context, path, token string, req any) (io.ReadCloser, error) {
+	b, _ := json.Marshal(req) //nolint:errchkjson
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(e.Address, "/")+path, bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("etcd request: %w", err)
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	if token != "" {
+		r.Header.Set("Authorization", token)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, fmt.Errorf("etcd request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		msg, _ := io.ReadAll(resp.Body)
+
+		return nil, fmt.Errorf("etcd: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// Files are configuration files loaded by Load in order, later files override earlier ones.  The format is
+	// selected by extension (.yaml, .yml, .toml, .json), other extensions use Type.  Unlike File, these files must
+	// exist.  Keys are matched case-insensitively against the env tag names, e.g. `port: 3000` for `env:"PORT"`.
+	Files []string
+	// FlagName is the command line flag selecting additional configuration files, e.g. `--config prod.yaml`.  The
+	// flag may be repeated, the files are layered after Files.  Empty disables the flag.
+	FlagName = "config"
+	// Args are the command line arguments searched for FlagName, defaults to os.Args.
+	Args = os.Args
+)
+
+// fileTypes maps file extensions to viper config types.
+var fileTypes = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+	".json": "json",
+}
+
+// FlagPaths returns the values of the FlagName flag in args, accepting `--config path`, `--config=path` and the
+// single dash forms.  Parsing stops at "--".
+func FlagPaths(args []string) []string {
+	if FlagName == "" {
+		return nil
+	}
+
+	var out []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != FlagName {
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				break
+			}
+
+			i++
+			value = args[i]
+		}
+
+		out = append(out, value)
+	}
+
+	return out
+}
+
+func fileType(path string) string {
+	if t, ok := fileTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return t
+	}
+
+	return Type
+}
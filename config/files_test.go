@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type FileConfig struct {
+	Debug    bool          `env:"DEBUG, false"`
+	Port     int           `env:"PORT, 3000"`
+	Interval time.Duration `env:"INTERVAL"`
+	URL      string        `env:"MY_URL"`
+}
+
+func loadFiles(t *testing.T, files []string, args []string, env map[string]string) (FileConfig, error) {
+	t.Helper()
+
+	defaultFile, defaultArgs := config.File, config.Args
+
+	t.Cleanup(func() {
+		config.File, config.Files, config.Args = defaultFile, nil, defaultArgs
+	})
+
+	config.File = ".envEMPTY"
+	config.Files = files
+	config.Args = append([]string{"app"}, args...)
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg FileConfig
+
+	err := config.Load(&cfg)
+
+	return cfg, err
+}
+
+func TestLoadFiles(t *testing.T) {
+	cfg, err := loadFiles(t, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, FileConfig{Port: 3000}, cfg)
+
+	cfg, err = loadFiles(t, []string{"testdata/base.yaml"}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, FileConfig{Debug: true, Port: 8080, Interval: 5 * time.Second}, cfg)
+
+	cfg, err = loadFiles(t, []string{"testdata/base.yaml", "testdata/override.toml"},
+		[]string{"--config", "testdata/local.json"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, FileConfig{Debug: true, Port: 9090, Interval: 30 * time.Second, URL: "https://example.com"}, cfg)
+
+	cfg, err = loadFiles(t, nil, []string{"-config=testdata/base.yaml", "--config", "testdata/override.toml"},
+		map[string]string{"PORT": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, FileConfig{Debug: true, Port: 1, Interval: 5 * time.Second, URL: "https://example.com"}, cfg)
+
+	_, err = loadFiles(t, []string{"testdata/missing.yaml"}, nil, nil)
+	require.Error(t, err)
+
+	_, err = loadFiles(t, []string{"testdata/bad.yaml"}, nil, nil)
+	require.Error(t, err)
+}
+
+func TestFlagPaths(t *testing.T) {
+	assert.Equal(t, []string{"a.yaml", "b.toml", "c.json"},
+		config.FlagPaths([]string{"-v", "--config", "a.yaml", "--config=b.toml", "-config", "c.json", "--", "--config=d"}))
+	assert.Nil(t, config.FlagPaths([]string{"--configs=x", "config", "--config"}))
+
+	defer func(name string) { config.FlagName = name }(config.FlagName)
+
+	config.FlagName = ""
+	assert.Nil(t, config.FlagPaths([]string{"--config", "a.yaml"}))
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// UsageTag is the struct tag describing a field in the flag usage registered by RegisterFlags.
+var UsageTag = "usage"
+
+// flagSets are the flag sets registered by RegisterFlags, by config type.
+var flagSets = map[reflect.Type]*flag.FlagSet{}
+
+// fieldFlag is the flag.Value of a config field, holding the raw value decoded by Load.
+type fieldFlag struct {
+	key    string
+	value  string
+	isBool bool
+}
+
+func (f *fieldFlag) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.value
+}
+
+func (f *fieldFlag) Set(s string) error {
+	f.value = s
+
+	return nil
+}
+
+func (f *fieldFlag) IsBoolFlag() bool {
+	return f.isBool
+}
+
+// configFlag accepts the FlagName flag, its values are read from Args by Load.
+type configFlag struct{}
+
+func (configFlag) String() string   { return "" }
+func (configFlag) Set(string) error { return nil }
+
+// RegisterFlags defines a flag on fs for each field of cfg, named by the lower-cased env tag names joined with "-",
+// e.g. -db-host for DB_HOST.  The usage lists the environment variable, prefixed with the UsageTag, and the tag
+// default, so -help documents every setting.  The FlagName flag is also defined when fs does not have it.
+//
+// Flags set when Load is called with the same config type take precedence over all other sources, see FlagSource.  Call
+// RegisterFlags after setting Prefix and before fs.Parse.  For spf13/pflag use pflag.CommandLine.AddGoFlagSet.
+func RegisterFlags(fs *flag.FlagSet, cfg any) error {
+	t := reflect.TypeOf(cfg)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return ErrInvalidConfigObject
+	}
+
+	err := walkFields(t.Elem(), nil, nil, func(path []string, f reflect.StructField, tag string) error {
+		args := strings.Split(tag, ",")
+
+		name := strings.TrimSpace(args[keyPos])
+		if name == "" {
+			return fmt.Errorf("%w: `%s`", ErrInvalidTag, tag)
+		}
+
+		names := append(path[:len(path):len(path)], name)
+
+		usage := "env `" + envName(names) + "`"
+		if f.Type.Kind() == reflect.Bool {
+			usage = "env " + envName(names)
+		}
+		if u := f.Tag.Get(UsageTag); u != "" {
+			usage = u + " (" + usage + ")"
+		}
+
+		def := f.Tag.Get(DefaultTag)
+		if len(args) > defaultPos && strings.TrimSpace(args[defaultPos]) != "" {
+			def = strings.TrimSpace(args[defaultPos])
+		}
+
+		value := &fieldFlag{key: strings.ToLower(strings.Join(names, ".")), value: def, isBool: f.Type.Kind() == reflect.Bool}
+		fs.Var(value, strings.ToLower(strings.Join(names, "-")), usage)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if FlagName != "" && fs.Lookup(FlagName) == nil {
+		fs.Var(configFlag{}, FlagName, "configuration `file`, may be repeated")
+	}
+
+	flagSets[t.Elem()] = fs
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package config_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type FlagConfig struct {
+	Port    int           `env:"PORT, 3000" usage:"listen port"`
+	Debug   bool          `env:"DEBUG"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s"`
+	DB      FlagDB        `env:"DB"`
+}
+
+type FlagDB struct {
+	Host string `env:"HOST, localhost" usage:"database host"`
+}
+
+func loadFlags(t *testing.T, args []string, env map[string]string) (FlagConfig, *flag.FlagSet, error) {
+	t.Helper()
+
+	defaultFile, defaultArgs := config.File, config.Args
+
+	t.Cleanup(func() { config.File, config.Files, config.Args = defaultFile, nil, defaultArgs })
+
+	config.File = ".envEMPTY"
+	config.Args = append([]string{"app"}, args...)
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg FlagConfig
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	require.NoError(t, config.RegisterFlags(fs, &cfg))
+	require.NoError(t, fs.Parse(args))
+
+	err := config.Load(&cfg)
+
+	return cfg, fs, err
+}
+
+func TestRegisterFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("port: 1\ntimeout: 1m\ndb:\n  host: file\n"), 0o600))
+
+	cfg, _, err := loadFlags(t, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, FlagConfig{Port: 3000, Timeout: 5 * time.Second, DB: FlagDB{Host: "localhost"}}, cfg)
+
+	cfg, _, err = loadFlags(t, []string{"--config", path}, map[string]string{"PORT": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, FlagConfig{Port: 2, Timeout: time.Minute, DB: FlagDB{Host: "file"}}, cfg, "env > file > defaults")
+
+	cfg, _, err = loadFlags(t, []string{"-config=" + path, "-port", "8080", "--db-host=flag", "-debug"},
+		map[string]string{"PORT": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, FlagConfig{Port: 8080, Debug: true, Timeout: time.Minute, DB: FlagDB{Host: "flag"}}, cfg,
+		"flags > env")
+
+	settings, err := config.Dump(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, config.Setting{Key: "PORT", Value: "8080", Source: "flag"}, settings[0])
+	assert.Equal(t, config.Setting{Key: "TIMEOUT", Value: "1m0s", Source: "file " + path}, settings[2])
+}
+
+func TestRegisterFlagsUsage(t *testing.T) {
+	_, fs, err := loadFlags(t, nil, nil)
+	require.NoError(t, err)
+
+	var usage bytes.Buffer
+
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+
+	assert.Contains(t, usage.String(), "-db-host DB_HOST\n    \tdatabase host (env DB_HOST) (default localhost)")
+	assert.Contains(t, usage.String(), "-port PORT\n    \tlisten port (env PORT) (default 3000)")
+	assert.Contains(t, usage.String(), "-timeout TIMEOUT\n    \tenv TIMEOUT (default 5s)")
+	assert.Contains(t, usage.String(), "-debug\n    \tenv DEBUG\n")
+	assert.Contains(t, usage.String(), "-config file\n    \tconfiguration file, may be repeated")
+}
+
+func TestRegisterFlagsInvalid(t *testing.T) {
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+
+	assert.ErrorIs(t, config.RegisterFlags(fs, FlagConfig{}), config.ErrInvalidConfigObject)
+	assert.ErrorIs(t, config.RegisterFlags(fs, &InvalidConfig{}), config.ErrInvalidTag)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/mitchellh/mapstructure"
@@ -32,17 +33,32 @@ func StringToLocationHookFunc(f reflect.Type, t reflect.Type, data any) (any, er
 	return l, nil
 }
 
-// StringToMapStringStringHookFunc converts strings to map[string]string.
+// StringToMapStringStringHookFunc converts strings to maps with string keys, from JSON objects or comma separated
+// key=value pairs (e.g. "a=1,b=2").  Values are converted to the map's value type by the decoder.
 func StringToMapStringStringHookFunc(f reflect.Type, t reflect.Type, data any) (any, error) {
 	if f.Kind() != reflect.String {
 		return data, nil
 	}
 
-	if t != reflect.TypeOf(map[string]string{}) {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
 		return data, nil
 	}
 
-	return cast.ToStringMapString(data), nil
+	s := strings.TrimSpace(data.(string))
+	if strings.HasPrefix(s, "{") || !strings.Contains(s, "=") {
+		return cast.ToStringMapString(s), nil
+	}
+
+	out := map[string]string{}
+
+	for _, pair := range strings.Split(s, ",") {
+		k, v, _ := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); k != "" {
+			out[k] = strings.TrimSpace(v)
+		}
+	}
+
+	return out, nil
 }
 
 // ErrInvalidURL is returned when a URL tag fails to parse.
@@ -87,13 +103,35 @@ func StringToTimeFunc(f reflect.Type, t reflect.Type, data any) (any, error) {
 	return out, nil
 }
 
+// ErrInvalidDuration is returned when a time.Duration fails to parse.
+var ErrInvalidDuration = errors.New("failed parsing duration")
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// StringToDurationHookFunc converts strings to time.Duration, reporting the accepted formats on failure.
+func StringToDurationHookFunc(f reflect.Type, t reflect.Type, data any) (any, error) {
+	if f.Kind() != reflect.String || t != durationType {
+		return data, nil
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(data.(string))) //nolint:forcetypeassert
+	if err != nil {
+		return nil, fmt.Errorf("%w: `%v`, expected a number with a unit ns, us, ms, s, m or h, e.g. \"90s\" or \"1h30m\"",
+			ErrInvalidDuration, data)
+	}
+
+	return d, nil
+}
+
 func defaultDecoderConfig(c *mapstructure.DecoderConfig) {
 	c.TagName = TagName
 	c.DecodeHook = mapstructure.ComposeDecodeHookFunc(
+		StringFromFileHookFunc,
 		StringToLocationHookFunc,
 		StringToMapStringStringHookFunc,
 		StringToURLHookFunc,
 		StringToTimeFunc,
-		mapstructure.StringToTimeDurationHookFunc(),
+		StringToDurationHookFunc,
+		StringToByteSizeHookFunc,
 		mapstructure.StringToSliceHookFunc(","))
 }
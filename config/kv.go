@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// KVClient reads a remote key/value store such as Consul or etcd, see ConsulKV and EtcdKV.
+type KVClient interface {
+	// List returns the values of the keys under prefix, by full key.
+	List(ctx context.Context, prefix string) (map[string]string, error)
+	// Watch blocks until a key under prefix changes after the last List, or ctx is done.
+	Watch(ctx context.Context, prefix string) error
+}
+
+// WatchableSource is a Source notifying changes, reloaded by Watch.
+type WatchableSource interface {
+	Source
+	// Watch blocks until the values of the source change, or ctx is done.
+	Watch(ctx context.Context) error
+}
+
+var (
+	// RemoteSources are added to DefaultSources after the environment variables, so they override files.  Sources
+	// implementing WatchableSource trigger reloads in Watch.
+	RemoteSources []Source
+	// WatchRetry is the delay before watching a WatchableSource again after an error.
+	WatchRetry = 5 * time.Second
+)
+
+// KVSource is a Source reading the keys under Prefix of a remote KV store, the remainder of each key is the
+// configuration key with "/" separating nested names, e.g. "app/db/host" is DB_HOST with Prefix "app/".
+type KVSource struct {
+	Client KVClient
+	Prefix string
+
+	values map[string]any
+}
+
+// NewKVSource returns a KVSource reading the keys under prefix, a trailing "/" is added if missing.
+func NewKVSource(client KVClient, prefix string) *KVSource {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	return &KVSource{Client: client, Prefix: prefix}
+}
+
+// Name returns "kv " and the prefix.
+func (s *KVSource) Name() string { return "kv " + s.Prefix }
+
+// Read lists the keys.
+func (s *KVSource) Read(ctx context.Context) error {
+	values, err := s.Client.List(ctx, s.Prefix)
+	if err != nil {
+		return fmt.Errorf("error loading config %s: %w", s.Name(), err)
+	}
+
+	s.values = make(map[string]any, len(values))
+
+	for k, v := range values {
+		key := strings.Trim(strings.TrimPrefix(k, s.Prefix), "/")
+		if key != "" {
+			s.values[strings.ToLower(strings.ReplaceAll(key, "/", "."))] = v
+		}
+	}
+
+	return nil
+}
+
+// Lookup returns the value of key.
+func (s *KVSource) Lookup(key string) (any, bool, error) {
+	v, ok := s.values[key]
+
+	return v, ok, nil
+}
+
+// Watch blocks until a key under the prefix changes.
+func (s *KVSource) Watch(ctx context.Context) error {
+	return s.Client.Watch(ctx, s.Prefix) //nolint:wrapcheck
+}
+
+// watchSources signals changes of the WatchableSource RemoteSources on changed until ctx is done.
+func watchSources(ctx context.Context, changed chan<- struct{}) {
+	for _, s := range RemoteSources {
+		ws, ok := s.(WatchableSource)
+		if !ok {
+			continue
+		}
+
+		go func() {
+			for ctx.Err() == nil {
+				if err := ws.Watch(ctx); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+
+					zerolog.Ctx(ctx).Error().Err(err).Str("source", ws.Name()).Msg("config watch")
+
+					select {
+					case <-ctx.Done():
+					case <-time.After(WatchRetry):
+					}
+
+					continue
+				}
+
+				select {
+				case changed <- struct{}{}:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+}
@@ -0,0 +1,216 @@
+package config_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+// consulServer is a minimal Consul KV API, blocking queries wait for the index to change.
+type consulServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	index  int
+	values map[string]string
+}
+
+func newConsulServer(t *testing.T, values map[string]string) *consulServer {
+	t.Helper()
+
+	s := &consulServer{index: 1, values: values}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		wait, _ := strconv.Atoi(r.URL.Query().Get("index"))
+		for deadline := time.Now().Add(time.Second); wait == s.currentIndex() && time.Now().Before(deadline); {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+		pairs := []map[string]any{{"Key": prefix, "Value": nil}}
+
+		for k, v := range s.values {
+			if strings.HasPrefix(k, prefix) {
+				pairs = append(pairs, map[string]any{"Key": k, "Value": base64.StdEncoding.EncodeToString([]byte(v))})
+			}
+		}
+
+		w.Header().Set("X-Consul-Index", strconv.Itoa(s.index))
+		_ = json.NewEncoder(w).Encode(pairs)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *consulServer) currentIndex() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.index
+}
+
+func (s *consulServer) set(key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	s.index++
+}
+
+func b64(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+// newEtcdServer is a minimal etcd v3 JSON gateway, watches report a single event after changed is closed.
+func newEtcdServer(t *testing.T, values map[string]string, changed chan struct{}) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			var req struct{ Name, Password string }
+
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Name != "app" || req.Password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "etcd-token"})
+		case "/v3/kv/range":
+			if r.Header.Get("Authorization") != "etcd-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+
+				return
+			}
+
+			var req struct {
+				Key      string `json:"key"`
+				RangeEnd string `json:"range_end"`
+			}
+
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, b64("app/"), req.Key)
+			assert.Equal(t, b64("app0"), req.RangeEnd)
+
+			kvs := []map[string]string{}
+			for k, v := range values {
+				kvs = append(kvs, map[string]string{"key": b64(k), "value": b64(v)})
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"header": map[string]string{"revision": "7"}, "kvs": kvs})
+		case "/v3/watch":
+			var req struct {
+				Create struct {
+					StartRevision string `json:"start_revision"`
+				} `json:"create_request"`
+			}
+
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			assert.Equal(t, "8", req.Create.StartRevision)
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"created": true}})
+			w.(http.Flusher).Flush()
+
+			<-changed
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"result": map[string]any{"events": []any{map[string]any{}}}})
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestConsulKVSource(t *testing.T) {
+	s := newConsulServer(t, map[string]string{"app/port": "7000", "app/db/host": "consul", "other/port": "1"})
+
+	resetLoader(t, map[string]string{"DB_PORT": "6543"})
+
+	source := config.NewKVSource(&config.ConsulKV{Address: s.URL, Token: "token"}, "app")
+	assert.Equal(t, "kv app/", source.Name())
+
+	var cfg NestedConfig
+
+	require.NoError(t, config.NewLoader(config.EnvSource{}, source).Load(context.Background(), &cfg))
+	assert.Equal(t, 7000, cfg.Port)
+	assert.Equal(t, "consul", cfg.DB.Host)
+	assert.Equal(t, 6543, cfg.DB.Port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error)
+
+	go func() { done <- source.Watch(ctx) }()
+
+	s.set("app/port", "8000")
+	require.NoError(t, <-done)
+
+	err := config.NewLoader(config.NewKVSource(&config.ConsulKV{Address: s.URL}, "app")).
+		Load(context.Background(), &cfg)
+	assert.ErrorContains(t, err, "kv app/: consul: status 403")
+}
+
+func TestEtcdKVSource(t *testing.T) {
+	changed := make(chan struct{})
+	s := newEtcdServer(t, map[string]string{"app/port": "7000", "app/db/port": "6000"}, changed)
+
+	resetLoader(t, nil)
+
+	source := config.NewKVSource(&config.EtcdKV{Address: s.URL, Username: "app", Password: "secret"}, "app/")
+
+	var cfg NestedConfig
+
+	require.NoError(t, config.NewLoader(source).Load(context.Background(), &cfg))
+	assert.Equal(t, 7000, cfg.Port)
+	assert.Equal(t, 6000, cfg.DB.Port)
+
+	done := make(chan error)
+
+	go func() { done <- source.Watch(context.Background()) }()
+
+	close(changed)
+	require.NoError(t, <-done)
+
+	err := config.NewLoader(config.NewKVSource(&config.EtcdKV{Address: s.URL, Username: "app"}, "app")).
+		Load(context.Background(), &cfg)
+	assert.ErrorContains(t, err, "etcd authenticate: etcd: status 401")
+}
+
+func TestWatchRemoteSource(t *testing.T) {
+	s := newConsulServer(t, map[string]string{"app/level": "warn"})
+
+	t.Cleanup(func() { config.RemoteSources = nil })
+
+	config.RemoteSources = []config.Source{config.NewKVSource(&config.ConsulKV{Address: s.URL, Token: "token"}, "app")}
+
+	_, cfg, changes := watchFile(t, context.Background(), "level: info\n")
+	assert.Equal(t, "warn", cfg.Load().Level)
+
+	s.set("app/rate", "3")
+
+	c, ok := waitChange(t, changes)
+	require.True(t, ok)
+	assert.Equal(t, WatchConfig{Level: "warn", Rate: 3}, c.new)
+}
@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type DBConfig struct {
+	Host    string        `env:"HOST, localhost"`
+	Port    int           `env:"PORT, 5432"`
+	Timeout time.Duration `env:"TIMEOUT"`
+}
+
+type CORSConfig struct {
+	Origins []string `env:"ORIGINS"`
+}
+
+type NestedConfig struct {
+	Port    int               `env:"PORT, 3000"`
+	DB      DBConfig          `env:"DB"`
+	Replica DBConfig          `env:"REPLICA"`
+	CORS    CORSConfig        `env:"CORS"`
+	Limits  map[string]int    `env:"LIMITS"`
+	Labels  map[string]string `env:"LABELS"`
+	Started time.Time         `env:"STARTED"`
+	Ignored DBConfig
+}
+
+func loadNested(t *testing.T, prefix, separator string, files []string, env map[string]string) (NestedConfig, error) {
+	t.Helper()
+
+	defaultFile := config.File
+
+	t.Cleanup(func() {
+		config.File, config.Files, config.Prefix, config.Separator = defaultFile, nil, "", "_"
+	})
+
+	config.File = ".envEMPTY"
+	config.Files = files
+	config.Prefix = prefix
+	config.Separator = separator
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg NestedConfig
+
+	err := config.Load(&cfg)
+
+	return cfg, err
+}
+
+func TestLoadNested(t *testing.T) {
+	cfg, err := loadNested(t, "APP", "_", nil, map[string]string{
+		"APP_PORT":           "8080",
+		"APP_DB_HOST":        "db.internal",
+		"APP_DB_TIMEOUT":     "3s",
+		"APP_REPLICA_PORT":   "5433",
+		"APP_CORS_ORIGINS":   "https://a.com,https://b.com",
+		"APP_LIMITS":         "read=100,write=10",
+		"APP_LABELS":         `{"team":"core"}`,
+		"APP_STARTED":        "2024-01-02T03:04:05Z",
+		"DB_HOST":            "unprefixed",
+		"APP_IGNORED_HOST":   "ignored",
+		"APP_UNKNOWN_OPTION": "x",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, NestedConfig{
+		Port:    8080,
+		DB:      DBConfig{Host: "db.internal", Port: 5432, Timeout: 3 * time.Second},
+		Replica: DBConfig{Host: "localhost", Port: 5433},
+		CORS:    CORSConfig{Origins: []string{"https://a.com", "https://b.com"}},
+		Limits:  map[string]int{"read": 100, "write": 10},
+		Labels:  map[string]string{"team": "core"},
+		Started: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, cfg)
+
+	cfg, err = loadNested(t, "", "__", nil, map[string]string{"DB__HOST": "double", "DB_HOST": "single"})
+	require.NoError(t, err)
+	assert.Equal(t, "double", cfg.DB.Host)
+
+	cfg, err = loadNested(t, "", "_", []string{"testdata/nested.yaml"}, map[string]string{"DB_PORT": "6543"})
+	require.NoError(t, err)
+	assert.Equal(t, DBConfig{Host: "file.internal", Port: 6543}, cfg.DB)
+	assert.Equal(t, []string{"x", "y"}, cfg.CORS.Origins)
+}
+
+func TestLoadNestedInvalidTag(t *testing.T) {
+	viper.Reset()
+
+	err := config.Load(&struct {
+		DB DBConfig `env:" ,x"`
+	}{})
+	assert.ErrorIs(t, err, config.ErrInvalidTag)
+}
@@ -5,15 +5,14 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-
-	"github.com/spf13/viper"
 )
 
 var ApplicationName string
 
-// PgDBResolver is a wrapper to the GetPgDBString that adheres to the Resolver interface.
+// PgDBResolver is a wrapper to the GetPgDBString that adheres to the Resolver interface, reporting unreadable secret
+// files.
 func PgDBResolver(key string) (any, error) {
-	return GetPgDBString(key), nil
+	return pgDBString(key)
 }
 
 func appendIf(aa []string, key, value string) []string {
@@ -28,20 +27,38 @@ func appendIf(aa []string, key, value string) []string {
 // for example GetPgDBString("DB") will read "DB_HOST", "DB_PORT", etc.  This is used
 // in environments that manage the params separately.  Otherwise, just use a string type directly.
 // application_name is attached to the connection string, it automatically uses the current running application.
-// Set ApplicationName to override the value.
+// Set ApplicationName to override the value.  Values may be read from files, e.g. DB_PASSWORD_FILE, see FileSuffix.
 func GetPgDBString(base string) string {
+	s, _ := pgDBString(base)
+
+	return s
+}
+
+var pgParams = []struct{ name, suffix string }{
+	{"host", "_HOST"},
+	{"port", "_PORT"},
+	{"user", "_USER"},
+	{"password", "_PASSWORD"},
+	{"dbname", "_NAME"},
+	{"sslmode", "_SSLMODE"},
+	{"pool_max_conns", "_MAX_CONN"},
+	{"search_path", "_SEARCH_PATH"},
+}
+
+func pgDBString(base string) (string, error) {
 	var pairs []string
-	pairs = appendIf(pairs, "host", viper.GetString(base+"_HOST"))
-	pairs = appendIf(pairs, "port", viper.GetString(base+"_PORT"))
-	pairs = appendIf(pairs, "user", viper.GetString(base+"_USER"))
-	pairs = appendIf(pairs, "password", viper.GetString(base+"_PASSWORD"))
-	pairs = appendIf(pairs, "dbname", viper.GetString(base+"_NAME"))
-	pairs = appendIf(pairs, "sslmode", viper.GetString(base+"_SSLMODE"))
-	pairs = appendIf(pairs, "pool_max_conns", viper.GetString(base+"_MAX_CONN"))
-	pairs = appendIf(pairs, "search_path", viper.GetString(base+"_SEARCH_PATH"))
+
+	for _, p := range pgParams {
+		value, err := getString(base + p.suffix)
+		if err != nil {
+			return "", err
+		}
+
+		pairs = appendIf(pairs, p.name, value)
+	}
 
 	if len(pairs) == 0 {
-		return ""
+		return "", nil
 	}
 
 	if ApplicationName == "" {
@@ -50,5 +67,5 @@ func GetPgDBString(base string) string {
 
 	pairs = append(pairs, "application_name="+ApplicationName)
 
-	return strings.Join(pairs, " ")
+	return strings.Join(pairs, " "), nil
 }
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Provider resolves configuration values stored in external systems, declared as scheme://ref values (e.g.
+// ssm:///prod/db/password).  Resolve receives every ref of the provider's scheme at once, so providers can batch
+// requests.  Refs that do not exist are omitted from the result, Load reports them with ErrMissingValue.
+type Provider interface {
+	Resolve(ctx context.Context, refs []string) (map[string]string, error)
+}
+
+// ProviderFunc adapts a function to Provider.
+type ProviderFunc func(ctx context.Context, refs []string) (map[string]string, error)
+
+// Resolve calls f.
+func (f ProviderFunc) Resolve(ctx context.Context, refs []string) (map[string]string, error) {
+	return f(ctx, refs)
+}
+
+// ProviderMap maintains the map of value schemes to Provider.
+type ProviderMap = map[string]Provider
+
+var (
+	// Providers resolve values by scheme, e.g. Providers["ssm"] resolves "ssm://..." values.  Values with schemes not
+	// registered are used as is.
+	Providers = ProviderMap{}
+	// ErrMissingValue is returned when a provider reference does not exist.
+	ErrMissingValue = errors.New("config value not found")
+)
+
+// resolveProviders replaces the values referencing a registered provider with the resolved values.  Failures name
+// the configuration key and reference.
+func resolveProviders(ctx context.Context, st *loadState) error {
+	if len(Providers) == 0 {
+		return nil
+	}
+
+	refs := map[string]map[string][]string{} // scheme => ref => keys
+
+	for _, key := range st.instance().AllKeys() {
+		value, ok := st.instance().Get(key).(string)
+		if !ok {
+			continue
+		}
+
+		scheme, ref, found := strings.Cut(value, "://")
+		if _, registered := Providers[scheme]; !found || !registered {
+			continue
+		}
+
+		if refs[scheme] == nil {
+			refs[scheme] = map[string][]string{}
+		}
+
+		refs[scheme][ref] = append(refs[scheme][ref], key)
+	}
+
+	for scheme, keys := range refs {
+		list := make([]string, 0, len(keys))
+		for ref := range keys {
+			list = append(list, ref)
+		}
+
+		sort.Strings(list)
+
+		values, err := Providers[scheme].Resolve(ctx, list)
+		if err != nil {
+			return fmt.Errorf("error resolving %s values: %w", scheme, err)
+		}
+
+		for _, ref := range list {
+			value, ok := values[ref]
+			if !ok {
+				return fmt.Errorf("error resolving %s: %s://%s: %w", keyName(keys[ref][0]), scheme, ref, ErrMissingValue)
+			}
+
+			for _, key := range keys[ref] {
+				st.set(key, "provider", value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// cachedProvider caches resolved values for a TTL.
+type cachedProvider struct {
+	p   Provider
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	values map[string]cachedValue
+}
+
+type cachedValue struct {
+	value   string
+	expires time.Time
+}
+
+// Cached wraps p, caching resolved values for ttl so repeated loads (e.g. reloads, multiple config structs) do not
+// refetch them.  ttl <= 0 caches values forever.
+func Cached(p Provider, ttl time.Duration) Provider {
+	return &cachedProvider{p: p, ttl: ttl, now: time.Now, values: map[string]cachedValue{}}
+}
+
+func (c *cachedProvider) Resolve(ctx context.Context, refs []string) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	out := make(map[string]string, len(refs))
+
+	var missing []string
+
+	for _, ref := range refs {
+		if v, ok := c.values[ref]; ok && (c.ttl <= 0 || now.Before(v.expires)) {
+			out[ref] = v.value
+
+			continue
+		}
+
+		missing = append(missing, ref)
+	}
+
+	if len(missing) == 0 {
+		return out, nil
+	}
+
+	values, err := c.p.Resolve(ctx, missing)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	for ref, value := range values {
+		c.values[ref] = cachedValue{value: value, expires: now.Add(c.ttl)}
+		out[ref] = value
+	}
+
+	return out, nil
+}
+
+// batch calls fn with refs in batches of at most size, merging the results.
+func batch(refs []string, size int, fn func(batch []string) (map[string]string, error)) (map[string]string, error) {
+	out := make(map[string]string, len(refs))
+
+	for len(refs) > 0 {
+		n := min(size, len(refs))
+
+		values, err := fn(refs[:n])
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range values {
+			out[k] = v
+		}
+
+		refs = refs[n:]
+	}
+
+	return out, nil
+}
+
+// keyName is the environment variable name of the viper key.
+func keyName(key string) string {
+	return envName(strings.Split(strings.ToUpper(key), "."))
+}
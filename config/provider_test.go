@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type ProviderConfig struct {
+	Password string `env:"PASSWORD"`
+	Token    string `env:"TOKEN, ssm:///app/token"`
+	APIKey   string `env:"API_KEY"`
+	Plain    string `env:"PLAIN, https://example.com"`
+}
+
+type fakeSSM struct {
+	values map[string]string
+	calls  [][]string
+}
+
+func (f *fakeSSM) GetParameters(_ context.Context, names []string) (map[string]string, error) {
+	f.calls = append(f.calls, names)
+
+	out := map[string]string{}
+
+	for _, n := range names {
+		if v, ok := f.values[n]; ok {
+			out[n] = v
+		}
+	}
+
+	return out, nil
+}
+
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) GetSecretValues(_ context.Context, ids []string) (map[string]string, error) {
+	out := map[string]string{}
+
+	for _, id := range ids {
+		if v, ok := f[id]; ok {
+			out[id] = v
+		}
+	}
+
+	return out, nil
+}
+
+func loadProviders(t *testing.T, providers config.ProviderMap, env map[string]string) (ProviderConfig, error) {
+	t.Helper()
+
+	defaultFile, defaultProviders := config.File, config.Providers
+
+	t.Cleanup(func() { config.File, config.Providers = defaultFile, defaultProviders })
+
+	config.File = ".envEMPTY"
+	config.ApplicationName = "test"
+	config.Providers = providers
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg ProviderConfig
+
+	err := config.LoadCtx(context.Background(), &cfg)
+
+	return cfg, err
+}
+
+func TestProviders(t *testing.T) {
+	ssm := &fakeSSM{values: map[string]string{"/app/token": "tok", "/app/password": "pw"}}
+	secrets := fakeSecrets{"prod/api": `{"key":"k1","n":2}`}
+
+	cfg, err := loadProviders(t, config.ProviderMap{
+		config.SSMScheme:            config.SSMProvider{Client: ssm},
+		config.SecretsManagerScheme: config.SecretsManagerProvider{Client: secrets},
+	}, map[string]string{"PASSWORD": "ssm:///app/password", "API_KEY": "secretsmanager://prod/api#key"})
+	require.NoError(t, err)
+	assert.Equal(t, ProviderConfig{Password: "pw", Token: "tok", APIKey: "k1", Plain: "https://example.com"}, cfg)
+	assert.Equal(t, [][]string{{"/app/password", "/app/token"}}, ssm.calls, "single batched call")
+
+	_, err = loadProviders(t, config.ProviderMap{config.SSMScheme: config.SSMProvider{Client: ssm}},
+		map[string]string{"PASSWORD": "ssm:///app/missing"})
+	require.ErrorIs(t, err, config.ErrMissingValue)
+	assert.EqualError(t, err, "error resolving PASSWORD: ssm:///app/missing: config value not found")
+
+	failing := config.ProviderFunc(func(context.Context, []string) (map[string]string, error) {
+		return nil, errors.New("denied")
+	})
+	_, err = loadProviders(t, config.ProviderMap{config.SSMScheme: failing}, nil)
+	assert.EqualError(t, err, "error resolving ssm values: denied")
+
+	cfg, err = loadProviders(t, config.ProviderMap{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ssm:///app/token", cfg.Token, "unregistered schemes are used as is")
+}
+
+func TestSSMProviderBatches(t *testing.T) {
+	ssm := &fakeSSM{values: map[string]string{}}
+
+	refs := make([]string, 25)
+	for i := range refs {
+		refs[i] = "/p/" + strings.Repeat("x", i)
+		ssm.values[refs[i]] = refs[i]
+	}
+
+	values, err := config.SSMProvider{Client: ssm}.Resolve(context.Background(), refs)
+	require.NoError(t, err)
+	assert.Len(t, values, 25)
+	require.Len(t, ssm.calls, 3)
+	assert.Len(t, ssm.calls[0], 10)
+	assert.Len(t, ssm.calls[2], 5)
+}
+
+func TestSecretsManagerProvider(t *testing.T) {
+	p := config.SecretsManagerProvider{Client: fakeSecrets{"db": `{"user":"u","port":5432}`, "raw": "plain"}}
+
+	values, err := p.Resolve(context.Background(), []string{"db#user", "db#port", "db#none", "raw", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db#user": "u", "db#port": "5432", "raw": "plain"}, values)
+
+	_, err = p.Resolve(context.Background(), []string{"raw#field"})
+	assert.ErrorContains(t, err, "secret raw is not a JSON object")
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	p := config.Cached(config.ProviderFunc(func(_ context.Context, refs []string) (map[string]string, error) {
+		calls++
+
+		out := map[string]string{}
+		for _, r := range refs {
+			out[r] = r
+		}
+
+		return out, nil
+	}), time.Hour)
+
+	for range 2 {
+		values, err := p.Resolve(context.Background(), []string{"a", "b"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "a", "b": "b"}, values)
+	}
+
+	assert.Equal(t, 1, calls)
+
+	_, err := p.Resolve(context.Background(), []string{"a", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// RequiredTag marks fields that must be set by a source, e.g. `env:"DB_HOST" required:"true"`.  Empty values count
+// as missing, tag defaults satisfy the requirement.
+const RequiredTag = "required"
+
+// ErrMissingRequired is returned when required fields are not set.
+var ErrMissingRequired = errors.New("missing required config")
+
+// RequiredField is a required field that is not set.
+type RequiredField struct {
+	// Env is the environment variable expected.
+	Env string
+	// Key is the dotted key expected in files, e.g. "db.host".
+	Key string
+}
+
+// RequiredError lists every required field that is not set.
+type RequiredError struct {
+	Fields []RequiredField
+}
+
+// Error lists the expected variables and file keys.
+func (e *RequiredError) Error() string {
+	items := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		items[i] = fmt.Sprintf("%s (file key %s)", f.Env, f.Key)
+	}
+
+	return fmt.Sprintf("%s: %s", ErrMissingRequired, strings.Join(items, ", "))
+}
+
+// Is reports ErrMissingRequired.
+func (e *RequiredError) Is(target error) bool {
+	return target == ErrMissingRequired //nolint:errorlint
+}
+
+// checkRequired reports the required fields without values.
+func checkRequired(st *loadState, fields []tagField) error {
+	var missing []RequiredField
+
+	for _, f := range fields {
+		if f.required && (!st.instance().IsSet(f.key) || cast.ToString(st.instance().Get(f.key)) == "") {
+			missing = append(missing, RequiredField{Env: keyName(f.key), Key: f.key})
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return &RequiredError{Fields: missing}
+}
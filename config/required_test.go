@@ -0,0 +1,44 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type RequiredConfig struct {
+	Token string         `env:"TOKEN" required:"true"`
+	Port  int            `env:"PORT, 3000" required:"true"`
+	DB    RequiredDB     `env:"DB"`
+	Opt   string         `env:"OPT"`
+	Other map[string]int `env:"OTHER" required:"false"`
+}
+
+type RequiredDB struct {
+	Host string `env:"HOST" required:"true"`
+	Name string `env:"NAME" required:"true"`
+}
+
+func TestRequired(t *testing.T) {
+	var cfg RequiredConfig
+
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{"TOKEN": "t", "DB_HOST": "h", "DB_NAME": "n"}))
+	assert.Equal(t, RequiredConfig{Token: "t", Port: 3000, DB: RequiredDB{Host: "h", Name: "n"}}, cfg)
+
+	err := loadDefaults(t, &RequiredConfig{}, map[string]string{"DB_NAME": ""})
+	require.ErrorIs(t, err, config.ErrMissingRequired)
+	assert.EqualError(t, err, "missing required config: TOKEN (file key token), DB_HOST (file key db.host), "+
+		"DB_NAME (file key db.name)")
+
+	var re *config.RequiredError
+
+	require.ErrorAs(t, err, &re)
+	assert.Equal(t, []config.RequiredField{
+		{Env: "TOKEN", Key: "token"},
+		{Env: "DB_HOST", Key: "db.host"},
+		{Env: "DB_NAME", Key: "db.name"},
+	}, re.Fields)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// FileScheme prefixes values read from a file, e.g. DB_PASSWORD=file:///run/secrets/db_password.
+const FileScheme = "file://"
+
+var (
+	// FileSuffix is appended to environment variable names to read the value from a file, as used by Docker and
+	// Kubernetes secrets, e.g. DB_PASSWORD_FILE=/run/secrets/db_password.  The variable itself takes precedence if
+	// set.  Empty disables the lookup.
+	FileSuffix = "_FILE"
+	// ErrSecretFile is returned when a file referenced by FileSuffix or FileScheme can not be read.
+	ErrSecretFile = errors.New("failed reading secret file")
+)
+
+// readSecret returns the contents of the file path, without the trailing newline.
+func readSecret(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrSecretFile, err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// getString returns the value of key from the Loader running, or the environment, honoring FileSuffix and FileScheme.
+func getString(key string) (string, error) {
+	l := active.Load()
+	if l == nil {
+		l = NewLoader(EnvSource{})
+	}
+
+	value, _, ok, err := l.lookup(strings.ToLower(key))
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		value = l.instance().Get(key)
+	}
+
+	return fromFile(cast.ToString(value))
+}
+
+func fromFile(value string) (string, error) {
+	path, ok := strings.CutPrefix(value, FileScheme)
+	if !ok {
+		return value, nil
+	}
+
+	return readSecret(path)
+}
+
+// StringFromFileHookFunc replaces string values using FileScheme with the contents of the file.
+func StringFromFileHookFunc(f reflect.Type, _ reflect.Type, data any) (any, error) {
+	if f.Kind() != reflect.String {
+		return data, nil
+	}
+
+	return fromFile(data.(string)) //nolint:forcetypeassert
+}
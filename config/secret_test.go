@@ -0,0 +1,98 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type SecretConfig struct {
+	Password string    `env:"PASSWORD"`
+	Token    string    `env:"TOKEN, file://testdata/token"`
+	DB       string    `env:"DB,,pg"`
+	API      APIConfig `env:"API"`
+}
+
+type APIConfig struct {
+	Key string `env:"KEY"`
+}
+
+func loadSecrets(t *testing.T, env map[string]string) (SecretConfig, error) {
+	t.Helper()
+
+	defaultFile := config.File
+
+	t.Cleanup(func() { config.File = defaultFile })
+
+	config.File = ".envEMPTY"
+	config.ApplicationName = "test"
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg SecretConfig
+
+	err := config.Load(&cfg)
+
+	return cfg, err
+}
+
+func writeSecret(t *testing.T, name, value string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(value), 0o600))
+
+	return path
+}
+
+func TestSecretFiles(t *testing.T) {
+	password := writeSecret(t, "password", "s3cret\n")
+	key := writeSecret(t, "key", "api-key\r\n")
+	dbPassword := writeSecret(t, "db_password", "dbpass\n")
+
+	cfg, err := loadSecrets(t, map[string]string{
+		"PASSWORD_FILE":    password,
+		"API_KEY":          "file://" + key,
+		"DB_HOST":          "localhost",
+		"DB_PASSWORD_FILE": dbPassword,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SecretConfig{
+		Password: "s3cret",
+		Token:    "from-file",
+		DB:       "host=localhost password=dbpass application_name=test",
+		API:      APIConfig{Key: "api-key"},
+	}, cfg)
+
+	cfg, err = loadSecrets(t, map[string]string{"PASSWORD": "direct", "PASSWORD_FILE": password})
+	require.NoError(t, err)
+	assert.Equal(t, "direct", cfg.Password)
+
+	_, err = loadSecrets(t, map[string]string{"PASSWORD_FILE": "testdata/missing"})
+	require.ErrorIs(t, err, config.ErrSecretFile)
+
+	// Decoder errors are not wrapped by mapstructure
+	_, err = loadSecrets(t, map[string]string{"API_KEY": "file://testdata/missing"})
+	require.ErrorContains(t, err, config.ErrSecretFile.Error())
+
+	_, err = loadSecrets(t, map[string]string{"DB_PASSWORD_FILE": "testdata/missing"})
+	require.ErrorIs(t, err, config.ErrSecretFile)
+
+	defer func(s string) { config.FileSuffix = s }(config.FileSuffix)
+
+	config.FileSuffix = ""
+	cfg, err = loadSecrets(t, map[string]string{"PASSWORD_FILE": password})
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.Password)
+}
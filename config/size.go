@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes, loaded from values such as "512", "64MiB" or "1GB".
+type ByteSize int64
+
+// Byte size units, KB and friends are decimal, KiB and friends binary.
+const (
+	KB  ByteSize = 1000
+	MB           = KB * 1000
+	GB           = MB * 1000
+	TB           = GB * 1000
+	KiB ByteSize = 1 << 10
+	MiB          = KiB << 10
+	GiB          = MiB << 10
+	TiB          = GiB << 10
+)
+
+// ErrInvalidByteSize is returned when a byte size fails to parse.
+var ErrInvalidByteSize = errors.New("failed parsing byte size")
+
+// byteSizeFormats describes the accepted byte size formats in errors.
+const byteSizeFormats = `expected a number with an optional unit B, KB, MB, GB, TB, KiB, MiB, GiB or TiB, e.g. "64MiB"`
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"kib", KiB},
+	{"mib", MiB},
+	{"gib", GiB},
+	{"tib", TiB},
+	{"kb", KB},
+	{"mb", MB},
+	{"gb", GB},
+	{"tb", TB},
+	{"k", KiB},
+	{"m", MiB},
+	{"g", GiB},
+	{"t", TiB},
+	{"b", 1},
+}
+
+var byteSizeNames = []struct {
+	suffix string
+	size   ByteSize
+}{{"TiB", TiB}, {"TB", TB}, {"GiB", GiB}, {"GB", GB}, {"MiB", MiB}, {"MB", MB}, {"KiB", KiB}, {"KB", KB}}
+
+// ParseByteSize parses a number of bytes with an optional unit, units are case-insensitive and the short forms K, M,
+// G and T are binary.  Fractional values are allowed with a unit, e.g. "1.5GiB".
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.ToLower(strings.TrimSpace(s))
+	unit := ByteSize(1)
+
+	for _, u := range byteSizeUnits {
+		if n, ok := strings.CutSuffix(value, u.suffix); ok {
+			value, unit = strings.TrimSpace(n), u.size
+
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || n*float64(unit) > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: `%s`, %s", ErrInvalidByteSize, s, byteSizeFormats)
+	}
+
+	size := n * float64(unit)
+	if size != math.Trunc(size) {
+		return 0, fmt.Errorf("%w: `%s` is not a whole number of bytes", ErrInvalidByteSize, s)
+	}
+
+	return ByteSize(size), nil
+}
+
+// String formats the size with the largest unit dividing it exactly, e.g. "64MiB".
+func (b ByteSize) String() string {
+	for _, u := range byteSizeNames {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.suffix
+		}
+	}
+
+	return strconv.FormatInt(int64(b), 10) + "B"
+}
+
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+// StringToByteSizeHookFunc converts strings to ByteSize, and to other integer types when the string has a byte size
+// unit, e.g. "64MiB" for an int64 field.
+func StringToByteSizeHookFunc(f reflect.Type, t reflect.Type, data any) (any, error) {
+	if f.Kind() != reflect.String {
+		return data, nil
+	}
+
+	s := data.(string) //nolint:forcetypeassert
+
+	switch {
+	case t == byteSizeType:
+		return ParseByteSize(s)
+	case t == durationType:
+		return data, nil
+	case t.Kind() == reflect.Int || t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64:
+		if _, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+			return data, nil
+		}
+
+		if size, err := ParseByteSize(s); err == nil {
+			return int64(size), nil
+		}
+	}
+
+	return data, nil
+}
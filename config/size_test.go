@@ -0,0 +1,64 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for s, want := range map[string]config.ByteSize{
+		"512":    512,
+		"512B":   512,
+		"64MiB":  64 << 20,
+		"64mib":  64 << 20,
+		"1GB":    1_000_000_000,
+		"1.5KiB": 1536,
+		"2 k":    2048,
+		"1TiB":   1 << 40,
+	} {
+		got, err := config.ParseByteSize(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+
+	for _, s := range []string{"", "MiB", "-1KB", "1XB", "1.5"} {
+		_, err := config.ParseByteSize(s)
+		assert.ErrorIs(t, err, config.ErrInvalidByteSize, s)
+	}
+
+	_, err := config.ParseByteSize("64XB")
+	assert.EqualError(t, err, "failed parsing byte size: `64XB`, expected a number with an optional unit B, KB, MB, GB, "+
+		"TB, KiB, MiB, GiB or TiB, e.g. \"64MiB\"")
+}
+
+func TestByteSizeString(t *testing.T) {
+	assert.Equal(t, "64MiB", (64 * config.MiB).String())
+	assert.Equal(t, "2GB", (2 * config.GB).String())
+	assert.Equal(t, "1500B", config.ByteSize(1500).String())
+	assert.Equal(t, "0B", config.ByteSize(0).String())
+}
+
+func TestLoadSizesAndDurations(t *testing.T) {
+	type SizeConfig struct {
+		Body    config.ByteSize `env:"BODY" default:"1MiB"`
+		Cache   int64           `env:"CACHE"`
+		Count   int             `env:"COUNT"`
+		Timeout time.Duration   `env:"TIMEOUT"`
+	}
+
+	var cfg SizeConfig
+
+	require.NoError(t, loadDefaults(t, &cfg, map[string]string{"CACHE": "64MiB", "COUNT": "7", "TIMEOUT": "1m30s"}))
+	assert.Equal(t, SizeConfig{Body: config.MiB, Cache: 64 << 20, Count: 7, Timeout: 90 * time.Second}, cfg)
+
+	err := loadDefaults(t, &SizeConfig{}, map[string]string{"BODY": "lots"})
+	assert.ErrorContains(t, err, "error decoding 'BODY': failed parsing byte size: `lots`, expected a number")
+
+	err = loadDefaults(t, &SizeConfig{}, map[string]string{"TIMEOUT": "90"})
+	assert.ErrorContains(t, err, "failed parsing duration: `90`, expected a number with a unit ns, us, ms, s, m or h")
+}
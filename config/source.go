@@ -0,0 +1,384 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+// Source provides configuration values to a Loader, e.g. environment variables, files, flags or a remote store.
+type Source interface {
+	// Name describes the source in ValidationError and Dump, e.g. "env" or "file app.yaml".
+	Name() string
+	// Read prepares the source for lookups, called once per load, e.g. to read a file.
+	Read(ctx context.Context) error
+	// Lookup returns the value of the dotted, lower-case key (e.g. "db.host"), ok is false if the source does not
+	// set it.
+	Lookup(key string) (value any, ok bool, err error)
+}
+
+// Loader loads configuration structs from Sources in decreasing precedence, the first source setting a key wins.
+// Tag defaults apply to keys no source sets.  See Load for the default sources.
+type Loader struct {
+	Sources []Source
+
+	// private loads into a new viper instance instead of the global one, see Watch.
+	private bool
+	// state holds the values while loading.
+	state *loadState
+}
+
+// NewLoader returns a Loader reading sources in decreasing precedence, e.g. to insert a custom source:
+//
+//	config.NewLoader(append([]config.Source{legacyINI}, config.DefaultSources(&cfg)...)...).Load(ctx, &cfg)
+func NewLoader(sources ...Source) *Loader {
+	return &Loader{Sources: sources}
+}
+
+// DefaultSources are the sources used by Load for cfg, in decreasing precedence: the flags registered for the type
+// of cfg (see RegisterFlags), environment variables, RemoteSources, DotEnvFiles (when DotEnv is enabled), File, files
+// given by FlagName (last first), then Files (last first).
+func DefaultSources(cfg any) []Source {
+	var out []Source
+
+	if t := reflect.TypeOf(cfg); t != nil && t.Kind() == reflect.Ptr {
+		if fs, ok := flagSets[t.Elem()]; ok {
+			out = append(out, FlagSource(fs))
+		}
+	}
+
+	out = append(out, EnvSource{})
+	out = append(out, RemoteSources...)
+	out = append(out, dotEnvSources()...)
+	out = append(out, &FileSource{Path: File, Type: Type, Optional: true})
+
+	paths := configPaths()
+	for _, path := range slices.Backward(paths[:len(paths)-1]) {
+		out = append(out, NewFileSource(path))
+	}
+
+	return out
+}
+
+// origin is the source of a value set by a Loader.
+type origin struct {
+	name  string
+	value any
+}
+
+// loadState holds the values set by a Loader and their sources.
+type loadState struct {
+	// v is the viper instance holding the values, nil for the global viper.
+	v       *viper.Viper
+	origins map[string]origin
+}
+
+var (
+	// globalState is the state of Loaders, other than the private Loaders of Watch.
+	globalState = &loadState{origins: map[string]origin{}}
+	// current is the state of the last successful Load, read by Dump.
+	current atomic.Pointer[loadState]
+	// active is the Loader running, used by Resolvers to look up values, see getString.
+	active atomic.Pointer[Loader]
+	// loadMu serializes loads, Resolvers and the global state are shared.
+	loadMu sync.Mutex
+)
+
+func (s *loadState) instance() *viper.Viper {
+	if s.v == nil {
+		return viper.GetViper()
+	}
+
+	return s.v
+}
+
+func (s *loadState) set(key, name string, value any) {
+	s.instance().Set(key, value)
+
+	s.origins[key] = origin{name: name, value: value}
+}
+
+// source describes where the current value of key came from.
+func (s *loadState) source(key string) string {
+	v := s.instance()
+
+	if o, ok := s.origins[key]; ok && reflect.DeepEqual(v.Get(key), o.value) {
+		return o.name
+	}
+
+	if v.IsSet(key) {
+		return "default"
+	}
+
+	return "unset"
+}
+
+// source describes where the value of key loaded last came from.
+func source(key string) string {
+	st := current.Load()
+	if st == nil {
+		st = globalState
+	}
+
+	return st.source(key)
+}
+
+// Load loads cfg, a pointer to a struct, from the Sources, see the package Load for the struct tags.  The values of a
+// valid configuration are published to Values.
+func (l *Loader) Load(ctx context.Context, cfg any) error {
+	values, err := l.load(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	Values.Update(ctx, values)
+
+	return nil
+}
+
+// load loads cfg, returning the values of the loaded fields.
+func (l *Loader) load(ctx context.Context, cfg any) (map[string]any, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsZero() {
+		return nil, ErrInvalidConfigObject
+	}
+
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	st := globalState
+	if l.private {
+		st = &loadState{v: viper.New(), origins: map[string]origin{}}
+	}
+
+	fields, err := parseFields(st, reflect.Indirect(v).Type(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range l.Sources {
+		if err = s.Read(ctx); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+
+	l.state = st
+
+	active.Store(l)
+	defer active.Store(nil)
+
+	for _, f := range fields {
+		delete(st.origins, f.key)
+
+		value, name, ok, err := l.lookup(f.key)
+		if err != nil {
+			return nil, fmt.Errorf("error loading %s: %w", keyName(f.key), err)
+		}
+
+		if ok {
+			st.set(f.key, name, value)
+		}
+	}
+
+	for _, f := range fields {
+		if f.resolver == nil {
+			continue
+		}
+
+		value, err := f.resolver(strings.Join(f.names, "_"))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s tag on field %s: %w", TagName, f.field, err)
+		}
+
+		st.set(f.key, "resolver", value)
+	}
+
+	if err = resolveProviders(ctx, st); err != nil {
+		return nil, err
+	}
+
+	if err = checkRequired(st, fields); err != nil {
+		return nil, err
+	}
+
+	if err = st.instance().Unmarshal(cfg, defaultDecoderConfig); err != nil {
+		return nil, fmt.Errorf("error Unmarshaling: %w", err)
+	}
+
+	if err = validate(ctx, st, cfg); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(fields))
+	for _, f := range fields {
+		if v := st.instance().Get(f.key); v != nil {
+			values[f.key] = v
+		}
+	}
+
+	current.Store(st)
+
+	return values, nil
+}
+
+// instance returns the viper instance of the values being loaded.
+func (l *Loader) instance() *viper.Viper {
+	if l.state == nil {
+		return viper.GetViper()
+	}
+
+	return l.state.instance()
+}
+
+// lookup returns the value of key from the first source setting it, with the source name.
+func (l *Loader) lookup(key string) (any, string, bool, error) {
+	for _, s := range l.Sources {
+		value, ok, err := s.Lookup(key)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("%s: %w", s.Name(), err)
+		}
+
+		if ok {
+			return value, s.Name(), true, nil
+		}
+	}
+
+	return nil, "", false, nil
+}
+
+// EnvSource reads environment variables named by the keys, see Prefix and Separator, or the files named by the
+// variables with FileSuffix.  Empty variables are treated as unset, like viper.AutomaticEnv.
+type EnvSource struct {
+	// AllowEmpty sets keys to empty variables, overriding defaults, like viper.AllowEmptyEnv.
+	AllowEmpty bool
+}
+
+// Name returns "env".
+func (EnvSource) Name() string { return "env" }
+
+// Read does nothing, variables are read by Lookup.
+func (EnvSource) Read(context.Context) error { return nil }
+
+// Lookup returns the variable of key.
+func (s EnvSource) Lookup(key string) (any, bool, error) {
+	env := keyName(key)
+	if value, ok := os.LookupEnv(env); ok && (value != "" || s.AllowEmpty) {
+		return value, true, nil
+	}
+
+	if FileSuffix == "" {
+		return nil, false, nil
+	}
+
+	path := os.Getenv(env + FileSuffix)
+	if path == "" {
+		return nil, false, nil
+	}
+
+	value, err := readSecret(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", env+FileSuffix, err)
+	}
+
+	return value, true, nil
+}
+
+// FileSource reads a configuration file.  Files of Type "env" (dotenv) hold environment variables, other types are
+// nested objects, e.g. `db: {host: localhost}` for "db.host".
+type FileSource struct {
+	// Path of the file.
+	Path string
+	// Type is the viper config type, e.g. "yaml", "toml", "json" or "env".
+	Type string
+	// Optional ignores a missing file.
+	Optional bool
+
+	v *viper.Viper
+}
+
+// NewFileSource returns a FileSource for path, the type is selected by extension (.yaml, .yml, .toml, .json), other
+// extensions use Type.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path, Type: fileType(path)}
+}
+
+// Name returns "file " and the path.
+func (s *FileSource) Name() string { return "file " + s.Path }
+
+// Read reads the file.
+func (s *FileSource) Read(context.Context) error {
+	s.v = viper.New()
+	s.v.SetConfigFile(s.Path)
+	s.v.SetConfigType(s.Type)
+
+	err := s.v.ReadInConfig()
+
+	var pathError *os.PathError
+	if s.Optional && errors.As(err, &pathError) {
+		s.v = nil
+
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("error loading config %s: %w", s.Path, err)
+	}
+
+	return nil
+}
+
+// Lookup returns the value of key in the file.
+func (s *FileSource) Lookup(key string) (any, bool, error) {
+	if s.v == nil {
+		return nil, false, nil
+	}
+
+	if s.Type == "env" {
+		key = strings.ToLower(keyName(key))
+	}
+
+	if !s.v.IsSet(key) {
+		return nil, false, nil
+	}
+
+	return s.v.Get(key), true, nil
+}
+
+// FlagSource returns a Source for the flags defined by RegisterFlags on fs and set on the command line.
+func FlagSource(fs *flag.FlagSet) Source {
+	return &flagSource{fs: fs}
+}
+
+type flagSource struct {
+	fs     *flag.FlagSet
+	values map[string]string
+}
+
+func (s *flagSource) Name() string { return "flag" }
+
+func (s *flagSource) Read(context.Context) error {
+	s.values = map[string]string{}
+
+	s.fs.Visit(func(f *flag.Flag) {
+		if v, ok := f.Value.(*fieldFlag); ok {
+			s.values[v.key] = v.value
+		}
+	})
+
+	return nil
+}
+
+func (s *flagSource) Lookup(key string) (any, bool, error) {
+	value, ok := s.values[key]
+
+	return value, ok, nil
+}
@@ -0,0 +1,149 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+// iniSource is a minimal legacy INI source, with "section.key = value" lines.
+type iniSource struct {
+	text   string
+	values map[string]string
+}
+
+func (s *iniSource) Name() string { return "ini" }
+
+func (s *iniSource) Read(context.Context) error {
+	s.values = map[string]string{}
+
+	for _, line := range strings.Split(s.text, "\n") {
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		s.values[strings.ToLower(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+
+	return nil
+}
+
+func (s *iniSource) Lookup(key string) (any, bool, error) {
+	v, ok := s.values[key]
+
+	return v, ok, nil
+}
+
+type failingSource struct{ readErr, lookupErr error }
+
+func (failingSource) Name() string                       { return "failing" }
+func (s failingSource) Read(context.Context) error       { return s.readErr }
+func (s failingSource) Lookup(string) (any, bool, error) { return nil, false, s.lookupErr }
+
+func resetLoader(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	ini := &iniSource{text: "port = 7000\ndb.host = legacy\n"}
+
+	resetLoader(t, map[string]string{"PORT": "1", "DB_HOST": "env"})
+
+	var cfg NestedConfig
+
+	require.NoError(t, config.NewLoader(ini, config.EnvSource{}).Load(context.Background(), &cfg))
+	assert.Equal(t, 7000, cfg.Port)
+	assert.Equal(t, "legacy", cfg.DB.Host)
+	assert.Equal(t, 5432, cfg.DB.Port, "tag default")
+
+	settings, err := config.Dump(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, config.Setting{Key: "PORT", Value: "7000", Source: "ini"}, settings[0])
+
+	resetLoader(t, map[string]string{"PORT": "1", "DB_HOST": "env"})
+
+	cfg = NestedConfig{}
+
+	require.NoError(t, config.NewLoader(config.EnvSource{}, ini).Load(context.Background(), &cfg))
+	assert.Equal(t, 1, cfg.Port)
+	assert.Equal(t, "env", cfg.DB.Host)
+
+	resetLoader(t, nil)
+
+	cfg = NestedConfig{}
+
+	require.NoError(t, config.NewLoader(config.NewFileSource("testdata/nested.yaml"), ini).
+		Load(context.Background(), &cfg))
+	assert.Equal(t, 7000, cfg.Port, "ini sets keys absent from the file")
+}
+
+func TestEnvSourceEmpty(t *testing.T) {
+	resetLoader(t, map[string]string{"PORT": ""})
+
+	var cfg NestedConfig
+
+	require.NoError(t, config.NewLoader(config.EnvSource{}).Load(context.Background(), &cfg))
+	assert.Equal(t, 3000, cfg.Port, "empty variables keep the tag default")
+
+	resetLoader(t, map[string]string{"PORT": "", "DB_HOST": ""})
+
+	value, ok, err := config.EnvSource{AllowEmpty: true}.Lookup("db.host")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "", value)
+}
+
+func TestLoaderErrors(t *testing.T) {
+	resetLoader(t, nil)
+
+	boom := errors.New("boom")
+
+	err := config.NewLoader(failingSource{readErr: boom}).Load(context.Background(), &NestedConfig{})
+	require.ErrorIs(t, err, boom)
+
+	err = config.NewLoader(failingSource{lookupErr: boom}).Load(context.Background(), &NestedConfig{})
+	require.ErrorIs(t, err, boom)
+	assert.EqualError(t, err, "error loading PORT: failing: boom")
+
+	err = config.NewLoader(config.NewFileSource("testdata/missing.yaml")).Load(context.Background(), &NestedConfig{})
+	require.Error(t, err)
+
+	err = config.NewLoader(&config.FileSource{Path: "testdata/missing.yaml", Type: "yaml", Optional: true}).
+		Load(context.Background(), &NestedConfig{})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, config.NewLoader().Load(context.Background(), NestedConfig{}), config.ErrInvalidConfigObject)
+}
+
+func TestDefaultSources(t *testing.T) {
+	defaultFile, defaultArgs := config.File, config.Args
+
+	t.Cleanup(func() { config.File, config.Files, config.Args = defaultFile, nil, defaultArgs })
+
+	config.File = ".env"
+	config.Files = []string{"a.yaml", "b.toml"}
+	config.Args = []string{"app", "--config", "c.json"}
+
+	names := make([]string, 0, 5)
+	for _, s := range config.DefaultSources(&NestedConfig{}) {
+		names = append(names, s.Name())
+	}
+
+	assert.Equal(t, []string{"env", "file .env", "file c.json", "file b.toml", "file a.yaml"}, names)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rs/zerolog"
+)
+
+// Store holds configuration values by lower case dotted key (e.g. "server.port" for SERVER_PORT), for code reading
+// tunables without access to the application config struct, see Get and Subscribe.
+type Store struct {
+	mu     sync.RWMutex
+	values map[string]any
+	subs   map[string]map[int]func(ctx context.Context, value any)
+	next   int
+}
+
+// Values is the Store updated by every successful Load with the values of the loaded fields.
+var Values = NewStore()
+
+// NewStore returns an empty Store.
+func NewStore() *Store {
+	return &Store{values: map[string]any{}, subs: map[string]map[int]func(context.Context, any){}}
+}
+
+// Lookup returns the raw value of key.
+func (s *Store) Lookup(key string) (any, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.values[strings.ToLower(key)]
+
+	return v, ok
+}
+
+// Update sets values, calling the subscribers of each key whose value changed.
+func (s *Store) Update(ctx context.Context, values map[string]any) {
+	type notification struct {
+		fns   []func(context.Context, any)
+		value any
+	}
+
+	var changed []notification
+
+	s.mu.Lock()
+
+	for k, v := range values {
+		k = strings.ToLower(k)
+
+		if old, ok := s.values[k]; ok && reflect.DeepEqual(old, v) {
+			continue
+		}
+
+		s.values[k] = v
+
+		n := notification{value: v}
+		for _, fn := range s.subs[k] {
+			n.fns = append(n.fns, fn)
+		}
+
+		changed = append(changed, n)
+	}
+
+	s.mu.Unlock()
+
+	for _, n := range changed {
+		for _, fn := range n.fns {
+			fn(ctx, n.value)
+		}
+	}
+}
+
+// Get returns the value of key converted to T, with the same conversions as Load (e.g. "10s" for a time.Duration).
+// Missing keys return ErrMissingValue.
+func Get[T any](s *Store, key string) (T, error) {
+	var out T
+
+	v, ok := s.Lookup(key)
+	if !ok {
+		return out, fmt.Errorf("%s: %w", key, ErrMissingValue)
+	}
+
+	return convert[T](key, v)
+}
+
+// GetOr returns the value of key converted to T, or fallback if it is missing or invalid.
+func GetOr[T any](s *Store, key string, fallback T) T {
+	v, err := Get[T](s, key)
+	if err != nil {
+		return fallback
+	}
+
+	return v
+}
+
+// Subscribe calls fn with the value of key converted to T whenever an update changes it, until the returned cancel
+// func is called.  Values that do not convert are logged to the zerolog logger of the update context and skipped.
+func Subscribe[T any](s *Store, key string, fn func(T)) (cancel func()) {
+	key = strings.ToLower(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.next
+	s.next++
+
+	if s.subs[key] == nil {
+		s.subs[key] = map[int]func(context.Context, any){}
+	}
+
+	s.subs[key][id] = func(ctx context.Context, value any) {
+		v, err := convert[T](key, value)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("key", key).Msg("config subscription")
+
+			return
+		}
+
+		fn(v)
+	}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.subs[key], id)
+	}
+}
+
+func convert[T any](key string, value any) (T, error) {
+	var out T
+
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+
+	c := &mapstructure.DecoderConfig{Result: &out, WeaklyTypedInput: true}
+	defaultDecoderConfig(c)
+
+	d, err := mapstructure.NewDecoder(c)
+	if err == nil {
+		err = d.Decode(value)
+	}
+
+	if err != nil {
+		return out, fmt.Errorf("%s: %w", key, err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,66 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+func TestStore(t *testing.T) {
+	s := config.NewStore()
+
+	var ports []int
+
+	cancel := config.Subscribe(s, "server.port", func(v int) { ports = append(ports, v) })
+
+	s.Update(context.Background(), map[string]any{"server.port": "8080", "timeout": "5s"})
+
+	port, err := config.Get[int](s, "server.port")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	timeout, err := config.Get[time.Duration](s, "TIMEOUT")
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	_, err = config.Get[int](s, "missing")
+	require.ErrorIs(t, err, config.ErrMissingValue)
+
+	_, err = config.Get[int](s, "timeout")
+	require.ErrorContains(t, err, "timeout")
+
+	assert.Equal(t, 3, config.GetOr(s, "missing", 3))
+
+	s.Update(context.Background(), map[string]any{"server.port": "8080"})
+	s.Update(context.Background(), map[string]any{"server.port": 9090})
+	s.Update(context.Background(), map[string]any{"server.port": "invalid"})
+	cancel()
+	s.Update(context.Background(), map[string]any{"server.port": 1})
+
+	assert.Equal(t, []int{8080, 9090}, ports, "called on changes until canceled")
+}
+
+func TestValuesWatch(t *testing.T) {
+	config.Values.Update(context.Background(), map[string]any{"rate": -1})
+
+	rates := make(chan int, 10)
+	cancel := config.Subscribe(config.Values, "rate", func(v int) { rates <- v })
+
+	t.Cleanup(cancel)
+
+	path, _, changes := watchFile(t, context.Background(), "rate: 2\n")
+	assert.Equal(t, 2, <-rates)
+	assert.Equal(t, "info", config.GetOr(config.Values, "level", ""))
+
+	require.NoError(t, os.WriteFile(path, []byte("rate: 4\n"), 0o600))
+
+	_, ok := waitChange(t, changes)
+	require.True(t, ok)
+	assert.Equal(t, 4, <-rates)
+}
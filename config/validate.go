@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bir/iken/validation"
+)
+
+// Validation validates loaded configuration structs with rules in `validate` tags, e.g.
+// `env:"PORT, 3000" validate:"min=1,max=65535"`.  Fields are named by their env tags.
+var Validation = validation.NewValidator().WithNameTag(TagName)
+
+// Validator is implemented by config structs with checks beyond the validate tag rules, called by Load after the tag
+// rules pass.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError lists every configuration value failing the validate tag rules, keyed by environment variable
+// name, with the source of each value.
+type ValidationError struct {
+	// Errors are the rule failures keyed by environment variable name.
+	Errors *validation.Errors
+	// Sources describe where each failing value came from, e.g. "env", "file config.yaml" or "default".
+	Sources map[string]string
+}
+
+// Error lists the failures, one per line.
+func (e *ValidationError) Error() string {
+	keys := e.Errors.Keys()
+	lines := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s (%s): %s", key, e.Sources[key], (*e.Errors)[key].Error()))
+	}
+
+	return "invalid config:\n\t" + strings.Join(lines, "\n\t")
+}
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (e *ValidationError) Unwrap() error {
+	return e.Errors
+}
+
+// validate applies Validation, then Validator, to cfg.
+func validate(ctx context.Context, st *loadState, cfg any) error {
+	err := Validation.StructCtx(ctx, cfg)
+	if err != nil {
+		ee, ok := err.(*validation.Errors) //nolint:errorlint
+		if !ok {
+			return fmt.Errorf("error validating config: %w", err)
+		}
+
+		return newValidationError(st, *ee)
+	}
+
+	if v, ok := cfg.(Validator); ok {
+		if err = v.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func newValidationError(st *loadState, ee validation.Errors) *ValidationError {
+	out := &ValidationError{Errors: &validation.Errors{}, Sources: map[string]string{}}
+
+	for path, messages := range ee {
+		names := strings.Split(path, ".")
+		env := envName(names)
+
+		key, _, _ := strings.Cut(strings.ToLower(strings.Join(names, ".")), "[")
+
+		(*out.Errors)[env] = messages
+		out.Sources[env] = st.source(key)
+	}
+
+	return out
+}
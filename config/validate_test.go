@@ -0,0 +1,122 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+	"github.com/bir/iken/validation"
+)
+
+type ValidatedConfig struct {
+	Port  int            `env:"PORT, 3000" validate:"min=1,max=65535"`
+	Mode  string         `env:"MODE, dev" validate:"oneof=dev prod"`
+	Name  string         `env:"NAME" validate:"required"`
+	DB    ValidatedDB    `env:"DB"`
+	Other map[string]int `env:"OTHER"`
+}
+
+type ValidatedDB struct {
+	Host string `env:"HOST" validate:"required"`
+	Pool int    `env:"POOL, 4" validate:"min=1"`
+}
+
+func (c *ValidatedConfig) Validate() error {
+	if c.Mode == "prod" && c.DB.Host == "localhost" {
+		return errors.New("prod must not use localhost")
+	}
+
+	return nil
+}
+
+func loadValidated(t *testing.T, file string, env map[string]string) (ValidatedConfig, error) {
+	t.Helper()
+
+	defaultFile, defaultArgs := config.File, config.Args
+
+	t.Cleanup(func() { config.File, config.Files, config.Args = defaultFile, nil, defaultArgs })
+
+	config.File = ".envEMPTY"
+	config.Files = nil
+	config.Args = nil
+
+	if file != "" {
+		path := filepath.Join(t.TempDir(), "app.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(file), 0o600))
+
+		config.Files = []string{path}
+	}
+
+	viper.Reset()
+	os.Clearenv()
+
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+
+	var cfg ValidatedConfig
+
+	err := config.Load(&cfg)
+
+	return cfg, err
+}
+
+func TestLoadValidation(t *testing.T) {
+	cfg, err := loadValidated(t, "", map[string]string{"NAME": "app", "DB_HOST": "db"})
+	require.NoError(t, err)
+	assert.Equal(t, "db", cfg.DB.Host)
+
+	_, err = loadValidated(t, "mode: test\ndb:\n  pool: 0\n", map[string]string{"PORT": "0"})
+
+	var ve *config.ValidationError
+
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, map[string][]string{
+		"PORT":    {"must be at least 1"},
+		"MODE":    {"must be one of: dev, prod"},
+		"NAME":    {"is required"},
+		"DB_HOST": {"is required"},
+		"DB_POOL": {"must be at least 1"},
+	}, ve.Errors.Fields())
+	assert.Equal(t, "env", ve.Sources["PORT"])
+	assert.Contains(t, ve.Sources["MODE"], "file ")
+	assert.Contains(t, ve.Sources["DB_POOL"], "app.yaml")
+	assert.Equal(t, "unset", ve.Sources["NAME"])
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, err, &ee)
+	assert.Contains(t, err.Error(), "invalid config:\n\tDB_HOST (unset): is required\n\tDB_POOL (file ")
+
+	_, err = loadValidated(t, "", map[string]string{"NAME": "app", "DB_HOST": "localhost", "MODE": "prod"})
+	assert.EqualError(t, err, "invalid config: prod must not use localhost")
+}
+
+func TestLoadValidationDefaultSource(t *testing.T) {
+	defaultPrefix, defaultFile := config.Prefix, config.File
+
+	t.Cleanup(func() { config.Prefix, config.File = defaultPrefix, defaultFile })
+
+	config.Prefix = "APP"
+	config.File = ".envEMPTY"
+
+	type PortConfig struct {
+		Port int `env:"PORT, 0" validate:"min=1"`
+	}
+
+	viper.Reset()
+	os.Clearenv()
+
+	err := config.Load(&PortConfig{})
+
+	var ve *config.ValidationError
+
+	require.ErrorAs(t, err, &ve)
+	assert.Equal(t, map[string]string{"APP_PORT": "default"}, ve.Sources)
+}
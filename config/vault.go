@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// VaultScheme is the value scheme resolved by VaultProvider, e.g. vault://secret/data/app#password for a KV v2
+	// secret or vault://database/creds/app#username for dynamic credentials.
+	VaultScheme = "vault"
+	// KubernetesTokenPath is the default path of the Kubernetes service account token used by VaultKubernetesAuth.
+	KubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token" //nolint:gosec
+
+	vaultTokenHeader     = "X-Vault-Token"
+	vaultNamespaceHeader = "X-Vault-Namespace"
+)
+
+// ErrVault is returned when Vault responds with an error.
+var ErrVault = errors.New("vault error")
+
+// VaultAuth obtains the Vault client token used by VaultProvider.  ttl is the token lifetime, 0 for tokens that do
+// not expire.
+type VaultAuth interface {
+	Login(ctx context.Context, v *VaultProvider) (token string, ttl time.Duration, err error)
+}
+
+// VaultToken authenticates with a static Vault token, e.g. from VAULT_TOKEN.
+type VaultToken string
+
+// Login returns the token.
+func (t VaultToken) Login(context.Context, *VaultProvider) (string, time.Duration, error) {
+	return string(t), 0, nil
+}
+
+// VaultKubernetesAuth authenticates with the Kubernetes auth method, logging in with the pod service account token.
+type VaultKubernetesAuth struct {
+	// Role is the Vault role bound to the service account.
+	Role string
+	// Mount is the auth method mount path, defaults to "kubernetes".
+	Mount string
+	// TokenPath is the service account token file, defaults to KubernetesTokenPath.
+	TokenPath string
+}
+
+// Login exchanges the service account token for a Vault token.
+func (k VaultKubernetesAuth) Login(ctx context.Context, v *VaultProvider) (string, time.Duration, error) {
+	mount, path := k.Mount, k.TokenPath
+	if mount == "" {
+		mount = "kubernetes"
+	}
+
+	if path == "" {
+		path = KubernetesTokenPath
+	}
+
+	jwt, err := readSecret(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	body, _ := json.Marshal(map[string]string{"role": k.Role, "jwt": jwt}) //nolint:errchkjson
+
+	var resp vaultResponse
+	if _, err = v.do(ctx, http.MethodPost, "auth/"+mount+"/login", "", body, &resp); err != nil {
+		return "", 0, fmt.Errorf("vault kubernetes login: %w", err)
+	}
+
+	return resp.Auth.ClientToken, time.Duration(resp.Auth.LeaseDuration) * time.Second, nil
+}
+
+// VaultProvider resolves vault:// values, the ref is the API path of the secret with an optional "#field" suffix
+// selecting a field of the secret data.  KV v2 responses are unwrapped, without a field the data is returned as a JSON
+// object.
+//
+// Secrets with a lease (dynamic credentials) are reused until two thirds of the lease duration have passed, then the
+// next Resolve (e.g. a reload) reads new credentials.  NextRefresh reports when that is due.  Secrets without a lease
+// are read on every Resolve.
+type VaultProvider struct {
+	// Address of the Vault server, e.g. https://vault:8200.
+	Address string
+	// Namespace is the optional Vault Enterprise namespace.
+	Namespace string
+	// Auth obtains the client token.
+	Auth VaultAuth
+	// Client is the HTTP client, defaults to http.DefaultClient.
+	Client *http.Client
+
+	mu           sync.Mutex
+	token        string
+	tokenRefresh time.Time
+	leases       map[string]vaultLease
+}
+
+type vaultLease struct {
+	data    map[string]any
+	refresh time.Time
+}
+
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Data          map[string]any `json:"data"`
+	Auth          struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// NewVaultProvider returns a VaultProvider for address, defaulting to the VAULT_ADDR environment variable.
+func NewVaultProvider(address string, auth VaultAuth) *VaultProvider {
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+
+	return &VaultProvider{Address: address, Auth: auth}
+}
+
+// Resolve reads the secrets referenced by refs, each path is read once.
+func (v *VaultProvider) Resolve(ctx context.Context, refs []string) (map[string]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	secrets := map[string]map[string]any{}
+	out := make(map[string]string, len(refs))
+
+	for _, ref := range refs {
+		path, field, hasField := strings.Cut(ref, "#")
+
+		data, ok := secrets[path]
+		if !ok {
+			var err error
+			if data, err = v.read(ctx, path); err != nil {
+				return nil, err
+			}
+
+			secrets[path] = data
+		}
+
+		if data == nil {
+			continue
+		}
+
+		if !hasField {
+			b, _ := json.Marshal(data) //nolint:errchkjson
+			out[ref] = string(b)
+
+			continue
+		}
+
+		if value, ok := data[field]; ok {
+			out[ref] = fmt.Sprint(value)
+		}
+	}
+
+	return out, nil
+}
+
+// NextRefresh returns the earliest time a leased secret is due to be read again, zero if there are none.
+func (v *VaultProvider) NextRefresh() time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var next time.Time
+
+	for _, l := range v.leases {
+		if next.IsZero() || l.refresh.Before(next) {
+			next = l.refresh
+		}
+	}
+
+	return next
+}
+
+// read returns the data of the secret at path, nil if it does not exist.
+func (v *VaultProvider) read(ctx context.Context, path string) (map[string]any, error) {
+	if l, ok := v.leases[path]; ok && time.Now().Before(l.refresh) {
+		return l.data, nil
+	}
+
+	delete(v.leases, path)
+
+	token, err := v.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp vaultResponse
+
+	status, err := v.do(ctx, http.MethodGet, path, token, nil, &resp)
+	if status == http.StatusNotFound {
+		return nil, nil //nolint:nilnil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("vault read %s: %w", path, err)
+	}
+
+	data := resp.Data
+	if inner, ok := data["data"].(map[string]any); ok && data["metadata"] != nil {
+		data = inner // KV v2
+	}
+
+	if resp.LeaseDuration > 0 {
+		if v.leases == nil {
+			v.leases = map[string]vaultLease{}
+		}
+
+		v.leases[path] = vaultLease{data: data, refresh: time.Now().Add(refreshAfter(resp.LeaseDuration))}
+	}
+
+	return data, nil
+}
+
+func (v *VaultProvider) login(ctx context.Context) (string, error) {
+	if v.token != "" && (v.tokenRefresh.IsZero() || time.Now().Before(v.tokenRefresh)) {
+		return v.token, nil
+	}
+
+	token, ttl, err := v.Auth.Login(ctx, v)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+
+	v.token, v.tokenRefresh = token, time.Time{}
+	if ttl > 0 {
+		v.tokenRefresh = time.Now().Add(ttl * 2 / 3) //nolint:mnd
+	}
+
+	return token, nil
+}
+
+func refreshAfter(leaseSeconds int) time.Duration {
+	return time.Duration(leaseSeconds) * time.Second * 2 / 3 //nolint:mnd
+}
+
+// do calls the Vault API, decoding the response into out.
+func (v *VaultProvider) do(ctx context.Context, method, path, token string, body []byte, out *vaultResponse) (int, error) {
+	url := strings.TrimRight(v.Address, "/") + "/v1/" + strings.TrimLeft(path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("vault request: %w", err)
+	}
+
+	if token != "" {
+		req.Header.Set(vaultTokenHeader, token)
+	}
+
+	if v.Namespace != "" {
+		req.Header.Set(vaultNamespaceHeader, v.Namespace)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("vault request: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("vault response: %w", err)
+	}
+
+	_ = json.Unmarshal(b, out)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, fmt.Errorf("%w: status %d: %s", ErrVault, resp.StatusCode, strings.Join(out.Errors, ", "))
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,161 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type vaultServer struct {
+	*httptest.Server
+	reads  map[string]*atomic.Int32
+	logins atomic.Int32
+}
+
+func newVaultServer(t *testing.T) *vaultServer {
+	t.Helper()
+
+	s := &vaultServer{reads: map[string]*atomic.Int32{"kv": {}, "creds": {}}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/auth/kubernetes/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		if body["role"] != "app" || body["jwt"] != "sa-jwt" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+
+			return
+		}
+
+		s.logins.Add(1)
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"k8s-token","lease_duration":3600}}`))
+	})
+	mux.HandleFunc("GET /v1/secret/data/app", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+
+		s.reads["kv"].Add(1)
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"pw","port":5432},"metadata":{"version":3}}}`))
+	})
+	mux.HandleFunc("GET /v1/database/creds/app", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+
+		s.reads["creds"].Add(1)
+		_, _ = w.Write([]byte(`{"lease_id":"database/creds/app/1","lease_duration":1,"renewable":true,` +
+			`"data":{"username":"u1","password":"p1"}}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+	})
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func authorized(w http.ResponseWriter, r *http.Request) bool {
+	switch r.Header.Get("X-Vault-Token") {
+	case "root", "k8s-token":
+		return true
+	}
+
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+
+	return false
+}
+
+func TestVaultProvider(t *testing.T) {
+	s := newVaultServer(t)
+	v := config.NewVaultProvider(s.URL, config.VaultToken("root"))
+
+	values, err := v.Resolve(context.Background(),
+		[]string{"secret/data/app#password", "secret/data/app#port", "secret/data/app", "secret/data/app#none", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"secret/data/app#password": "pw",
+		"secret/data/app#port":     "5432",
+		"secret/data/app":          `{"password":"pw","port":5432}`,
+	}, values)
+	assert.Equal(t, int32(1), s.reads["kv"].Load(), "each path is read once")
+	assert.True(t, v.NextRefresh().IsZero())
+
+	_, err = config.NewVaultProvider(s.URL, config.VaultToken("bad")).Resolve(context.Background(), []string{"secret/data/app"})
+	require.ErrorIs(t, err, config.ErrVault)
+	assert.EqualError(t, err, "vault read secret/data/app: vault error: status 403: permission denied")
+}
+
+func TestVaultProviderLeases(t *testing.T) {
+	s := newVaultServer(t)
+	v := config.NewVaultProvider(s.URL, config.VaultToken("root"))
+
+	for range 2 {
+		values, err := v.Resolve(context.Background(), []string{"database/creds/app#username"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"database/creds/app#username": "u1"}, values)
+	}
+
+	assert.Equal(t, int32(1), s.reads["creds"].Load(), "leased credentials are reused")
+	assert.WithinDuration(t, time.Now().Add(666*time.Millisecond), v.NextRefresh(), 100*time.Millisecond)
+
+	time.Sleep(time.Until(v.NextRefresh()) + 10*time.Millisecond)
+
+	_, err := v.Resolve(context.Background(), []string{"database/creds/app#password"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), s.reads["creds"].Load(), "expiring leases are refreshed")
+}
+
+func TestVaultKubernetesAuth(t *testing.T) {
+	s := newVaultServer(t)
+	jwt := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(jwt, []byte("sa-jwt\n"), 0o600))
+
+	v := config.NewVaultProvider(s.URL, config.VaultKubernetesAuth{Role: "app", TokenPath: jwt})
+
+	for range 2 {
+		values, err := v.Resolve(context.Background(), []string{"secret/data/app#password"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"secret/data/app#password": "pw"}, values)
+	}
+
+	assert.Equal(t, int32(1), s.logins.Load(), "token is reused")
+
+	v = config.NewVaultProvider(s.URL, config.VaultKubernetesAuth{Role: "other", TokenPath: jwt})
+	_, err := v.Resolve(context.Background(), []string{"secret/data/app#password"})
+	assert.EqualError(t, err, "vault kubernetes login: vault error: status 403: permission denied")
+
+	v = config.NewVaultProvider(s.URL, config.VaultKubernetesAuth{Role: "app", TokenPath: jwt + "missing"})
+	_, err = v.Resolve(context.Background(), []string{"secret/data/app#password"})
+	assert.ErrorIs(t, err, config.ErrSecretFile)
+}
+
+func TestDumpRedactsProviderValues(t *testing.T) {
+	s := newVaultServer(t)
+
+	cfg, err := loadProviders(t, config.ProviderMap{config.VaultScheme: config.NewVaultProvider(s.URL, config.VaultToken("root"))},
+		map[string]string{"PASSWORD": "vault://secret/data/app#password", "API_KEY": "key"})
+	require.NoError(t, err)
+	assert.Equal(t, "pw", cfg.Password)
+
+	settings, err := config.Dump(&cfg)
+	require.NoError(t, err)
+	assert.Equal(t, config.Setting{Key: "PASSWORD", Value: config.Redacted, Source: "provider", Secret: true}, settings[0])
+}
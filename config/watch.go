@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// WatchSignals trigger a reload by Watch, defaults to SIGHUP.
+	WatchSignals = []os.Signal{syscall.SIGHUP}
+	// WatchDebounce is the delay Watch waits for file changes to settle before reloading, editors and Kubernetes
+	// ConfigMap updates produce several events per change.
+	WatchDebounce = 100 * time.Millisecond
+)
+
+// Watch reloads cfg when the configuration files (Files, files given by FlagName and File) or WatchableSource
+// RemoteSources change, or the process receives one of WatchSignals, until ctx is done.  cfg must hold the loaded
+// configuration, see Load:
+//
+//	var loaded AppConfig
+//	if err := config.Load(&loaded); err != nil { ... }
+//
+//	var cfg atomic.Pointer[AppConfig]
+//	cfg.Store(&loaded)
+//	err := config.Watch(ctx, &cfg, onChange)
+//	...
+//	limit := cfg.Load().RateLimit
+//
+// Each reload parses and validates the configuration into a new value with a private viper instance, see Load.  Only
+// if that succeeds is the new value stored in cfg and onChange called with the old and new values, failures are
+// logged to the zerolog logger of ctx and the current configuration is kept.  Values are never modified once stored,
+// readers see either the old or the new configuration.  The changed values are logged and passed to the listeners
+// registered by AddChangeListener, see Diff.
+func Watch[T any](ctx context.Context, cfg *atomic.Pointer[T], onChange ...func(old, new T)) error {
+	if cfg == nil || cfg.Load() == nil {
+		return ErrInvalidConfigObject
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error watching config: %w", err)
+	}
+
+	files := map[string]bool{}
+
+	for _, path := range configPaths() {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+
+		files[abs] = true
+
+		// Watch the directory, files replaced by renames or symlink swaps are not tracked by file watches.
+		if err = watcher.Add(filepath.Dir(abs)); err != nil {
+			_ = watcher.Close()
+
+			return fmt.Errorf("error watching config %s: %w", path, err)
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, WatchSignals...)
+
+	remote := make(chan struct{})
+	watchSources(ctx, remote)
+
+	go func() {
+		defer watcher.Close()
+		defer signal.Stop(signals)
+
+		var debounce <-chan time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if files[filepath.Clean(event.Name)] {
+					debounce = time.After(WatchDebounce)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				zerolog.Ctx(ctx).Error().Err(err).Msg("config watch")
+			case <-signals:
+				reload(ctx, cfg, onChange)
+			case <-remote:
+				reload(ctx, cfg, onChange)
+			case <-debounce:
+				debounce = nil
+
+				reload(ctx, cfg, onChange)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func reload[T any](ctx context.Context, cfg *atomic.Pointer[T], onChange []func(old, new T)) {
+	next := new(T)
+
+	l := &Loader{Sources: DefaultSources(next), private: true}
+	if err := l.Load(ctx, next); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("config reload")
+
+		return
+	}
+
+	old := cfg.Swap(next)
+
+	changes, err := Diff(old, next)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("config diff")
+	}
+
+	notifyChanges(ctx, changes)
+
+	for _, f := range onChange {
+		f(*old, *next)
+	}
+}
+
+// configPaths are the configuration files read by Load.
+func configPaths() []string {
+	var args []string
+	if len(Args) > 1 {
+		args = Args[1:]
+	}
+
+	return append(append(append([]string{}, Files...), FlagPaths(args)...), File)
+}
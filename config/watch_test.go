@@ -0,0 +1,156 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+)
+
+type WatchConfig struct {
+	Level string `env:"LEVEL, info"`
+	Rate  int    `env:"RATE, 10"`
+}
+
+func (c *WatchConfig) Validate() error {
+	if c.Rate < 0 {
+		return errors.New("rate must not be negative")
+	}
+
+	return nil
+}
+
+type change struct{ old, new WatchConfig }
+
+func watchFile(t *testing.T, ctx context.Context, content string) (string, *atomic.Pointer[WatchConfig], chan change) {
+	t.Helper()
+
+	defaultFile, defaultArgs := config.File, config.Args
+
+	t.Cleanup(func() { config.File, config.Files, config.Args = defaultFile, nil, defaultArgs })
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	config.File = ".envEMPTY"
+	config.Files = []string{path}
+	config.Args = nil
+
+	viper.Reset()
+	os.Clearenv()
+
+	var loaded WatchConfig
+	require.NoError(t, config.Load(&loaded))
+
+	var cfg atomic.Pointer[WatchConfig]
+	cfg.Store(&loaded)
+
+	ctx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+
+	changes := make(chan change, 10)
+	require.NoError(t, config.Watch(ctx, &cfg, func(old, new WatchConfig) { changes <- change{old, new} }))
+
+	return path, &cfg, changes
+}
+
+func waitChange(t *testing.T, changes chan change) (change, bool) {
+	t.Helper()
+
+	select {
+	case c := <-changes:
+		return c, true
+	case <-time.After(time.Second):
+		return change{}, false
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	path, cfg, changes := watchFile(t, context.Background(), "level: info\n")
+	assert.Equal(t, WatchConfig{Level: "info", Rate: 10}, *cfg.Load())
+
+	require.NoError(t, os.WriteFile(path, []byte("level: debug\nrate: 5\n"), 0o600))
+
+	c, ok := waitChange(t, changes)
+	require.True(t, ok, "reloaded")
+	assert.Equal(t, WatchConfig{Level: "info", Rate: 10}, c.old)
+	assert.Equal(t, WatchConfig{Level: "debug", Rate: 5}, c.new)
+	assert.Equal(t, c.new, *cfg.Load())
+
+	require.NoError(t, os.WriteFile(path, []byte("rate: -1\n"), 0o600))
+
+	_, ok = waitChange(t, changes)
+	assert.False(t, ok, "invalid config is not applied")
+	assert.Equal(t, WatchConfig{Level: "debug", Rate: 5}, *cfg.Load())
+
+	require.NoError(t, os.WriteFile(path, []byte("rate: [\n"), 0o600))
+
+	_, ok = waitChange(t, changes)
+	assert.False(t, ok, "unparsable config is not applied")
+
+	// Atomic replace, as done by editors and Kubernetes.
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte("level: warn\n"), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+
+	c, ok = waitChange(t, changes)
+	require.True(t, ok, "reloaded after rename")
+	assert.Equal(t, WatchConfig{Level: "warn", Rate: 10}, c.new)
+}
+
+func TestWatchSignal(t *testing.T) {
+	_, cfg, changes := watchFile(t, context.Background(), "level: info\n")
+
+	t.Setenv("LEVEL", "error")
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	c, ok := waitChange(t, changes)
+	require.True(t, ok, "reloaded on SIGHUP")
+	assert.Equal(t, "error", c.new.Level)
+	assert.Equal(t, "error", cfg.Load().Level)
+}
+
+func TestWatchConcurrentReads(t *testing.T) {
+	path, cfg, changes := watchFile(t, context.Background(), "level: info\n")
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = cfg.Load().Level
+				_, _ = config.Dump(cfg.Load())
+			}
+		}
+	}()
+
+	for _, level := range []string{"debug", "warn", "error"} {
+		require.NoError(t, os.WriteFile(path, []byte("level: "+level+"\n"), 0o600))
+
+		c, ok := waitChange(t, changes)
+		require.True(t, ok)
+		assert.Equal(t, level, c.new.Level)
+	}
+
+	assert.Equal(t, "error", cfg.Load().Level)
+	assert.Equal(t, "info", viper.GetString("level"), "reloads do not modify the global viper")
+}
+
+func TestWatchInvalid(t *testing.T) {
+	assert.ErrorIs(t, config.Watch[WatchConfig](context.Background(), nil), config.ErrInvalidConfigObject)
+	assert.ErrorIs(t, config.Watch(context.Background(), &atomic.Pointer[WatchConfig]{}), config.ErrInvalidConfigObject)
+}
@@ -0,0 +1,116 @@
+package errs
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code classifies an error independent of the transport.  See Status for the HTTP mapping.
+type Code string
+
+const (
+	Internal           Code = "internal"
+	InvalidArgument    Code = "invalid_argument"
+	NotFound           Code = "not_found"
+	AlreadyExists      Code = "already_exists"
+	Conflict           Code = "conflict"
+	FailedPrecondition Code = "failed_precondition"
+	Unauthenticated    Code = "unauthenticated"
+	PermissionDenied   Code = "permission_denied"
+	TooManyRequests    Code = "too_many_requests"
+	Unimplemented      Code = "unimplemented"
+	Unavailable        Code = "unavailable"
+	DeadlineExceeded   Code = "deadline_exceeded"
+	Canceled           Code = "canceled"
+)
+
+// statusCanceled matches httputil.StatusContextCancelled, the client closed the request.
+const statusCanceled = 499
+
+var codeStatus = map[Code]int{
+	Internal:           http.StatusInternalServerError,
+	InvalidArgument:    http.StatusBadRequest,
+	NotFound:           http.StatusNotFound,
+	AlreadyExists:      http.StatusConflict,
+	Conflict:           http.StatusConflict,
+	FailedPrecondition: http.StatusPreconditionFailed,
+	Unauthenticated:    http.StatusUnauthorized,
+	PermissionDenied:   http.StatusForbidden,
+	TooManyRequests:    http.StatusTooManyRequests,
+	Unimplemented:      http.StatusNotImplemented,
+	Unavailable:        http.StatusServiceUnavailable,
+	DeadlineExceeded:   http.StatusGatewayTimeout,
+	Canceled:           statusCanceled,
+}
+
+// Status returns the default HTTP status for the code, unknown codes are 500.
+func (c Code) Status() int {
+	if s, ok := codeStatus[c]; ok {
+		return s
+	}
+
+	return http.StatusInternalServerError
+}
+
+// codeError attaches a Code to the wrapped error.
+type codeError struct {
+	err  error
+	code Code
+}
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (e *codeError) Unwrap() error {
+	return e.err
+}
+
+// Error directly returns the wrapped error's Error string.
+func (e *codeError) Error() string {
+	return e.err.Error()
+}
+
+// Code returns the attached code.
+func (e *codeError) Code() Code {
+	return e.code
+}
+
+// WithCode attaches code to err.
+func WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+
+	return &codeError{err: err, code: code}
+}
+
+// GetCode returns the first Code found in err's chain, any error implementing `Code() errs.Code` is honored.
+func GetCode(err error) (Code, bool) {
+	var c interface {
+		error
+		Code() Code
+	}
+
+	if errors.As(err, &c) {
+		return c.Code(), true
+	}
+
+	return "", false
+}
+
+// Status returns the HTTP status for err.  Errors in the chain implementing `Status() int` (e.g. SentinelError) take
+// precedence, followed by the status of the error's Code, otherwise 500.
+func Status(err error) int {
+	var s interface {
+		error
+		Status() int
+	}
+
+	if errors.As(err, &s) {
+		return s.Status()
+	}
+
+	if code, ok := GetCode(err); ok {
+		return code.Status()
+	}
+
+	return http.StatusInternalServerError
+}
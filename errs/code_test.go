@@ -0,0 +1,32 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+func TestCode(t *testing.T) {
+	base := errors.New("base")
+
+	assert.Nil(t, errs.WithCode(nil, errs.NotFound))
+
+	_, ok := errs.GetCode(base)
+	assert.False(t, ok)
+
+	err := fmt.Errorf("wrap: %w", errs.WithCode(base, errs.NotFound))
+	code, ok := errs.GetCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, errs.NotFound, code)
+	assert.Equal(t, "wrap: base", err.Error())
+	assert.ErrorIs(t, err, base)
+
+	assert.Equal(t, 404, errs.NotFound.Status())
+	assert.Equal(t, 429, errs.TooManyRequests.Status())
+	assert.Equal(t, 499, errs.Canceled.Status())
+	assert.Equal(t, 500, errs.Code("bogus").Status())
+}
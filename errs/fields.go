@@ -0,0 +1,56 @@
+package errs
+
+import "errors"
+
+// fieldsError attaches structured logging fields to the wrapped error.
+type fieldsError struct {
+	err    error
+	fields map[string]any
+}
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (e *fieldsError) Unwrap() error {
+	return e.err
+}
+
+// Error directly returns the wrapped error's Error string.
+func (e *fieldsError) Error() string {
+	return e.err.Error()
+}
+
+// WithFields attaches fields to err so context (e.g. order_id, upstream status) travels with the error and is
+// logged once where the error is handled, see Fields.
+func WithFields(err error, fields map[string]any) error {
+	if err == nil {
+		return nil
+	}
+
+	return &fieldsError{err: err, fields: fields}
+}
+
+// Fields collects all fields attached with WithFields along the error chain.  When a key is defined more than
+// once, the outermost value wins.  Returns nil if no fields are attached.
+func Fields(err error) map[string]any {
+	var out map[string]any
+
+	for err != nil {
+		var f *fieldsError
+		if !errors.As(err, &f) {
+			break
+		}
+
+		if out == nil {
+			out = make(map[string]any, len(f.fields))
+		}
+
+		for k, v := range f.fields {
+			if _, ok := out[k]; !ok {
+				out[k] = v
+			}
+		}
+
+		err = f.err
+	}
+
+	return out
+}
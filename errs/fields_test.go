@@ -0,0 +1,27 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+func TestWithFields(t *testing.T) {
+	base := errors.New("base")
+
+	assert.Nil(t, errs.WithFields(nil, map[string]any{"a": 1}))
+	assert.Nil(t, errs.Fields(base))
+	assert.Nil(t, errs.Fields(nil))
+
+	inner := errs.WithFields(base, map[string]any{"order_id": 1, "status": 500})
+	outer := errs.WithFields(fmt.Errorf("wrap: %w", inner), map[string]any{"status": 502, "upstream": "billing"})
+
+	assert.Equal(t, "wrap: base", outer.Error())
+	assert.ErrorIs(t, outer, base)
+	assert.Equal(t, map[string]any{"order_id": 1, "status": 502, "upstream": "billing"}, errs.Fields(outer))
+	assert.Equal(t, map[string]any{"order_id": 1, "status": 500}, errs.Fields(inner))
+}
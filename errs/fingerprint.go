@@ -0,0 +1,53 @@
+package errs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// fingerprintFrames is the number of top stack frames included in a Fingerprint.
+const fingerprintFrames = 5
+
+// fingerprintSize is the number of hash bytes reported by Fingerprint.
+const fingerprintSize = 8
+
+// Fingerprint returns a stable hash identifying the failure "shape" of err, useful to group and rate limit
+// identical failures.  The hash is composed of the types in the error chain, the error code and the function
+// names of the top stack frames.  Variable parts (messages, line numbers, arguments) are ignored.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	h := sha256.New()
+
+	var frames []Frame
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		_, _ = fmt.Fprintf(h, "%T;", e)
+
+		if p, ok := e.(*PanicError); ok { //nolint:errorlint // walking the chain manually
+			_, _ = fmt.Fprintf(h, "%T;", p.Value)
+		}
+
+		if frames == nil {
+			frames = ExtractStackFrame(e)
+		}
+	}
+
+	if code, ok := GetCode(err); ok {
+		_, _ = fmt.Fprintf(h, "code=%s;", code)
+	}
+
+	for i, f := range frames {
+		if i == fingerprintFrames {
+			break
+		}
+
+		_, _ = fmt.Fprintf(h, "%s;", f.Func)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:fingerprintSize])
+}
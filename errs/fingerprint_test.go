@@ -0,0 +1,35 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+func failAt(id int) error {
+	return fmt.Errorf("order %d: %w", id, errs.WithStack("charge failed", 0))
+}
+
+func failElsewhere(id int) error {
+	return fmt.Errorf("order %d: %w", id, errs.WithStack("charge failed", 0))
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Empty(t, errs.Fingerprint(nil))
+
+	a := errs.Fingerprint(failAt(1))
+	assert.Len(t, a, 16)
+	assert.Equal(t, a, errs.Fingerprint(failAt(2)), "variable messages are ignored")
+	assert.NotEqual(t, a, errs.Fingerprint(failElsewhere(1)), "different stacks")
+	assert.NotEqual(t, a, errs.Fingerprint(errs.WithCode(failAt(1), errs.NotFound)), "codes")
+
+	plain := errors.New("x")
+	assert.Equal(t, errs.Fingerprint(plain), errs.Fingerprint(errors.New("y")))
+	assert.NotEqual(t, errs.Fingerprint(plain), errs.Fingerprint(fmt.Errorf("wrap: %w", plain)))
+
+	assert.NotEqual(t, errs.Fingerprint(errs.FromPanic("s")), errs.Fingerprint(errs.FromPanic(1)))
+}
@@ -0,0 +1,78 @@
+package errs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Group runs tasks concurrently and collects every failure, unlike errgroup which only reports the first.  Panics
+// in tasks are recovered as *PanicError and errors keep their codes, so the aggregate can be inspected with
+// errors.Is/As and GetCode.
+type Group struct {
+	ctx     context.Context //nolint:containedctx // parent of every task
+	timeout time.Duration
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	errs    []error
+}
+
+// NewGroup creates a Group whose tasks derive their context from ctx.
+func NewGroup(ctx context.Context) *Group {
+	return &Group{ctx: ctx}
+}
+
+// WithTimeout applies timeout to each task started after this call, 0 disables.
+func (g *Group) WithTimeout(timeout time.Duration) *Group {
+	g.timeout = timeout
+
+	return g
+}
+
+// Go runs fn in a new goroutine.  Tasks exceeding the group timeout that fail without a code are marked with
+// DeadlineExceeded.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	timeout := g.timeout
+
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		ctx := g.ctx
+
+		if timeout > 0 {
+			var cancel context.CancelFunc
+
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		if err := g.run(ctx, fn); err != nil {
+			if _, ok := GetCode(err); !ok && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = WithCode(err, DeadlineExceeded)
+			}
+
+			g.mu.Lock()
+			g.errs = append(g.errs, err)
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *Group) run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer Recover(&err)
+
+	return fn(ctx)
+}
+
+// Wait blocks until all tasks complete and returns the joined failures (see errors.Join), nil if all succeeded.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return errors.Join(g.errs...)
+}
@@ -0,0 +1,59 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+func TestGroup(t *testing.T) {
+	errA := errors.New("a")
+	errB := errs.WithCode(errors.New("b"), errs.Unavailable)
+
+	g := errs.NewGroup(context.Background())
+	g.Go(func(_ context.Context) error { return nil })
+	g.Go(func(_ context.Context) error { return errA })
+	g.Go(func(_ context.Context) error { return errB })
+	g.Go(func(_ context.Context) error { panic("boom") })
+
+	err := g.Wait()
+	assert.ErrorIs(t, err, errA)
+	assert.ErrorIs(t, err, errB)
+
+	var pErr *errs.PanicError
+	assert.ErrorAs(t, err, &pErr)
+	assert.Equal(t, "boom", pErr.Value)
+
+	code, ok := errs.GetCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, errs.Unavailable, code)
+}
+
+func TestGroupSuccess(t *testing.T) {
+	g := errs.NewGroup(context.Background())
+	g.Go(func(_ context.Context) error { return nil })
+
+	assert.NoError(t, g.Wait())
+	assert.NoError(t, errs.NewGroup(context.Background()).Wait())
+}
+
+func TestGroupTimeout(t *testing.T) {
+	g := errs.NewGroup(context.Background()).WithTimeout(time.Millisecond)
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	err := g.Wait()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	code, ok := errs.GetCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, errs.DeadlineExceeded, code)
+}
@@ -0,0 +1,77 @@
+package errs
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// PanicError is the error produced from a recovered panic.  The stack is trimmed to start at the panicking
+// function and is available via ExtractStackFrame/MarshalStack.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	*stack
+}
+
+// Error returns the recovered value formatted as a panic message.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the recovered value if it was an error, allowing errors.Is/As to match panics of errors.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// FromPanic converts the result of recover() to a *PanicError, it must be called from the deferred function
+// for the stack to be accurate.  Returns nil if recovered is nil.
+func FromPanic(recovered any) error {
+	if recovered == nil {
+		return nil
+	}
+
+	return &PanicError{Value: recovered, stack: panicStack(stackOffset + 1)}
+}
+
+// Recover is deferred to convert a panic to an error assigned to err, overriding any existing error:
+//
+//	func process() (err error) {
+//		defer errs.Recover(&err)
+//		...
+//	}
+//
+// A nil err has nowhere to report the panic, so the panic is resumed rather than swallowed.
+func Recover(err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+
+	if err == nil {
+		panic(recovered)
+	}
+
+	*err = &PanicError{Value: recovered, stack: panicStack(stackOffset + 1)}
+}
+
+// panicStack records the stack, dropping runtime frames up to and including the panic call.
+func panicStack(skip int) *stack {
+	var pcs [maxDepth]uintptr
+	n := runtime.Callers(skip, pcs[:])
+
+	var st stack = pcs[0:n]
+
+	for i, pc := range st {
+		if f := runtime.FuncForPC(pc - 1); f != nil && f.Name() == "runtime.gopanic" {
+			st = st[i+1:]
+
+			break
+		}
+	}
+
+	return &st
+}
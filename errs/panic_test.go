@@ -0,0 +1,73 @@
+package errs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+func panics(v any) (err error) {
+	defer errs.Recover(&err)
+
+	panic(v)
+}
+
+func noPanic() (err error) {
+	defer errs.Recover(&err)
+
+	return errors.New("normal")
+}
+
+func TestRecover(t *testing.T) {
+	base := errors.New("base")
+
+	err := panics("boom")
+	assert.Equal(t, "panic: boom", err.Error())
+
+	var pErr *errs.PanicError
+	assert.ErrorAs(t, err, &pErr)
+	assert.Equal(t, "boom", pErr.Value)
+	assert.Nil(t, pErr.Unwrap())
+
+	frames := errs.ExtractStackFrame(err)
+	assert.NotEmpty(t, frames)
+	assert.Equal(t, "panics", frames[0].Func)
+
+	err = panics(base)
+	assert.ErrorIs(t, err, base)
+
+	assert.Equal(t, "normal", noPanic().Error())
+
+	assert.NotPanics(t, func() {
+		defer errs.Recover(nil)
+	})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer errs.Recover(nil)
+
+		panic("boom")
+	})
+}
+
+func TestFromPanic(t *testing.T) {
+	assert.Nil(t, errs.FromPanic(nil))
+
+	var err error
+
+	func() {
+		defer func() {
+			err = errs.FromPanic(recover())
+		}()
+
+		panic(42)
+	}()
+
+	assert.Equal(t, "panic: 42", err.Error())
+
+	frames := errs.ExtractStackFrame(err)
+	assert.NotEmpty(t, frames)
+	assert.Equal(t, "TestFromPanic.func1", frames[0].Func)
+}
@@ -0,0 +1,51 @@
+package errs
+
+import "errors"
+
+// publicError pairs the internal diagnostic error with a message that is safe to return to clients.
+type publicError struct {
+	err    error
+	public string
+}
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (e *publicError) Unwrap() error {
+	return e.err
+}
+
+// Error returns the internal message, this is the value that should be logged.
+func (e *publicError) Error() string {
+	return e.err.Error()
+}
+
+// UserError returns the public message, compatible with validation.UserError.
+func (e *publicError) UserError() string {
+	return e.public
+}
+
+// WithPublic attaches a client safe message to err.  Error() continues to report the internal message (e.g. the
+// SQL error) for logging, while PublicMessage returns the message for API responses.
+func WithPublic(err error, public string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &publicError{err: err, public: public}
+}
+
+// PublicMessage returns the first client safe message found in err's chain.  Any error implementing
+// `UserError() string` (e.g. validation.Error) is considered public.
+func PublicMessage(err error) (string, bool) {
+	var u interface {
+		error
+		UserError() string
+	}
+
+	if errors.As(err, &u) {
+		if msg := u.UserError(); msg != "" {
+			return msg, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,31 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+func TestWithPublic(t *testing.T) {
+	internal := errors.New(`pq: relation "users" does not exist`)
+
+	assert.Nil(t, errs.WithPublic(nil, "public"))
+
+	err := fmt.Errorf("repo: %w", errs.WithPublic(internal, "user lookup failed"))
+	assert.Equal(t, `repo: pq: relation "users" does not exist`, err.Error())
+	assert.ErrorIs(t, err, internal)
+
+	msg, ok := errs.PublicMessage(err)
+	assert.True(t, ok)
+	assert.Equal(t, "user lookup failed", msg)
+
+	_, ok = errs.PublicMessage(internal)
+	assert.False(t, ok)
+
+	_, ok = errs.PublicMessage(errs.WithPublic(internal, ""))
+	assert.False(t, ok)
+}
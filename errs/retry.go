@@ -0,0 +1,93 @@
+package errs
+
+import (
+	"errors"
+	"time"
+)
+
+// retryableError marks the wrapped error as safe to retry, optionally after a minimum delay.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Error directly returns the wrapped error's Error string.
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Retryable always reports true, see IsRetryable.
+func (e *retryableError) Retryable() bool {
+	return true
+}
+
+// MarkRetryable flags err as a transient failure that is safe to retry.  after is the minimum delay suggested
+// before retrying (e.g. from a Retry-After header), 0 leaves the delay to the caller's backoff policy.
+func MarkRetryable(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+
+	return &retryableError{err: err, after: after}
+}
+
+// IsRetryable reports whether any error in err's chain was marked with MarkRetryable, or self-reports as
+// retryable via `Retryable() bool`, `Temporary() bool` or `Timeout() bool` (e.g. net.Error).
+func IsRetryable(err error) bool {
+	for err != nil {
+		switch t := err.(type) { //nolint:errorlint // walking the chain manually
+		case interface{ Retryable() bool }:
+			if t.Retryable() {
+				return true
+			}
+		case interface{ Temporary() bool }:
+			if t.Temporary() {
+				return true
+			}
+		case interface{ Timeout() bool }:
+			if t.Timeout() {
+				return true
+			}
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return false
+}
+
+// RetryAfter returns the delay requested by MarkRetryable.  ok is false if err is not marked or no delay was
+// specified.
+func RetryAfter(err error) (time.Duration, bool) {
+	var r *retryableError
+	if errors.As(err, &r) && r.after > 0 {
+		return r.after, true
+	}
+
+	return 0, false
+}
+
+// RetryDelay returns the delay before the next attempt (0 based) of a failed operation.  The RetryAfter value of err
+// is honored if present, otherwise the delay grows exponentially from base, capped at maxDelay.
+func RetryDelay(err error, attempt int, base, maxDelay time.Duration) time.Duration {
+	if after, ok := RetryAfter(err); ok {
+		return after
+	}
+
+	delay := base
+
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		return maxDelay
+	}
+
+	return delay
+}
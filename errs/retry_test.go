@@ -0,0 +1,61 @@
+package errs_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+func TestIsRetryable(t *testing.T) {
+	base := errors.New("base")
+	deadline, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-deadline.Done()
+
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		after     time.Duration
+		hasAfter  bool
+	}{
+		{"nil", nil, false, 0, false},
+		{"plain", base, false, 0, false},
+		{"marked", errs.MarkRetryable(base, 0), true, 0, false},
+		{"marked after", errs.MarkRetryable(base, time.Second), true, time.Second, true},
+		{"wrapped", fmt.Errorf("wrap: %w", errs.MarkRetryable(base, time.Second)), true, time.Second, true},
+		{"timeout", &net.DNSError{IsTimeout: true}, true, 0, false},
+		{"not timeout", &net.DNSError{}, false, 0, false},
+		{"deadline", deadline.Err(), true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, errs.IsRetryable(tt.err))
+
+			after, ok := errs.RetryAfter(tt.err)
+			assert.Equal(t, tt.hasAfter, ok)
+			assert.Equal(t, tt.after, after)
+		})
+	}
+
+	assert.Nil(t, errs.MarkRetryable(nil, 0))
+	assert.ErrorIs(t, errs.MarkRetryable(base, 0), base)
+	assert.Equal(t, "base", errs.MarkRetryable(base, 0).Error())
+}
+
+func TestRetryDelay(t *testing.T) {
+	base := errors.New("base")
+
+	assert.Equal(t, 10*time.Millisecond, errs.RetryDelay(base, 0, 10*time.Millisecond, time.Second))
+	assert.Equal(t, 40*time.Millisecond, errs.RetryDelay(base, 2, 10*time.Millisecond, time.Second))
+	assert.Equal(t, time.Second, errs.RetryDelay(base, 20, 10*time.Millisecond, time.Second))
+	assert.Equal(t, 3*time.Second, errs.RetryDelay(errs.MarkRetryable(base, 3*time.Second), 0, 10*time.Millisecond, time.Second))
+}
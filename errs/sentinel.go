@@ -0,0 +1,88 @@
+package errs
+
+import (
+	"fmt"
+)
+
+// SentinelError is a package level error carrying a code, default status and documentation URL.  Sentinels are
+// compared by identity so errors.Is works as with errors.New, see Sentinel.
+type SentinelError struct {
+	name    string
+	code    Code
+	status  int
+	message string
+	docsURL string
+}
+
+// Sentinel declares a sentinel error, the status defaults to the code's status:
+//
+//	var ErrQuotaExceeded = errs.Sentinel("ErrQuotaExceeded", errs.TooManyRequests).
+//		WithMessage("quota exceeded").
+//		WithDocs("https://example.com/errors/quota")
+func Sentinel(name string, code Code) *SentinelError {
+	return &SentinelError{name: name, code: code, status: code.Status()}
+}
+
+// WithStatus overrides the default status, intended for use at declaration.
+func (e *SentinelError) WithStatus(status int) *SentinelError {
+	e.status = status
+
+	return e
+}
+
+// WithMessage sets the public message, intended for use at declaration.
+func (e *SentinelError) WithMessage(message string) *SentinelError {
+	e.message = message
+
+	return e
+}
+
+// WithDocs sets the documentation URL, intended for use at declaration.
+func (e *SentinelError) WithDocs(url string) *SentinelError {
+	e.docsURL = url
+
+	return e
+}
+
+// Error returns the message if defined, otherwise the name.
+func (e *SentinelError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+
+	return e.name
+}
+
+// Name returns the declared name.
+func (e *SentinelError) Name() string {
+	return e.name
+}
+
+// Code returns the declared code, see GetCode.
+func (e *SentinelError) Code() Code {
+	return e.code
+}
+
+// Status returns the default HTTP status, see Status.
+func (e *SentinelError) Status() int {
+	return e.status
+}
+
+// DocsURL returns the documentation URL.
+func (e *SentinelError) DocsURL() string {
+	return e.docsURL
+}
+
+// UserError returns the public message, see PublicMessage.
+func (e *SentinelError) UserError() string {
+	return e.message
+}
+
+// Wrap returns an error matching both the sentinel and cause with errors.Is.
+func (e *SentinelError) Wrap(cause error) error {
+	if cause == nil {
+		return e
+	}
+
+	return fmt.Errorf("%w: %w", e, cause)
+}
@@ -0,0 +1,47 @@
+package errs_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+var (
+	errQuotaExceeded = errs.Sentinel("ErrQuotaExceeded", errs.TooManyRequests).
+				WithMessage("quota exceeded").
+				WithDocs("https://example.com/errors/quota")
+	errTeapot = errs.Sentinel("ErrTeapot", errs.Internal).WithStatus(418)
+)
+
+func TestSentinel(t *testing.T) {
+	cause := errors.New("limit 100")
+
+	assert.Equal(t, "quota exceeded", errQuotaExceeded.Error())
+	assert.Equal(t, "ErrQuotaExceeded", errQuotaExceeded.Name())
+	assert.Equal(t, "ErrTeapot", errTeapot.Error())
+	assert.Equal(t, "https://example.com/errors/quota", errQuotaExceeded.DocsURL())
+
+	err := fmt.Errorf("checkout: %w", errQuotaExceeded.Wrap(cause))
+	assert.ErrorIs(t, err, errQuotaExceeded)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, errTeapot)
+	assert.Equal(t, "checkout: quota exceeded: limit 100", err.Error())
+	assert.Equal(t, errQuotaExceeded, errQuotaExceeded.Wrap(nil))
+
+	code, ok := errs.GetCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, errs.TooManyRequests, code)
+
+	msg, ok := errs.PublicMessage(err)
+	assert.True(t, ok)
+	assert.Equal(t, "quota exceeded", msg)
+
+	assert.Equal(t, 429, errs.Status(err))
+	assert.Equal(t, 418, errs.Status(errTeapot))
+	assert.Equal(t, 404, errs.Status(errs.WithCode(cause, errs.NotFound)))
+	assert.Equal(t, 500, errs.Status(cause))
+}
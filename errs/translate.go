@@ -0,0 +1,161 @@
+package errs
+
+import (
+	"errors"
+	"sync"
+)
+
+// translation maps matching errors to a code and optional public message.
+type translation struct {
+	match   func(error) bool
+	code    Code
+	message string
+}
+
+// Translator is a registry mapping upstream errors (sql.ErrNoRows, pg errors by SQLSTATE, context.DeadlineExceeded,
+// etc.) to codes and public messages.  Rules are registered once at startup and evaluated in registration order.
+type Translator struct {
+	mu    sync.RWMutex
+	rules []translation
+	hook  HookFunc
+}
+
+// Event describes an error classified by a Translator, see HookFunc.
+type Event struct {
+	// Err is the classified error, after translation.
+	Err error
+	// Code is the error code, empty if the error has no code.
+	Code Code
+	// Fingerprint is the error's Fingerprint.
+	Fingerprint string
+	// Translated is true if the error matched a translation rule.
+	Translated bool
+}
+
+// HookFunc is invoked whenever a Translator classifies or translates an error.  It is intended for metrics,
+// e.g. incrementing a counter by code/fingerprint, and must be safe for concurrent use.
+type HookFunc func(Event)
+
+// NewTranslator creates an empty Translator.
+func NewTranslator() *Translator {
+	return &Translator{}
+}
+
+// DefaultTranslator is the registry used by Translate.
+var DefaultTranslator = NewTranslator()
+
+// Translate applies DefaultTranslator to err.
+func Translate(err error) error {
+	return DefaultTranslator.Translate(err)
+}
+
+// Classify applies DefaultTranslator.Classify to err.
+func Classify(err error) Event {
+	return DefaultTranslator.Classify(err)
+}
+
+// WithHook sets the hook invoked by Translate and Classify, nil disables.
+func (t *Translator) WithHook(hook HookFunc) *Translator {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.hook = hook
+
+	return t
+}
+
+// Register maps errors matching target (via errors.Is) to code and message.  message is the public message
+// (see WithPublic), it is optional.
+func (t *Translator) Register(target error, code Code, message string) *Translator {
+	return t.RegisterFunc(func(err error) bool { return errors.Is(err, target) }, code, message)
+}
+
+// RegisterSQLState maps database errors reporting state (e.g. "23505" for unique violations) to code and message.
+// Any error implementing `SQLState() string`, such as *pgconn.PgError, is matched.
+func (t *Translator) RegisterSQLState(state string, code Code, message string) *Translator {
+	return t.RegisterFunc(func(err error) bool {
+		var s interface {
+			error
+			SQLState() string
+		}
+
+		return errors.As(err, &s) && s.SQLState() == state
+	}, code, message)
+}
+
+// RegisterFunc maps errors satisfying match to code and message.
+func (t *Translator) RegisterFunc(match func(error) bool, code Code, message string) *Translator {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rules = append(t.rules, translation{match: match, code: code, message: message})
+
+	return t
+}
+
+// Translate returns err with the code and public message of the first matching rule attached.  Errors that
+// already carry a code, or match no rules, are returned unchanged.
+func (t *Translator) Translate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	err, translated := t.translate(err)
+
+	t.notify(err, translated)
+
+	return err
+}
+
+// Classify reports the code and fingerprint of err, invoking the hook.  err is not translated.
+func (t *Translator) Classify(err error) Event {
+	if err == nil {
+		return Event{}
+	}
+
+	return t.notify(err, false)
+}
+
+func (t *Translator) translate(err error) (error, bool) {
+	if _, ok := GetCode(err); ok {
+		return err, false
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, rule := range t.rules {
+		if !rule.match(err) {
+			continue
+		}
+
+		if rule.message != "" {
+			err = WithPublic(err, rule.message)
+		}
+
+		return WithCode(err, rule.code), true
+	}
+
+	return err, false
+}
+
+func (t *Translator) notify(err error, translated bool) Event {
+	code, _ := GetCode(err)
+
+	event := Event{
+		Err:         err,
+		Code:        code,
+		Fingerprint: Fingerprint(err),
+		Translated:  translated,
+	}
+
+	t.mu.RLock()
+	hook := t.hook
+	t.mu.RUnlock()
+
+	if hook != nil {
+		hook(event)
+	}
+
+	return event
+}
@@ -0,0 +1,98 @@
+package errs_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+)
+
+type sqlStateErr string
+
+func (e sqlStateErr) Error() string    { return "pg: " + string(e) }
+func (e sqlStateErr) SQLState() string { return string(e) }
+
+func TestTranslator(t *testing.T) {
+	tr := errs.NewTranslator().
+		Register(sql.ErrNoRows, errs.NotFound, "not found").
+		Register(context.DeadlineExceeded, errs.DeadlineExceeded, "").
+		RegisterSQLState("23505", errs.AlreadyExists, "already exists").
+		RegisterFunc(func(err error) bool { return errors.Is(err, io.EOF) }, errs.InvalidArgument, "truncated")
+
+	tests := []struct {
+		name    string
+		err     error
+		code    errs.Code
+		coded   bool
+		message string
+	}{
+		{"no rows", fmt.Errorf("repo: %w", sql.ErrNoRows), errs.NotFound, true, "not found"},
+		{"deadline", context.DeadlineExceeded, errs.DeadlineExceeded, true, ""},
+		{"sqlstate", fmt.Errorf("insert: %w", sqlStateErr("23505")), errs.AlreadyExists, true, "already exists"},
+		{"other sqlstate", sqlStateErr("40001"), "", false, ""},
+		{"func", io.EOF, errs.InvalidArgument, true, "truncated"},
+		{"already coded", errs.WithCode(sql.ErrNoRows, errs.Internal), errs.Internal, true, ""},
+		{"unknown", errors.New("unknown"), "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tr.Translate(tt.err)
+			assert.ErrorIs(t, got, tt.err)
+
+			code, ok := errs.GetCode(got)
+			assert.Equal(t, tt.coded, ok)
+			assert.Equal(t, tt.code, code)
+
+			msg, _ := errs.PublicMessage(got)
+			assert.Equal(t, tt.message, msg)
+		})
+	}
+
+	assert.Nil(t, tr.Translate(nil))
+}
+
+func TestTranslate(t *testing.T) {
+	errCustom := errors.New("custom")
+
+	errs.DefaultTranslator.Register(errCustom, errs.Conflict, "conflict")
+
+	code, ok := errs.GetCode(errs.Translate(errCustom))
+	assert.True(t, ok)
+	assert.Equal(t, errs.Conflict, code)
+}
+
+func TestTranslatorHook(t *testing.T) {
+	var events []errs.Event
+
+	tr := errs.NewTranslator().
+		Register(sql.ErrNoRows, errs.NotFound, "").
+		WithHook(func(e errs.Event) { events = append(events, e) })
+
+	_ = tr.Translate(sql.ErrNoRows)
+	_ = tr.Translate(io.EOF)
+	_ = tr.Translate(nil)
+	event := tr.Classify(errs.WithCode(io.EOF, errs.InvalidArgument))
+	_ = tr.Classify(nil)
+
+	assert.Len(t, events, 3)
+	assert.Equal(t, errs.NotFound, events[0].Code)
+	assert.True(t, events[0].Translated)
+	assert.Equal(t, errs.Fingerprint(events[0].Err), events[0].Fingerprint)
+	assert.Equal(t, errs.Code(""), events[1].Code)
+	assert.False(t, events[1].Translated)
+	assert.Equal(t, errs.InvalidArgument, events[2].Code)
+	assert.Equal(t, events[2], event)
+
+	events = nil
+
+	tr.WithHook(nil)
+	_ = tr.Translate(sql.ErrNoRows)
+	assert.Empty(t, events)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/rs/zerolog"
 
+	"github.com/bir/iken/errs"
 	"github.com/bir/iken/httputil"
 )
 
@@ -52,7 +53,11 @@ func LogRecoverError(ctx context.Context, stackSkip int, recoverErr any) {
 
 	s := string(debug.Stack())
 
-	zerolog.Ctx(ctx).Err(err).Ctx(ctx).Strs(httputil.LogStack, SimplifyStack(s, stackSkip+1)).Msg("Panic")
+	zerolog.Ctx(ctx).Err(err).Ctx(ctx).
+		Fields(errs.Fields(err)).
+		Str(httputil.LogFingerprint, errs.Classify(err).Fingerprint).
+		Strs(httputil.LogStack, SimplifyStack(s, stackSkip+1)).
+		Msg("Panic")
 }
 
 var RecoverBasePath = initBasePath()
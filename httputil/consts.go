@@ -5,10 +5,14 @@ var (
 	ContentType = "Content-Type"
 	// ApplicationJSON content-type.
 	ApplicationJSON = "application/json"
+	// ApplicationProblemJSON content-type, see RFC 9457.
+	ApplicationProblemJSON = "application/problem+json"
 	// TextHTML content-type.
 	TextHTML = "text/html"
 	// TextPlain content-type.
 	TextPlain = "text/plain; charset=utf-8"
+	// TextMetrics content-type, the Prometheus text exposition format.
+	TextMetrics = "text/plain; version=0.0.4; charset=utf-8"
 )
 
 type Error string
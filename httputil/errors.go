@@ -22,8 +22,16 @@ type ClientValidationError struct {
 	Code    int                 `json:"code,omitempty"`
 	Message string              `json:"message"`
 	Fields  map[string][]string `json:"fields,omitempty"`
+	// Errors lists the failures with JSON Pointers to the fields, set if ValidationPointers is enabled.
+	Errors []validation.FieldError `json:"errors,omitempty"`
+	// Truncated is set when failures were omitted, see validation.Validator.WithMaxErrors.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
+// ValidationPointers adds the Errors list, with RFC 6901 JSON Pointers of each field, to validation error
+// responses.
+var ValidationPointers bool
+
 // CustomResponseError - respond with a custom status Code and optional Body.
 // content-type is text/plain if Body is a string, otherwise application/json is used.
 type CustomResponseError struct {
@@ -47,19 +55,24 @@ func (e CustomResponseError) Error() string {
 // json.SyntaxError to "BadRequest", body is the JSON string of the error
 // message.
 // validation.Errors to "BadRequest", body is the JSON of the error
-// object (map of field name to list of errors).
+// object (map of field name to list of errors), translated by ValidationCatalog if set.
 // AuthError to "Forbidden" or "Unauthorized" as defined by the err instance.  In addition
 // ErrBasicAuthenticate issues a basic auth challenge using default realm of "Restricted".
 // To override handle in your custom error handlers instead.
 //
 // Unhandled errors are added to the ctx and return "Internal Server Error" with
-// the request ID to aid with troubleshooting.
+// the request ID to aid with troubleshooting.  If the error carries a public message
+// (see errs.WithPublic) a problem details body with that message is returned instead.
+// Errors with an errs.Code return a problem details body using the code's status.
+// Every error is reported with errs.Classify, so the errs hook observes all handled errors.
 func ErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
 	if err == nil {
 		return
 	}
 
 	logctx.AddStrToContext(r.Context(), LogErrorMessage, err.Error())
+	logctx.AddStrToContext(r.Context(), LogFingerprint, errs.Classify(err).Fingerprint)
+	logctx.AddErrorFieldsToContext(r.Context(), err)
 
 	if stack := errs.MarshalStack(err); stack != nil {
 		logctx.AddToContext(r.Context(), LogStack, stack)
@@ -71,6 +84,8 @@ func ErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
 		validationErr  validation.Error
 	)
 
+	_, isCoded := errs.GetCode(err)
+
 	switch {
 	case errors.Is(err, context.Canceled):
 		http.Error(w, "canceled", StatusContextCancelled)
@@ -100,14 +115,32 @@ func ErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
 		}
 
 	case errors.As(err, &validationErrs):
-		JSONWrite(w, r, http.StatusBadRequest,
-			ClientValidationError{http.StatusBadRequest, "validation errors", validationErrs.Fields()})
+		localized := localizeValidation(w, r, validationErrs)
+		resp := ClientValidationError{Code: http.StatusBadRequest, Message: "validation errors", Fields: localized.Fields()}
+
+		var limitErr *validation.LimitError
+		resp.Truncated = errors.As(err, &limitErr)
+
+		if ValidationPointers {
+			resp.Errors = localized.Details()
+		}
+
+		JSONWrite(w, r, http.StatusBadRequest, resp)
 
 	case errors.As(err, &validationErr):
 		JSONWrite(w, r, http.StatusBadRequest,
-			ClientValidationError{http.StatusBadRequest, validationErr.UserError(), nil})
+			ClientValidationError{Code: http.StatusBadRequest, Message: validationErr.UserError()})
+
+	case isCoded:
+		writeProblem(w, r, errs.Status(err), err)
 
 	default:
+		if _, ok := errs.PublicMessage(err); ok {
+			writeProblem(w, r, http.StatusInternalServerError, err)
+
+			return
+		}
+
 		HTTPInternalServerError(w, r)
 	}
 }
@@ -117,6 +150,8 @@ const (
 	LogErrorMessage = "error.message"
 	// LogStack is used to report available error stacks to logging.
 	LogStack = "error.stack"
+	// LogFingerprint is used to report the error fingerprint (see errs.Fingerprint) for grouping identical failures.
+	LogFingerprint = "error.fingerprint"
 
 	RequestIDHeader = "X-Request-Id"
 
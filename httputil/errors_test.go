@@ -41,6 +41,9 @@ func TestErrorHandler(t *testing.T) {
 		{"not found error", context.Background(), errs.WithStack(httputil.ErrNotFound, 0), "", 404, "Not Found\n", "not found"},
 		{"unknown error", context.Background(), errs.WithStack("unknown error", 0), "", 500, "Internal Server Error\n", "unknown error"},
 		{"unknown error w/request ID", context.Background(), errs.WithStack("unknown error", 0), "FOO", 500, "Internal Server Error: Request \"FOO\"\n", "unknown error"},
+		{"public error", context.Background(), errs.WithPublic(errors.New("pq: bad sql"), "lookup failed"), "", 500, `{"title":"Internal Server Error","status":500,"detail":"lookup failed"}`, "pq: bad sql"},
+		{"coded error", context.Background(), errs.WithCode(errs.WithPublic(errors.New("pq: no rows"), "no user"), errs.NotFound), "", 404, `{"title":"Not Found","status":404,"detail":"no user"}`, "pq: no rows"},
+		{"sentinel error", context.Background(), errs.Sentinel("ErrTeapot", errs.Internal).WithStatus(418), "", 418, `{"title":"I'm a teapot","status":418}`, "ErrTeapot"},
 		{"validation errors", context.Background(), validation.New("name", "bad"), "", 400, `{"code":400,"message":"validation errors","fields":{"name":["bad"]}}`, "name: bad."},
 		{"validation errors public", context.Background(), validation.New("name", validation.Error{Message: "public message", Source: errors.New("private error")}), "", 400, `{"code":400,"message":"validation errors","fields":{"name":["public message"]}}`, "name: public message: private error."},
 		{"validation errors json", context.Background(), validation.New("name", validation.Error{Message: "json error", Source: json.Unmarshal([]byte("bad json"), &nop)}), "", 400, `{"code":400,"message":"validation errors","fields":{"name":["json error"]}}`, "name: json error: invalid character 'b' looking for beginning of value."},
@@ -0,0 +1,85 @@
+package httputil
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// LocalizeFunc translates the client facing title/detail of a problem response, code is empty for errors without
+// an errs.Code.  Logs are unaffected and keep the canonical internal message.  Set Problem.Language to the language of
+// the translation for the Content-Language header.
+type LocalizeFunc func(r *http.Request, code errs.Code, p Problem) Problem
+
+// ProblemLocalizer is applied by WriteProblem and ErrorHandler problem responses when not nil.
+var ProblemLocalizer LocalizeFunc
+
+// LocalizedMessage is the translated title and detail for a code, empty values keep the default.
+type LocalizedMessage struct {
+	Title  string
+	Detail string
+}
+
+// MessageCatalog maps language tags (e.g. "fr", "pt-BR") to the messages per code.
+type MessageCatalog map[string]map[errs.Code]LocalizedMessage
+
+// CatalogLocalizer returns a LocalizeFunc selecting messages from catalog by the request's AcceptLanguages.  Region
+// specific tags fall back to the base language ("fr-CA" => "fr"), which is then the Problem.Language.
+func CatalogLocalizer(catalog MessageCatalog) LocalizeFunc {
+	return func(r *http.Request, code errs.Code, p Problem) Problem {
+		for _, tag := range AcceptLanguages(r) {
+			msg, language, ok := catalog.lookup(tag, code)
+			if !ok {
+				continue
+			}
+
+			p.Language = language
+
+			if msg.Title != "" {
+				p.Title = msg.Title
+			}
+
+			if msg.Detail != "" {
+				p.Detail = msg.Detail
+			}
+
+			break
+		}
+
+		return p
+	}
+}
+
+func (c MessageCatalog) lookup(tag string, code errs.Code) (LocalizedMessage, string, bool) {
+	if msg, ok := c[tag][code]; ok {
+		return msg, tag, true
+	}
+
+	if base, _, found := strings.Cut(tag, "-"); found {
+		msg, ok := c[base][code]
+
+		return msg, base, ok
+	}
+
+	return LocalizedMessage{}, "", false
+}
+
+// ValidationCatalog translates validation.Errors messages returned by ErrorHandler when not nil, selected by the
+// request's AcceptLanguages.
+var ValidationCatalog validation.Catalog
+
+func localizeValidation(w http.ResponseWriter, r *http.Request, ee *validation.Errors) *validation.Errors {
+	if ValidationCatalog == nil {
+		return ee
+	}
+
+	w.Header().Add("Vary", "Accept-Language")
+
+	if localized, ok := ValidationCatalog.Localize(ee, AcceptLanguages(r)...).(*validation.Errors); ok {
+		return localized
+	}
+
+	return ee
+}
@@ -0,0 +1,110 @@
+package httputil_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/httputil"
+	"github.com/bir/iken/validation"
+)
+
+func TestCatalogLocalizer(t *testing.T) {
+	httputil.ProblemLocalizer = httputil.CatalogLocalizer(httputil.MessageCatalog{
+		"fr": {errs.NotFound: {Title: "Introuvable", Detail: "Utilisateur introuvable"}},
+		"de": {errs.NotFound: {Title: "Nicht gefunden"}},
+	})
+
+	defer func() { httputil.ProblemLocalizer = nil }()
+
+	err := errs.WithCode(errs.WithPublic(errors.New("no rows"), "user not found"), errs.NotFound)
+
+	tests := []struct {
+		name     string
+		language string
+		err      error
+		body     string
+		content  string
+	}{
+		{"default", "", err, `{"title":"Not Found","status":404,"detail":"user not found"}`, ""},
+		{"unsupported", "es", err, `{"title":"Not Found","status":404,"detail":"user not found"}`, ""},
+		{"base language", "fr-CA", err, `{"title":"Introuvable","status":404,"detail":"Utilisateur introuvable"}`, "fr"},
+		{
+			"preference", "es, de;q=0.9, fr;q=0.8", err,
+			`{"title":"Nicht gefunden","status":404,"detail":"user not found"}`, "de",
+		},
+		{"uncoded", "fr", errors.New("x"), `{"title":"Not Found","status":404}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept-Language", tt.language)
+
+			w := httptest.NewRecorder()
+			httputil.WriteProblem(w, r, http.StatusNotFound, tt.err)
+
+			b, _ := io.ReadAll(w.Result().Body)
+			assert.Equal(t, tt.body, string(b))
+			assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+			assert.Equal(t, tt.content, w.Header().Get("Content-Language"))
+		})
+	}
+}
+
+func TestValidationCatalog(t *testing.T) {
+	httputil.ValidationCatalog = validation.Catalog{
+		"fr": {"min.string": "doit contenir au moins {min} caractères"},
+	}
+
+	defer func() { httputil.ValidationCatalog = nil }()
+
+	err := validation.Struct(struct {
+		Name string `json:"name" validate:"min=3"`
+	}{Name: "a"})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "fr-FR")
+
+	w := httptest.NewRecorder()
+	httputil.ErrorHandler(w, r, err)
+
+	b, _ := io.ReadAll(w.Result().Body)
+	assert.Equal(t, `{"code":400,"message":"validation errors","fields":{"name":["doit contenir au moins 3 caractères"]}}`,
+		string(b))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+}
+
+func TestValidationPointers(t *testing.T) {
+	httputil.ValidationPointers = true
+
+	defer func() { httputil.ValidationPointers = false }()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	httputil.ErrorHandler(w, r, validation.New("items[3].quantity", "must be at least 1"))
+
+	b, _ := io.ReadAll(w.Result().Body)
+	assert.Equal(t, `{"code":400,"message":"validation errors","fields":{"items[3].quantity":["must be at least 1"]},`+
+		`"errors":[{"path":"items[3].quantity","pointer":"/items/3/quantity","messages":["must be at least 1"]}]}`,
+		string(b))
+}
+
+func TestValidationTruncated(t *testing.T) {
+	err := validation.NewValidator().WithMaxErrors(1).Struct(struct {
+		A string `json:"a" validate:"required"`
+		B string `json:"b" validate:"required"`
+	}{})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	httputil.ErrorHandler(w, r, err)
+
+	b, _ := io.ReadAll(w.Result().Body)
+	assert.Equal(t, `{"code":400,"message":"validation errors","fields":{"a":["is required"]},"truncated":true}`, string(b))
+}
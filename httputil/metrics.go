@@ -0,0 +1,90 @@
+package httputil
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// Metrics builds metrics in the Prometheus text exposition format, escaping help texts and label values.
+type Metrics struct {
+	b strings.Builder
+}
+
+// Family starts the metric family name of kind (counter, gauge or histogram) described by help.
+func (m *Metrics) Family(name, kind, help string) {
+	m.b.WriteString("# HELP " + name + " " + helpEscaper.Replace(help) + "\n# TYPE " + name + " " + kind + "\n")
+}
+
+// Sample adds the sample value of name, labeled by pairs of label names and values.
+func (m *Metrics) Sample(name string, value any, labels ...string) {
+	m.b.WriteString(name)
+
+	if len(labels) > 1 {
+		m.b.WriteByte('{')
+
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.b.WriteByte(',')
+			}
+
+			m.b.WriteString(labels[i] + `="` + labelEscaper.Replace(labels[i+1]) + `"`)
+		}
+
+		m.b.WriteByte('}')
+	}
+
+	m.b.WriteString(" " + MetricValue(value) + "\n")
+}
+
+// WriteTo writes the metrics to w.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, m.b.String())
+	if err != nil {
+		return int64(n), fmt.Errorf("write metrics: %w", err)
+	}
+
+	return int64(n), nil
+}
+
+// MetricValue formats v as a sample value or le label: floats as +Inf, -Inf, NaN or their shortest representation.
+func MetricValue(v any) string {
+	var f float64
+
+	switch v := v.(type) {
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	default:
+		return fmt.Sprint(v)
+	}
+
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// MetricsHandler serves the metrics written by write, for scraping by Prometheus.
+func MetricsHandler(write func(w io.Writer) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(ContentType, TextMetrics)
+
+		_ = write(w)
+	}
+}
@@ -0,0 +1,71 @@
+package httputil_test
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/httputil"
+)
+
+func TestMetrics(t *testing.T) {
+	var m httputil.Metrics
+
+	m.Family("requests_total", "counter", "Requests by path,\nwith \\ escapes.")
+	m.Sample("requests_total", uint64(3), "path", `/a"b\c`+"\n", "method", "GET")
+	m.Sample("requests_total", 1)
+	m.Family("latency_seconds", "histogram", "Latency.")
+	m.Sample("latency_seconds_bucket", 2, "le", httputil.MetricValue(0.005))
+	m.Sample("latency_seconds_bucket", 2, "le", httputil.MetricValue(math.Inf(1)))
+	m.Sample("latency_seconds_sum", 0.25)
+	m.Sample("temperature", math.NaN(), "city", "Zürich\t")
+
+	var b strings.Builder
+
+	n, err := m.WriteTo(&b)
+	require.NoError(t, err)
+	assert.Equal(t, int64(b.Len()), n)
+	assert.Equal(t, `# HELP requests_total Requests by path,\nwith \\ escapes.
+# TYPE requests_total counter
+requests_total{path="/a\"b\\c\n",method="GET"} 3
+requests_total 1
+# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.005"} 2
+latency_seconds_bucket{le="+Inf"} 2
+latency_seconds_sum 0.25
+temperature{city="Zürich	"} NaN
+`, b.String())
+
+	assert.Equal(t, "-Inf", httputil.MetricValue(float32(math.Inf(-1))))
+	assert.Equal(t, "1e-05", httputil.MetricValue(0.00001))
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("closed") }
+
+func TestMetricsHandler(t *testing.T) {
+	var m httputil.Metrics
+
+	m.Sample("up", 1)
+
+	_, err := m.WriteTo(failWriter{})
+	require.EqualError(t, err, "write metrics: closed")
+
+	w := httptest.NewRecorder()
+	httputil.MetricsHandler(func(w io.Writer) error {
+		_, err := m.WriteTo(w)
+
+		return err
+	})(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, httputil.TextMetrics, w.Header().Get("Content-Type"))
+	assert.Equal(t, "up 1\n", w.Body.String())
+}
@@ -0,0 +1,77 @@
+package httputil
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/logctx"
+)
+
+// Problem is the RFC 9457 problem details response body.
+type Problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// RequestID is the request_id extension member, set from the RequestIDHeader.  Instance is a URI reference.
+	RequestID string `json:"request_id,omitempty"`
+	// Language is the Content-Language of the title and detail, set by localizers.
+	Language string `json:"-"`
+}
+
+// WriteProblem responds with an RFC 9457 problem details body for err.  Only the public message of err (see
+// errs.WithPublic) is returned to the client as the detail, the internal message is logged to the request context.
+// The type is set from errors providing a docs URL, see errs.SentinelError.  Messages are translated with
+// ProblemLocalizer if set, the response then varies by Accept-Language.
+func WriteProblem(w http.ResponseWriter, r *http.Request, status int, err error) {
+	if err != nil {
+		logctx.AddStrToContext(r.Context(), LogErrorMessage, err.Error())
+	}
+
+	writeProblem(w, r, status, err)
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, err error) {
+	p := Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+
+	if msg, ok := errs.PublicMessage(err); ok {
+		p.Detail = msg
+	}
+
+	var docs interface {
+		error
+		DocsURL() string
+	}
+
+	if errors.As(err, &docs) {
+		p.Type = docs.DocsURL()
+	}
+
+	p.RequestID = r.Header.Get(RequestIDHeader)
+
+	if ProblemLocalizer != nil {
+		code, _ := errs.GetCode(err)
+		p = ProblemLocalizer(r, code, p)
+
+		w.Header().Add("Vary", "Accept-Language")
+
+		if p.Language != "" {
+			w.Header().Set("Content-Language", p.Language)
+		}
+	}
+
+	b, mErr := json.Marshal(p)
+	if mErr != nil {
+		HTTPError(w, http.StatusInternalServerError) // Ignore coverage - Problem always marshals
+
+		return
+	}
+
+	Write(w, r, ApplicationProblemJSON, status, b)
+}
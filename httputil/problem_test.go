@@ -0,0 +1,51 @@
+package httputil_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/httputil"
+)
+
+func TestWriteProblem(t *testing.T) {
+	internal := errors.New("pq: syntax error")
+
+	tests := []struct {
+		name      string
+		err       error
+		requestID string
+		status    int
+		body      string
+	}{
+		{"nil", nil, "", 400, `{"title":"Bad Request","status":400}`},
+		{"internal only", internal, "", 500, `{"title":"Internal Server Error","status":500}`},
+		{"sentinel", errs.Sentinel("ErrQuota", errs.TooManyRequests).WithMessage("quota").WithDocs("https://example.com/quota"), "", 429, `{"type":"https://example.com/quota","title":"Too Many Requests","status":429,"detail":"quota"}`},
+		{"public", errs.WithPublic(internal, "lookup failed"), "REQ", 503, `{"title":"Service Unavailable","status":503,"detail":"lookup failed","request_id":"REQ"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.requestID != "" {
+				r.Header.Set(httputil.RequestIDHeader, tt.requestID)
+			}
+
+			w := httptest.NewRecorder()
+
+			httputil.WriteProblem(w, r, tt.status, tt.err)
+
+			result := w.Result()
+			b, _ := io.ReadAll(result.Body)
+
+			assert.Equal(t, tt.status, result.StatusCode)
+			assert.Equal(t, httputil.ApplicationProblemJSON, result.Header.Get(httputil.ContentType))
+			assert.Equal(t, tt.body, string(b))
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package httputil
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bir/iken/errs"
+)
+
+// RetryClassifier maps the result of a round trip to an error.  Returning an error marked with errs.MarkRetryable
+// causes the request to be retried, any other result is returned to the caller as is.
+type RetryClassifier func(resp *http.Response, err error) error
+
+// RetryTransport is an http.RoundTripper that retries requests that fail with retryable errors (see
+// errs.IsRetryable).  Requests with a body are only retried if GetBody is available.
+type RetryTransport struct {
+	// Next is the underlying transport, defaults to http.DefaultTransport.
+	Next http.RoundTripper
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// BaseDelay is the initial backoff delay, doubled for each attempt, defaults to 100ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay, defaults to 5s.
+	MaxDelay time.Duration
+	// Classify determines if a result is retryable, defaults to DefaultRetryClassifier.
+	Classify RetryClassifier
+}
+
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// NewRetryTransport wraps next with retries using the default policies.
+func NewRetryTransport(next http.RoundTripper, maxAttempts int) *RetryTransport {
+	return &RetryTransport{
+		Next:        next,
+		MaxAttempts: maxAttempts,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+		Classify:    DefaultRetryClassifier,
+	}
+}
+
+// DefaultRetryClassifier treats transport errors as retryable if errs.IsRetryable reports so, as well as 429, 502,
+// 503 and 504 responses.  The Retry-After header (in seconds) is honored.
+func DefaultRetryClassifier(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return errs.MarkRetryable(Error(resp.Status), retryAfter(resp.Header))
+	}
+
+	return nil
+}
+
+func retryAfter(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	classify := t.Classify
+	if classify == nil {
+		classify = DefaultRetryClassifier
+	}
+
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		resp, err := next.RoundTrip(req)
+
+		rErr := classify(resp, err)
+		if rErr == nil || !errs.IsRetryable(rErr) || !canRetry || attempt+1 >= t.MaxAttempts {
+			return resp, err //nolint:wrapcheck // transparent proxy
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(errs.RetryDelay(rErr, attempt, t.BaseDelay, t.MaxDelay))
+
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, fmt.Errorf("retry: %w", req.Context().Err())
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("retry GetBody: %w", err)
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
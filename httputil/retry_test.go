@@ -0,0 +1,114 @@
+package httputil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/httputil"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		attempts  int
+		wantCalls int32
+		want      int
+	}{
+		{"success", []int{200}, 3, 1, 200},
+		{"retry then success", []int{503, 502, 200}, 3, 3, 200},
+		{"exhausted", []int{503, 503, 503}, 3, 3, 503},
+		{"not retryable", []int{500, 200}, 3, 1, 500},
+		{"single attempt", []int{429, 200}, 1, 1, 429},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, "body", string(body))
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			rt := httputil.NewRetryTransport(nil, tt.attempts)
+			rt.BaseDelay = time.Millisecond
+
+			client := http.Client{Transport: rt}
+
+			resp, err := client.Post(srv.URL, "text/plain", bytes.NewBufferString("body"))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, atomic.LoadInt32(&calls))
+			_ = resp.Body.Close()
+		})
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	calls := 0
+
+	rt := &httputil.RetryTransport{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Millisecond,
+		Next: roundTripFunc(func(_ *http.Request) (*http.Response, error) {
+			calls++
+			if calls == 1 {
+				return nil, errs.MarkRetryable(errBoom, 0)
+			}
+
+			return nil, errBoom
+		}),
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err := rt.RoundTrip(r) //nolint:bodyclose
+	assert.ErrorIs(t, err, errBoom)
+	assert.Equal(t, 2, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rt.BaseDelay = time.Hour
+	rt.MaxDelay = time.Hour
+	rt.Next = roundTripFunc(func(_ *http.Request) (*http.Response, error) {
+		cancel()
+
+		return nil, errs.MarkRetryable(errBoom, 0)
+	})
+
+	_, err = rt.RoundTrip(r.WithContext(ctx)) //nolint:bodyclose
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests", Header: http.Header{}}
+	resp.Header.Set("Retry-After", "7")
+
+	err := httputil.DefaultRetryClassifier(resp, nil)
+	assert.True(t, errs.IsRetryable(err))
+
+	after, ok := errs.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, after)
+
+	assert.Nil(t, httputil.DefaultRetryClassifier(&http.Response{StatusCode: http.StatusOK}, nil))
+}
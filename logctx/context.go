@@ -9,6 +9,7 @@ type ContextKey string
 const (
 	opID      ContextKey = "request_id"
 	opMessage ContextKey = "request_message"
+	opName    ContextKey = "operation"
 )
 
 // SetID sets the request ID logged to the context.
@@ -25,3 +26,15 @@ func GetID(ctx context.Context) string {
 
 	return ""
 }
+
+// SetOperation sets the operation (e.g. the API operation ID) logged to the context.
+func SetOperation(ctx context.Context, op string) context.Context {
+	return context.WithValue(ctx, opName, op)
+}
+
+// GetOperation returns the operation logged to the context, otherwise "".
+func GetOperation(ctx context.Context) string {
+	s, _ := ctx.Value(opName).(string)
+
+	return s
+}
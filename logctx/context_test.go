@@ -17,3 +17,11 @@ func TestID(t *testing.T) {
 	ctx = logctx.SetID(ctx, "123")
 	assert.Equal(t, "123", logctx.GetID(ctx))
 }
+
+func TestOperation(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, logctx.GetOperation(ctx))
+
+	ctx = logctx.SetOperation(ctx, "getUser")
+	assert.Equal(t, "getUser", logctx.GetOperation(ctx))
+}
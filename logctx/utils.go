@@ -4,6 +4,9 @@ import (
 	"context"
 
 	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/strutil"
 )
 
 // NewContextFrom returns a child context without cancel and a sub-logger attached.
@@ -39,6 +42,13 @@ func AddMapToContext(ctx context.Context, fields map[string]any) {
 	})
 }
 
+// AddErrorFieldsToContext adds the structured fields attached to err (see errs.WithFields) to the log context.
+func AddErrorFieldsToContext(ctx context.Context, err error) {
+	if fields := errs.Fields(err); len(fields) > 0 {
+		AddMapToContext(ctx, fields)
+	}
+}
+
 // AddBytesToContext adds the key/value to the log context.
 func AddBytesToContext(ctx context.Context, key string, value []byte, maxSize uint32) {
 	zerolog.Ctx(ctx).UpdateContext(func(c zerolog.Context) zerolog.Context {
@@ -46,16 +56,17 @@ func AddBytesToContext(ctx context.Context, key string, value []byte, maxSize ui
 	})
 }
 
-// AddBytes adds the key/value (truncated by maxSize) to the log context.
+// AddBytes adds the key/value (truncated by maxSize, without splitting UTF-8 runes) to the log context.
 func AddBytes(ctx zerolog.Context, key string, value []byte, maxSize uint32) zerolog.Context {
 	size := len(value)
 
 	ctx = ctx.Int(key+".size", size)
 
 	if size > int(maxSize) {
-		ctx = ctx.Bytes(key+".body", value[:maxSize])
+		truncated := strutil.TruncateBytes(value, int(maxSize))
+		ctx = ctx.Bytes(key+".body", truncated)
 		ctx = ctx.Bool(key+".truncated", true)
-		ctx = ctx.Uint32(key+".truncatedSize", maxSize)
+		ctx = ctx.Int(key+".truncatedSize", len(truncated))
 	} else {
 		ctx = ctx.Bytes(key+".body", value)
 	}
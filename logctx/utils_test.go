@@ -3,11 +3,13 @@ package logctx_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/bir/iken/errs"
 	"github.com/bir/iken/logctx"
 )
 
@@ -43,3 +45,28 @@ func TestLogContext(t *testing.T) {
 	assert.Equal(t, id, logctx.GetID(ctx))
 	assert.Equal(t, id, logctx.GetID(ctx2))
 }
+
+func TestAddErrorFieldsToContext(t *testing.T) {
+	logBuffer := bytes.NewBuffer(nil)
+	ctx := logctx.NewSubLoggerContext(context.Background(), zerolog.New(logBuffer))
+
+	logctx.AddErrorFieldsToContext(ctx, errors.New("no fields"))
+	logctx.AddErrorFieldsToContext(ctx, errs.WithFields(errors.New("fields"), map[string]any{"order_id": 42}))
+
+	zerolog.Ctx(ctx).Log().Msg("ctx")
+
+	assert.Equal(t, `{"order_id":42,"message":"ctx"}
+`, logBuffer.String())
+}
+
+func TestAddBytesRuneBoundary(t *testing.T) {
+	logBuffer := bytes.NewBuffer(nil)
+	ctx := logctx.NewSubLoggerContext(context.Background(), zerolog.New(logBuffer))
+
+	logctx.AddBytesToContext(ctx, "body", []byte("ab日本"), 4)
+
+	zerolog.Ctx(ctx).Log().Msg("ctx")
+
+	assert.Equal(t, `{"body.size":8,"body.body":"ab","body.truncated":true,"body.truncatedSize":2,"message":"ctx"}
+`, logBuffer.String())
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNotifierType is returned by New for unknown notifier types.
+var ErrNotifierType = errors.New("unknown notifier type, use slack, teams, discord, pagerduty or webhook")
+
+// Config selects and configures a notifier, so destinations are switched by configuration, e.g. with the config
+// package:
+//
+//	notifier:
+//	  type: discord
+//	  url: https://discord.com/api/webhooks/...
+//	  routes:
+//	    critical: https://discord.com/api/webhooks/...
+type Config struct {
+	// Type is slack, teams, discord, pagerduty or webhook.
+	Type string
+	// URL is the webhook URL, or overrides the PagerDuty Events API URL.
+	URL string
+	// Token and Channel post Slack messages with a bot token instead of a webhook, Token is the PagerDuty routing key
+	// or the signing secret of a webhook.
+	Token   string
+	Channel string
+	// Routes are the destinations (webhook URLs, Slack channels or PagerDuty routing keys) of the messages at or
+	// above a severity, by severity name.
+	Routes map[string]string
+	// Timeout bounds each delivery, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+// New returns the notifier configured by cfg.
+func New(cfg Config) (Notifier, error) {
+	routes := make(map[Severity]string, len(cfg.Routes))
+
+	for name, dest := range cfg.Routes {
+		s, err := ParseSeverity(name)
+		if err != nil {
+			return nil, fmt.Errorf("notifier routes: %w", err)
+		}
+
+		routes[s] = dest
+	}
+
+	switch strings.ToLower(cfg.Type) {
+	case "slack":
+		return &Slack{
+			WebhookURL: cfg.URL, Token: cfg.Token, Channel: cfg.Channel, Routes: routes, Timeout: cfg.Timeout,
+		}, nil
+	case "teams":
+		return &Teams{WebhookURL: cfg.URL, Routes: routes, Timeout: cfg.Timeout}, nil
+	case "discord":
+		return &Discord{WebhookURL: cfg.URL, Routes: routes, Timeout: cfg.Timeout}, nil
+	case "pagerduty":
+		return &PagerDuty{RoutingKey: cfg.Token, Routes: routes, Timeout: cfg.Timeout, URL: cfg.URL}, nil
+	case "webhook":
+		if cfg.Token == "" {
+			return nil, fmt.Errorf("notifier token: %w", ErrWebhookSecret)
+		}
+
+		return &Webhook{URL: cfg.URL, Secret: []byte(cfg.Token), Routes: routes, Timeout: cfg.Timeout}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrNotifierType, cfg.Type)
+}
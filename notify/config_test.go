@@ -0,0 +1,51 @@
+package notify_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+func TestNew(t *testing.T) {
+	n, err := notify.New(notify.Config{
+		Type: "Slack", Token: "xoxb", Channel: "#alerts",
+		Routes: map[string]string{"critical": "#incidents"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &notify.Slack{
+		Token: "xoxb", Channel: "#alerts",
+		Routes: map[notify.Severity]string{notify.Critical: "#incidents"},
+	}, n)
+
+	n, err = notify.New(notify.Config{Type: "teams", URL: "https://teams", Timeout: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, &notify.Teams{
+		WebhookURL: "https://teams", Routes: map[notify.Severity]string{},
+		Timeout: time.Second,
+	}, n)
+
+	n, err = notify.New(notify.Config{Type: "discord", URL: "https://discord"})
+	require.NoError(t, err)
+	assert.IsType(t, &notify.Discord{}, n)
+
+	n, err = notify.New(notify.Config{Type: "pagerduty", Token: "R0UT1NG"})
+	require.NoError(t, err)
+	assert.Equal(t, "R0UT1NG", n.(*notify.PagerDuty).RoutingKey)
+
+	n, err = notify.New(notify.Config{Type: "webhook", URL: "https://hooks", Token: "s3cret"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("s3cret"), n.(*notify.Webhook).Secret)
+
+	_, err = notify.New(notify.Config{Type: "webhook", URL: "https://hooks"})
+	require.ErrorIs(t, err, notify.ErrWebhookSecret)
+
+	_, err = notify.New(notify.Config{Type: "pager"})
+	require.ErrorIs(t, err, notify.ErrNotifierType)
+
+	_, err = notify.New(notify.Config{Type: "slack", Routes: map[string]string{"loud": "#x"}})
+	require.EqualError(t, err, `notifier routes: invalid severity: "loud"`)
+}
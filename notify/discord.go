@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Discord embed limits.
+const (
+	discordTitleMax       = 256
+	discordDescriptionMax = 4096
+	discordFieldsMax      = 25
+	discordFieldNameMax   = 256
+	discordFieldValueMax  = 1024
+)
+
+// Embed colors of the severities.
+var discordColor = map[Severity]int{
+	Info:     0x3498db,
+	Warning:  0xf1c40f,
+	Error:    0xe74c3c,
+	Critical: 0x992d22,
+}
+
+// Discord posts messages to a Discord webhook as embeds.
+type Discord struct {
+	// WebhookURL is the webhook.
+	WebhookURL string
+	// Username overrides the name of the webhook.
+	Username string
+	// Routes overrides the webhook URL of the messages at or above a severity.
+	Routes map[Severity]string
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+	// Timeout bounds each delivery, within the ctx deadline, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+var _ Notifier = (*Discord)(nil)
+
+// NewDiscord returns a Discord notifier posting to the webhook url.
+func NewDiscord(url string) *Discord {
+	return &Discord{WebhookURL: url}
+}
+
+// DiscordEmbed is a rich message of a Discord webhook.
+type DiscordEmbed struct {
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []DiscordEmbedField `json:"fields,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+	Footer      *DiscordEmbedFooter `json:"footer,omitempty"`
+}
+
+// DiscordEmbedField is a field of a DiscordEmbed.
+type DiscordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// DiscordEmbedFooter is the footer of a DiscordEmbed.
+type DiscordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// DiscordMessage is the payload of a Discord webhook.
+type DiscordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Content  string         `json:"content,omitempty"`
+	Embeds   []DiscordEmbed `json:"embeds"`
+}
+
+// NewDiscordEmbed renders m as an embed colored by severity, truncated to the Discord limits.
+func NewDiscordEmbed(m Message) DiscordEmbed {
+	e := DiscordEmbed{
+		Title:       truncate(m.Title, discordTitleMax),
+		Description: truncate(m.Text, discordDescriptionMax),
+		URL:         m.URL,
+		Color:       discordColor[m.Severity],
+		Footer:      &DiscordEmbedFooter{Text: m.Severity.String()},
+	}
+
+	if !m.Time.IsZero() {
+		e.Timestamp = m.Time.UTC().Format(time.RFC3339)
+	}
+
+	for _, f := range m.Fields[:min(len(m.Fields), discordFieldsMax)] {
+		e.Fields = append(e.Fields, DiscordEmbedField{
+			Name:   truncate(f.Name, discordFieldNameMax),
+			Value:  truncate(f.Value, discordFieldValueMax),
+			Inline: true,
+		})
+	}
+
+	return e
+}
+
+// Notify posts m.
+func (d *Discord) Notify(ctx context.Context, m Message) error {
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+
+	msg := DiscordMessage{Username: d.Username, Embeds: []DiscordEmbed{NewDiscordEmbed(m)}}
+
+	_, err := postJSON(ctx, d.Client, d.Timeout, route(d.Routes, m.Severity, d.WebhookURL), nil, msg)
+
+	return err
+}
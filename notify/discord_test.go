@@ -0,0 +1,54 @@
+package notify_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+func TestDiscord(t *testing.T) {
+	rec := newRecorder(t, "")
+
+	n := notify.NewDiscord(rec.URL)
+	n.Username = "alerts"
+
+	require.NoError(t, n.Notify(context.Background(), notify.Message{
+		Title:    "Payment failures",
+		Text:     "3 charges failed",
+		Severity: notify.Warning,
+		Fields:   []notify.Field{{Name: "Service", Value: "billing"}},
+		URL:      "https://logs.example.com",
+		Time:     testTime,
+	}))
+
+	assert.JSONEq(t, `{
+		"username": "alerts",
+		"embeds": [{
+			"title": "Payment failures",
+			"description": "3 charges failed",
+			"url": "https://logs.example.com",
+			"color": 15844367,
+			"fields": [{"name": "Service", "value": "billing", "inline": true}],
+			"timestamp": "2024-05-01T12:00:00Z",
+			"footer": {"text": "warning"}
+		}]
+	}`, rec.bodies[0])
+}
+
+func TestNewDiscordEmbed(t *testing.T) {
+	fields := make([]notify.Field, 30)
+	for i := range fields {
+		fields[i] = notify.Field{Name: "n", Value: strings.Repeat("v", 2000)}
+	}
+
+	e := notify.NewDiscordEmbed(notify.Message{Title: strings.Repeat("é", 300), Fields: fields})
+	assert.Len(t, []rune(e.Title), 256)
+	assert.Len(t, e.Fields, 25)
+	assert.Len(t, []rune(e.Fields[0].Value), 1024)
+	assert.Empty(t, e.Timestamp)
+}
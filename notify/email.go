@@ -0,0 +1,515 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bir/iken/errs"
+)
+
+var (
+	// ErrNoRecipients is returned when sending a Mail without sender or recipients.
+	ErrNoRecipients = errors.New("email without sender or recipients")
+	// ErrStartTLS is returned when the server does not offer the STARTTLS required by the StartTLS mode.
+	ErrStartTLS = errors.New("STARTTLS not offered")
+)
+
+// TLSMode selects how Email secures the SMTP connection.
+type TLSMode int8
+
+const (
+	// StartTLS upgrades the connection with STARTTLS, failing if the server does not offer it.
+	StartTLS TLSMode = iota
+	// ImplicitTLS connects with TLS, e.g. to port 465.
+	ImplicitTLS
+	// OptionalStartTLS upgrades the connection with STARTTLS if offered, e.g. for a local relay.
+	OptionalStartTLS
+)
+
+// Default email templates, executed with the Message.
+var (
+	DefaultEmailSubject = template.Must(template.New("subject").Parse(`[{{.Severity}}] {{.Title}}`))
+	DefaultEmailText    = template.Must(template.New("text").Parse(`{{.Title}}
+{{if .Text}}
+{{.Text}}
+{{end}}{{if .Fields}}
+{{range .Fields}}{{.Name}}: {{.Value}}
+{{end}}{{end}}{{if .URL}}
+{{.URL}}
+{{end}}`))
+	DefaultEmailHTML = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html><body>
+<h2>{{.Title}}</h2>
+{{if .Text}}<p>{{.Text}}</p>
+{{end}}{{if .Fields}}<table>
+{{range .Fields}}<tr><th align="left">{{.Name}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{end}}{{if .URL}}<p><a href="{{.URL}}">Details</a></p>
+{{end}}<p><small>{{.Severity}}</small></p>
+</body></html>
+`))
+)
+
+// Email sends messages by SMTP, rendered by templates into text and HTML alternatives.
+type Email struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+	// TLS selects how the connection is secured, defaults to StartTLS.
+	TLS TLSMode
+	// TLSConfig configures TLS, defaults to verifying the host of Addr.
+	TLSConfig *tls.Config
+	// Username and Password authenticate with PLAIN auth if set, Auth overrides them.
+	Username string
+	Password string
+	Auth     smtp.Auth
+	// From is the sender address of notifications.
+	From string
+	// To are the recipients of notifications.
+	To []string
+	// Routes overrides the recipients (comma separated) of the messages at or above a severity.
+	Routes map[Severity]string
+	// Subject, Text and HTML render notifications, defaulting to DefaultEmailSubject, DefaultEmailText and
+	// DefaultEmailHTML.  Setting Text without HTML sends text only emails.
+	Subject *template.Template
+	Text    *template.Template
+	HTML    *htmltemplate.Template
+	// Timeout bounds each connection, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+var _ Notifier = (*Email)(nil)
+
+// NewEmail returns an Email notifier sending from the from address to the to addresses through addr.
+func NewEmail(addr, from string, to ...string) *Email {
+	return &Email{Addr: addr, From: from, To: to}
+}
+
+// Mail is an email sent by Email.Send.
+type Mail struct {
+	From        string
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a Mail.
+type Attachment struct {
+	Name string
+	// ContentType defaults to the type of the Name extension, or application/octet-stream.
+	ContentType string
+	Data        []byte
+}
+
+// Notify renders m and sends it.
+func (e *Email) Notify(ctx context.Context, m Message) error {
+	mail, err := e.Render(m)
+	if err != nil {
+		return err
+	}
+
+	return e.Send(ctx, mail)
+}
+
+// Render renders m as a Mail from From to the recipients of its severity.
+func (e *Email) Render(m Message) (Mail, error) {
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+
+	subject, text, html := e.Subject, e.Text, e.HTML
+	if subject == nil {
+		subject = DefaultEmailSubject
+	}
+
+	if text == nil {
+		text, html = DefaultEmailText, DefaultEmailHTML
+	}
+
+	mail := Mail{From: e.From, To: e.To}
+	if dest := route(e.Routes, m.Severity, ""); dest != "" {
+		mail.To = strings.Split(dest, ",")
+	}
+
+	var b strings.Builder
+
+	if err := subject.Execute(&b, m); err != nil {
+		return mail, fmt.Errorf("email subject: %w", err)
+	}
+
+	mail.Subject = strings.Join(strings.Fields(b.String()), " ")
+
+	b.Reset()
+
+	if err := text.Execute(&b, m); err != nil {
+		return mail, fmt.Errorf("email text: %w", err)
+	}
+
+	mail.Text = b.String()
+
+	if html != nil {
+		b.Reset()
+
+		if err := html.Execute(&b, m); err != nil {
+			return mail, fmt.Errorf("email html: %w", err)
+		}
+
+		mail.HTML = b.String()
+	}
+
+	return mail, nil
+}
+
+// Send sends mails over a single connection, e.g. for digests and reports.  Every mail is attempted and the failures
+// joined, temporary (4xx) and connection failures are marked retryable.
+func (e *Email) Send(ctx context.Context, mails ...Mail) error {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c, err := e.dial(ctx)
+	if err != nil {
+		return smtpError(ctx, err)
+	}
+	defer c.Close()
+
+	var failures []error
+
+	for _, mail := range mails {
+		err = sendMail(c, mail)
+		if err == nil {
+			continue
+		}
+
+		failures = append(failures, smtpError(ctx, err))
+
+		if c.Reset() != nil {
+			return errors.Join(failures...)
+		}
+	}
+
+	if err = c.Quit(); err != nil && len(failures) == 0 {
+		return smtpError(ctx, err)
+	}
+
+	return errors.Join(failures...)
+}
+
+// dial connects to Addr, securing the connection and authenticating.  The connection is interrupted when ctx is done.
+func (e *Email) dial(ctx context.Context) (*smtp.Client, error) {
+	host, _, err := net.SplitHostPort(e.Addr)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	cfg := e.TLSConfig
+	if cfg == nil {
+		cfg = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}
+	}
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", e.Addr)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+
+	if e.TLS == ImplicitTLS {
+		conn = tls.Client(conn, cfg)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err //nolint:wrapcheck
+	}
+
+	if err = e.secure(c, host, cfg); err != nil {
+		_ = c.Close()
+
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// secure upgrades the connection per TLS and authenticates.
+func (e *Email) secure(c *smtp.Client, host string, cfg *tls.Config) error {
+	if e.TLS != ImplicitTLS {
+		ok, _ := c.Extension("STARTTLS")
+
+		switch {
+		case ok:
+			if err := c.StartTLS(cfg); err != nil {
+				return err //nolint:wrapcheck
+			}
+		case e.TLS == StartTLS:
+			return ErrStartTLS
+		}
+	}
+
+	auth := e.Auth
+	if auth == nil && e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	return nil
+}
+
+func sendMail(c *smtp.Client, m Mail) error {
+	if m.From == "" || len(m.To) == 0 {
+		return ErrNoRecipients
+	}
+
+	body, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if err = c.Mail(m.From); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	for _, to := range m.To {
+		if err = c.Rcpt(strings.TrimSpace(to)); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if _, err = w.Write(body); err != nil {
+		_ = w.Close()
+
+		return err //nolint:wrapcheck
+	}
+
+	return w.Close() //nolint:wrapcheck
+}
+
+// smtpError wraps err, marking temporary (4xx) replies and connection failures retryable unless ctx was canceled.
+func smtpError(ctx context.Context, err error) error {
+	var (
+		reply  *textproto.Error
+		netErr net.Error
+	)
+
+	retryable := errors.As(err, &reply) && reply.Code >= 400 && reply.Code < 500 ||
+		errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+
+	err = fmt.Errorf("smtp: %w", err)
+
+	if retryable && !errors.Is(ctx.Err(), context.Canceled) {
+		return errs.MarkRetryable(err, 0)
+	}
+
+	return err
+}
+
+// Bytes returns m formatted as a MIME message: the text and HTML bodies as alternatives, mixed with the
+// attachments.
+func (m Mail) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", m.From)
+	header.Set("To", strings.Join(m.To, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-Id", messageID(m.From))
+	header.Set("Mime-Version", "1.0")
+
+	var err error
+
+	switch {
+	case len(m.Attachments) > 0:
+		w := multipart.NewWriter(&buf)
+		header.Set("Content-Type", "multipart/mixed; boundary="+w.Boundary())
+		writeHeader(&buf, header)
+		err = m.writeMixed(w)
+	case m.HTML != "":
+		w := multipart.NewWriter(&buf)
+		header.Set("Content-Type", "multipart/alternative; boundary="+w.Boundary())
+		writeHeader(&buf, header)
+		err = m.writeAlternative(w)
+	default:
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		err = writeQuoted(&buf, m.Text)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("email: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeMixed writes the body, nesting the alternatives if there is an HTML body, followed by the attachments.
+func (m Mail) writeMixed(w *multipart.Writer) error {
+	if m.HTML == "" {
+		if err := writeTextPart(w, "text/plain", m.Text); err != nil {
+			return err
+		}
+	} else {
+		var alt bytes.Buffer
+
+		aw := multipart.NewWriter(&alt)
+		if err := m.writeAlternative(aw); err != nil {
+			return err
+		}
+
+		p, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {"multipart/alternative; boundary=" + aw.Boundary()},
+		})
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		if _, err = p.Write(alt.Bytes()); err != nil {
+			return err //nolint:wrapcheck
+		}
+	}
+
+	for _, a := range m.Attachments {
+		if err := writeAttachment(w, a); err != nil {
+			return err
+		}
+	}
+
+	return w.Close() //nolint:wrapcheck
+}
+
+func (m Mail) writeAlternative(w *multipart.Writer) error {
+	if err := writeTextPart(w, "text/plain", m.Text); err != nil {
+		return err
+	}
+
+	if err := writeTextPart(w, "text/html", m.HTML); err != nil {
+		return err
+	}
+
+	return w.Close() //nolint:wrapcheck
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, k := range []string{
+		"From", "To", "Subject", "Date", "Message-Id", "Mime-Version", "Content-Type",
+		"Content-Transfer-Encoding",
+	} {
+		if v := header.Get(k); v != "" {
+			buf.WriteString(k + ": " + v + "\r\n")
+		}
+	}
+
+	buf.WriteString("\r\n")
+}
+
+func writeTextPart(w *multipart.Writer, contentType, text string) error {
+	p, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return writeQuoted(p, text)
+}
+
+func writeQuoted(w io.Writer, text string) error {
+	q := quotedprintable.NewWriter(w)
+	if _, err := q.Write([]byte(text)); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	return q.Close() //nolint:wrapcheck
+}
+
+// base64Line is the maximum encoded line length of RFC 2045.
+const base64Line = 76
+
+func writeAttachment(w *multipart.Writer, a Attachment) error {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(a.Name))
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	p, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+	})
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 0 {
+		n := min(len(encoded), base64Line)
+		if _, err = io.WriteString(p, encoded[:n]+"\r\n"); err != nil {
+			return err //nolint:wrapcheck
+		}
+
+		encoded = encoded[n:]
+	}
+
+	return nil
+}
+
+// messageID returns a unique Message-Id in the domain of from.
+func messageID(from string) string {
+	var b [12]byte
+
+	_, _ = rand.Read(b[:])
+
+	domain := "localhost"
+	if at := strings.LastIndexByte(from, '@'); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+
+	return "<" + hex.EncodeToString(b[:]) + "@" + domain + ">"
+}
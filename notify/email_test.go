@@ -0,0 +1,372 @@
+package notify_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/notify"
+)
+
+type smtpMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpServer is a minimal SMTP server recording the mails it receives.  It offers STARTTLS if tls is set, and
+// rejects the reject recipient as busy.
+type smtpServer struct {
+	net.Listener
+	tls    *tls.Config
+	reject string
+
+	mu    sync.Mutex
+	conns int
+	auth  []string
+	mails []smtpMail
+}
+
+func newSMTPServer(t *testing.T, implicit, startTLS *tls.Config) *smtpServer {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	if implicit != nil {
+		l = tls.NewListener(l, implicit)
+	}
+
+	s := &smtpServer{Listener: l, tls: startTLS}
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.conns++
+	s.mu.Unlock()
+
+	tc := textproto.NewConn(conn)
+	secured := false
+
+	var current smtpMail
+
+	_ = tc.PrintfLine("220 localhost ESMTP")
+
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if s.tls != nil && !secured {
+				_ = tc.PrintfLine("250-localhost\r\n250-STARTTLS\r\n250 AUTH PLAIN")
+			} else {
+				_ = tc.PrintfLine("250-localhost\r\n250 AUTH PLAIN")
+			}
+		case "STARTTLS":
+			_ = tc.PrintfLine("220 ready")
+
+			tlsConn := tls.Server(conn, s.tls)
+			if tlsConn.Handshake() != nil {
+				return
+			}
+
+			conn, secured = tlsConn, true
+			tc = textproto.NewConn(conn)
+		case "AUTH":
+			s.mu.Lock()
+			s.auth = append(s.auth, arg)
+			s.mu.Unlock()
+
+			_ = tc.PrintfLine("235 ok")
+		case "MAIL":
+			current = smtpMail{from: strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")}
+
+			_ = tc.PrintfLine("250 ok")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if to == s.reject {
+				_ = tc.PrintfLine("450 mailbox busy")
+
+				continue
+			}
+
+			current.to = append(current.to, to)
+
+			_ = tc.PrintfLine("250 ok")
+		case "DATA":
+			_ = tc.PrintfLine("354 go ahead")
+
+			data, err := tc.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			current.data = string(data)
+
+			s.mu.Lock()
+			s.mails = append(s.mails, current)
+			s.mu.Unlock()
+
+			_ = tc.PrintfLine("250 queued")
+		case "RSET", "NOOP":
+			_ = tc.PrintfLine("250 ok")
+		case "QUIT":
+			_ = tc.PrintfLine("221 bye")
+
+			return
+		default:
+			_ = tc.PrintfLine("502 unknown")
+		}
+	}
+}
+
+func (s *smtpServer) received() (int, []string, []smtpMail) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conns, s.auth, s.mails
+}
+
+// testCerts returns a server certificate for 127.0.0.1 and the client config trusting it.
+func testCerts(t *testing.T) (*tls.Config, *tls.Config) {
+	t.Helper()
+
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+
+	return &tls.Config{Certificates: srv.TLS.Certificates, MinVersion: tls.VersionTLS12},
+		&tls.Config{RootCAs: pool, ServerName: "127.0.0.1", MinVersion: tls.VersionTLS12}
+}
+
+// parts returns the decoded parts of a multipart body by content type, recursing into nested multiparts.
+func parts(t *testing.T, contentType string, body io.Reader) map[string]string {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	require.NoError(t, err)
+
+	out := map[string]string{}
+
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		b, err := io.ReadAll(body)
+		require.NoError(t, err)
+
+		out[mediaType] = string(b)
+
+		return out
+	}
+
+	r := multipart.NewReader(body, params["boundary"])
+
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			return out
+		}
+
+		require.NoError(t, err)
+
+		var content io.Reader = p
+		if p.Header.Get("Content-Transfer-Encoding") == "base64" {
+			content = base64.NewDecoder(base64.StdEncoding, p)
+		}
+
+		for k, v := range parts(t, p.Header.Get("Content-Type"), content) {
+			if name := p.FileName(); name != "" {
+				k = name
+			}
+
+			out[k] = v
+		}
+	}
+}
+
+func readMail(t *testing.T, data string) (*mail.Message, map[string]string) {
+	t.Helper()
+
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(data)))
+	require.NoError(t, err)
+
+	return msg, parts(t, msg.Header.Get("Content-Type"), msg.Body)
+}
+
+func TestEmail(t *testing.T) {
+	srv := newSMTPServer(t, nil, nil)
+
+	e := notify.NewEmail(srv.Addr().String(), "alerts@example.com", "ops@example.com")
+	e.TLS = notify.OptionalStartTLS
+	e.Username, e.Password = "user", "secret"
+	e.Routes = map[notify.Severity]string{notify.Critical: "oncall@example.com, lead@example.com"}
+
+	m := notify.Message{
+		Title:    "Payment failures <billing>",
+		Text:     "3 charges failed",
+		Severity: notify.Critical,
+		Fields:   []notify.Field{{Name: "Service", Value: "billing"}},
+		URL:      "https://logs.example.com",
+		Time:     testTime,
+	}
+
+	require.NoError(t, e.Notify(context.Background(), m))
+	require.NoError(t, e.Notify(context.Background(), notify.Message{Title: "Deploy", Severity: notify.Warning}))
+
+	conns, auth, mails := srv.received()
+	assert.Equal(t, 2, conns)
+	assert.Equal(t, []string{
+		"PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret")),
+		"PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret")),
+	}, auth)
+	require.Len(t, mails, 2)
+	assert.Equal(t, "alerts@example.com", mails[0].from)
+	assert.Equal(t, []string{"oncall@example.com", "lead@example.com"}, mails[0].to)
+	assert.Equal(t, []string{"ops@example.com"}, mails[1].to)
+
+	msg, body := readMail(t, mails[0].data)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[critical] Payment failures <billing>", subject)
+	assert.Equal(t, "alerts@example.com", msg.Header.Get("From"))
+	assert.Contains(t, msg.Header.Get("Message-Id"), "@example.com>")
+	assert.Equal(t, "Payment failures <billing>\n\n3 charges failed\n\nService: billing\n\nhttps://logs.example.com\n",
+		body["text/plain"])
+	assert.Contains(t, body["text/html"], "<h2>Payment failures &lt;billing&gt;</h2>")
+	assert.Contains(t, body["text/html"], `<a href="https://logs.example.com">Details</a>`)
+}
+
+func TestEmailTextOnly(t *testing.T) {
+	e := notify.NewEmail("127.0.0.1:25", "alerts@example.com", "ops@example.com")
+	e.Text = notify.DefaultEmailText
+
+	mail, err := e.Render(notify.Message{Title: "Deploy", Text: "v1.2.3", Severity: notify.Info})
+	require.NoError(t, err)
+	assert.Equal(t, "[info] Deploy", mail.Subject)
+	assert.Empty(t, mail.HTML)
+
+	b, err := mail.Bytes()
+	require.NoError(t, err)
+
+	msg, body := readMail(t, string(b))
+	assert.Equal(t, "text/plain; charset=utf-8", msg.Header.Get("Content-Type"))
+	assert.Equal(t, "Deploy\r\n\r\nv1.2.3\r\n", body["text/plain"])
+}
+
+func TestEmailSend(t *testing.T) {
+	srv := newSMTPServer(t, nil, nil)
+	srv.reject = "busy@example.com"
+
+	e := notify.NewEmail(srv.Addr().String(), "reports@example.com")
+	e.TLS = notify.OptionalStartTLS
+
+	csv := strings.Repeat("id,name\n1,bob\n", 10)
+
+	err := e.Send(context.Background(),
+		notify.Mail{
+			From: "reports@example.com", To: []string{"ops@example.com"}, Subject: "Daily report",
+			Text: "See attached", HTML: "<p>See attached</p>",
+			Attachments: []notify.Attachment{{Name: "report.csv", Data: []byte(csv)}},
+		},
+		notify.Mail{From: "reports@example.com", To: []string{"busy@example.com"}, Subject: "Busy", Text: "x"},
+		notify.Mail{From: "reports@example.com", Subject: "Nobody"},
+		notify.Mail{From: "reports@example.com", To: []string{"dev@example.com"}, Subject: "Digest", Text: "3 alerts"},
+	)
+	require.ErrorIs(t, err, notify.ErrNoRecipients)
+
+	var reply *textproto.Error
+	require.ErrorAs(t, err, &reply)
+	assert.Equal(t, 450, reply.Code)
+
+	conns, _, mails := srv.received()
+	assert.Equal(t, 1, conns)
+	require.Len(t, mails, 2)
+	assert.Equal(t, []string{"dev@example.com"}, mails[1].to)
+
+	msg, body := readMail(t, mails[0].data)
+	assert.True(t, strings.HasPrefix(msg.Header.Get("Content-Type"), "multipart/mixed; boundary="))
+	assert.Equal(t, map[string]string{
+		"text/plain": "See attached",
+		"text/html":  "<p>See attached</p>",
+		"report.csv": csv,
+	}, body)
+
+	e.Addr = "127.0.0.1:1"
+	err = e.Send(context.Background(), notify.Mail{})
+	require.Error(t, err)
+	assert.True(t, errs.IsRetryable(err))
+}
+
+func TestEmailTLS(t *testing.T) {
+	serverTLS, clientTLS := testCerts(t)
+
+	srv := newSMTPServer(t, nil, serverTLS)
+
+	e := notify.NewEmail(srv.Addr().String(), "alerts@example.com", "ops@example.com")
+	e.TLSConfig = clientTLS
+	e.Username, e.Password = "user", "secret"
+
+	require.NoError(t, e.Notify(context.Background(), notify.Message{Title: "StartTLS"}))
+
+	implicit := newSMTPServer(t, serverTLS, nil)
+	e.Addr = implicit.Addr().String()
+	e.TLS = notify.ImplicitTLS
+
+	require.NoError(t, e.Notify(context.Background(), notify.Message{Title: "Implicit"}))
+
+	plain := newSMTPServer(t, nil, nil)
+	e.Addr = plain.Addr().String()
+	e.TLS = notify.StartTLS
+
+	err := e.Notify(context.Background(), notify.Message{Title: "Plain"})
+	require.ErrorIs(t, err, notify.ErrStartTLS)
+	assert.False(t, errs.IsRetryable(err))
+
+	for _, s := range []*smtpServer{srv, implicit} {
+		_, auth, mails := s.received()
+		assert.Len(t, auth, 1)
+		assert.Len(t, mails, 1)
+	}
+
+	_, _, mails := plain.received()
+	assert.Empty(t, mails)
+}
@@ -0,0 +1,249 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Defaults of Grouper.
+const (
+	DefaultGroupWindow    = time.Minute
+	DefaultDigestInterval = 15 * time.Minute
+)
+
+// Grouper wraps a Notifier, sending the first of identical messages (by Fingerprint, otherwise Title, or Template and
+// Data) within Window and a summary counting the others when the window ends, so an error loop sends two messages
+// rather than thousands.  Messages below DigestBelow are batched into a digest sent every DigestInterval.  Run sends
+// the summaries and digests, a summary still pending when the next identical message arrives is sent before it.
+type Grouper struct {
+	// Next delivers the messages.
+	Next Notifier
+	// Logger logs the delivery failures of Run.
+	Logger zerolog.Logger
+	// Window groups identical messages, defaults to DefaultGroupWindow.
+	Window time.Duration
+	// DigestBelow batches the messages below this severity into digests, the default Info disables digests.
+	DigestBelow Severity
+	// DigestInterval is the period of digests, defaults to DefaultDigestInterval.
+	DigestInterval time.Duration
+	// DigestTitle titles digests, defaults to "Digest: <n> notifications".
+	DigestTitle string
+
+	mu       sync.Mutex
+	groups   map[string]*group
+	digest   []*group
+	digested map[string]*group
+	digestAt time.Time
+}
+
+// group is the pending state of identical messages.
+type group struct {
+	last  Message
+	first time.Time
+	count int // messages not sent yet
+}
+
+var _ Notifier = (*Grouper)(nil)
+
+// NewGrouper returns a Grouper of the messages to next within window.
+func NewGrouper(next Notifier, window time.Duration, logger zerolog.Logger) *Grouper {
+	return &Grouper{Next: next, Window: window, Logger: logger.With().Str("module", "notify").Logger()}
+}
+
+// Notify sends m unless an identical message was sent within Window, or batches it into the digest.
+func (g *Grouper) Notify(ctx context.Context, m Message) error {
+	now := time.Now()
+	if m.Time.IsZero() {
+		m.Time = now
+	}
+
+	key := m.Fingerprint
+	if key == "" {
+		key = m.Title
+	}
+
+	if key == "" && m.Template != "" {
+		key = m.Template + "\n" + fmt.Sprint(m.Data)
+	}
+
+	g.mu.Lock()
+
+	if m.Severity < g.DigestBelow {
+		g.addDigest(key, m, now)
+		g.mu.Unlock()
+
+		return nil
+	}
+
+	gr, ok := g.groups[key]
+	if ok && now.Sub(gr.first) < g.window() {
+		gr.last = m
+		gr.count++
+		g.mu.Unlock()
+
+		return nil
+	}
+
+	if g.groups == nil {
+		g.groups = map[string]*group{}
+	}
+
+	g.groups[key] = &group{last: m, first: now}
+	g.mu.Unlock()
+
+	// The window of gr ended before Run took it, send its summary first.
+	var pending error
+	if ok && gr.count > 0 {
+		pending = g.send(ctx, []Message{summary(gr)})
+	}
+
+	return errors.Join(pending, g.Next.Notify(ctx, m))
+}
+
+// Run sends the summaries of the ended windows and the digests until ctx is done, then flushes the pending ones.
+func (g *Grouper) Run(ctx context.Context) {
+	ticker := time.NewTicker(min(g.window(), g.digestInterval()))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			g.log(g.Flush(context.WithoutCancel(ctx)))
+
+			return
+		case <-ticker.C:
+			g.log(g.send(ctx, g.take(time.Now(), false)))
+		}
+	}
+}
+
+// Flush sends the pending summaries and digest.
+func (g *Grouper) Flush(ctx context.Context) error {
+	return g.send(ctx, g.take(time.Now(), true))
+}
+
+func (g *Grouper) window() time.Duration {
+	if g.Window <= 0 {
+		return DefaultGroupWindow
+	}
+
+	return g.Window
+}
+
+func (g *Grouper) digestInterval() time.Duration {
+	if g.DigestInterval <= 0 {
+		return DefaultDigestInterval
+	}
+
+	return g.DigestInterval
+}
+
+func (g *Grouper) addDigest(key string, m Message, now time.Time) {
+	if g.digested == nil {
+		g.digested = map[string]*group{}
+		g.digestAt = now
+	}
+
+	if gr, ok := g.digested[key]; ok {
+		gr.last = m
+		gr.count++
+
+		return
+	}
+
+	gr := &group{last: m, first: now, count: 1}
+	g.digested[key] = gr
+	g.digest = append(g.digest, gr)
+}
+
+// take removes the summaries of the windows ended by now and the digest if due, or all of them if force.
+func (g *Grouper) take(now time.Time, force bool) []Message {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var ended []*group
+
+	for key, gr := range g.groups {
+		if !force && now.Sub(gr.first) < g.window() {
+			continue
+		}
+
+		delete(g.groups, key)
+
+		if gr.count > 0 {
+			ended = append(ended, gr)
+		}
+	}
+
+	slices.SortFunc(ended, func(a, b *group) int { return a.first.Compare(b.first) })
+
+	out := make([]Message, 0, len(ended)+1)
+	for _, gr := range ended {
+		out = append(out, summary(gr))
+	}
+
+	if len(g.digest) > 0 && (force || now.Sub(g.digestAt) >= g.digestInterval()) {
+		out = append(out, g.digestMessage(now))
+		g.digest, g.digested = nil, nil
+	}
+
+	return out
+}
+
+// summary returns the last message of gr, counting the messages grouped since the first.
+func summary(gr *group) Message {
+	m := gr.last
+	m.Title = fmt.Sprintf("%s (%d more)", m.Title, gr.count)
+	m.Fields = append(append([]Field(nil), m.Fields...), Field{Name: "Repeated", Value: strconv.Itoa(gr.count)})
+
+	return m
+}
+
+func (g *Grouper) digestMessage(now time.Time) Message {
+	total := 0
+	severity := Info
+	lines := make([]string, len(g.digest))
+
+	for i, gr := range g.digest {
+		total += gr.count
+		severity = max(severity, gr.last.Severity)
+
+		lines[i] = "• " + gr.last.Title
+		if gr.count > 1 {
+			lines[i] += " (×" + strconv.Itoa(gr.count) + ")"
+		}
+	}
+
+	title := g.DigestTitle
+	if title == "" {
+		title = "Digest: " + strconv.Itoa(total) + " notifications"
+	}
+
+	return Message{Title: title, Text: strings.Join(lines, "\n"), Severity: severity, Time: now}
+}
+
+func (g *Grouper) send(ctx context.Context, msgs []Message) error {
+	var failures []error
+
+	for _, m := range msgs {
+		if err := g.Next.Notify(ctx, m); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", m.Title, err))
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+func (g *Grouper) log(err error) {
+	if err != nil {
+		g.Logger.Error().Err(err).Msg("notification flush")
+	}
+}
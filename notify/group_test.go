@@ -0,0 +1,143 @@
+package notify_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+// sink records the messages it is notified.
+type sink struct {
+	mu   sync.Mutex
+	msgs []notify.Message
+}
+
+func (s *sink) Notify(_ context.Context, m notify.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.msgs = append(s.msgs, m)
+
+	return nil
+}
+
+func (s *sink) titles() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]string, len(s.msgs))
+	for i, m := range s.msgs {
+		out[i] = m.Title
+	}
+
+	return out
+}
+
+func TestGrouper(t *testing.T) {
+	var out sink
+
+	g := notify.NewGrouper(&out, time.Hour, zerolog.Nop())
+	ctx := context.Background()
+
+	for range 1000 {
+		require.NoError(t, g.Notify(ctx, notify.Message{Title: "Query failed", Severity: notify.Error, Fingerprint: "f1"}))
+	}
+
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Disk full", Severity: notify.Critical}))
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Disk full", Severity: notify.Critical}))
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Deploy", Severity: notify.Info}))
+
+	assert.Equal(t, []string{"Query failed", "Disk full", "Deploy"}, out.titles())
+
+	require.NoError(t, g.Flush(ctx))
+	assert.Equal(t, []string{
+		"Query failed", "Disk full", "Deploy", "Query failed (999 more)", "Disk full (1 more)",
+	}, out.titles())
+	assert.Equal(t, notify.Field{Name: "Repeated", Value: "999"}, out.msgs[3].Fields[0])
+
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Query failed", Severity: notify.Error, Fingerprint: "f1"}))
+	require.NoError(t, g.Flush(ctx))
+	assert.Len(t, out.titles(), 6, "sent after the flush, without a summary")
+}
+
+func TestGrouperWindowEnded(t *testing.T) {
+	var out sink
+
+	g := notify.NewGrouper(&out, 10*time.Millisecond, zerolog.Nop())
+	ctx := context.Background()
+	m := notify.Message{Title: "Timeout", Severity: notify.Error}
+
+	for range 3 {
+		require.NoError(t, g.Notify(ctx, m))
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	require.NoError(t, g.Notify(ctx, m), "after the window, without Run")
+	assert.Equal(t, []string{"Timeout", "Timeout (2 more)", "Timeout"}, out.titles())
+
+	require.NoError(t, g.Flush(ctx))
+	assert.Len(t, out.titles(), 3, "nothing left pending")
+}
+
+func TestGrouperDigest(t *testing.T) {
+	var out sink
+
+	g := notify.NewGrouper(&out, time.Hour, zerolog.Nop())
+	g.DigestBelow = notify.Warning
+	ctx := context.Background()
+
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Cache miss"}))
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Slow query"}))
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Cache miss"}))
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Outage", Severity: notify.Warning}))
+
+	assert.Equal(t, []string{"Outage"}, out.titles())
+
+	require.NoError(t, g.Flush(ctx))
+	require.Len(t, out.msgs, 2)
+	assert.Equal(t, "Digest: 3 notifications", out.msgs[1].Title)
+	assert.Equal(t, "• Cache miss (×2)\n• Slow query", out.msgs[1].Text)
+	assert.Equal(t, notify.Info, out.msgs[1].Severity)
+
+	require.NoError(t, g.Flush(ctx))
+	assert.Len(t, out.msgs, 2, "empty digests are not sent")
+}
+
+func TestGrouperRun(t *testing.T) {
+	var out sink
+
+	g := notify.NewGrouper(&out, 20*time.Millisecond, zerolog.Nop())
+	g.DigestBelow, g.DigestInterval = notify.Warning, 20*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		g.Run(ctx)
+		close(done)
+	}()
+
+	m := notify.Message{Title: "Timeout", Severity: notify.Error}
+	require.NoError(t, g.Notify(ctx, m))
+	require.NoError(t, g.Notify(ctx, m))
+	require.NoError(t, g.Notify(ctx, notify.Message{Title: "Retry"}))
+
+	assert.Eventually(t, func() bool { return len(out.titles()) == 3 }, time.Second, 5*time.Millisecond)
+	assert.ElementsMatch(t, []string{"Timeout", "Timeout (1 more)", "Digest: 1 notifications"}, out.titles())
+
+	require.NoError(t, g.Notify(ctx, m))
+	require.NoError(t, g.Notify(ctx, m))
+
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"Timeout", "Timeout (1 more)"}, out.titles()[3:], "flushed when done")
+}
@@ -0,0 +1,242 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultLimitInterval is the check interval of Limiter.Run when Limiter.Interval is 0.
+const DefaultLimitInterval = 5 * time.Second
+
+// Rate is a rate limit of PerMinute messages, with bursts of up to Burst messages (defaults to PerMinute).  A 0
+// PerMinute is unlimited.
+type Rate struct {
+	PerMinute int
+	Burst     int
+}
+
+// bucket is the token bucket of a Rate.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens accrued since the last refill, reporting whether one is available.
+func (b *bucket) refill(r Rate, now time.Time) bool {
+	if r.PerMinute <= 0 {
+		return true
+	}
+
+	burst := float64(r.Burst)
+	if r.Burst <= 0 {
+		burst = float64(r.PerMinute)
+	}
+
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Minutes()*float64(r.PerMinute))
+	}
+
+	b.last = now
+
+	return b.tokens >= 1
+}
+
+func (b *bucket) take(r Rate) {
+	if r.PerMinute > 0 {
+		b.tokens--
+	}
+}
+
+// Limiter rate limits the notifiers it wraps: each destination to PerDestination and all of them together to
+// Global.  Messages over the limits are suppressed and counted, the count is sent as a summary to the destination
+// when its limit lifts, by its next message or by Run.
+type Limiter struct {
+	// Global limits the messages of all destinations.
+	Global Rate
+	// PerDestination limits the messages of each destination.
+	PerDestination Rate
+	// Logger logs the summary delivery failures of Run.
+	Logger zerolog.Logger
+	// Interval is the summary check interval of Run, defaults to DefaultLimitInterval.
+	Interval time.Duration
+
+	mu           sync.Mutex
+	global       bucket
+	destinations map[string]*destination
+}
+
+// destination is the limit state of a wrapped notifier.
+type destination struct {
+	next       Notifier
+	bucket     bucket
+	suppressed int
+	severity   Severity
+}
+
+// NewLimiter returns a Limiter of global and per destination rates.
+func NewLimiter(global, perDestination Rate, logger zerolog.Logger) *Limiter {
+	return &Limiter{
+		Global:         global,
+		PerDestination: perDestination,
+		Logger:         logger.With().Str("module", "notify").Logger(),
+	}
+}
+
+// Wrap returns next rate limited as the destination named name.  Wrapping the same name again shares its limit.
+func (l *Limiter) Wrap(name string, next Notifier) Notifier {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.destinations == nil {
+		l.destinations = map[string]*destination{}
+	}
+
+	if _, ok := l.destinations[name]; !ok {
+		l.destinations[name] = &destination{next: next}
+	}
+
+	return NotifierFunc(func(ctx context.Context, m Message) error {
+		return l.notify(ctx, name, next, m)
+	})
+}
+
+// Suppressed returns the count of messages suppressed for the destination name since its last summary.
+func (l *Limiter) Suppressed(name string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if d, ok := l.destinations[name]; ok {
+		return d.suppressed
+	}
+
+	return 0
+}
+
+func (l *Limiter) notify(ctx context.Context, name string, next Notifier, m Message) error {
+	l.mu.Lock()
+
+	d := l.destinations[name]
+	if !l.allow(d, time.Now()) {
+		d.suppressed++
+		d.severity = max(d.severity, m.Severity)
+		l.mu.Unlock()
+
+		return nil
+	}
+
+	s, ok := l.summary(name, d)
+	l.mu.Unlock()
+
+	if ok {
+		if err := next.Notify(ctx, s); err != nil {
+			return fmt.Errorf("%s: %w", s.Title, err)
+		}
+	}
+
+	return next.Notify(ctx, m)
+}
+
+// allow takes a token of d and the global limit if both are available.
+func (l *Limiter) allow(d *destination, now time.Time) bool {
+	ok := d.bucket.refill(l.PerDestination, now)
+	if !l.global.refill(l.Global, now) || !ok {
+		return false
+	}
+
+	d.bucket.take(l.PerDestination)
+	l.global.take(l.Global)
+
+	return true
+}
+
+// summary returns and resets the suppressed count summary of d, ok is false if nothing was suppressed.
+func (l *Limiter) summary(name string, d *destination) (Message, bool) {
+	if d.suppressed == 0 {
+		return Message{}, false
+	}
+
+	m := Message{
+		Title:    "Rate limited: " + strconv.Itoa(d.suppressed) + " notifications suppressed",
+		Severity: d.severity,
+		Fields:   []Field{{Name: "Destination", Value: name}},
+		Time:     time.Now(),
+	}
+
+	d.suppressed, d.severity = 0, Info
+
+	return m, true
+}
+
+// Run sends the summaries of the destinations whose limit lifted until ctx is done.
+func (l *Limiter) Run(ctx context.Context) {
+	interval := l.Interval
+	if interval <= 0 {
+		interval = DefaultLimitInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Flush(ctx); err != nil {
+				l.Logger.Error().Err(err).Msg("rate limit summary")
+			}
+		}
+	}
+}
+
+// Flush sends the summaries of the destinations whose limit lifted.
+func (l *Limiter) Flush(ctx context.Context) error {
+	type pending struct {
+		next Notifier
+		m    Message
+	}
+
+	l.mu.Lock()
+
+	names := make([]string, 0, len(l.destinations))
+	for name := range l.destinations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var out []pending
+
+	now := time.Now()
+
+	for _, name := range names {
+		d := l.destinations[name]
+		if d.suppressed == 0 || !l.allow(d, now) {
+			continue
+		}
+
+		s, _ := l.summary(name, d)
+		out = append(out, pending{d.next, s})
+	}
+
+	l.mu.Unlock()
+
+	var failures []error
+
+	for _, p := range out {
+		if err := p.next.Notify(ctx, p.m); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", p.m.Title, err))
+		}
+	}
+
+	return errors.Join(failures...)
+}
@@ -0,0 +1,70 @@
+package notify_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+func TestLimiter(t *testing.T) {
+	var slackOut, pagerOut sink
+
+	l := notify.NewLimiter(notify.Rate{PerMinute: 1, Burst: 3}, notify.Rate{PerMinute: 1, Burst: 2}, zerolog.Nop())
+	slack := l.Wrap("slack", &slackOut)
+	pager := l.Wrap("pager", &pagerOut)
+	ctx := context.Background()
+
+	for _, title := range []string{"1", "2", "3", "4"} {
+		require.NoError(t, slack.Notify(ctx, notify.Message{Title: title}))
+	}
+
+	require.NoError(t, pager.Notify(ctx, notify.Message{Title: "5", Severity: notify.Critical}))
+	require.NoError(t, pager.Notify(ctx, notify.Message{Title: "6", Severity: notify.Critical}))
+
+	assert.Equal(t, []string{"1", "2"}, slackOut.titles())
+	assert.Equal(t, []string{"5"}, pagerOut.titles())
+	assert.Equal(t, 2, l.Suppressed("slack"))
+	assert.Equal(t, 1, l.Suppressed("pager"), "global limit")
+
+	require.NoError(t, l.Flush(ctx))
+	assert.Len(t, slackOut.titles(), 2, "still limited")
+}
+
+func TestLimiterSummary(t *testing.T) {
+	var out sink
+
+	l := notify.NewLimiter(notify.Rate{}, notify.Rate{PerMinute: 6000, Burst: 1}, zerolog.Nop())
+	n := l.Wrap("slack", &out)
+	ctx := context.Background()
+
+	require.NoError(t, n.Notify(ctx, notify.Message{Title: "first"}))
+	require.NoError(t, n.Notify(ctx, notify.Message{Title: "lost", Severity: notify.Error}))
+	require.NoError(t, n.Notify(ctx, notify.Message{Title: "lost"}))
+
+	time.Sleep(20 * time.Millisecond)
+
+	require.NoError(t, n.Notify(ctx, notify.Message{Title: "next"}))
+	assert.Equal(t, []string{"first", "Rate limited: 2 notifications suppressed", "next"}, out.titles())
+	assert.Equal(t, notify.Error, out.msgs[1].Severity)
+	assert.Equal(t, []notify.Field{{Name: "Destination", Value: "slack"}}, out.msgs[1].Fields)
+
+	require.NoError(t, n.Notify(ctx, notify.Message{Title: "lost"}))
+	assert.Equal(t, 1, l.Suppressed("slack"))
+
+	l.Interval = 5 * time.Millisecond
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go l.Run(runCtx)
+
+	assert.Eventually(t, func() bool { return len(out.titles()) == 4 }, time.Second, time.Millisecond)
+	assert.Equal(t, "Rate limited: 1 notifications suppressed", out.titles()[3])
+	assert.Equal(t, 0, l.Suppressed("slack"))
+}
@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/httputil"
+)
+
+// logSkipped are the fields of log events not copied to Message.Fields.
+var logSkipped = map[string]bool{
+	httputil.LogStack:        true,
+	httputil.LogFingerprint:  true,
+	httputil.LogErrorMessage: true,
+}
+
+// LogWriter forwards the log events at or above Level to Next, e.g. a Pipeline so logging never blocks and error
+// loops are grouped and rate limited.  It is a zerolog.LevelWriter (zerolog hooks can't read the event fields), add it
+// with zerolog.MultiLevelWriter:
+//
+//	p := notify.NewPipeline("slack", notify.NewSlackWebhook(url), logger)
+//	go p.Run(ctx)
+//	logger = logger.Output(zerolog.MultiLevelWriter(os.Stdout, notify.NewLogWriter(p, zerolog.ErrorLevel)))
+//
+// The message is the Title, the error the Text and the other fields the Fields.  The Fingerprint is the
+// httputil.LogFingerprint field (see errs.Fingerprint), otherwise a hash of the message and caller.  Events of the
+// notify module are skipped, so delivery failures are not notified.
+type LogWriter struct {
+	// Next delivers the messages.
+	Next Notifier
+	// Level is the minimum level of forwarded events.
+	Level zerolog.Level
+	// Timeout bounds each Notify, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+var _ zerolog.LevelWriter = (*LogWriter)(nil)
+
+// NewLogWriter returns a LogWriter forwarding the events at or above level to next.
+func NewLogWriter(next Notifier, level zerolog.Level) *LogWriter {
+	return &LogWriter{Next: next, Level: level}
+}
+
+// Write forwards JSON encoded events by their level field.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	var event struct {
+		Level string `json:"level"`
+	}
+
+	_ = json.Unmarshal(p, &event)
+
+	level, err := zerolog.ParseLevel(event.Level)
+	if err != nil {
+		level = zerolog.NoLevel
+	}
+
+	return w.WriteLevel(level, p)
+}
+
+// WriteLevel forwards the event p if level is at or above Level.  Failures are ignored, logging must not fail for
+// notifications.
+func (w *LogWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < w.Level || level >= zerolog.NoLevel {
+		return len(p), nil
+	}
+
+	m, ok := logMessage(level, p)
+	if !ok {
+		return len(p), nil
+	}
+
+	timeout := w.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_ = w.Next.Notify(ctx, m)
+
+	return len(p), nil
+}
+
+// logMessage returns the Message of the JSON event p, ok is false for invalid or notify module events.
+func logMessage(level zerolog.Level, p []byte) (Message, bool) {
+	var fields map[string]json.RawMessage
+
+	if json.Unmarshal(p, &fields) != nil || str(fields["module"]) == "notify" {
+		return Message{}, false
+	}
+
+	m := Message{
+		Title:       str(fields[zerolog.MessageFieldName]),
+		Text:        str(fields[zerolog.ErrorFieldName]),
+		Severity:    LevelSeverity(level),
+		Fingerprint: str(fields[httputil.LogFingerprint]),
+		Time:        time.Now(),
+	}
+
+	if m.Text == "" {
+		m.Text = str(fields[httputil.LogErrorMessage])
+	}
+
+	if t, err := time.Parse(zerolog.TimeFieldFormat, str(fields[zerolog.TimestampFieldName])); err == nil {
+		m.Time = t
+	}
+
+	if m.Title == "" {
+		m.Title, m.Text = m.Text, ""
+	}
+
+	if m.Fingerprint == "" {
+		h := sha256.Sum256([]byte(level.String() + "\n" + m.Title + "\n" + str(fields[zerolog.CallerFieldName])))
+		m.Fingerprint = hex.EncodeToString(h[:8])
+	}
+
+	names := make([]string, 0, len(fields))
+
+	for name := range fields {
+		switch name {
+		case zerolog.MessageFieldName, zerolog.ErrorFieldName, zerolog.LevelFieldName, zerolog.TimestampFieldName:
+		default:
+			if !logSkipped[name] {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		m.Fields = append(m.Fields, Field{Name: name, Value: str(fields[name])})
+	}
+
+	return m, true
+}
+
+// str returns the JSON string v unquoted, or other JSON values as is.
+func str(v json.RawMessage) string {
+	var s string
+	if json.Unmarshal(v, &s) == nil {
+		return s
+	}
+
+	return string(v)
+}
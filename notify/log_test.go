@@ -0,0 +1,63 @@
+package notify_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/httputil"
+	"github.com/bir/iken/notify"
+)
+
+func TestLogWriter(t *testing.T) {
+	var (
+		out sink
+		buf bytes.Buffer
+	)
+
+	w := notify.NewLogWriter(&out, zerolog.WarnLevel)
+	log := zerolog.New(zerolog.MultiLevelWriter(&buf, w)).With().Str("service", "billing").Logger()
+
+	log.Info().Msg("started")
+	log.Error().Err(errors.New("card declined")).Int("attempt", 2).Str(httputil.LogFingerprint, "f1").
+		Str(httputil.LogStack, "main.go:1").Msg("payment failed")
+	log.Warn().Msg("slow")
+	log.Error().Str("module", "notify").Msg("notification dead letter")
+	log.WithLevel(zerolog.FatalLevel).Err(errors.New("boom")).Send()
+
+	assert.Equal(t, 5, bytes.Count(buf.Bytes(), []byte("\n")), "all events are logged")
+	require.Equal(t, []string{"payment failed", "slow", "boom"}, out.titles())
+
+	m := out.msgs[0]
+	assert.Equal(t, "card declined", m.Text)
+	assert.Equal(t, notify.Error, m.Severity)
+	assert.Equal(t, "f1", m.Fingerprint)
+	assert.Equal(t, []notify.Field{{Name: "attempt", Value: "2"}, {Name: "service", Value: "billing"}}, m.Fields)
+
+	assert.Equal(t, notify.Warning, out.msgs[1].Severity)
+	assert.Len(t, out.msgs[1].Fingerprint, 16)
+	assert.Empty(t, out.msgs[2].Text)
+	assert.Equal(t, notify.Critical, out.msgs[2].Severity)
+}
+
+func TestLogWriterWrite(t *testing.T) {
+	var out sink
+
+	w := notify.NewLogWriter(&out, zerolog.ErrorLevel)
+
+	event := []byte(`{"level":"error","message":"plain writer","time":"2024-05-01T12:00:00Z"}`)
+
+	n, err := w.Write(event)
+	require.NoError(t, err)
+	assert.Equal(t, len(event), n)
+
+	_, _ = w.Write([]byte(`{"level":"info","message":"ignored"}`))
+	_, _ = w.Write([]byte(`not json`))
+
+	require.Equal(t, []string{"plain writer"}, out.titles())
+	assert.Equal(t, testTime, out.msgs[0].Time)
+}
@@ -0,0 +1,230 @@
+// Package notify delivers operational notifications (alerts, reports) to chat, paging and email destinations
+// through a common Notifier interface.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/strutil"
+)
+
+// ErrDelivery is returned when a destination rejects a notification.  Rate limited (429) and server (5xx) failures
+// are marked retryable, see errs.IsRetryable.
+var ErrDelivery = errors.New("notification delivery failed")
+
+// DefaultTimeout bounds each delivery when the Timeout of a notifier is 0.
+const DefaultTimeout = 10 * time.Second
+
+// maxErrorBody truncates the response bodies reported in delivery errors.
+const maxErrorBody = 512
+
+// Notifier delivers notifications.
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// NotifierFunc adapts a func to Notifier.
+type NotifierFunc func(ctx context.Context, m Message) error
+
+// Notify calls f.
+func (f NotifierFunc) Notify(ctx context.Context, m Message) error {
+	return f(ctx, m)
+}
+
+// Message is a notification, rendered by each notifier in the format of its destination.
+type Message struct {
+	// Title is the headline, e.g. "Payment failures".
+	Title string
+	// Text is the body, in markdown for the destinations supporting it.
+	Text string
+	// Severity routes and styles the message.
+	Severity Severity
+	// Fields are the key/value details, listed in order.
+	Fields []Field
+	// URL links to the details, e.g. a dashboard or log search.
+	URL string
+	// Fingerprint identifies repeated notifications of the same problem, e.g. errs.Fingerprint.
+	Fingerprint string
+	// Time is when the notified event happened, defaults to when it is sent.
+	Time time.Time
+	// Template names the template rendering Title and Text from Data for each destination, see Templates.
+	Template string
+	Data     any
+}
+
+// ErrorMessage returns a Message titled title about err, at Error severity and fingerprinted by errs.Fingerprint so
+// repeated failures are grouped.
+func ErrorMessage(title string, err error) Message {
+	return Message{Title: title, Text: err.Error(), Severity: Error, Fingerprint: errs.Fingerprint(err)}
+}
+
+// Field is a detail of a Message.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Severity is the urgency of a Message.
+type Severity int8
+
+const (
+	Info Severity = iota
+	Warning
+	Error
+	Critical
+)
+
+var severityNames = [...]string{Info: "info", Warning: "warning", Error: "error", Critical: "critical"}
+
+// String returns the lower case name of s.
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+
+	return "severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// ParseSeverity returns the Severity named s, case insensitively.
+func ParseSeverity(s string) (Severity, error) {
+	for i, name := range severityNames {
+		if strings.EqualFold(s, name) {
+			return Severity(i), nil //nolint:gosec // bounded by severityNames
+		}
+	}
+
+	return Info, fmt.Errorf("invalid severity: %q", s)
+}
+
+// LevelSeverity returns the Severity of a log level: warn is Warning, error is Error, fatal and panic are Critical,
+// lower levels are Info.
+func LevelSeverity(l zerolog.Level) Severity {
+	switch {
+	case l < zerolog.WarnLevel || l >= zerolog.NoLevel:
+		return Info
+	case l == zerolog.WarnLevel:
+		return Warning
+	case l == zerolog.ErrorLevel:
+		return Error
+	default:
+		return Critical
+	}
+}
+
+// MarshalText encodes s by name, for JSON and configuration files.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes s by name, see ParseSeverity.
+func (s *Severity) UnmarshalText(text []byte) error {
+	v, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = v
+
+	return nil
+}
+
+// route returns the destination of the highest severity of routes not above s, otherwise fallback.
+func route(routes map[Severity]string, s Severity, fallback string) string {
+	for ; s >= Info; s-- {
+		if dest, ok := routes[s]; ok {
+			return dest
+		}
+	}
+
+	return fallback
+}
+
+// truncate returns s truncated to n runes, ending with an ellipsis if truncated.
+func truncate(s string, n int) string {
+	return strutil.TruncateRunes(s, n, strutil.Ellipsis)
+}
+
+// postJSON posts body as JSON to url within timeout, returning the response body.
+func postJSON(ctx context.Context, client *http.Client, timeout time.Duration, url string, header http.Header,
+	body any,
+) ([]byte, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+
+	return post(ctx, client, timeout, url, header, "application/json", b)
+}
+
+// post posts body to url within timeout, returning the response body.
+func post(ctx context.Context, client *http.Client, timeout time.Duration, url string, header http.Header,
+	contentType string, body []byte,
+) ([]byte, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("notify request: %w", err)
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return nil, fmt.Errorf("notify request: %w", err)
+		}
+
+		return nil, errs.MarkRetryable(fmt.Errorf("notify request: %w", err), 0)
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errs.MarkRetryable(fmt.Errorf("notify response: %w", err), 0)
+	}
+
+	if resp.StatusCode < http.StatusMultipleChoices {
+		return b, nil
+	}
+
+	if len(b) > maxErrorBody {
+		b = b[:maxErrorBody]
+	}
+
+	err = fmt.Errorf("%w: status %d: %s", ErrDelivery, resp.StatusCode, bytes.TrimSpace(b))
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		after, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+
+		return nil, errs.MarkRetryable(err, time.Duration(after)*time.Second)
+	}
+
+	return nil, err
+}
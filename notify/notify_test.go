@@ -0,0 +1,117 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/notify"
+)
+
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, "critical", notify.Critical.String())
+	assert.Equal(t, "severity(9)", notify.Severity(9).String())
+
+	s, err := notify.ParseSeverity("WARNING")
+	require.NoError(t, err)
+	assert.Equal(t, notify.Warning, s)
+
+	_, err = notify.ParseSeverity("loud")
+	require.EqualError(t, err, `invalid severity: "loud"`)
+
+	var cfg struct {
+		Min notify.Severity `json:"min"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"min":"error"}`), &cfg))
+	assert.Equal(t, notify.Error, cfg.Min)
+
+	b, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"min":"error"}`, string(b))
+
+	require.Error(t, json.Unmarshal([]byte(`{"min":"loud"}`), &cfg))
+}
+
+func TestLevelSeverity(t *testing.T) {
+	for level, want := range map[zerolog.Level]notify.Severity{
+		zerolog.TraceLevel: notify.Info,
+		zerolog.InfoLevel:  notify.Info,
+		zerolog.WarnLevel:  notify.Warning,
+		zerolog.ErrorLevel: notify.Error,
+		zerolog.FatalLevel: notify.Critical,
+		zerolog.PanicLevel: notify.Critical,
+		zerolog.NoLevel:    notify.Info,
+	} {
+		assert.Equal(t, want, notify.LevelSeverity(level), level)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := errors.New("connection refused")
+
+	m := notify.ErrorMessage("db check", err)
+	assert.Equal(t, "db check", m.Title)
+	assert.Equal(t, "connection refused", m.Text)
+	assert.Equal(t, notify.Error, m.Severity)
+	assert.Equal(t, errs.Fingerprint(err), m.Fingerprint)
+}
+
+func TestNotifierFunc(t *testing.T) {
+	var got notify.Message
+
+	n := notify.NotifierFunc(func(_ context.Context, m notify.Message) error {
+		got = m
+
+		return nil
+	})
+
+	require.NoError(t, n.Notify(context.Background(), notify.Message{Title: "hi"}))
+	assert.Equal(t, "hi", got.Title)
+}
+
+func TestDeliveryErrors(t *testing.T) {
+	status := http.StatusInternalServerError
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("broken\n"))
+	}))
+	defer srv.Close()
+
+	s := notify.NewSlackWebhook(srv.URL)
+
+	err := s.Notify(context.Background(), notify.Message{Title: "t"})
+	require.ErrorIs(t, err, notify.ErrDelivery)
+	require.EqualError(t, err, "notification delivery failed: status 500: broken")
+	assert.True(t, errs.IsRetryable(err))
+
+	after, ok := errs.RetryAfter(err)
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, after)
+
+	status = http.StatusBadRequest
+	err = s.Notify(context.Background(), notify.Message{Title: "t"})
+	require.ErrorIs(t, err, notify.ErrDelivery)
+	assert.False(t, errs.IsRetryable(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = s.Notify(ctx, notify.Message{Title: "t"})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.False(t, errs.IsRetryable(err))
+
+	s.WebhookURL = "http://127.0.0.1:1"
+	assert.True(t, errs.IsRetryable(s.Notify(context.Background(), notify.Message{Title: "t"})))
+}
@@ -0,0 +1,239 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySummaryMax is the summary limit of the Events API.
+const pagerDutySummaryMax = 1024
+
+// PagerDutyAction is the event_action of a PagerDuty event.
+type PagerDutyAction string
+
+const (
+	PagerDutyTrigger     PagerDutyAction = "trigger"
+	PagerDutyAcknowledge PagerDutyAction = "acknowledge"
+	PagerDutyResolve     PagerDutyAction = "resolve"
+)
+
+// PagerDuty sends messages to the PagerDuty Events API v2, deduplicated into incidents by Message.Fingerprint (e.g.
+// errs.Fingerprint, see ErrorMessage).
+type PagerDuty struct {
+	// RoutingKey is the integration key of the service.
+	RoutingKey string
+	// Routes overrides the routing key of the messages at or above a severity.
+	Routes map[Severity]string
+	// Source is the payload source, defaults to the hostname.
+	Source string
+	// Component, Group and Class are the optional payload details, e.g. the service, cluster and check type.
+	Component string
+	Group     string
+	Class     string
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+	// Timeout bounds each delivery, within the ctx deadline, defaults to DefaultTimeout.
+	Timeout time.Duration
+	// URL is the Events API endpoint, defaults to PagerDutyEventsURL.
+	URL string
+
+	mu      sync.Mutex
+	failing map[string]map[string]string // check -> dedup key -> routing key
+}
+
+var _ Notifier = (*PagerDuty)(nil)
+
+// NewPagerDuty returns a PagerDuty notifier sending events with routingKey.
+func NewPagerDuty(routingKey string) *PagerDuty {
+	return &PagerDuty{RoutingKey: routingKey}
+}
+
+// PagerDutyEvent is an Events API v2 event.
+type PagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction PagerDutyAction   `json:"event_action"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Payload     *PagerDutyPayload `json:"payload,omitempty"`
+	Links       []PagerDutyLink   `json:"links,omitempty"`
+}
+
+// PagerDutyPayload describes a triggered PagerDuty event.
+type PagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// PagerDutyLink is a link of a PagerDuty event.
+type PagerDutyLink struct {
+	Href string `json:"href"`
+	Text string `json:"text,omitempty"`
+}
+
+// Notify triggers an incident for m, see Trigger.
+func (p *PagerDuty) Notify(ctx context.Context, m Message) error {
+	return p.Trigger(ctx, m)
+}
+
+// Trigger triggers an incident for m, deduplicated by its Fingerprint (or Title if empty).  The PagerDuty severity
+// is the name of m.Severity.
+func (p *PagerDuty) Trigger(ctx context.Context, m Message) error {
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+
+	return p.Send(ctx, p.event(m))
+}
+
+// Acknowledge acknowledges the incident of dedupKey, sent with the routing key of severity.
+func (p *PagerDuty) Acknowledge(ctx context.Context, dedupKey string, severity Severity) error {
+	return p.Send(ctx, PagerDutyEvent{
+		RoutingKey: route(p.Routes, severity, p.RoutingKey), EventAction: PagerDutyAcknowledge, DedupKey: dedupKey,
+	})
+}
+
+// Resolve resolves the incident of dedupKey, sent with the routing key of severity.
+func (p *PagerDuty) Resolve(ctx context.Context, dedupKey string, severity Severity) error {
+	return p.Send(ctx, PagerDutyEvent{
+		RoutingKey: route(p.Routes, severity, p.RoutingKey), EventAction: PagerDutyResolve, DedupKey: dedupKey,
+	})
+}
+
+// Check reports the result of a recurring check: a failure triggers an incident (see ErrorMessage) keyed by the
+// check and the fingerprint of err, a success resolves the incidents triggered since the check last succeeded.
+func (p *PagerDuty) Check(ctx context.Context, check string, err error) error {
+	if err != nil {
+		m := ErrorMessage(check+" failed", err)
+		m.Fingerprint = check + ":" + m.Fingerprint
+		m.Fields = []Field{{Name: "check", Value: check}}
+
+		e := p.event(m)
+		if sendErr := p.Send(ctx, e); sendErr != nil {
+			return sendErr
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.failing == nil {
+			p.failing = map[string]map[string]string{}
+		}
+
+		if p.failing[check] == nil {
+			p.failing[check] = map[string]string{}
+		}
+
+		p.failing[check][e.DedupKey] = e.RoutingKey
+
+		return nil
+	}
+
+	p.mu.Lock()
+	keys := p.failing[check]
+	p.mu.Unlock()
+
+	dedupKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		dedupKeys = append(dedupKeys, k)
+	}
+
+	sort.Strings(dedupKeys)
+
+	for _, k := range dedupKeys {
+		resolve := PagerDutyEvent{RoutingKey: keys[k], EventAction: PagerDutyResolve, DedupKey: k}
+		if err = p.Send(ctx, resolve); err != nil {
+			return err // still failing, resolved on the next success
+		}
+
+		p.mu.Lock()
+		delete(p.failing[check], k)
+		p.mu.Unlock()
+	}
+
+	p.mu.Lock()
+	if len(p.failing[check]) == 0 {
+		delete(p.failing, check)
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
+// Send sends e to the Events API.
+func (p *PagerDuty) Send(ctx context.Context, e PagerDutyEvent) error {
+	url := p.URL
+	if url == "" {
+		url = PagerDutyEventsURL
+	}
+
+	_, err := postJSON(ctx, p.Client, p.Timeout, url, nil, e)
+
+	return err
+}
+
+// event returns the trigger event of m.
+func (p *PagerDuty) event(m Message) PagerDutyEvent {
+	source := p.Source
+	if source == "" {
+		source, _ = os.Hostname()
+	}
+
+	summary := m.Title
+	if summary == "" {
+		summary = m.Text
+	}
+
+	dedupKey := m.Fingerprint
+	if dedupKey == "" {
+		dedupKey = m.Title
+	}
+
+	payload := &PagerDutyPayload{
+		Summary:   truncate(summary, pagerDutySummaryMax),
+		Source:    source,
+		Severity:  max(Info, min(m.Severity, Critical)).String(),
+		Component: p.Component,
+		Group:     p.Group,
+		Class:     p.Class,
+	}
+
+	if !m.Time.IsZero() {
+		payload.Timestamp = m.Time.UTC().Format(time.RFC3339)
+	}
+
+	if m.Text != "" || len(m.Fields) > 0 {
+		payload.CustomDetails = make(map[string]string, len(m.Fields)+1)
+		for _, f := range m.Fields {
+			payload.CustomDetails[f.Name] = f.Value
+		}
+
+		if m.Text != "" && summary != m.Text {
+			payload.CustomDetails["text"] = m.Text
+		}
+	}
+
+	e := PagerDutyEvent{
+		RoutingKey:  route(p.Routes, m.Severity, p.RoutingKey),
+		EventAction: PagerDutyTrigger,
+		DedupKey:    dedupKey,
+		Payload:     payload,
+	}
+
+	if m.URL != "" {
+		e.Links = []PagerDutyLink{{Href: m.URL, Text: "Details"}}
+	}
+
+	return e
+}
@@ -0,0 +1,106 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/notify"
+)
+
+func TestPagerDuty(t *testing.T) {
+	rec := newRecorder(t, `{"status":"success","message":"Event processed"}`)
+
+	p := notify.NewPagerDuty("R0UT1NG")
+	p.URL = rec.URL
+	p.Source, p.Component = "api-1", "billing"
+	p.Routes = map[notify.Severity]string{notify.Critical: "CR1T1CAL"}
+
+	m := notify.Message{
+		Title:       "Payment failures",
+		Text:        "3 charges failed",
+		Severity:    notify.Critical,
+		Fields:      []notify.Field{{Name: "Service", Value: "billing"}},
+		URL:         "https://logs.example.com",
+		Fingerprint: "abc123",
+		Time:        testTime,
+	}
+
+	require.NoError(t, p.Notify(context.Background(), m))
+	require.NoError(t, p.Acknowledge(context.Background(), "abc123", notify.Critical))
+	require.NoError(t, p.Resolve(context.Background(), "abc123", notify.Info))
+
+	require.Len(t, rec.bodies, 3)
+	assert.JSONEq(t, `{
+		"routing_key": "CR1T1CAL",
+		"event_action": "trigger",
+		"dedup_key": "abc123",
+		"payload": {
+			"summary": "Payment failures",
+			"source": "api-1",
+			"severity": "critical",
+			"timestamp": "2024-05-01T12:00:00Z",
+			"component": "billing",
+			"custom_details": {"Service": "billing", "text": "3 charges failed"}
+		},
+		"links": [{"href": "https://logs.example.com", "text": "Details"}]
+	}`, rec.bodies[0])
+	assert.JSONEq(t, `{"routing_key":"CR1T1CAL","event_action":"acknowledge","dedup_key":"abc123"}`, rec.bodies[1])
+	assert.JSONEq(t, `{"routing_key":"R0UT1NG","event_action":"resolve","dedup_key":"abc123"}`, rec.bodies[2])
+}
+
+func TestPagerDutyCheck(t *testing.T) {
+	rec := newRecorder(t, `{"status":"success"}`)
+
+	p := notify.NewPagerDuty("R0UT1NG")
+	p.URL = rec.URL
+
+	require.NoError(t, p.Check(context.Background(), "db", nil))
+	assert.Empty(t, rec.bodies, "no resolve without a failure")
+
+	failure := errors.New("connection refused")
+	key := "db:" + errs.Fingerprint(failure)
+
+	require.NoError(t, p.Check(context.Background(), "db", failure))
+	require.NoError(t, p.Check(context.Background(), "db", failure))
+	require.NoError(t, p.Check(context.Background(), "db", nil))
+	require.NoError(t, p.Check(context.Background(), "db", nil))
+
+	require.Len(t, rec.bodies, 3)
+	assert.Contains(t, rec.bodies[0], `"event_action":"trigger","dedup_key":"`+key+`"`)
+	assert.Contains(t, rec.bodies[0], `"summary":"db failed"`)
+	assert.Contains(t, rec.bodies[0], `"severity":"error"`)
+	assert.Contains(t, rec.bodies[0], `"custom_details":{"check":"db","text":"connection refused"}`)
+	assert.Equal(t, rec.bodies[0], rec.bodies[1])
+	assert.JSONEq(t, `{"routing_key":"R0UT1NG","event_action":"resolve","dedup_key":"`+key+`"}`, rec.bodies[2])
+}
+
+func TestPagerDutyCheckResolveFailure(t *testing.T) {
+	rec := newRecorder(t, `{"status":"success"}`)
+
+	p := notify.NewPagerDuty("R0UT1NG")
+	p.URL = rec.URL
+
+	require.NoError(t, p.Check(context.Background(), "db", errors.New("down")))
+
+	rec.Config.Handler = http.NotFoundHandler()
+
+	err := p.Check(context.Background(), "db", nil)
+	require.ErrorIs(t, err, notify.ErrDelivery)
+
+	resolved := 0
+	rec.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		resolved++
+
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	})
+
+	require.NoError(t, p.Check(context.Background(), "db", nil))
+	require.NoError(t, p.Check(context.Background(), "db", nil))
+	assert.Equal(t, 1, resolved, "resolved on the next success")
+}
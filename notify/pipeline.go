@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// DefaultPipelineRate is the rate limit of a Pipeline destination.
+var DefaultPipelineRate = Rate{PerMinute: 20, Burst: 5}
+
+// Pipeline delivers messages to a destination grouped (see Grouper), then rate limited (see Limiter), then
+// asynchronously with retries (see Queue), so Notify never blocks on the destination.  The stages are exported to
+// adjust their settings before Run.
+type Pipeline struct {
+	Grouper *Grouper
+	Limiter *Limiter
+	Queue   *Queue
+}
+
+var _ Notifier = (*Pipeline)(nil)
+
+// NewPipeline returns a Pipeline to the destination dest named name, with DefaultGroupWindow and
+// DefaultPipelineRate.
+func NewPipeline(name string, dest Notifier, logger zerolog.Logger) *Pipeline {
+	q := NewQueue(dest, 0, logger)
+	l := NewLimiter(Rate{}, DefaultPipelineRate, logger)
+
+	return &Pipeline{
+		Grouper: NewGrouper(l.Wrap(name, q), DefaultGroupWindow, logger),
+		Limiter: l,
+		Queue:   q,
+	}
+}
+
+// Notify sends m through the pipeline.
+func (p *Pipeline) Notify(ctx context.Context, m Message) error {
+	return p.Grouper.Notify(ctx, m)
+}
+
+// Run runs the stages until ctx is done, flushing the pending summaries before the queue is drained.
+func (p *Pipeline) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		p.Grouper.Run(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		p.Limiter.Run(ctx)
+	}()
+
+	queueCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+
+	p.Queue.Run(queueCtx)
+}
@@ -0,0 +1,43 @@
+package notify_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+func TestPipeline(t *testing.T) {
+	var out sink
+
+	p := notify.NewPipeline("slack", &out, zerolog.Nop())
+	p.Limiter.PerDestination = notify.Rate{PerMinute: 1, Burst: 2}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	for range 100 {
+		require.NoError(t, p.Notify(ctx, notify.Message{Title: "db down", Fingerprint: "db"}))
+	}
+
+	require.NoError(t, p.Notify(ctx, notify.Message{Title: "cache down"}))
+	require.NoError(t, p.Notify(ctx, notify.Message{Title: "queue down"}))
+
+	assert.Eventually(t, func() bool { return len(out.titles()) == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+
+	assert.Equal(t, []string{"db down", "cache down"}, out.titles(), "queue down and the summary are rate limited")
+	assert.Equal(t, 2, p.Limiter.Suppressed("slack"))
+}
@@ -0,0 +1,240 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/errs"
+)
+
+var (
+	// ErrQueueFull is the error of the messages dropped by a full Queue.
+	ErrQueueFull = errors.New("notification queue full")
+	// ErrQueueClosed is returned by Queue.Notify after Run returned.
+	ErrQueueClosed = errors.New("notification queue closed")
+)
+
+// Defaults of Queue.
+const (
+	DefaultQueueSize   = 1000
+	DefaultMaxAttempts = 5
+	DefaultRetryBase   = time.Second
+	DefaultRetryMax    = time.Minute
+)
+
+// Overflow is the policy of a full Queue.
+type Overflow int8
+
+const (
+	// DropNewest rejects the new message with ErrQueueFull.
+	DropNewest Overflow = iota
+	// DropOldest drops the oldest queued message to make room for the new one.
+	DropOldest
+	// Block waits for room, until the ctx of Notify is done.
+	Block
+)
+
+// Queue wraps a Notifier, delivering messages asynchronously from a bounded queue with retries.  Retryable failures
+// (see errs.IsRetryable) are retried with exponential backoff and jitter, honoring errs.RetryAfter.  Messages failing
+// permanently, running out of attempts or dropped by the Overflow policy are passed to DeadLetter.  Create it with
+// NewQueue.
+type Queue struct {
+	// Next delivers the messages.
+	Next Notifier
+	// Logger logs the dead letters when DeadLetter is nil.
+	Logger zerolog.Logger
+	// Overflow is the policy of a full queue, defaults to DropNewest.
+	Overflow Overflow
+	// MaxAttempts is the total delivery attempts of a message, defaults to DefaultMaxAttempts.
+	MaxAttempts int
+	// BaseDelay is the delay after the first failure, doubled for each attempt, defaults to DefaultRetryBase.
+	BaseDelay time.Duration
+	// MaxDelay caps the retry delay, defaults to DefaultRetryMax.
+	MaxDelay time.Duration
+	// Workers is the number of concurrent deliveries, defaults to 1.
+	Workers int
+	// DeadLetter receives the messages that could not be delivered with the last error, defaults to logging them.
+	DeadLetter func(m Message, err error)
+
+	queue  chan Message
+	done   chan struct{}
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ Notifier = (*Queue)(nil)
+
+// NewQueue returns a Queue of up to size messages (DefaultQueueSize if 0) delivered to next.
+func NewQueue(next Notifier, size int, logger zerolog.Logger) *Queue {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+
+	return &Queue{
+		Next:   next,
+		Logger: logger.With().Str("module", "notify").Logger(),
+		queue:  make(chan Message, size),
+		done:   make(chan struct{}),
+	}
+}
+
+// Notify queues m for delivery, applying the Overflow policy if the queue is full.
+func (q *Queue) Notify(ctx context.Context, m Message) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.queue <- m:
+		return nil
+	default:
+	}
+
+	switch q.Overflow {
+	case Block:
+		select {
+		case q.queue <- m:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-q.done:
+			return ErrQueueClosed
+		}
+	case DropOldest:
+		for {
+			select {
+			case q.queue <- m:
+				return nil
+			default:
+			}
+
+			select {
+			case old := <-q.queue:
+				q.deadLetter(old, ErrQueueFull)
+			default:
+			}
+		}
+	default:
+		q.deadLetter(m, ErrQueueFull)
+
+		return ErrQueueFull
+	}
+}
+
+// Len returns the number of queued messages.
+func (q *Queue) Len() int {
+	return len(q.queue)
+}
+
+// Run delivers the queued messages until ctx is done, then attempts the remaining messages once and closes the
+// queue.
+func (q *Queue) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for range max(q.Workers, 1) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case m := <-q.queue:
+					q.deliver(ctx, m)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(q.done)
+
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+
+	for {
+		select {
+		case m := <-q.queue:
+			if err := q.Next.Notify(ctx, m); err != nil {
+				q.deadLetter(m, err)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// deliver attempts m until delivered, failed permanently or out of attempts.
+func (q *Queue) deliver(ctx context.Context, m Message) {
+	maxAttempts := q.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := q.Next.Notify(ctx, m)
+		if err == nil {
+			return
+		}
+
+		if !errs.IsRetryable(err) || attempt+1 >= maxAttempts {
+			q.deadLetter(m, err)
+
+			return
+		}
+
+		timer := time.NewTimer(jitterDelay(err, attempt, q.BaseDelay, q.MaxDelay))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			q.deadLetter(m, err)
+
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// jitterDelay returns the delay before retrying attempt (0 based), jittered between half and all of the exponential
+// delay from base (DefaultRetryBase if 0) to maxDelay (DefaultRetryMax if 0), unless err requested a delay.
+func jitterDelay(err error, attempt int, base, maxDelay time.Duration) time.Duration {
+	if base <= 0 {
+		base = DefaultRetryBase
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMax
+	}
+
+	d := errs.RetryDelay(err, attempt, base, maxDelay)
+	if _, ok := errs.RetryAfter(err); ok || d < 2 {
+		return d
+	}
+
+	return d/2 + rand.N(d/2) //nolint:gosec // jitter
+}
+
+func (q *Queue) deadLetter(m Message, err error) {
+	if q.DeadLetter != nil {
+		q.DeadLetter(m, err)
+
+		return
+	}
+
+	q.Logger.Error().Err(err).Str("title", m.Title).Str("severity", m.Severity.String()).
+		Msg("notification dead letter")
+}
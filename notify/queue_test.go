@@ -0,0 +1,162 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/notify"
+)
+
+// deadLetters records the dead letters of a Queue.
+type deadLetters struct {
+	mu     sync.Mutex
+	titles []string
+	errs   []error
+}
+
+func (d *deadLetters) add(m notify.Message, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.titles = append(d.titles, m.Title)
+	d.errs = append(d.errs, err)
+}
+
+func (d *deadLetters) get() ([]string, []error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.titles...), append([]error(nil), d.errs...)
+}
+
+func runQueue(t *testing.T, q *notify.Queue) context.CancelFunc {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	stop := func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+
+	return stop
+}
+
+func TestQueueRetry(t *testing.T) {
+	var (
+		out      sink
+		dead     deadLetters
+		mu       sync.Mutex
+		attempts = map[string]int{}
+	)
+
+	q := notify.NewQueue(notify.NotifierFunc(func(ctx context.Context, m notify.Message) error {
+		mu.Lock()
+		attempts[m.Title]++
+		n := attempts[m.Title]
+		mu.Unlock()
+
+		switch {
+		case m.Title == "invalid":
+			return errors.New("bad request")
+		case m.Title == "down" || n < 3:
+			return errs.MarkRetryable(errors.New("unavailable"), 0)
+		}
+
+		return out.Notify(ctx, m)
+	}), 10, zerolog.Nop())
+	q.BaseDelay, q.MaxDelay, q.MaxAttempts = time.Millisecond, 5*time.Millisecond, 4
+	q.DeadLetter = dead.add
+
+	stop := runQueue(t, q)
+
+	for _, title := range []string{"flaky", "invalid", "down"} {
+		require.NoError(t, q.Notify(context.Background(), notify.Message{Title: title}))
+	}
+
+	assert.Eventually(t, func() bool { titles, _ := dead.get(); return len(titles) == 2 }, time.Second, time.Millisecond)
+	stop()
+
+	assert.Equal(t, []string{"flaky"}, out.titles())
+
+	titles, failures := dead.get()
+	assert.Equal(t, []string{"invalid", "down"}, titles)
+	require.EqualError(t, failures[0], "bad request")
+	require.EqualError(t, failures[1], "unavailable")
+
+	mu.Lock()
+	assert.Equal(t, map[string]int{"flaky": 3, "invalid": 1, "down": 4}, attempts)
+	mu.Unlock()
+
+	require.ErrorIs(t, q.Notify(context.Background(), notify.Message{}), notify.ErrQueueClosed)
+}
+
+func TestQueueOverflow(t *testing.T) {
+	var dead deadLetters
+
+	q := notify.NewQueue(&sink{}, 2, zerolog.Nop())
+	q.DeadLetter = dead.add
+
+	ctx := context.Background()
+
+	require.NoError(t, q.Notify(ctx, notify.Message{Title: "1"}))
+	require.NoError(t, q.Notify(ctx, notify.Message{Title: "2"}))
+	require.ErrorIs(t, q.Notify(ctx, notify.Message{Title: "3"}), notify.ErrQueueFull)
+
+	q.Overflow = notify.DropOldest
+	require.NoError(t, q.Notify(ctx, notify.Message{Title: "4"}))
+	assert.Equal(t, 2, q.Len())
+
+	titles, failures := dead.get()
+	assert.Equal(t, []string{"3", "1"}, titles)
+	require.ErrorIs(t, failures[1], notify.ErrQueueFull)
+
+	q.Overflow = notify.Block
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, q.Notify(timeout, notify.Message{Title: "5"}), context.DeadlineExceeded)
+
+	var out sink
+
+	q.Next = &out
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		runQueue(t, q)
+	}()
+
+	require.NoError(t, q.Notify(ctx, notify.Message{Title: "6"}), "blocks until delivered")
+	assert.Eventually(t, func() bool { return len(out.titles()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"2", "4", "6"}, out.titles())
+}
+
+func TestQueueDrain(t *testing.T) {
+	var out sink
+
+	q := notify.NewQueue(&out, 0, zerolog.Nop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, q.Notify(context.Background(), notify.Message{Title: "pending"}))
+	q.Run(ctx)
+
+	assert.Equal(t, []string{"pending"}, out.titles(), "delivered when done")
+}
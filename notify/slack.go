@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bir/iken/errs"
+)
+
+// SlackPostMessageURL is the Slack Web API method posting messages with a bot token.
+const SlackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// Slack Block Kit limits.
+const (
+	slackHeaderMax  = 150
+	slackTextMax    = 3000
+	slackFieldsMax  = 10
+	slackFieldMax   = 2000
+	slackContextMax = 10
+)
+
+var slackEmoji = map[Severity]string{
+	Info:     ":information_source:",
+	Warning:  ":warning:",
+	Error:    ":x:",
+	Critical: ":rotating_light:",
+}
+
+// Slack posts messages to Slack with an incoming webhook, or with a bot token using chat.postMessage.
+type Slack struct {
+	// WebhookURL is the incoming webhook, used when Token is empty.
+	WebhookURL string
+	// Token is the bot token of chat.postMessage.
+	Token string
+	// Channel is the chat.postMessage channel.
+	Channel string
+	// Routes overrides the channel (with Token) or webhook URL of the messages at or above a severity, e.g.
+	// {notify.Critical: "#incidents"}.
+	Routes map[Severity]string
+	// Blocks renders messages as Block Kit blocks, defaults to SlackBlocks.
+	Blocks func(m Message) []SlackBlock
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+	// Timeout bounds each delivery, within the ctx deadline, defaults to DefaultTimeout.
+	Timeout time.Duration
+	// APIURL overrides SlackPostMessageURL, e.g. for tests.
+	APIURL string
+}
+
+var _ Notifier = (*Slack)(nil)
+
+// NewSlackWebhook returns a Slack notifier posting to the incoming webhook url.
+func NewSlackWebhook(url string) *Slack {
+	return &Slack{WebhookURL: url}
+}
+
+// NewSlackBot returns a Slack notifier posting to channel with the bot token.
+func NewSlackBot(token, channel string) *Slack {
+	return &Slack{Token: token, Channel: channel}
+}
+
+// SlackMessage is the payload of incoming webhooks and chat.postMessage.
+type SlackMessage struct {
+	Channel string       `json:"channel,omitempty"`
+	Text    string       `json:"text"`
+	Blocks  []SlackBlock `json:"blocks,omitempty"`
+}
+
+// SlackBlock is a Block Kit layout block, see SlackHeader, SlackSection, SlackFields, SlackContext and SlackDivider.
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Fields   []SlackText `json:"fields,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+// SlackText is a Block Kit text object, of type plain_text or mrkdwn.
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SlackHeader returns a header block of plain text.
+func SlackHeader(text string) SlackBlock {
+	return SlackBlock{Type: "header", Text: &SlackText{Type: "plain_text", Text: truncate(text, slackHeaderMax)}}
+}
+
+// SlackSection returns a section block of markdown.
+func SlackSection(markdown string) SlackBlock {
+	return SlackBlock{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: truncate(markdown, slackTextMax)}}
+}
+
+// SlackFields returns a section block of the fields (up to 10), shown in two columns.
+func SlackFields(fields ...Field) SlackBlock {
+	b := SlackBlock{Type: "section"}
+
+	for _, f := range fields[:min(len(fields), slackFieldsMax)] {
+		b.Fields = append(b.Fields, SlackText{
+			Type: "mrkdwn",
+			Text: truncate("*"+SlackEscape(f.Name)+"*\n"+SlackEscape(f.Value), slackFieldMax),
+		})
+	}
+
+	return b
+}
+
+// SlackContext returns a context block of markdown elements (up to 10), shown in small print.
+func SlackContext(markdown ...string) SlackBlock {
+	b := SlackBlock{Type: "context"}
+
+	for _, m := range markdown[:min(len(markdown), slackContextMax)] {
+		b.Elements = append(b.Elements, SlackText{Type: "mrkdwn", Text: m})
+	}
+
+	return b
+}
+
+// SlackDivider returns a divider block.
+func SlackDivider() SlackBlock {
+	return SlackBlock{Type: "divider"}
+}
+
+// SlackEscape escapes the control characters of Slack markdown in s.
+func SlackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// SlackBlocks renders m as a header with the severity emoji, a section of the text, sections of the fields, and a
+// context of the severity, time and URL.
+func SlackBlocks(m Message) []SlackBlock {
+	blocks := []SlackBlock{SlackHeader(slackEmoji[m.Severity] + " " + m.Title)}
+
+	if m.Text != "" {
+		blocks = append(blocks, SlackSection(m.Text))
+	}
+
+	for i := 0; i < len(m.Fields); i += slackFieldsMax {
+		blocks = append(blocks, SlackFields(m.Fields[i:]...))
+	}
+
+	footer := []string{"*" + m.Severity.String() + "*"}
+	if !m.Time.IsZero() {
+		footer = append(footer, fmt.Sprintf("<!date^%d^{date_short_pretty} {time_secs}|%s>",
+			m.Time.Unix(), m.Time.UTC().Format(time.RFC3339)))
+	}
+
+	if m.URL != "" {
+		footer = append(footer, "<"+m.URL+"|Details>")
+	}
+
+	return append(blocks, SlackContext(footer...))
+}
+
+// Notify posts m.
+func (s *Slack) Notify(ctx context.Context, m Message) error {
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+
+	render := s.Blocks
+	if render == nil {
+		render = SlackBlocks
+	}
+
+	msg := SlackMessage{Text: m.Title, Blocks: render(m)}
+	if m.Text != "" {
+		msg.Text += ": " + m.Text
+	}
+
+	if s.Token == "" {
+		_, err := postJSON(ctx, s.Client, s.Timeout, route(s.Routes, m.Severity, s.WebhookURL), nil, msg)
+
+		return err
+	}
+
+	msg.Channel = route(s.Routes, m.Severity, s.Channel)
+
+	url := s.APIURL
+	if url == "" {
+		url = SlackPostMessageURL
+	}
+
+	b, err := postJSON(ctx, s.Client, s.Timeout, url, http.Header{"Authorization": {"Bearer " + s.Token}}, msg)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+
+	if err = json.Unmarshal(b, &resp); err != nil {
+		return fmt.Errorf("slack response: %w", err)
+	}
+
+	if !resp.OK {
+		err = fmt.Errorf("%w: slack: %s", ErrDelivery, resp.Error)
+		if resp.Error == "ratelimited" {
+			return errs.MarkRetryable(err, 0)
+		}
+
+		return err
+	}
+
+	return nil
+}
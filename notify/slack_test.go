@@ -0,0 +1,135 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/notify"
+)
+
+// recorder is a destination recording the requests it receives, replying with reply.
+type recorder struct {
+	*httptest.Server
+	paths   []string
+	headers []http.Header
+	bodies  []string
+	reply   string
+}
+
+func newRecorder(t *testing.T, reply string) *recorder {
+	t.Helper()
+
+	r := &recorder{reply: reply}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+
+		r.paths = append(r.paths, req.URL.Path)
+		r.headers = append(r.headers, req.Header)
+		r.bodies = append(r.bodies, string(b))
+
+		_, _ = w.Write([]byte(r.reply))
+	}))
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+var testTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestSlackWebhook(t *testing.T) {
+	rec := newRecorder(t, "ok")
+
+	s := notify.NewSlackWebhook(rec.URL + "/default")
+	s.Routes = map[notify.Severity]string{notify.Error: rec.URL + "/errors"}
+
+	m := notify.Message{
+		Title:    "Payment failures",
+		Text:     "3 charges failed",
+		Severity: notify.Critical,
+		Fields:   []notify.Field{{Name: "Service", Value: "billing"}, {Name: "Query", Value: "a < b"}},
+		URL:      "https://logs.example.com",
+		Time:     testTime,
+	}
+
+	require.NoError(t, s.Notify(context.Background(), m))
+	require.NoError(t, s.Notify(context.Background(), notify.Message{Title: "Deploy", Severity: notify.Warning}))
+
+	assert.Equal(t, []string{"/errors", "/default"}, rec.paths)
+	assert.Equal(t, "application/json", rec.headers[0].Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"text": "Payment failures: 3 charges failed",
+		"blocks": [
+			{"type": "header", "text": {"type": "plain_text", "text": ":rotating_light: Payment failures"}},
+			{"type": "section", "text": {"type": "mrkdwn", "text": "3 charges failed"}},
+			{"type": "section", "fields": [
+				{"type": "mrkdwn", "text": "*Service*\nbilling"},
+				{"type": "mrkdwn", "text": "*Query*\na &lt; b"}
+			]},
+			{"type": "context", "elements": [
+				{"type": "mrkdwn", "text": "*critical*"},
+				{"type": "mrkdwn", "text": "<!date^1714564800^{date_short_pretty} {time_secs}|2024-05-01T12:00:00Z>"},
+				{"type": "mrkdwn", "text": "<https://logs.example.com|Details>"}
+			]}
+		]
+	}`, rec.bodies[0])
+}
+
+func TestSlackBot(t *testing.T) {
+	rec := newRecorder(t, `{"ok":true}`)
+
+	s := notify.NewSlackBot("xoxb-1", "#alerts")
+	s.APIURL = rec.URL
+	s.Routes = map[notify.Severity]string{notify.Critical: "#incidents"}
+	s.Blocks = func(m notify.Message) []notify.SlackBlock {
+		return []notify.SlackBlock{notify.SlackSection(m.Title), notify.SlackDivider()}
+	}
+
+	require.NoError(t, s.Notify(context.Background(), notify.Message{Title: "Disk", Severity: notify.Error}))
+	require.NoError(t, s.Notify(context.Background(), notify.Message{Title: "Down", Severity: notify.Critical}))
+
+	assert.Equal(t, "Bearer xoxb-1", rec.headers[0].Get("Authorization"))
+	assert.JSONEq(t, `{"channel":"#alerts","text":"Disk",
+		"blocks":[{"type":"section","text":{"type":"mrkdwn","text":"Disk"}},{"type":"divider"}]}`, rec.bodies[0])
+
+	var second notify.SlackMessage
+
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[1]), &second))
+	assert.Equal(t, "#incidents", second.Channel)
+
+	rec.reply = `{"ok":false,"error":"channel_not_found"}`
+	err := s.Notify(context.Background(), notify.Message{Title: "Disk"})
+	require.ErrorIs(t, err, notify.ErrDelivery)
+	require.EqualError(t, err, "notification delivery failed: slack: channel_not_found")
+	assert.False(t, errs.IsRetryable(err))
+
+	rec.reply = `{"ok":false,"error":"ratelimited"}`
+	assert.True(t, errs.IsRetryable(s.Notify(context.Background(), notify.Message{Title: "Disk"})))
+
+	rec.reply = `<html>`
+	require.Error(t, s.Notify(context.Background(), notify.Message{Title: "Disk"}))
+}
+
+func TestSlackBlocks(t *testing.T) {
+	fields := make([]notify.Field, 12)
+	for i := range fields {
+		fields[i] = notify.Field{Name: "n", Value: "v"}
+	}
+
+	blocks := notify.SlackBlocks(notify.Message{Title: strings.Repeat("x", 200), Fields: fields})
+	require.Len(t, blocks, 4)
+	assert.Len(t, []rune(blocks[0].Text.Text), 150)
+	assert.True(t, strings.HasSuffix(blocks[0].Text.Text, "…"))
+	assert.Len(t, blocks[1].Fields, 10)
+	assert.Len(t, blocks[2].Fields, 2)
+	assert.Equal(t, []notify.SlackText{{Type: "mrkdwn", Text: "*info*"}}, blocks[3].Elements)
+}
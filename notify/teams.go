@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Adaptive Card styles of the severities.
+var teamsColor = map[Severity]string{
+	Info:     "Accent",
+	Warning:  "Warning",
+	Error:    "Attention",
+	Critical: "Attention",
+}
+
+// Teams posts messages to a Microsoft Teams incoming webhook (or Workflows webhook) as Adaptive Cards.
+type Teams struct {
+	// WebhookURL is the webhook.
+	WebhookURL string
+	// Routes overrides the webhook URL of the messages at or above a severity.
+	Routes map[Severity]string
+	// Card renders messages as an Adaptive Card, defaults to TeamsCard.
+	Card func(m Message) map[string]any
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+	// Timeout bounds each delivery, within the ctx deadline, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+var _ Notifier = (*Teams)(nil)
+
+// NewTeams returns a Teams notifier posting to the webhook url.
+func NewTeams(url string) *Teams {
+	return &Teams{WebhookURL: url}
+}
+
+// TeamsCard renders m as an Adaptive Card of the title styled by severity, the text, a fact set of the fields, the
+// severity and time, and a link to the URL.
+func TeamsCard(m Message) map[string]any {
+	body := []map[string]any{
+		{
+			"type": "TextBlock", "text": m.Title, "size": "Large", "weight": "Bolder", "color": teamsColor[m.Severity],
+			"wrap": true,
+		},
+	}
+
+	if m.Text != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": m.Text, "wrap": true})
+	}
+
+	if len(m.Fields) > 0 {
+		facts := make([]map[string]string, len(m.Fields))
+		for i, f := range m.Fields {
+			facts[i] = map[string]string{"title": f.Name, "value": f.Value}
+		}
+
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+
+	footer := m.Severity.String()
+	if !m.Time.IsZero() {
+		footer += " · " + m.Time.UTC().Format(time.RFC3339)
+	}
+
+	body = append(body, map[string]any{"type": "TextBlock", "text": footer, "isSubtle": true, "size": "Small"})
+
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+
+	if m.URL != "" {
+		card["actions"] = []map[string]any{{"type": "Action.OpenUrl", "title": "Details", "url": m.URL}}
+	}
+
+	return card
+}
+
+// Notify posts m.
+func (t *Teams) Notify(ctx context.Context, m Message) error {
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+
+	render := t.Card
+	if render == nil {
+		render = TeamsCard
+	}
+
+	msg := map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     render(m),
+		}},
+	}
+
+	_, err := postJSON(ctx, t.Client, t.Timeout, route(t.Routes, m.Severity, t.WebhookURL), nil, msg)
+
+	return err
+}
@@ -0,0 +1,61 @@
+package notify_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+func TestTeams(t *testing.T) {
+	rec := newRecorder(t, "1")
+
+	n := notify.NewTeams(rec.URL + "/default")
+	n.Routes = map[notify.Severity]string{notify.Critical: rec.URL + "/critical"}
+
+	require.NoError(t, n.Notify(context.Background(), notify.Message{
+		Title:    "Payment failures",
+		Text:     "3 charges failed",
+		Severity: notify.Error,
+		Fields:   []notify.Field{{Name: "Service", Value: "billing"}},
+		URL:      "https://logs.example.com",
+		Time:     testTime,
+	}))
+	require.NoError(t, n.Notify(context.Background(), notify.Message{Title: "Down", Severity: notify.Critical}))
+
+	assert.Equal(t, []string{"/default", "/critical"}, rec.paths)
+	assert.JSONEq(t, `{
+		"type": "message",
+		"attachments": [{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": {
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type": "AdaptiveCard",
+				"version": "1.4",
+				"body": [
+					{"type": "TextBlock", "text": "Payment failures", "size": "Large", "weight": "Bolder",
+						"color": "Attention", "wrap": true},
+					{"type": "TextBlock", "text": "3 charges failed", "wrap": true},
+					{"type": "FactSet", "facts": [{"title": "Service", "value": "billing"}]},
+					{"type": "TextBlock", "text": "error · 2024-05-01T12:00:00Z", "isSubtle": true, "size": "Small"}
+				],
+				"actions": [{"type": "Action.OpenUrl", "title": "Details", "url": "https://logs.example.com"}]
+			}
+		}]
+	}`, rec.bodies[0])
+}
+
+func TestTeamsCard(t *testing.T) {
+	rec := newRecorder(t, "1")
+
+	n := notify.NewTeams(rec.URL)
+	n.Card = func(m notify.Message) map[string]any {
+		return map[string]any{"type": "AdaptiveCard", "body": []any{}, "title": m.Title}
+	}
+
+	require.NoError(t, n.Notify(context.Background(), notify.Message{Title: "custom"}))
+	assert.Contains(t, rec.bodies[0], `"content":{"body":[],"title":"custom","type":"AdaptiveCard"}`)
+}
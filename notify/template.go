@@ -0,0 +1,209 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/bir/iken/strutil"
+)
+
+// ErrTemplate is returned when rendering a template missing from Templates.
+var ErrTemplate = errors.New("unknown notification template")
+
+// TemplateFuncs are the helpers of notification templates, sprig style: the piped value is the last argument, e.g.
+// {{.Name | default "unknown" | upper}} or {{.Error | abbrev 80}}.
+var TemplateFuncs = template.FuncMap{
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"title":     titleCase,
+	"trim":      strings.TrimSpace,
+	"trunc":     func(n int, s string) string { return strutil.TruncateRunes(s, n, "") },
+	"abbrev":    func(n int, s string) string { return truncate(s, n) },
+	"replace":   func(old, replacement, s string) string { return strings.ReplaceAll(s, old, replacement) },
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"quote":     strconv.Quote,
+	"indent":    func(n int, s string) string { return indent(n, s) },
+	"nindent":   func(n int, s string) string { return "\n" + indent(n, s) },
+	"join":      join,
+	"default":   func(d, v any) any { return coalesce(v, d) },
+	"coalesce":  func(v ...any) any { return coalesce(v...) },
+	"empty":     empty,
+	"date":      func(layout string, t time.Time) string { return t.Format(layout) },
+	"since":     func(t time.Time) string { return time.Since(t).Round(time.Second).String() },
+	"toJson":    toJSON,
+}
+
+// Templates render the Title and Text of messages from text templates named by Message.Template, executed with
+// Message.Data.  The first line of the output is the Title, the rest is the Text.  A "<channel>/<name>" template
+// overrides <name> for the destinations wrapped as that channel, see Wrap.  Templates are strict: referencing a
+// missing map key or field fails, use Check at startup to render every template with sample data.
+type Templates struct {
+	tmpl  *template.Template
+	names []string
+}
+
+// ParseTemplates parses the templates defs by name, with TemplateFuncs.
+func ParseTemplates(defs map[string]string) (*Templates, error) {
+	t := &Templates{tmpl: template.New("").Funcs(TemplateFuncs).Option("missingkey=error")}
+
+	for name, def := range defs {
+		if _, err := t.tmpl.New(name).Parse(def); err != nil {
+			return nil, fmt.Errorf("notification template: %w", err)
+		}
+
+		t.names = append(t.names, name)
+	}
+
+	sort.Strings(t.names)
+
+	return t, nil
+}
+
+// MustParseTemplates is ParseTemplates, panicking on errors.
+func MustParseTemplates(defs map[string]string) *Templates {
+	t, err := ParseTemplates(defs)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// Render renders the template name for channel (see Templates) with data into the Title and Text of m.
+func (t *Templates) Render(channel, name string, data any, m Message) (Message, error) {
+	tmpl := t.tmpl.Lookup(channel + "/" + name)
+	if channel == "" || tmpl == nil {
+		tmpl = t.tmpl.Lookup(name)
+	}
+
+	if tmpl == nil {
+		return m, fmt.Errorf("%w: %q", ErrTemplate, name)
+	}
+
+	var b strings.Builder
+
+	if err := tmpl.Execute(&b, data); err != nil {
+		return m, fmt.Errorf("notification template: %w", err)
+	}
+
+	title, text, _ := strings.Cut(strings.TrimLeft(b.String(), "\n"), "\n")
+	m.Title, m.Text = strings.TrimSpace(title), strings.TrimSpace(text)
+
+	return m, nil
+}
+
+// Check renders every template with the sample data of its name (channel overrides use the sample of the name they
+// override), returning all the failures.  Templates without a sample are rendered with nil.
+func (t *Templates) Check(samples map[string]any) error {
+	var failures []error
+
+	for _, name := range t.names {
+		channel, base, ok := strings.Cut(name, "/")
+		if !ok {
+			channel, base = "", name
+		}
+
+		if _, err := t.Render(channel, base, samples[base], Message{}); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(failures...)
+}
+
+// Wrap returns next rendering the messages with a Template for channel before delivery.
+func (t *Templates) Wrap(channel string, next Notifier) Notifier {
+	return NotifierFunc(func(ctx context.Context, m Message) error {
+		if m.Template == "" {
+			return next.Notify(ctx, m)
+		}
+
+		m, err := t.Render(channel, m.Template, m.Data, m)
+		if err != nil {
+			return err
+		}
+
+		return next.Notify(ctx, m)
+	})
+}
+
+func titleCase(s string) string {
+	prev := ' '
+
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+
+		if unicode.IsSpace(prev) {
+			return unicode.ToTitle(r)
+		}
+
+		return r
+	}, s)
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// join joins the elements of a slice formatted with fmt.Sprint.
+func join(sep string, v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Sprint(v)
+	}
+
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+
+	return strings.Join(parts, sep)
+}
+
+// empty reports whether v is nil or the zero value, or an empty slice, map or string.
+func empty(v any) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return true
+	}
+
+	switch rv.Kind() { //nolint:exhaustive
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
+
+// coalesce returns the first non empty value.
+func coalesce(v ...any) any {
+	for _, x := range v {
+		if !empty(x) {
+			return x
+		}
+	}
+
+	return nil
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("toJson: %w", err)
+	}
+
+	return string(b), nil
+}
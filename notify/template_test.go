@@ -0,0 +1,103 @@
+package notify_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+type paymentFailed struct {
+	Customer string
+	Amount   float64
+	Errors   []string
+	At       time.Time
+}
+
+var paymentTemplates = map[string]string{
+	"payment_failed": `Payment failed for {{.Customer | title}}
+{{printf "%.2f" .Amount}} USD at {{date "15:04" .At}}: {{join ", " .Errors}}`,
+	"slack/payment_failed": `:x: Payment failed for *{{.Customer}}*
+{{.Errors | join "\n" | indent 2}}`,
+	"deploy": `Deployed {{.version | default "unknown"}}`,
+}
+
+func TestTemplates(t *testing.T) {
+	tmpl, err := notify.ParseTemplates(paymentTemplates)
+	require.NoError(t, err)
+
+	data := paymentFailed{Customer: "acme corp", Amount: 12.5, Errors: []string{"declined", "retry"}, At: testTime}
+
+	m, err := tmpl.Render("email", "payment_failed", data, notify.Message{Severity: notify.Error})
+	require.NoError(t, err)
+	assert.Equal(t, notify.Message{
+		Title: "Payment failed for Acme Corp", Text: "12.50 USD at 12:00: declined, retry", Severity: notify.Error,
+	}, m)
+
+	m, err = tmpl.Render("slack", "payment_failed", data, notify.Message{})
+	require.NoError(t, err)
+	assert.Equal(t, ":x: Payment failed for *acme corp*", m.Title)
+	assert.Equal(t, "declined\n  retry", m.Text)
+
+	m, err = tmpl.Render("", "deploy", map[string]any{"version": ""}, notify.Message{})
+	require.NoError(t, err)
+	assert.Equal(t, "Deployed unknown", m.Title)
+
+	_, err = tmpl.Render("", "deploy", map[string]any{}, notify.Message{})
+	require.ErrorContains(t, err, `map has no entry for key "version"`)
+
+	_, err = tmpl.Render("", "missing", nil, notify.Message{})
+	require.ErrorIs(t, err, notify.ErrTemplate)
+}
+
+func TestTemplatesCheck(t *testing.T) {
+	_, err := notify.ParseTemplates(map[string]string{"bad": "{{.Name"})
+	require.Error(t, err)
+
+	tmpl := notify.MustParseTemplates(paymentTemplates)
+
+	require.NoError(t, tmpl.Check(map[string]any{
+		"payment_failed": paymentFailed{Errors: []string{"x"}},
+		"deploy":         map[string]any{"version": "v1"},
+	}))
+
+	err = tmpl.Check(map[string]any{"payment_failed": map[string]any{"Customer": "x"}})
+	require.ErrorContains(t, err, "deploy: notification template:")
+	require.ErrorContains(t, err, "payment_failed: notification template:")
+	require.ErrorContains(t, err, "slack/payment_failed: notification template:")
+}
+
+func TestTemplatesWrap(t *testing.T) {
+	var slackOut, emailOut sink
+
+	tmpl := notify.MustParseTemplates(paymentTemplates)
+	slack := tmpl.Wrap("slack", &slackOut)
+	email := tmpl.Wrap("email", &emailOut)
+
+	m := notify.Message{Template: "payment_failed", Data: paymentFailed{Customer: "bob", Errors: []string{"declined"}}}
+
+	require.NoError(t, slack.Notify(context.Background(), m))
+	require.NoError(t, email.Notify(context.Background(), m))
+	require.NoError(t, email.Notify(context.Background(), notify.Message{Title: "Plain"}))
+	require.ErrorIs(t, email.Notify(context.Background(), notify.Message{Template: "nope"}), notify.ErrTemplate)
+
+	assert.Equal(t, []string{":x: Payment failed for *bob*"}, slackOut.titles())
+	assert.Equal(t, []string{"Payment failed for Bob", "Plain"}, emailOut.titles())
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	tmpl := notify.MustParseTemplates(map[string]string{
+		"funcs": `{{upper "a"}} {{lower "B"}} {{trim " c "}} {{trunc 3 "abcdef"}} {{abbrev 4 "abcdef"}}
+{{replace "a" "b" "aa"}} {{contains "b" "abc"}} {{hasPrefix "a" "abc"}} {{hasSuffix "c" "abc"}} {{quote "q"}}
+{{coalesce "" 0 "x"}} {{empty ""}} {{empty 1}} {{toJson .}}{{nindent 2 "n"}}`,
+	})
+
+	m, err := tmpl.Render("", "funcs", map[string]int{"n": 1}, notify.Message{})
+	require.NoError(t, err)
+	assert.Equal(t, "A b c abc abc…", m.Title)
+	assert.Equal(t, "bb true true true \"q\"\nx true false {\"n\":1}\n  n", m.Text)
+}
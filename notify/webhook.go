@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bir/iken/errs"
+)
+
+var (
+	// ErrSignature is returned by VerifyWebhookSignature for missing, invalid or expired webhook signatures, or without
+	// a secret to check them.
+	ErrSignature = errors.New("invalid webhook signature")
+	// ErrWebhookSecret is returned when sending a Webhook without Secret, receivers could not verify the requests.
+	ErrWebhookSecret = errors.New("webhook without secret")
+)
+
+const (
+	// WebhookSignatureHeader is the signature header of Webhook requests: "t=<unix seconds>,v1=<hex HMAC-SHA256 of
+	// "<t>.<body>">".
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// DefaultReplayWindow is the accepted age of webhook signatures when the window of VerifyWebhookSignature is 0.
+	DefaultReplayWindow = 5 * time.Minute
+	// maxWebhookBody limits the bodies read by VerifyWebhook.
+	maxWebhookBody = 1 << 20
+)
+
+// Webhook posts messages as JSON (see WebhookPayload) to a URL, signed with Secret, retrying retryable failures with
+// backoff.  Receivers check the signature with VerifyWebhook.
+type Webhook struct {
+	// URL is the destination.
+	URL string
+	// Secret signs the requests, required.
+	Secret []byte
+	// Routes overrides the URL of the messages at or above a severity.
+	Routes map[Severity]string
+	// Header is added to the requests.
+	Header http.Header
+	// MaxAttempts is the total delivery attempts, defaults to DefaultMaxAttempts, 1 disables retries.
+	MaxAttempts int
+	// BaseDelay and MaxDelay bound the jittered exponential backoff, default to DefaultRetryBase and DefaultRetryMax.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Client sends the requests, defaults to http.DefaultClient.
+	Client *http.Client
+	// Timeout bounds each attempt, within the ctx deadline, defaults to DefaultTimeout.
+	Timeout time.Duration
+}
+
+var _ Notifier = (*Webhook)(nil)
+
+// NewWebhook returns a Webhook posting to url signed with secret.
+func NewWebhook(url string, secret []byte) *Webhook {
+	return &Webhook{URL: url, Secret: secret}
+}
+
+// WebhookPayload is the JSON body of Webhook requests.
+type WebhookPayload struct {
+	Title       string    `json:"title"`
+	Text        string    `json:"text,omitempty"`
+	Severity    Severity  `json:"severity"`
+	Fields      []Field   `json:"fields,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// Notify posts m, retrying retryable failures until MaxAttempts or ctx is done.
+func (w *Webhook) Notify(ctx context.Context, m Message) error {
+	if len(w.Secret) == 0 {
+		return ErrWebhookSecret
+	}
+
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		Title: m.Title, Text: m.Text, Severity: m.Severity, Fields: m.Fields, URL: m.URL,
+		Fingerprint: m.Fingerprint, Time: m.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+
+	maxAttempts := w.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	url := route(w.Routes, m.Severity, w.URL)
+
+	for attempt := 0; ; attempt++ {
+		header := w.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+
+		header.Set(WebhookSignatureHeader, SignWebhook(w.Secret, time.Now(), body))
+
+		_, err = post(ctx, w.Client, w.Timeout, url, header, "application/json", body)
+		if err == nil || !errs.IsRetryable(err) || attempt+1 >= maxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(jitterDelay(err, attempt, w.BaseDelay, w.MaxDelay))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// SignWebhook returns the WebhookSignatureHeader value of body signed with secret at t.
+func SignWebhook(secret []byte, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(signature(secret, ts, body))
+}
+
+func signature(secret []byte, ts string, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
+
+// VerifyWebhookSignature checks that header (see SignWebhook) signs body with one of secrets (e.g. the current and previous
+// secret while rotating), at most window (DefaultReplayWindow if 0) before or after now.  Empty secrets are ignored,
+// failing without any other.  Receivers should also reject repeated Fingerprint and Time payloads within the window to
+// stop replays.
+func VerifyWebhookSignature(header string, body []byte, now time.Time, window time.Duration, secrets ...[]byte) error {
+	secrets = slices.DeleteFunc(slices.Clone(secrets), func(s []byte) bool { return len(s) == 0 })
+	if len(secrets) == 0 {
+		return fmt.Errorf("%w: no secret", ErrSignature)
+	}
+
+	if window <= 0 {
+		window = DefaultReplayWindow
+	}
+
+	var (
+		ts   string
+		sigs [][]byte
+	)
+
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			if sig, err := hex.DecodeString(v); err == nil {
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return fmt.Errorf("%w: malformed header", ErrSignature)
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > window || age < -window {
+		return fmt.Errorf("%w: timestamp outside the replay window", ErrSignature)
+	}
+
+	for _, secret := range secrets {
+		expected := signature(secret, ts, body)
+
+		for _, sig := range sigs {
+			if hmac.Equal(sig, expected) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: signature mismatch", ErrSignature)
+}
+
+// VerifyWebhook reads the body of a Webhook request and checks its signature, see VerifyWebhookSignature.
+func VerifyWebhook(r *http.Request, window time.Duration, secrets ...[]byte) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		return nil, fmt.Errorf("webhook body: %w", err)
+	}
+
+	if err = VerifyWebhookSignature(r.Header.Get(WebhookSignatureHeader), body, time.Now(), window, secrets...); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
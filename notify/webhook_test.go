@@ -0,0 +1,114 @@
+package notify_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/notify"
+)
+
+var webhookSecret = []byte("s3cret")
+
+func TestWebhook(t *testing.T) {
+	var (
+		attempts atomic.Int32
+		received []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := notify.VerifyWebhook(r, 0, []byte("old"), webhookSecret)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+
+			return
+		}
+
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		received = append(received, string(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	wh := notify.NewWebhook(srv.URL, webhookSecret)
+	wh.BaseDelay = time.Millisecond
+	wh.Header = http.Header{"X-Source": {"billing"}}
+
+	require.NoError(t, wh.Notify(context.Background(), notify.Message{
+		Title: "Payment failures", Severity: notify.Error, Fields: []notify.Field{{Name: "count", Value: "3"}},
+		Fingerprint: "f1", Time: testTime,
+	}))
+	assert.Equal(t, int32(3), attempts.Load())
+	require.Len(t, received, 1)
+	assert.JSONEq(t, `{
+		"title": "Payment failures", "severity": "error", "fields": [{"name": "count", "value": "3"}],
+		"fingerprint": "f1", "time": "2024-05-01T12:00:00Z"
+	}`, received[0])
+
+	wh.Secret = []byte("wrong")
+	wh.MaxAttempts = 1
+
+	err := wh.Notify(context.Background(), notify.Message{Title: "Forged"})
+	require.ErrorIs(t, err, notify.ErrDelivery)
+	require.ErrorContains(t, err, "status 401: invalid webhook signature: signature mismatch")
+
+	attempts.Store(0)
+	wh.Secret = webhookSecret
+
+	err = wh.Notify(context.Background(), notify.Message{Title: "No retries"})
+	require.ErrorContains(t, err, "status 503")
+	assert.Equal(t, int32(1), attempts.Load())
+
+	attempts.Store(0)
+	wh.Secret = nil
+
+	require.ErrorIs(t, wh.Notify(context.Background(), notify.Message{Title: "Unsigned"}), notify.ErrWebhookSecret)
+	assert.Zero(t, attempts.Load(), "not sent")
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"title":"x"}`)
+	header := notify.SignWebhook(webhookSecret, testTime, body)
+
+	assert.True(t, strings.HasPrefix(header, "t=1714564800,v1="))
+	require.NoError(t, notify.VerifyWebhookSignature(header, body, testTime.Add(time.Minute), 0, webhookSecret))
+
+	for name, tc := range map[string]struct {
+		header string
+		body   []byte
+		now    time.Time
+		want   string
+	}{
+		"malformed": {"v1=abc", body, testTime, "malformed header"},
+		"expired":   {header, body, testTime.Add(6 * time.Minute), "timestamp outside the replay window"},
+		"future":    {header, body, testTime.Add(-6 * time.Minute), "timestamp outside the replay window"},
+		"tampered":  {header, []byte(`{"title":"y"}`), testTime, "signature mismatch"},
+	} {
+		err := notify.VerifyWebhookSignature(tc.header, tc.body, tc.now, 0, webhookSecret)
+		require.ErrorIs(t, err, notify.ErrSignature, name)
+		require.ErrorContains(t, err, tc.want, name)
+	}
+
+	require.NoError(t, notify.VerifyWebhookSignature(header, body, testTime.Add(9*time.Minute), 10*time.Minute,
+		webhookSecret), "custom window")
+
+	unsigned := notify.SignWebhook(nil, testTime, body)
+	for name, secrets := range map[string][][]byte{"none": nil, "nil": {nil}, "empty": {{}, []byte("")}} {
+		err := notify.VerifyWebhookSignature(unsigned, body, testTime, 0, secrets...)
+		require.ErrorIs(t, err, notify.ErrSignature, name)
+		require.ErrorContains(t, err, "no secret", name)
+	}
+
+	require.NoError(t, notify.VerifyWebhookSignature(header, body, testTime, 0, nil, webhookSecret), "empty skipped")
+}
@@ -0,0 +1,488 @@
+package params
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+const (
+	// TagName is the struct tag naming the parameter bound to a field by Bind.
+	TagName = "param"
+	// QueryTag is the struct tag naming the query parameter bound to a field by BindQuery.
+	QueryTag = "query"
+)
+
+var (
+	// ErrInvalidTarget is returned when the bind target is not a pointer to a struct.
+	ErrInvalidTarget = errors.New("bind target must be a pointer to a struct")
+	// ErrUnsupportedType is returned when a tagged field has a type that can not be bound.
+	ErrUnsupportedType = errors.New("unsupported parameter type")
+	// ErrInvalidTag is returned for invalid tag options.
+	ErrInvalidTag = errors.New("invalid parameter tag")
+)
+
+// Bind populates the fields of dst tagged with `param:"name"` using the same lookup as GetString (path value, then
+// query, then header).  Supported field types are strings, bools, integers, floats, time.Time (see BindTimeParser and
+// the format tag option), uuid.UUID,
+// encoding.TextUnmarshaler implementations, pointers to these (left nil if absent), Opt of these, and slices of these
+// from comma separated values (see MaxSliceItems).  Absent parameters leave the field unchanged, unless a default is
+// given by a `default:"value"` tag or Defaulter.
+//
+// Conversion failures are reported as *validation.Errors keyed by the parameter name, so they can be merged with
+// struct validation failures.
+func Bind(r *http.Request, dst any) error {
+	return binder{ctx: r.Context(), tag: TagName, lookup: func(name string) []string {
+		s, ok, _ := GetString(r, name, false)
+		if !ok {
+			return nil
+		}
+
+		return []string{s}
+	}, lookupOpt: func(name string) []string {
+		s, ok := lookupPresent(r, name)
+		if !ok {
+			return nil
+		}
+
+		return []string{s}
+	}}.bind(dst)
+}
+
+// BindQuery populates the fields of dst tagged with `query:"name"` from the query parameters of r.  Field types are
+// the same as Bind, slices are populated from repeated (?id=1&id=2) and comma separated (?id=1,2) parameters.  Absent
+// parameters leave the field unchanged, conversion failures are reported as *validation.Errors keyed by the parameter
+// name.  Undeclared parameters are handled as configured by UnknownQueryParams.
+func BindQuery(r *http.Request, dst any) error {
+	query := r.URL.Query()
+
+	return binder{
+		ctx:    r.Context(),
+		tag:    QueryTag,
+		lookup: func(name string) []string { return query[name] },
+		check:  func(t reflect.Type, ee *validation.Errors) { checkUnknown(r, query, t, ee) },
+	}.bind(dst)
+}
+
+// binder populates fields tagged with tag from the values returned by lookup.  lookupOpt, if set, is used for Opt
+// fields to report empty values as present.  files, if set, provides multipart files.  Applied defaults are logged
+// to ctx, see LogAppliedDefaults.
+type binder struct {
+	ctx       context.Context //nolint:containedctx
+	tag       string
+	lookup    func(name string) []string
+	lookupOpt func(name string) []string
+	files     func(name string) []*multipart.FileHeader
+	// check, if set, reports additional failures for the target type, e.g. undeclared parameters.
+	check func(t reflect.Type, ee *validation.Errors)
+}
+
+func (b binder) bind(dst any) error {
+	val := reflect.ValueOf(dst)
+	if val.Kind() != reflect.Pointer || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	var (
+		ee       validation.Errors
+		defaults map[string]string
+	)
+
+	if d, ok := dst.(Defaulter); ok {
+		defaults = d.ParamDefaults()
+	}
+
+	if err := b.bindStruct(val.Elem(), defaults, &ee); err != nil {
+		return err
+	}
+
+	if b.check != nil {
+		b.check(val.Elem().Type(), &ee)
+	}
+
+	return ee.GetErr()
+}
+
+func (b binder) bindStruct(val reflect.Value, defaults map[string]string, ee *validation.Errors) error {
+	t := val.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		// Promoted fields of unexported embedded structs are settable, as with encoding/json
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if err := b.bindStruct(val.Field(i), defaults, ee); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(sf.Tag.Get(b.tag), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if err := b.bindField(val.Field(i), sf, name, opts, defaults, ee); err != nil {
+			return fmt.Errorf("%s.%s: %w", t.Name(), sf.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// bindField sets field from the parameter name, or its default if absent.  Conversion failures are added to ee, the
+// returned errors are programming errors, e.g. ErrUnsupportedType or an invalid default.
+func (b binder) bindField(field reflect.Value, sf reflect.StructField, name, opts string, defaults map[string]string,
+	ee *validation.Errors,
+) error {
+	if isFile(field.Type()) {
+		if b.files == nil {
+			return fmt.Errorf("%w: %s", ErrUnsupportedType, field.Type())
+		}
+
+		if files := b.files(name); len(files) > 0 {
+			return setFiles(field, name, opts, files, ee)
+		}
+
+		return nil
+	}
+
+	opt, isOpt := field.Addr().Interface().(optional)
+
+	lookup := b.lookup
+	if isOpt && b.lookupOpt != nil {
+		lookup = b.lookupOpt
+	}
+
+	values := lookup(name)
+	if len(values) == 0 {
+		def, ok := defaults[name]
+		if !ok {
+			def, ok = sf.Tag.Lookup(DefaultTag)
+		}
+
+		if !ok {
+			return nil
+		}
+
+		var failed validation.Errors
+
+		if err := b.setField(field, opt, isOpt, name, opts, []string{def}, &failed); err != nil {
+			return err
+		}
+
+		if len(failed) > 0 {
+			return fmt.Errorf("%w: default %q: %w", ErrInvalidTag, def, &failed)
+		}
+
+		b.logDefault(name, def)
+
+		return nil
+	}
+
+	return b.setField(field, opt, isOpt, name, opts, values, ee)
+}
+
+func (b binder) setField(field reflect.Value, opt optional, isOpt bool, name, opts string, values []string,
+	ee *validation.Errors,
+) error {
+	c, err := fieldConverter(name, opts)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case isOpt:
+		err = opt.setParam(c, values[0])
+	case isSlice(field.Type()):
+		return setSlice(c, field, opts, values, ee)
+	default:
+		err = c.setValue(field, values[0])
+	}
+
+	if err != nil && !errors.Is(err, ErrUnsupportedType) {
+		ee.Add(name, err)
+
+		return nil
+	}
+
+	return err
+}
+
+// MaxSliceItems limits the number of elements bound to a slice, overridden per field with the max tag option, e.g.
+// `query:"id,max=500"`.
+var MaxSliceItems = 100
+
+// setSlice binds repeated (?id=1&id=2) and comma separated (?id=1,2) values to field, empty elements are ignored.
+// Conversion failures are reported per element, e.g. "id[2]".  The field is unchanged if any element fails.
+func setSlice(c converter, field reflect.Value, opts string, values []string, ee *validation.Errors) error {
+	name := c.param
+
+	maxItems, err := maxItemsOption(opts)
+	if err != nil {
+		return err
+	}
+
+	items := make([]string, 0, len(values))
+
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	if len(items) > maxItems {
+		ee.Add(name, validation.RuleError{
+			Rule:    "max",
+			Param:   strconv.Itoa(maxItems),
+			Message: fmt.Sprintf("must contain at most %d items", maxItems),
+			Key:     "max.collection",
+		})
+
+		return nil
+	}
+
+	out := reflect.MakeSlice(field.Type(), len(items), len(items))
+	failed := false
+
+	for i, item := range items {
+		if err := c.setValue(out.Index(i), item); err != nil {
+			if errors.Is(err, ErrUnsupportedType) {
+				return err
+			}
+
+			ee.Add(fmt.Sprintf("%s[%d]", name, i), err)
+
+			failed = true
+		}
+	}
+
+	if !failed {
+		field.Set(out)
+	}
+
+	return nil
+}
+
+func maxItemsOption(opts string) (int, error) {
+	for _, opt := range strings.Split(opts, ",") {
+		if value, ok := strings.CutPrefix(opt, "max="); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("%w: max=%q", ErrInvalidTag, value)
+			}
+
+			return n, nil
+		}
+	}
+
+	return MaxSliceItems, nil
+}
+
+// Slice returns the query parameter name as a []T, accepting repeated and comma separated values, see BindQuery.
+// maxItems limits the number of elements, 0 uses MaxSliceItems.  Invalid values return *validation.Errors coded
+// errs.InvalidArgument.
+func Slice[T any](r *http.Request, name string, maxItems int) ([]T, error) {
+	if maxItems <= 0 {
+		maxItems = MaxSliceItems
+	}
+
+	values := r.URL.Query()[name]
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	var (
+		out []T
+		ee  validation.Errors
+	)
+
+	err := setSlice(newConverter(name), reflect.ValueOf(&out).Elem(), "max="+strconv.Itoa(maxItems), values, &ee)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ee) > 0 {
+		return nil, errs.WithCode(&ee, errs.InvalidArgument)
+	}
+
+	return out, nil
+}
+
+// isSlice reports if t is bound from multiple values, []byte, Unmarshaler and TextUnmarshaler implementations are
+// bound from a single value.
+func isSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 &&
+		!reflect.PointerTo(t).Implements(textUnmarshalerType) && !reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	uuidType            = reflect.TypeOf(uuid.UUID{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
+// BindTimeParser parses the time.Time fields bound by Bind, BindQuery, BindForm and Path, failures are reported as
+// *TimeError.  The format tag option overrides its Formats per field, e.g. `query:"since,format=date|unix"`.
+var BindTimeParser = TimeParser{Formats: []TimeFormat{TimeRFC3339}}
+
+// timeFormats are the names of the format tag option.
+var timeFormats = map[string]TimeFormat{
+	"rfc3339":   TimeRFC3339,
+	"date":      TimeDate,
+	"unix":      TimeUnix,
+	"unixmilli": TimeUnixMilli,
+}
+
+// converter converts the values of the parameter param.
+type converter struct {
+	param string
+	time  TimeParser
+}
+
+func newConverter(param string) converter {
+	return converter{param: param, time: BindTimeParser}
+}
+
+// fieldConverter returns the converter of the parameter name with the tag options opts.
+func fieldConverter(name, opts string) (converter, error) {
+	c := newConverter(name)
+
+	for _, opt := range strings.Split(opts, ",") {
+		value, ok := strings.CutPrefix(opt, "format=")
+		if !ok {
+			continue
+		}
+
+		c.time.Formats = nil
+
+		for _, fname := range strings.Split(value, "|") {
+			f, ok := timeFormats[fname]
+			if !ok {
+				return c, fmt.Errorf("%w: format=%q", ErrInvalidTag, value)
+			}
+
+			c.time.Formats = append(c.time.Formats, f)
+		}
+	}
+
+	return c, nil
+}
+
+// setValue converts s to the type of field, failures are reported as client facing validation.Error messages.
+func (c converter) setValue(field reflect.Value, s string) error {
+	if field.Kind() == reflect.Pointer {
+		v := reflect.New(field.Type().Elem())
+		if err := c.setValue(v.Elem(), s); err != nil {
+			return err
+		}
+
+		field.Set(v)
+
+		return nil
+	}
+
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(Unmarshaler); ok {
+			return unmarshalParam(u, s)
+		}
+	}
+
+	switch field.Type() {
+	case timeType:
+		ts, err := c.time.Parse(c.param, s)
+		if err != nil {
+			return err
+		}
+
+		field.Set(reflect.ValueOf(ts))
+
+		return nil
+	case uuidType:
+		id, err := DefaultUUIDParser.Parse("", s)
+		if err != nil {
+			return err
+		}
+
+		field.Set(reflect.ValueOf(id))
+
+		return nil
+	}
+
+	if field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := u.UnmarshalText([]byte(s)); err != nil {
+				return validation.Error{Message: "is invalid", Source: err}
+			}
+
+			return nil
+		}
+	}
+
+	return setKind(field, s)
+}
+
+func setKind(field reflect.Value, s string) error {
+	switch field.Kind() { //nolint:exhaustive
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return validation.Error{Message: "must be a boolean", Source: err}
+		}
+
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, field.Type().Bits())
+		if err != nil {
+			return validation.Error{Message: "must be an integer", Source: err}
+		}
+
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, field.Type().Bits())
+		if err != nil {
+			return validation.Error{Message: "must be a positive integer", Source: err}
+		}
+
+		field.SetUint(u)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("%w: %s", ErrUnsupportedType, field.Type())
+		}
+
+		field.SetBytes([]byte(s))
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			return validation.Error{Message: "must be a number", Source: err}
+		}
+
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, field.Type())
+	}
+
+	return nil
+}
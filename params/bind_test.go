@@ -0,0 +1,271 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+type level int
+
+func (l *level) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return assert.AnError
+	}
+
+	return nil
+}
+
+type Paging struct {
+	Limit int `param:"limit"`
+}
+
+type Search struct {
+	Paging
+	ID     uuid.UUID `param:"id"`
+	Query  string    `param:"q"`
+	Active *bool     `param:"active"`
+	Since  time.Time `param:"since"`
+	Score  float64   `param:"score"`
+	Tags   []string  `param:"tags"`
+	IDs    []int64   `param:"ids"`
+	Level  level     `param:"level"`
+	Tenant string    `param:"X-Tenant"`
+	Other  string
+}
+
+func TestBind(t *testing.T) {
+	id := uuid.New()
+
+	r := httptest.NewRequest(http.MethodGet, "/items/"+id.String()+
+		"?q=shoes&active=true&since=2024-01-02T03:04:05Z&score=1.5&tags=a,b&ids=1,2&level=high&limit=10", nil)
+	r.SetPathValue("id", id.String())
+	r.Header.Set("X-Tenant", "acme")
+
+	var s Search
+
+	require.NoError(t, Bind(r, &s))
+
+	active := true
+
+	assert.Equal(t, Search{
+		Paging: Paging{Limit: 10},
+		ID:     id,
+		Query:  "shoes",
+		Active: &active,
+		Since:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Score:  1.5,
+		Tags:   []string{"a", "b"},
+		IDs:    []int64{1, 2},
+		Level:  2,
+		Tenant: "acme",
+	}, s)
+
+	s = Search{Query: "default"}
+
+	require.NoError(t, Bind(httptest.NewRequest(http.MethodGet, "/", nil), &s))
+	assert.Equal(t, Search{Query: "default"}, s)
+}
+
+func TestBindErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet,
+		"/?id=x&active=maybe&since=today&score=high&ids=1,x&level=mid&limit=ten", nil)
+
+	var (
+		s  Search
+		ee *validation.Errors
+	)
+
+	require.ErrorAs(t, Bind(r, &s), &ee)
+	assert.Equal(t, map[string][]string{
+		"id":     {"must be a valid UUID"},
+		"active": {"must be a boolean"},
+		"since":  {"must be a time in one of the formats: RFC 3339"},
+		"score":  {"must be a number"},
+		"ids[1]": {"must be an integer"},
+		"level":  {"is invalid"},
+		"limit":  {"must be an integer"},
+	}, ee.Fields())
+
+	assert.ErrorIs(t, Bind(r, s), ErrInvalidTarget)
+	assert.ErrorIs(t, Bind(r, &struct {
+		C chan int `param:"id"`
+	}{}), ErrUnsupportedType)
+}
+
+type ListQuery struct {
+	Limit  *int        `query:"limit"`
+	Offset uint        `query:"offset"`
+	Status []string    `query:"status"`
+	IDs    []uuid.UUID `query:"id"`
+	Min    float32     `query:"min"`
+	Since  *time.Time  `query:"since"`
+	Raw    []byte      `query:"raw"`
+	Name   string      `param:"name"`
+}
+
+func TestBindQuery(t *testing.T) {
+	id1, id2 := uuid.New(), uuid.New()
+
+	r := httptest.NewRequest(http.MethodGet, "/?limit=5&offset=10&status=open&status=closed&id="+id1.String()+
+		"&id="+id2.String()+"&min=0.5&raw=abc&name=ignored", nil)
+
+	var q ListQuery
+
+	require.NoError(t, BindQuery(r, &q))
+
+	limit := 5
+
+	assert.Equal(t, ListQuery{
+		Limit:  &limit,
+		Offset: 10,
+		Status: []string{"open", "closed"},
+		IDs:    []uuid.UUID{id1, id2},
+		Min:    0.5,
+		Raw:    []byte("abc"),
+	}, q)
+
+	var ee *validation.Errors
+
+	r = httptest.NewRequest(http.MethodGet, "/?limit=x&offset=-1&id=1&min=a&since=yesterday", nil)
+	require.ErrorAs(t, BindQuery(r, &ListQuery{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"limit":  {"must be an integer"},
+		"offset": {"must be a positive integer"},
+		"id[0]":  {"must be a valid UUID"},
+		"min":    {"must be a number"},
+		"since":  {"must be a time in one of the formats: RFC 3339"},
+	}, ee.Fields())
+}
+
+type SliceQuery struct {
+	IDs  []int    `query:"id,max=3"`
+	Tags []string `query:"tag"`
+}
+
+func TestBindQuerySlices(t *testing.T) {
+	var q SliceQuery
+
+	r := httptest.NewRequest(http.MethodGet, "/?id=1,2&id=3&tag=a,,b", nil)
+	require.NoError(t, BindQuery(r, &q))
+	assert.Equal(t, SliceQuery{IDs: []int{1, 2, 3}, Tags: []string{"a", "b"}}, q)
+
+	var ee *validation.Errors
+
+	r = httptest.NewRequest(http.MethodGet, "/?id=1,x&id=y", nil)
+	require.ErrorAs(t, BindQuery(r, &SliceQuery{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"id[1]": {"must be an integer"},
+		"id[2]": {"must be an integer"},
+	}, ee.Fields())
+
+	r = httptest.NewRequest(http.MethodGet, "/?id=1,2,3,4", nil)
+	require.ErrorAs(t, BindQuery(r, &SliceQuery{}), &ee)
+	assert.Equal(t, map[string][]string{"id": {"must contain at most 3 items"}}, ee.Fields())
+
+	defer func(n int) { MaxSliceItems = n }(MaxSliceItems)
+
+	MaxSliceItems = 1
+
+	r = httptest.NewRequest(http.MethodGet, "/?tag=a,b", nil)
+	require.ErrorAs(t, BindQuery(r, &SliceQuery{}), &ee)
+	assert.Equal(t, map[string][]string{"tag": {"must contain at most 1 items"}}, ee.Fields())
+
+	r = httptest.NewRequest(http.MethodGet, "/?id=1", nil)
+	assert.ErrorIs(t, BindQuery(r, &struct {
+		IDs []int `query:"id,max=x"`
+	}{}), ErrInvalidTag)
+}
+
+type TimeQuery struct {
+	Since time.Time      `query:"since,format=date|unix"`
+	Until *time.Time     `query:"until"`
+	At    Opt[time.Time] `query:"at,format=unixmilli"`
+	Days  []time.Time    `query:"day,format=date"`
+}
+
+func TestBindQueryTimes(t *testing.T) {
+	var q TimeQuery
+
+	r := httptest.NewRequest(http.MethodGet,
+		"/?since=2024-03-01&until=2024-03-02T10:00:00Z&at=1709287200000&day=2024-03-01,2024-03-02", nil)
+	require.NoError(t, BindQuery(r, &q))
+
+	until := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), q.Since)
+	assert.Equal(t, &until, q.Until)
+	assert.True(t, q.At.Value.Equal(time.UnixMilli(1709287200000)))
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+	}, q.Days)
+
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, "/?since=1709251200", nil), &q))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), q.Since)
+
+	r = httptest.NewRequest(http.MethodGet, "/?since=2024-03-01T00:00:00Z&until=2024-03-01&day=x", nil)
+
+	err := BindQuery(r, &TimeQuery{})
+
+	var (
+		ee *validation.Errors
+		te *TimeError
+	)
+
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{
+		"since":  {"must be a time in one of the formats: date (YYYY-MM-DD), Unix seconds"},
+		"until":  {"must be a time in one of the formats: RFC 3339"},
+		"day[0]": {"must be a time in one of the formats: date (YYYY-MM-DD)"},
+	}, ee.Fields())
+	require.ErrorAs(t, (*ee)["day[0]"][0], &te)
+	assert.Equal(t, "day", te.Param)
+	assert.Equal(t, "x", te.Value)
+
+	defer func(p TimeParser) { BindTimeParser = p }(BindTimeParser)
+
+	BindTimeParser = DefaultTimeParser
+
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, "/?until=2024-03-01", nil), &q))
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *q.Until)
+
+	assert.ErrorIs(t, BindQuery(httptest.NewRequest(http.MethodGet, "/?since=1", nil), &struct {
+		Since time.Time `query:"since,format=iso"`
+	}{}), ErrInvalidTag)
+}
+
+func TestSlice(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?id=1,2&id=3", nil)
+
+	ids, err := Slice[int64](r, "id", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+
+	ids, err = Slice[int64](r, "missing", 0)
+	require.NoError(t, err)
+	assert.Nil(t, ids)
+
+	_, err = Slice[int64](r, "id", 2)
+	code, _ := errs.GetCode(err)
+	assert.Equal(t, errs.InvalidArgument, code)
+
+	var ee *validation.Errors
+
+	r = httptest.NewRequest(http.MethodGet, "/?id=1,a", nil)
+	_, err = Slice[int64](r, "id", 0)
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{"id[1]": {"must be an integer"}}, ee.Fields())
+}
@@ -0,0 +1,28 @@
+package params
+
+import (
+	"github.com/bir/iken/logctx"
+)
+
+// DefaultTag is the struct tag giving the value bound to a field when its parameter is absent, e.g.
+// `query:"limit" default:"25"`.  Defaults are not applied to parameters that fail parsing.
+const DefaultTag = "default"
+
+// LogParamDefaults prefixes the log context keys of applied defaults, e.g. "param.defaults.limit".
+const LogParamDefaults = "param.defaults"
+
+// LogAppliedDefaults enables logging the defaults applied by Bind, BindQuery and BindPath to the request log context,
+// for debugging.
+var LogAppliedDefaults = false
+
+// Defaulter is implemented by bind targets providing defaults programmatically, keyed by parameter name.  These take
+// precedence over default tags.
+type Defaulter interface {
+	ParamDefaults() map[string]string
+}
+
+func (b binder) logDefault(name, value string) {
+	if LogAppliedDefaults && b.ctx != nil {
+		logctx.AddStrToContext(b.ctx, LogParamDefaults+"."+name, value)
+	}
+}
@@ -0,0 +1,79 @@
+package params
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/logctx"
+	"github.com/bir/iken/validation"
+)
+
+type DefaultQuery struct {
+	Limit  int         `query:"limit" default:"25"`
+	Status []string    `query:"status" default:"open,pending"`
+	Sort   Opt[string] `query:"sort" default:"name"`
+	Owner  string      `query:"owner"`
+}
+
+type programmaticDefaults struct {
+	DefaultQuery
+}
+
+func (programmaticDefaults) ParamDefaults() map[string]string {
+	return map[string]string{"limit": "50", "owner": "me"}
+}
+
+func TestBindDefaults(t *testing.T) {
+	var q DefaultQuery
+
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, "/", nil), &q))
+	assert.Equal(t, DefaultQuery{Limit: 25, Status: []string{"open", "pending"}, Sort: Some("name")}, q)
+
+	q = DefaultQuery{}
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, "/?limit=10&status=closed&sort=", nil), &q))
+	assert.Equal(t, DefaultQuery{Limit: 10, Status: []string{"closed"}, Sort: Opt[string]{Present: true, Empty: true}}, q)
+
+	var ee *validation.Errors
+
+	q = DefaultQuery{}
+	require.ErrorAs(t, BindQuery(httptest.NewRequest(http.MethodGet, "/?limit=x", nil), &q), &ee)
+	assert.Equal(t, map[string][]string{"limit": {"must be an integer"}}, ee.Fields())
+	assert.Equal(t, 0, q.Limit, "defaults are not applied to invalid values")
+
+	var p programmaticDefaults
+
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, "/", nil), &p))
+	assert.Equal(t, 50, p.Limit)
+	assert.Equal(t, "me", p.Owner)
+	assert.Equal(t, []string{"open", "pending"}, p.Status)
+
+	assert.ErrorIs(t, BindQuery(httptest.NewRequest(http.MethodGet, "/", nil), &struct {
+		Limit int `query:"limit" default:"many"`
+	}{}), ErrInvalidTag)
+}
+
+func TestLogAppliedDefaults(t *testing.T) {
+	defer func(b bool) { LogAppliedDefaults = b }(LogAppliedDefaults)
+
+	LogAppliedDefaults = true
+
+	logBuffer := bytes.NewBuffer(nil)
+	ctx := logctx.NewSubLoggerContext(context.Background(), zerolog.New(logBuffer))
+	r := httptest.NewRequest(http.MethodGet, "/?status=closed", nil).WithContext(ctx)
+
+	var q DefaultQuery
+
+	require.NoError(t, BindQuery(r, &q))
+
+	zerolog.Ctx(ctx).Log().Msg("request")
+
+	assert.Equal(t, `{"param.defaults.limit":"25","param.defaults.sort":"name","message":"request"}
+`, logBuffer.String())
+}
@@ -0,0 +1,97 @@
+package params
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// EnumSet is the allowed values of a string enum parameter, matched case-insensitively by default.
+type EnumSet[T ~string] struct {
+	values        []T
+	aliases       map[string]T
+	caseSensitive bool
+}
+
+// NewEnumSet creates the set of allowed values.
+func NewEnumSet[T ~string](allowed ...T) *EnumSet[T] {
+	return &EnumSet[T]{values: allowed, aliases: map[string]T{}}
+}
+
+// WithAlias accepts alias as an alternate spelling of value, e.g. "cancelled" for "canceled".  Aliases are not listed
+// in errors.
+func (s *EnumSet[T]) WithAlias(alias string, value T) *EnumSet[T] {
+	s.aliases[alias] = value
+
+	return s
+}
+
+// WithCaseSensitive requires exact matches.
+func (s *EnumSet[T]) WithCaseSensitive(caseSensitive bool) *EnumSet[T] {
+	s.caseSensitive = caseSensitive
+
+	return s
+}
+
+func (s *EnumSet[T]) equal(a, b string) bool {
+	if s.caseSensitive {
+		return a == b
+	}
+
+	return strings.EqualFold(a, b)
+}
+
+// Parse returns the allowed value matching v.  Invalid values return a validation.RuleError listing the allowed
+// values.
+func (s *EnumSet[T]) Parse(v string) (T, error) {
+	for _, allowed := range s.values {
+		if s.equal(string(allowed), v) {
+			return allowed, nil
+		}
+	}
+
+	for alias, value := range s.aliases {
+		if s.equal(alias, v) {
+			return value, nil
+		}
+	}
+
+	names := make([]string, len(s.values))
+	for i, allowed := range s.values {
+		names[i] = string(allowed)
+	}
+
+	var zero T
+
+	return zero, validation.RuleError{
+		Rule:    "oneof",
+		Param:   strings.Join(names, " "),
+		Message: "must be one of: " + strings.Join(names, ", "),
+	}
+}
+
+// Enum returns the parameter name (see GetString) matched case-insensitively against allowed.  ok is false if the
+// parameter is absent.  Invalid values return *validation.Errors coded errs.InvalidArgument, listing the allowed
+// values.
+func Enum[T ~string](r *http.Request, name string, allowed ...T) (T, bool, error) {
+	return EnumOf(r, name, NewEnumSet(allowed...))
+}
+
+// EnumOf returns the parameter name matched against set, see Enum.
+func EnumOf[T ~string](r *http.Request, name string, set *EnumSet[T]) (T, bool, error) {
+	var zero T
+
+	s, ok, _ := GetString(r, name, false)
+	if !ok {
+		return zero, false, nil
+	}
+
+	v, err := set.Parse(s)
+	if err != nil {
+		return zero, false, errs.WithCode(validation.New(name, err), errs.InvalidArgument)
+	}
+
+	return v, true, nil
+}
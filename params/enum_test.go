@@ -0,0 +1,65 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+type status string
+
+const (
+	statusOpen     status = "open"
+	statusCanceled status = "canceled"
+)
+
+func TestEnumSet(t *testing.T) {
+	set := NewEnumSet(statusOpen, statusCanceled).WithAlias("cancelled", statusCanceled)
+
+	tests := []struct {
+		name    string
+		query   string
+		set     *EnumSet[status]
+		want    status
+		wantOk  bool
+		wantErr string
+	}{
+		{"exact", "?status=open", set, statusOpen, true, ""},
+		{"case insensitive", "?status=OPEN", set, statusOpen, true, ""},
+		{"alias", "?status=Cancelled", set, statusCanceled, true, ""},
+		{"absent", "", set, "", false, ""},
+		{"invalid", "?status=closed", set, "", false, "must be one of: open, canceled"},
+		{"case sensitive", "?status=OPEN", NewEnumSet(statusOpen).WithCaseSensitive(true), "", false, "must be one of: open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := EnumOf(httptest.NewRequest(http.MethodGet, "/"+tt.query, nil), "status", tt.set)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var ee *validation.Errors
+
+			assert.ErrorAs(t, err, &ee)
+			assert.Equal(t, map[string][]string{"status": {tt.wantErr}}, ee.Fields())
+			assert.Equal(t, http.StatusBadRequest, errs.Status(err))
+		})
+	}
+
+	got, ok, err := Enum(httptest.NewRequest(http.MethodGet, "/?status=Open", nil), "status", statusOpen)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, statusOpen, got)
+}
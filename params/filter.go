@@ -0,0 +1,185 @@
+package params
+
+import (
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// FilterParam is the query parameter parsed by Filters.
+const FilterParam = "filter"
+
+// FilterOp is a filter comparison operator.
+type FilterOp string
+
+// Filter operators.  OpIn matches any of a "|" separated list of values, OpContains is a substring match.
+const (
+	OpEq       FilterOp = "eq"
+	OpNe       FilterOp = "ne"
+	OpGt       FilterOp = "gt"
+	OpGte      FilterOp = "gte"
+	OpLt       FilterOp = "lt"
+	OpLte      FilterOp = "lte"
+	OpIn       FilterOp = "in"
+	OpContains FilterOp = "contains"
+)
+
+// FilterField declares a filterable field.
+type FilterField struct {
+	// Ops are the operators allowed for the field.
+	Ops []FilterOp
+	// Parse converts values to their type, e.g. FilterInt.  nil keeps values as strings.
+	Parse func(string) (any, error)
+}
+
+// FilterFields is the allowlist of filterable fields by name.
+type FilterFields map[string]FilterField
+
+// Filter is a parsed filter condition, Values holds a single value except for OpIn.
+type Filter struct {
+	Field  string
+	Op     FilterOp
+	Values []any
+}
+
+// Value returns the first value, the operand of every operator except OpIn.
+func (f Filter) Value() any {
+	if len(f.Values) == 0 {
+		return nil
+	}
+
+	return f.Values[0]
+}
+
+// Filters parses the filter query parameter, a comma separated list of field:operator:value conditions, e.g.
+// ?filter=status:in:active|pending,amount:gte:100.  Values may contain ":", but not ",".  Conditions are returned in
+// order, intended to be combined with AND.  Returns nil if the parameter is absent.  Unknown fields, disallowed
+// operators and invalid values return *validation.Errors coded errs.InvalidArgument.
+func Filters(r *http.Request, fields FilterFields) ([]Filter, error) {
+	s := r.URL.Query().Get(FilterParam)
+	if s == "" {
+		return nil, nil
+	}
+
+	var (
+		out []Filter
+		ee  validation.Errors
+	)
+
+	for _, item := range strings.Split(s, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		f, err := parseFilter(item, fields)
+		if err != nil {
+			ee.Add(FilterParam, err)
+
+			continue
+		}
+
+		out = append(out, f)
+	}
+
+	if len(ee) > 0 {
+		return nil, errs.WithCode(&ee, errs.InvalidArgument)
+	}
+
+	return out, nil
+}
+
+func parseFilter(item string, fields FilterFields) (Filter, error) {
+	parts := strings.SplitN(item, ":", 3) //nolint:mnd
+	if len(parts) != 3 || parts[2] == "" {
+		return Filter{}, validation.Error{Message: "must be field:operator:value, got " + strconv.Quote(item)}
+	}
+
+	name, op := parts[0], FilterOp(parts[1])
+
+	field, ok := fields[name]
+	if !ok {
+		names := slices.Sorted(maps.Keys(fields))
+
+		return Filter{}, validation.Error{
+			Message: "can not filter by " + name + ", must be one of: " + strings.Join(names, ", "),
+		}
+	}
+
+	if !slices.Contains(field.Ops, op) {
+		ops := make([]string, len(field.Ops))
+		for i, o := range field.Ops {
+			ops[i] = string(o)
+		}
+
+		return Filter{}, validation.Error{
+			Message: name + ": operator " + string(op) + " is not allowed, must be one of: " + strings.Join(ops, ", "),
+		}
+	}
+
+	raw := []string{parts[2]}
+	if op == OpIn {
+		raw = strings.Split(parts[2], "|")
+	}
+
+	f := Filter{Field: name, Op: op, Values: make([]any, len(raw))}
+
+	for i, s := range raw {
+		if field.Parse == nil {
+			f.Values[i] = s
+
+			continue
+		}
+
+		v, err := field.Parse(s)
+		if err != nil {
+			message := err.Error()
+
+			var ve validation.Error
+			if errors.As(err, &ve) {
+				message = ve.UserError()
+			}
+
+			return Filter{}, validation.Error{Message: name + ": " + message, Source: err}
+		}
+
+		f.Values[i] = v
+	}
+
+	return f, nil
+}
+
+// FilterInt parses int64 filter values.
+func FilterInt(s string) (any, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, validation.Error{Message: "must be an integer", Source: err}
+	}
+
+	return i, nil
+}
+
+// FilterFloat parses float64 filter values.
+func FilterFloat(s string) (any, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, validation.Error{Message: "must be a number", Source: err}
+	}
+
+	return f, nil
+}
+
+// FilterBool parses bool filter values.
+func FilterBool(s string) (any, error) {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil, validation.Error{Message: "must be a boolean", Source: err}
+	}
+
+	return b, nil
+}
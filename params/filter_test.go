@@ -0,0 +1,64 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+var testFilterFields = FilterFields{
+	"status":  {Ops: []FilterOp{OpEq, OpIn}},
+	"amount":  {Ops: []FilterOp{OpGte, OpLt}, Parse: FilterFloat},
+	"count":   {Ops: []FilterOp{OpEq}, Parse: FilterInt},
+	"active":  {Ops: []FilterOp{OpEq}, Parse: FilterBool},
+	"created": {Ops: []FilterOp{OpGt}},
+}
+
+func filterRequest(filter string) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/?filter="+url.QueryEscape(filter), nil)
+}
+
+func TestFilters(t *testing.T) {
+	filters, err := Filters(filterRequest(
+		"status:in:active|pending,amount:gte:100,count:eq:3,active:eq:true,created:gt:2024-01-01T00:00:00Z"),
+		testFilterFields)
+	require.NoError(t, err)
+	assert.Equal(t, []Filter{
+		{Field: "status", Op: OpIn, Values: []any{"active", "pending"}},
+		{Field: "amount", Op: OpGte, Values: []any{100.0}},
+		{Field: "count", Op: OpEq, Values: []any{int64(3)}},
+		{Field: "active", Op: OpEq, Values: []any{true}},
+		{Field: "created", Op: OpGt, Values: []any{"2024-01-01T00:00:00Z"}},
+	}, filters)
+	assert.Equal(t, 100.0, filters[1].Value())
+	assert.Nil(t, Filter{}.Value())
+
+	filters, err = Filters(httptest.NewRequest(http.MethodGet, "/", nil), testFilterFields)
+	require.NoError(t, err)
+	assert.Nil(t, filters)
+}
+
+func TestFiltersErrors(t *testing.T) {
+	_, err := Filters(filterRequest("status:gt:a,email:eq:x,amount:gte:lots,status,count:eq:"), testFilterFields)
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{FilterParam: {
+		"status: operator gt is not allowed, must be one of: eq, in",
+		"can not filter by email, must be one of: active, amount, count, created, status",
+		"amount: must be a number",
+		`must be field:operator:value, got "status"`,
+		`must be field:operator:value, got "count:eq:"`,
+	}}, ee.Fields())
+
+	code, _ := errs.GetCode(err)
+	assert.Equal(t, errs.InvalidArgument, code)
+}
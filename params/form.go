@@ -0,0 +1,188 @@
+package params
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bir/iken/config"
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// FormTag is the struct tag naming the form field bound to a field by BindForm.
+const FormTag = "form"
+
+// MaxFormMemory is the maximum bytes of multipart forms held in memory, larger files are stored in temporary files,
+// see http.Request.ParseMultipartForm.
+var MaxFormMemory int64 = 32 << 20
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// BindForm populates the fields of dst tagged with `form:"name"` from an application/x-www-form-urlencoded or
+// multipart/form-data body.  Field types are the same as BindQuery, multipart files are bound to *multipart.FileHeader
+// and []*multipart.FileHeader fields, constrained by the maxsize (see config.ParseByteSize, e.g. 512KiB or 2MB) and
+// type tag options, e.g. `form:"avatar,maxsize=2MB,type=image/png|image/*"`.  Requests with other content types are ignored.
+//
+// Malformed bodies return an error coded errs.InvalidArgument, conversion and constraint failures are reported as
+// *validation.Errors keyed by the field name.
+func BindForm(r *http.Request, dst any) error {
+	var files map[string][]*multipart.FileHeader
+
+	switch {
+	case isMultipart(r):
+		if err := r.ParseMultipartForm(MaxFormMemory); err != nil {
+			return errs.WithCode(fmt.Errorf("parse multipart form: %w", err), errs.InvalidArgument)
+		}
+
+		files = r.MultipartForm.File
+	case isForm(r):
+		if err := r.ParseForm(); err != nil {
+			return errs.WithCode(fmt.Errorf("parse form: %w", err), errs.InvalidArgument)
+		}
+	default:
+		return nil
+	}
+
+	return binder{ctx: r.Context(), tag: FormTag, lookup: func(name string) []string {
+		return r.PostForm[name]
+	}, files: func(name string) []*multipart.FileHeader {
+		return files[name]
+	}}.bind(dst)
+}
+
+func mediaType(r *http.Request) string {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	return mt
+}
+
+func isForm(r *http.Request) bool {
+	return mediaType(r) == "application/x-www-form-urlencoded"
+}
+
+func isMultipart(r *http.Request) bool {
+	return mediaType(r) == "multipart/form-data"
+}
+
+// IsForm reports if the body of r is a form handled by BindForm.
+func IsForm(r *http.Request) bool {
+	return isForm(r) || isMultipart(r)
+}
+
+func isFile(t reflect.Type) bool {
+	return t == fileHeaderType || t == fileHeaderSliceType
+}
+
+// setFiles binds files to field, checking the maxsize, type and max options.
+func setFiles(field reflect.Value, name, opts string, files []*multipart.FileHeader, ee *validation.Errors) error {
+	c, err := fileOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	if field.Type() == fileHeaderType {
+		if err := c.check(files[0]); err != nil {
+			ee.Add(name, err)
+
+			return nil
+		}
+
+		field.Set(reflect.ValueOf(files[0]))
+
+		return nil
+	}
+
+	if len(files) > c.maxItems {
+		ee.Add(name, validation.RuleError{
+			Rule:    "max",
+			Param:   strconv.Itoa(c.maxItems),
+			Message: fmt.Sprintf("must contain at most %d items", c.maxItems),
+			Key:     "max.collection",
+		})
+
+		return nil
+	}
+
+	failed := false
+
+	for i, f := range files {
+		if err := c.check(f); err != nil {
+			ee.Add(fmt.Sprintf("%s[%d]", name, i), err)
+
+			failed = true
+		}
+	}
+
+	if !failed {
+		field.Set(reflect.ValueOf(files))
+	}
+
+	return nil
+}
+
+type fileConstraints struct {
+	maxSize     int64
+	maxSizeText string
+	types       []string
+	maxItems    int
+}
+
+func fileOptions(opts string) (fileConstraints, error) {
+	maxItems, err := maxItemsOption(opts)
+	if err != nil {
+		return fileConstraints{}, err
+	}
+
+	c := fileConstraints{maxItems: maxItems}
+
+	for _, opt := range strings.Split(opts, ",") {
+		if value, ok := strings.CutPrefix(opt, "maxsize="); ok {
+			size, err := config.ParseByteSize(value)
+			if err != nil {
+				return fileConstraints{}, fmt.Errorf("%w: maxsize=%q: %w", ErrInvalidTag, value, err)
+			}
+
+			c.maxSize = int64(size)
+
+			c.maxSizeText = value
+		}
+
+		if value, ok := strings.CutPrefix(opt, "type="); ok {
+			c.types = strings.Split(value, "|")
+		}
+	}
+
+	return c, nil
+}
+
+func (c fileConstraints) check(f *multipart.FileHeader) error {
+	if c.maxSize > 0 && f.Size > c.maxSize {
+		return validation.RuleError{Rule: "maxsize", Param: c.maxSizeText, Message: "must be at most " + c.maxSizeText}
+	}
+
+	if len(c.types) == 0 {
+		return nil
+	}
+
+	mt, _, _ := mime.ParseMediaType(f.Header.Get("Content-Type"))
+
+	for _, t := range c.types {
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mt, prefix+"/") || t == mt {
+			return nil
+		}
+	}
+
+	return validation.RuleError{
+		Rule:    "type",
+		Param:   strings.Join(c.types, " "),
+		Message: "must be one of the types: " + strings.Join(c.types, ", "),
+	}
+}
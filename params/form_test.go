@@ -0,0 +1,178 @@
+package params
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/config"
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+type SignupForm struct {
+	Name   string                  `form:"name"`
+	Age    int                     `form:"age"`
+	Tags   []string                `form:"tag"`
+	Avatar *multipart.FileHeader   `form:"avatar,maxsize=1KB,type=image/png|image/jpeg"`
+	Docs   []*multipart.FileHeader `form:"doc,max=2,type=application/*"`
+}
+
+type formFile struct {
+	field, name, contentType string
+	size                     int
+}
+
+func multipartRequest(t *testing.T, values map[string]string, files ...formFile) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	w := multipart.NewWriter(&body)
+
+	for k, v := range values {
+		require.NoError(t, w.WriteField(k, v))
+	}
+
+	for _, f := range files {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+f.field+`"; filename="`+f.name+`"`)
+		h.Set("Content-Type", f.contentType)
+
+		part, err := w.CreatePart(h)
+		require.NoError(t, err)
+
+		_, err = part.Write(bytes.Repeat([]byte("x"), f.size))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	return r
+}
+
+func TestBindFormURLEncoded(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/?name=query", strings.NewReader("name=bob&age=42&tag=a&tag=b,c"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var f SignupForm
+
+	require.NoError(t, BindForm(r, &f))
+	assert.Equal(t, SignupForm{Name: "bob", Age: 42, Tags: []string{"a", "b", "c"}}, f)
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("age=old"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, BindForm(r, &SignupForm{}), &ee)
+	assert.Equal(t, map[string][]string{"age": {"must be an integer"}}, ee.Fields())
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"bob"}`))
+	r.Header.Set("Content-Type", "application/json")
+	require.NoError(t, BindForm(r, &f))
+	assert.False(t, IsForm(r))
+}
+
+func TestBindFormMultipart(t *testing.T) {
+	r := multipartRequest(t, map[string]string{"name": "bob"},
+		formFile{"avatar", "me.png", "image/png", 512},
+		formFile{"doc", "a.pdf", "application/pdf", 10},
+		formFile{"doc", "b.json", "application/json; charset=utf-8", 10})
+
+	var f SignupForm
+
+	require.NoError(t, BindForm(r, &f))
+	assert.Equal(t, "bob", f.Name)
+	require.NotNil(t, f.Avatar)
+	assert.Equal(t, "me.png", f.Avatar.Filename)
+	assert.Equal(t, int64(512), f.Avatar.Size)
+	require.Len(t, f.Docs, 2)
+	assert.Equal(t, "b.json", f.Docs[1].Filename)
+	assert.True(t, IsForm(r))
+
+	r = multipartRequest(t, nil,
+		formFile{"avatar", "me.gif", "image/gif", 10},
+		formFile{"doc", "a.pdf", "application/pdf", 10},
+		formFile{"doc", "b.txt", "text/plain", 10})
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, BindForm(r, &SignupForm{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"avatar": {"must be one of the types: image/png, image/jpeg"},
+		"doc[1]": {"must be one of the types: application/*"},
+	}, ee.Fields())
+
+	r = multipartRequest(t, nil,
+		formFile{"avatar", "me.png", "image/png", 2048},
+		formFile{"doc", "a.pdf", "application/pdf", 1},
+		formFile{"doc", "b.pdf", "application/pdf", 1},
+		formFile{"doc", "c.pdf", "application/pdf", 1})
+
+	require.ErrorAs(t, BindForm(r, &SignupForm{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"avatar": {"must be at most 1KB"},
+		"doc":    {"must contain at most 2 items"},
+	}, ee.Fields())
+}
+
+func TestBindFormErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("--x\r\nbroken"))
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+
+	err := BindForm(r, &SignupForm{})
+	require.Error(t, err)
+
+	code, _ := errs.GetCode(err)
+	assert.Equal(t, errs.InvalidArgument, code)
+
+	r = multipartRequest(t, nil, formFile{"avatar", "me.png", "image/png", 1})
+	assert.ErrorIs(t, BindForm(r, &struct {
+		Avatar *multipart.FileHeader `form:"avatar,maxsize=big"`
+	}{}), ErrInvalidTag)
+
+	r = httptest.NewRequest(http.MethodGet, "/?avatar=x", nil)
+	assert.ErrorIs(t, BindQuery(r, &struct {
+		Avatar *multipart.FileHeader `query:"avatar"`
+	}{}), ErrUnsupportedType)
+}
+
+func TestBindFormMaxSizeUnits(t *testing.T) {
+	type form struct {
+		Decimal *multipart.FileHeader `form:"decimal,maxsize=1KB"`
+		Binary  *multipart.FileHeader `form:"binary,maxsize=1KiB"`
+	}
+
+	r := multipartRequest(t, nil,
+		formFile{"decimal", "a.txt", "text/plain", 1000},
+		formFile{"binary", "b.txt", "text/plain", 1024})
+	require.NoError(t, BindForm(r, &form{}))
+
+	r = multipartRequest(t, nil,
+		formFile{"decimal", "a.txt", "text/plain", 1001},
+		formFile{"binary", "b.txt", "text/plain", 1025})
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, BindForm(r, &form{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"decimal": {"must be at most 1KB"},
+		"binary":  {"must be at most 1KiB"},
+	}, ee.Fields())
+
+	r = multipartRequest(t, nil, formFile{"avatar", "me.png", "image/png", 1})
+	assert.ErrorIs(t, BindForm(r, &struct {
+		Avatar *multipart.FileHeader `form:"avatar,maxsize=-1MB"`
+	}{}), config.ErrInvalidByteSize)
+}
@@ -0,0 +1,16 @@
+package params
+
+import (
+	"net/http"
+)
+
+// HeaderTag is the struct tag naming the request header bound to a field by BindHeader.
+const HeaderTag = "header"
+
+// BindHeader populates the fields of dst tagged with `header:"X-Tenant-Id"` from the headers of r, names are
+// canonicalized as by http.Header.  Field types are the same as Bind, slices are populated from repeated and comma
+// separated headers.  Absent headers leave the field unchanged, conversion failures are reported as
+// *validation.Errors keyed by the header name.
+func BindHeader(r *http.Request, dst any) error {
+	return binder{ctx: r.Context(), tag: HeaderTag, lookup: r.Header.Values}.bind(dst)
+}
@@ -0,0 +1,54 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/validation"
+)
+
+type HeaderParams struct {
+	Tenant   uuid.UUID   `header:"X-Tenant-Id"`
+	Features []string    `header:"x-features"`
+	Version  Opt[int]    `header:"X-Client-Version"`
+	Debug    bool        `header:"X-Debug" default:"false"`
+	Trace    Opt[string] `header:"X-Trace"`
+}
+
+func TestBindHeader(t *testing.T) {
+	tenant := uuid.New()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Tenant-Id", tenant.String())
+	r.Header.Add("X-Features", "beta,dark-mode")
+	r.Header.Add("X-Features", "search")
+	r.Header.Set("X-Client-Version", "3")
+	r.Header.Set("X-Trace", "")
+
+	var h HeaderParams
+
+	require.NoError(t, BindHeader(r, &h))
+	assert.Equal(t, HeaderParams{
+		Tenant:   tenant,
+		Features: []string{"beta", "dark-mode", "search"},
+		Version:  Some(3),
+		Trace:    Opt[string]{Present: true, Empty: true},
+	}, h)
+
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Tenant-Id", "acme")
+	r.Header.Set("X-Client-Version", "v3")
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, BindHeader(r, &HeaderParams{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"X-Tenant-Id":      {"must be a valid UUID"},
+		"X-Client-Version": {"must be an integer"},
+	}, ee.Fields())
+}
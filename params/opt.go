@@ -0,0 +1,132 @@
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// Opt is an optional parameter that distinguishes an absent parameter, a parameter present with an empty value
+// (e.g. ?name= or a JSON null), and a parameter with a value.  Opt fields are supported by Bind, BindQuery and JSON
+// decoding, for PATCH style updates and filters where "not given" and "clear" differ.
+type Opt[T any] struct {
+	Value T
+	// Present reports if the parameter was given.
+	Present bool
+	// Empty reports if the parameter was given without a value, Value is the zero value.
+	Empty bool
+}
+
+// Some returns a present Opt with value v.
+func Some[T any](v T) Opt[T] {
+	return Opt[T]{Value: v, Present: true}
+}
+
+// Get returns the value and if the parameter was given with a value.
+func (o Opt[T]) Get() (T, bool) {
+	return o.Value, o.HasValue()
+}
+
+// HasValue reports if the parameter was given with a value.
+func (o Opt[T]) HasValue() bool {
+	return o.Present && !o.Empty
+}
+
+// Or returns the value, or def if the parameter was not given with a value.
+func (o Opt[T]) Or(def T) T {
+	if o.HasValue() {
+		return o.Value
+	}
+
+	return def
+}
+
+// setParam implements optional.
+func (o *Opt[T]) setParam(c converter, s string) error {
+	*o = Opt[T]{Present: true, Empty: s == ""}
+	if o.Empty {
+		return nil
+	}
+
+	return c.setValue(reflect.ValueOf(&o.Value).Elem(), s)
+}
+
+// UnmarshalJSON decodes null as Empty, encoding/json does not call UnmarshalJSON for absent fields.
+func (o *Opt[T]) UnmarshalJSON(data []byte) error {
+	*o = Opt[T]{Present: true}
+
+	if bytes.Equal(data, []byte("null")) {
+		o.Empty = true
+
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &o.Value); err != nil {
+		return fmt.Errorf("opt: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes absent and empty values as null.
+func (o Opt[T]) MarshalJSON() ([]byte, error) {
+	if !o.HasValue() {
+		return []byte("null"), nil
+	}
+
+	b, err := json.Marshal(o.Value)
+	if err != nil {
+		return nil, fmt.Errorf("opt: %w", err)
+	}
+
+	return b, nil
+}
+
+// optional is implemented by *Opt, bound with empty values rather than treating them as absent.
+type optional interface {
+	setParam(c converter, s string) error
+}
+
+// GetOpt returns the parameter name, looked up as GetString but reporting empty query parameters and headers as
+// present.  Invalid values return *validation.Errors coded errs.InvalidArgument.
+func GetOpt[T any](r *http.Request, name string) (Opt[T], error) {
+	var o Opt[T]
+
+	s, ok := lookupPresent(r, name)
+	if !ok {
+		return o, nil
+	}
+
+	if err := o.setParam(newConverter(name), s); err != nil {
+		if errors.Is(err, ErrUnsupportedType) {
+			return Opt[T]{}, err
+		}
+
+		return Opt[T]{}, errs.WithCode(validation.New(name, err), errs.InvalidArgument)
+	}
+
+	return o, nil
+}
+
+// lookupPresent is GetString, distinguishing empty from absent query parameters and headers.
+func lookupPresent(r *http.Request, name string) (string, bool) {
+	if s := r.PathValue(name); s != "" {
+		return s, true
+	}
+
+	if query := r.URL.Query(); query.Has(name) {
+		return query.Get(name), true
+	}
+
+	if values := r.Header.Values(name); len(values) > 0 {
+		return values[0], true
+	}
+
+	return "", false
+}
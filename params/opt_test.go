@@ -0,0 +1,109 @@
+package params
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+func TestOpt(t *testing.T) {
+	var o Opt[int]
+
+	v, ok := o.Get()
+	assert.False(t, ok)
+	assert.Equal(t, 0, v)
+	assert.Equal(t, 5, o.Or(5))
+
+	o = Some(3)
+	v, ok = o.Get()
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, 3, o.Or(5))
+
+	o = Opt[int]{Present: true, Empty: true}
+	assert.False(t, o.HasValue())
+	assert.Equal(t, 5, o.Or(5))
+}
+
+func TestOptJSON(t *testing.T) {
+	var patch struct {
+		Name  Opt[string] `json:"name"`
+		Email Opt[string] `json:"email"`
+		Age   Opt[int]    `json:"age"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"bob","email":null}`), &patch))
+	assert.Equal(t, Some("bob"), patch.Name)
+	assert.Equal(t, Opt[string]{Present: true, Empty: true}, patch.Email)
+	assert.Equal(t, Opt[int]{}, patch.Age)
+
+	b, err := json.Marshal(patch)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"bob","email":null,"age":null}`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"age":"x"}`), &patch))
+}
+
+func TestGetOpt(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?limit=5&name=&bad=x", nil)
+	r.Header.Set("X-Empty", "")
+
+	limit, err := GetOpt[int](r, "limit")
+	require.NoError(t, err)
+	assert.Equal(t, Some(5), limit)
+
+	name, err := GetOpt[string](r, "name")
+	require.NoError(t, err)
+	assert.Equal(t, Opt[string]{Present: true, Empty: true}, name)
+
+	header, err := GetOpt[string](r, "X-Empty")
+	require.NoError(t, err)
+	assert.Equal(t, Opt[string]{Present: true, Empty: true}, header)
+
+	missing, err := GetOpt[int](r, "missing")
+	require.NoError(t, err)
+	assert.Equal(t, Opt[int]{}, missing)
+
+	_, err = GetOpt[int](r, "bad")
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{"bad": {"must be an integer"}}, ee.Fields())
+
+	code, _ := errs.GetCode(err)
+	assert.Equal(t, errs.InvalidArgument, code)
+
+	_, err = GetOpt[chan int](r, "limit")
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+type OptQuery struct {
+	Status Opt[string] `query:"status"`
+	Limit  Opt[int]    `query:"limit"`
+	Owner  Opt[string] `param:"owner"`
+	Page   Opt[int]    `param:"page"`
+}
+
+func TestBindOpt(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?status=&limit=10&owner=&page=x", nil)
+
+	var q OptQuery
+
+	require.NoError(t, BindQuery(r, &q))
+	assert.Equal(t, Opt[string]{Present: true, Empty: true}, q.Status)
+	assert.Equal(t, Some(10), q.Limit)
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, Bind(r, &q), &ee)
+	assert.Equal(t, map[string][]string{"page": {"must be an integer"}}, ee.Fields())
+	assert.Equal(t, Opt[string]{Present: true, Empty: true}, q.Owner)
+}
@@ -0,0 +1,152 @@
+package params
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// Pagination query parameter names.
+const (
+	LimitParam  = "limit"
+	OffsetParam = "offset"
+	CursorParam = "cursor"
+)
+
+const (
+	// DefaultPageLimit is the page size used when PageDefaults.Limit is 0.
+	DefaultPageLimit = 20
+	// DefaultMaxPageLimit is the maximum page size used when PageDefaults.MaxLimit is 0.
+	DefaultMaxPageLimit = 100
+
+	cursorMACSize = 16
+)
+
+// ErrInvalidCursor is returned by DecodeCursor for malformed or tampered cursors.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageDefaults configures Pagination.
+type PageDefaults struct {
+	// Limit is the page size if the limit parameter is absent, 0 uses DefaultPageLimit.
+	Limit int
+	// MaxLimit is the largest page size accepted, 0 uses DefaultMaxPageLimit.
+	MaxLimit int
+	// Secret signs cursors, cursor parameters are rejected if empty.
+	Secret []byte
+}
+
+// Page is the requested page.  Either Offset or Cursor is set, Cursor holds the position encoded by
+// PageDefaults.EncodeCursor for the previous page (e.g. the last key returned), nil for the first page.
+type Page struct {
+	Limit  int
+	Offset int
+	Cursor []byte
+}
+
+// Pagination parses the limit, offset and cursor query parameters of r.  Cursors are opaque base64 values signed
+// with d.Secret, so clients can not forge positions.  Invalid values, limits above the maximum page size, and
+// combining offset with cursor return *validation.Errors coded errs.InvalidArgument.
+func Pagination(r *http.Request, d PageDefaults) (Page, error) {
+	var (
+		ee    validation.Errors
+		query = r.URL.Query()
+		page  = Page{Limit: d.limit()}
+	)
+
+	if s := query.Get(LimitParam); s != "" {
+		limit, err := strconv.Atoi(s)
+
+		switch {
+		case err != nil:
+			ee.Add(LimitParam, validation.Error{Message: "must be an integer", Source: err})
+		case limit < 1:
+			ee.Add(LimitParam, validation.RuleError{Rule: "min", Param: "1", Message: "must be at least 1"})
+		case limit > d.maxLimit():
+			maxLimit := strconv.Itoa(d.maxLimit())
+			ee.Add(LimitParam, validation.RuleError{Rule: "max", Param: maxLimit, Message: "must be at most " + maxLimit})
+		default:
+			page.Limit = limit
+		}
+	}
+
+	if s := query.Get(OffsetParam); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			ee.Add(OffsetParam, validation.Error{Message: "must be a positive integer", Source: err})
+		}
+
+		page.Offset = offset
+	}
+
+	if s := query.Get(CursorParam); s != "" {
+		cursor, err := d.DecodeCursor(s)
+
+		switch {
+		case err != nil:
+			ee.Add(CursorParam, validation.Error{Message: "is invalid", Source: err})
+		case query.Has(OffsetParam):
+			ee.Add(CursorParam, validation.Error{Message: "can not be combined with offset"})
+		default:
+			page.Cursor = cursor
+		}
+	}
+
+	if len(ee) > 0 {
+		return Page{}, errs.WithCode(&ee, errs.InvalidArgument)
+	}
+
+	return page, nil
+}
+
+// EncodeCursor returns the signed, URL safe cursor for position.
+func (d PageDefaults) EncodeCursor(position []byte) string {
+	return base64.RawURLEncoding.EncodeToString(append(position[:len(position):len(position)], d.mac(position)...))
+}
+
+// DecodeCursor returns the position of a cursor created by EncodeCursor, or ErrInvalidCursor.
+func (d PageDefaults) DecodeCursor(cursor string) ([]byte, error) {
+	if len(d.Secret) == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(b) < cursorMACSize {
+		return nil, ErrInvalidCursor
+	}
+
+	position, mac := b[:len(b)-cursorMACSize], b[len(b)-cursorMACSize:]
+	if !hmac.Equal(mac, d.mac(position)) {
+		return nil, ErrInvalidCursor
+	}
+
+	return position, nil
+}
+
+func (d PageDefaults) mac(position []byte) []byte {
+	h := hmac.New(sha256.New, d.Secret)
+	h.Write(position)
+
+	return h.Sum(nil)[:cursorMACSize]
+}
+
+func (d PageDefaults) limit() int {
+	if d.Limit > 0 {
+		return min(d.Limit, d.maxLimit())
+	}
+
+	return min(DefaultPageLimit, d.maxLimit())
+}
+
+func (d PageDefaults) maxLimit() int {
+	if d.MaxLimit > 0 {
+		return d.MaxLimit
+	}
+
+	return DefaultMaxPageLimit
+}
@@ -0,0 +1,84 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+func TestPagination(t *testing.T) {
+	d := PageDefaults{Limit: 10, MaxLimit: 50, Secret: []byte("secret")}
+
+	page, err := Pagination(httptest.NewRequest(http.MethodGet, "/", nil), d)
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: 10}, page)
+
+	page, err = Pagination(httptest.NewRequest(http.MethodGet, "/?limit=25&offset=100", nil), d)
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: 25, Offset: 100}, page)
+
+	cursor := d.EncodeCursor([]byte("last-id"))
+	page, err = Pagination(httptest.NewRequest(http.MethodGet, "/?cursor="+url.QueryEscape(cursor), nil), d)
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: 10, Cursor: []byte("last-id")}, page)
+
+	page, err = Pagination(httptest.NewRequest(http.MethodGet, "/", nil), PageDefaults{})
+	require.NoError(t, err)
+	assert.Equal(t, Page{Limit: DefaultPageLimit}, page)
+}
+
+func TestPaginationErrors(t *testing.T) {
+	d := PageDefaults{MaxLimit: 50, Secret: []byte("secret")}
+	forged := PageDefaults{Secret: []byte("other")}.EncodeCursor([]byte("last-id"))
+
+	tests := []struct {
+		name  string
+		query string
+		want  map[string][]string
+	}{
+		{"limit", "limit=x", map[string][]string{"limit": {"must be an integer"}}},
+		{"zero", "limit=0", map[string][]string{"limit": {"must be at least 1"}}},
+		{"max", "limit=51", map[string][]string{"limit": {"must be at most 50"}}},
+		{"offset", "offset=-1", map[string][]string{"offset": {"must be a positive integer"}}},
+		{"forged", "cursor=" + forged, map[string][]string{"cursor": {"is invalid"}}},
+		{"base64", "cursor=!!", map[string][]string{"cursor": {"is invalid"}}},
+		{"combined", "offset=1&cursor=" + d.EncodeCursor(nil), map[string][]string{
+			"cursor": {"can not be combined with offset"},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Pagination(httptest.NewRequest(http.MethodGet, "/?"+test.query, nil), d)
+
+			var ee *validation.Errors
+
+			require.ErrorAs(t, err, &ee)
+			assert.Equal(t, test.want, ee.Fields())
+
+			code, _ := errs.GetCode(err)
+			assert.Equal(t, errs.InvalidArgument, code)
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	d := PageDefaults{Secret: []byte("secret")}
+
+	position, err := d.DecodeCursor(d.EncodeCursor([]byte{1, 2, 3}))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, position)
+
+	_, err = PageDefaults{}.DecodeCursor(d.EncodeCursor([]byte{1}))
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = d.DecodeCursor("AA")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -108,9 +108,9 @@ func GetUUID(r *http.Request, name string, required bool) (uuid.UUID, bool, erro
 		return uuid.UUID{}, false, err
 	}
 
-	id, err := uuid.Parse(s)
+	id, err := DefaultUUIDParser.Parse(name, s)
 	if err != nil {
-		return uuid.UUID{}, false, fmt.Errorf("invalid uuid: %w", err)
+		return uuid.UUID{}, false, err
 	}
 
 	return id, true, nil
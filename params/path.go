@@ -0,0 +1,89 @@
+package params
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/google/uuid"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// PathTag is the struct tag naming the path value bound to a field by BindPath.
+const PathTag = "path"
+
+// MalformedPathCode classifies path values that fail conversion.  errs.InvalidArgument (the default) reports them as
+// validation errors (400), errs.NotFound treats them as a missing resource (404), e.g. for "/users/abc" when IDs are
+// numeric.
+var MalformedPathCode = errs.InvalidArgument
+
+// Path returns the path value name (see http.Request.PathValue) converted to T, using the conversions of Bind.  A
+// missing value returns ErrNotFound coded errs.NotFound, a malformed value is classified by MalformedPathCode.
+func Path[T any](r *http.Request, name string) (T, error) {
+	var out T
+
+	s := r.PathValue(name)
+	if s == "" {
+		return out, errs.WithCode(fmt.Errorf("%s: %w", name, ErrNotFound), errs.NotFound)
+	}
+
+	if err := newConverter(name).setValue(reflect.ValueOf(&out).Elem(), s); err != nil {
+		if errors.Is(err, ErrUnsupportedType) {
+			return out, err
+		}
+
+		return out, malformedPath(name, err)
+	}
+
+	return out, nil
+}
+
+// PathString returns the path value name, see Path.
+func PathString(r *http.Request, name string) (string, error) {
+	return Path[string](r, name)
+}
+
+// PathInt returns the path value name as an int, see Path.
+func PathInt(r *http.Request, name string) (int, error) {
+	return Path[int](r, name)
+}
+
+// PathInt64 returns the path value name as an int64, see Path.
+func PathInt64(r *http.Request, name string) (int64, error) {
+	return Path[int64](r, name)
+}
+
+// PathUUID returns the path value name as a UUID, see Path.
+func PathUUID(r *http.Request, name string) (uuid.UUID, error) {
+	return Path[uuid.UUID](r, name)
+}
+
+// BindPath populates the fields of dst tagged with `path:"name"` from the path values of r, see Bind for the
+// supported types.  Absent values leave the field unchanged, malformed values are classified by MalformedPathCode.
+func BindPath(r *http.Request, dst any) error {
+	err := binder{ctx: r.Context(), tag: PathTag, lookup: func(name string) []string {
+		if s := r.PathValue(name); s != "" {
+			return []string{s}
+		}
+
+		return nil
+	}}.bind(dst)
+
+	var ee *validation.Errors
+	if MalformedPathCode == errs.NotFound && errors.As(err, &ee) {
+		return errs.WithCode(fmt.Errorf("%w: %w", ErrNotFound, err), errs.NotFound)
+	}
+
+	return err
+}
+
+func malformedPath(name string, err error) error {
+	if MalformedPathCode == errs.NotFound {
+		return errs.WithCode(fmt.Errorf("%s: %w: %w", name, ErrNotFound, err), errs.NotFound)
+	}
+
+	return errs.WithCode(validation.New(name, err), errs.InvalidArgument)
+}
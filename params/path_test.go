@@ -0,0 +1,104 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+func pathRequest(pattern, target string) *http.Request {
+	var out *http.Request
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, func(_ http.ResponseWriter, r *http.Request) { out = r })
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+
+	return out
+}
+
+func TestPath(t *testing.T) {
+	id := uuid.New()
+	r := pathRequest("GET /orgs/{org}/users/{id}/{uid}", "/orgs/acme/users/42/"+id.String())
+
+	org, err := PathString(r, "org")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", org)
+
+	i, err := PathInt(r, "id")
+	require.NoError(t, err)
+	assert.Equal(t, 42, i)
+
+	i64, err := PathInt64(r, "id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), i64)
+
+	u, err := PathUUID(r, "uid")
+	require.NoError(t, err)
+	assert.Equal(t, id, u)
+
+	_, err = PathInt(r, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, http.StatusNotFound, errs.Status(err))
+
+	var ee *validation.Errors
+
+	_, err = PathInt(r, "org")
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{"org": {"must be an integer"}}, ee.Fields())
+	assert.Equal(t, http.StatusBadRequest, errs.Status(err))
+
+	_, err = PathUUID(r, "id")
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{"id": {"must be a valid UUID"}}, ee.Fields())
+}
+
+func TestPathMalformedNotFound(t *testing.T) {
+	MalformedPathCode = errs.NotFound
+
+	defer func() { MalformedPathCode = errs.InvalidArgument }()
+
+	r := pathRequest("GET /users/{id}", "/users/abc")
+
+	_, err := PathInt(r, "id")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, http.StatusNotFound, errs.Status(err))
+
+	var dst struct {
+		ID int `path:"id"`
+	}
+
+	err = BindPath(r, &dst)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, http.StatusNotFound, errs.Status(err))
+}
+
+func TestBindPath(t *testing.T) {
+	id := uuid.New()
+	r := pathRequest("GET /orgs/{org}/users/{id}", "/orgs/acme/users/"+id.String())
+
+	var dst struct {
+		Org   string    `path:"org"`
+		ID    uuid.UUID `path:"id"`
+		Other int       `path:"other"`
+	}
+
+	require.NoError(t, BindPath(r, &dst))
+	assert.Equal(t, "acme", dst.Org)
+	assert.Equal(t, id, dst.ID)
+
+	var bad struct {
+		Org int `path:"org"`
+	}
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, BindPath(r, &bad), &ee)
+	assert.Equal(t, map[string][]string{"org": {"must be an integer"}}, ee.Fields())
+}
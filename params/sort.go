@@ -0,0 +1,78 @@
+package params
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// SortParam is the query parameter parsed by Sort.
+const SortParam = "sort"
+
+// SortField is an element of a sort parameter, e.g. "-created_at" is {Name: "created_at", Desc: true}.
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+// String formats f as it would appear in a sort parameter.
+func (f SortField) String() string {
+	if f.Desc {
+		return "-" + f.Name
+	}
+
+	return f.Name
+}
+
+// Sort parses the comma separated sort query parameter, e.g. ?sort=-created_at,name, in priority order.  Fields are
+// ascending unless prefixed with "-" ("+" is accepted for ascending), and must be one of allowed.  Returns nil if the
+// parameter is absent.  Unknown or repeated fields return *validation.Errors coded errs.InvalidArgument.
+func Sort(r *http.Request, allowed ...string) ([]SortField, error) {
+	s := r.URL.Query().Get(SortParam)
+	if s == "" {
+		return nil, nil
+	}
+
+	var (
+		out  []SortField
+		seen = map[string]bool{}
+	)
+
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		f := SortField{Name: strings.TrimPrefix(item, "+")}
+		if name, ok := strings.CutPrefix(item, "-"); ok {
+			f = SortField{Name: name, Desc: true}
+		}
+
+		var err error
+
+		switch {
+		case !slices.Contains(allowed, f.Name):
+			err = validation.RuleError{
+				Rule:    "oneof",
+				Param:   strings.Join(allowed, " "),
+				Message: "must be one of: " + strings.Join(allowed, ", "),
+			}
+		case seen[f.Name]:
+			err = validation.Error{Message: "must not repeat " + f.Name}
+		}
+
+		if err != nil {
+			return nil, errs.WithCode(validation.New(SortParam, err), errs.InvalidArgument)
+		}
+
+		seen[f.Name] = true
+
+		out = append(out, f)
+	}
+
+	return out, nil
+}
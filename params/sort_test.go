@@ -0,0 +1,50 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+func TestSort(t *testing.T) {
+	allowed := []string{"created_at", "name", "id"}
+
+	fields, err := Sort(httptest.NewRequest(http.MethodGet, "/?sort=-created_at,name,+id", nil), allowed...)
+	require.NoError(t, err)
+	assert.Equal(t, []SortField{{Name: "created_at", Desc: true}, {Name: "name"}, {Name: "id"}}, fields)
+	assert.Equal(t, "-created_at", fields[0].String())
+	assert.Equal(t, "name", fields[1].String())
+
+	fields, err = Sort(httptest.NewRequest(http.MethodGet, "/", nil), allowed...)
+	require.NoError(t, err)
+	assert.Nil(t, fields)
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"unknown", "sort=-email", "must be one of: created_at, name, id"},
+		{"repeated", "sort=name,-name", "must not repeat name"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Sort(httptest.NewRequest(http.MethodGet, "/?"+test.query, nil), allowed...)
+
+			var ee *validation.Errors
+
+			require.ErrorAs(t, err, &ee)
+			assert.Equal(t, map[string][]string{SortParam: {test.want}}, ee.Fields())
+
+			code, _ := errs.GetCode(err)
+			assert.Equal(t, errs.InvalidArgument, code)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package params
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bir/iken/logctx"
+	"github.com/bir/iken/validation"
+)
+
+// UnknownMode controls the handling of query parameters not declared by the struct bound by BindQuery.
+type UnknownMode int
+
+const (
+	// UnknownAllow ignores undeclared query parameters.
+	UnknownAllow UnknownMode = iota
+	// UnknownWarn logs undeclared query parameters to the request log context, see LogUnknownParams.
+	UnknownWarn
+	// UnknownReject reports undeclared query parameters as validation errors.
+	UnknownReject
+)
+
+// UnknownQueryParams is the handling of undeclared query parameters by BindQuery, parameters are declared by query
+// and param tags.  Defaults to UnknownAllow.
+var UnknownQueryParams = UnknownAllow
+
+// LogUnknownParams is the log context key listing undeclared query parameters in UnknownWarn mode.
+const LogUnknownParams = "param.unknown"
+
+// checkUnknown applies UnknownQueryParams to the parameters of query not declared by t.
+func checkUnknown(r *http.Request, query map[string][]string, t reflect.Type, ee *validation.Errors) {
+	if UnknownQueryParams == UnknownAllow {
+		return
+	}
+
+	declared := map[string]bool{}
+	declaredParams(t, declared)
+
+	var unknown []string
+
+	for name := range query {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return
+	}
+
+	sort.Strings(unknown)
+
+	if UnknownQueryParams == UnknownWarn {
+		logctx.AddStrToContext(r.Context(), LogUnknownParams, strings.Join(unknown, ","))
+
+		return
+	}
+
+	for _, name := range unknown {
+		ee.Add(name, validation.Error{Message: "is not allowed"})
+	}
+}
+
+func declaredParams(t reflect.Type, declared map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			declaredParams(sf.Type, declared)
+
+			continue
+		}
+
+		for _, tag := range []string{QueryTag, TagName} {
+			if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+				declared[name] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package params
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/logctx"
+	"github.com/bir/iken/validation"
+)
+
+type pageQuery struct {
+	Limit int `query:"limit" default:"25"`
+}
+
+type StrictQuery struct {
+	pageQuery
+	Status string `query:"status"`
+	Owner  string `param:"owner"`
+	Skip   string `query:"-"`
+}
+
+func TestUnknownQueryParams(t *testing.T) {
+	defer func(m UnknownMode) { UnknownQueryParams = m }(UnknownQueryParams)
+
+	target := "/?limt=10&status=open&owner=me&Skip=x&zeta=1"
+
+	var q StrictQuery
+
+	UnknownQueryParams = UnknownAllow
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, target, nil), &q))
+	assert.Equal(t, 25, q.Limit)
+
+	UnknownQueryParams = UnknownReject
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, BindQuery(httptest.NewRequest(http.MethodGet, target, nil), &StrictQuery{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"limt": {"is not allowed"},
+		"Skip": {"is not allowed"},
+		"zeta": {"is not allowed"},
+	}, ee.Fields())
+
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, "/?limit=1&owner=me", nil), &StrictQuery{}))
+
+	UnknownQueryParams = UnknownWarn
+
+	logBuffer := bytes.NewBuffer(nil)
+	ctx := logctx.NewSubLoggerContext(context.Background(), zerolog.New(logBuffer))
+
+	require.NoError(t, BindQuery(httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx), &StrictQuery{}))
+
+	zerolog.Ctx(ctx).Log().Msg("request")
+
+	assert.Equal(t, `{"param.unknown":"Skip,limt,zeta","message":"request"}
+`, logBuffer.String())
+}
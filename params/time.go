@@ -0,0 +1,167 @@
+package params
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// TimeFormat is an accepted time representation: a time.Parse layout, or TimeUnix/TimeUnixMilli for epoch values.
+type TimeFormat string
+
+const (
+	TimeRFC3339   TimeFormat = time.RFC3339
+	TimeDate      TimeFormat = time.DateOnly
+	TimeUnix      TimeFormat = "unix"
+	TimeUnixMilli TimeFormat = "unixmilli"
+)
+
+// unixMilliThreshold distinguishes epoch millis from seconds when both are accepted, 1e11 seconds is in year 5138.
+const unixMilliThreshold = 1e11
+
+// String describes the format for error messages.
+func (f TimeFormat) String() string {
+	switch f {
+	case TimeRFC3339:
+		return "RFC 3339"
+	case TimeDate:
+		return "date (YYYY-MM-DD)"
+	case TimeUnix:
+		return "Unix seconds"
+	case TimeUnixMilli:
+		return "Unix milliseconds"
+	}
+
+	return string(f)
+}
+
+// TimeParser parses time parameters in multiple formats.
+type TimeParser struct {
+	// Formats are tried in order.  When both TimeUnix and TimeUnixMilli are accepted, integers of 1e11 and above are
+	// milliseconds.
+	Formats []TimeFormat
+	// Location is used for layouts without a zone (e.g. TimeDate), defaults to UTC.
+	Location *time.Location
+	// Min and Max bound the accepted times, zero values are unbounded.
+	Min, Max time.Time
+}
+
+// DefaultTimeParser accepts RFC 3339, dates and Unix epoch seconds or milliseconds, in UTC.
+var DefaultTimeParser = TimeParser{Formats: []TimeFormat{TimeRFC3339, TimeDate, TimeUnix, TimeUnixMilli}}
+
+// TimeError reports a time parameter that could not be parsed or is out of range.
+type TimeError struct {
+	Param   string
+	Value   string
+	Formats []TimeFormat
+	Min     time.Time
+	Max     time.Time
+	// OutOfRange is set if the value parsed, but is before Min or after Max.
+	OutOfRange bool
+}
+
+func (e *TimeError) Error() string {
+	return fmt.Sprintf("%s: invalid time %q: %s", e.Param, e.Value, e.UserError())
+}
+
+// UserError describes the expected formats or range.
+func (e *TimeError) UserError() string {
+	if !e.OutOfRange {
+		names := make([]string, len(e.Formats))
+		for i, f := range e.Formats {
+			names[i] = f.String()
+		}
+
+		return "must be a time in one of the formats: " + strings.Join(names, ", ")
+	}
+
+	switch {
+	case !e.Min.IsZero() && !e.Max.IsZero():
+		return fmt.Sprintf("must be between %s and %s", e.Min.Format(time.RFC3339), e.Max.Format(time.RFC3339))
+	case !e.Min.IsZero():
+		return "must not be before " + e.Min.Format(time.RFC3339)
+	}
+
+	return "must not be after " + e.Max.Format(time.RFC3339)
+}
+
+// Parse converts the value s of the parameter name, returning *TimeError on failure.
+func (p TimeParser) Parse(name, s string) (time.Time, error) {
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	t, ok := p.parse(s, loc)
+	if !ok {
+		return time.Time{}, &TimeError{Param: name, Value: s, Formats: p.Formats, Min: p.Min, Max: p.Max}
+	}
+
+	if (!p.Min.IsZero() && t.Before(p.Min)) || (!p.Max.IsZero() && t.After(p.Max)) {
+		return time.Time{}, &TimeError{
+			Param: name, Value: s, Formats: p.Formats, Min: p.Min, Max: p.Max, OutOfRange: true,
+		}
+	}
+
+	return t, nil
+}
+
+func (p TimeParser) accepts(f TimeFormat) bool {
+	for _, accepted := range p.Formats {
+		if accepted == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p TimeParser) parse(s string, loc *time.Location) (time.Time, bool) {
+	for _, f := range p.Formats {
+		switch f {
+		case TimeUnix, TimeUnixMilli:
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			isMilli := f == TimeUnixMilli
+			if p.accepts(TimeUnix) && p.accepts(TimeUnixMilli) {
+				isMilli = n >= unixMilliThreshold || n <= -unixMilliThreshold
+			}
+
+			if isMilli {
+				return time.UnixMilli(n).In(loc), true
+			}
+
+			return time.Unix(n, 0).In(loc), true
+		default:
+			if t, err := time.ParseInLocation(string(f), s, loc); err == nil {
+				return t, true
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// GetTimeWith returns the parameter name (see GetString) parsed by p.  Invalid values return *validation.Errors coded
+// errs.InvalidArgument, wrapping the *TimeError.
+func GetTimeWith(r *http.Request, name string, required bool, p TimeParser) (time.Time, bool, error) {
+	s, ok, err := GetString(r, name, required)
+	if err != nil || !ok {
+		return time.Time{}, false, err
+	}
+
+	t, err := p.Parse(name, s)
+	if err != nil {
+		return time.Time{}, false, errs.WithCode(validation.New(name, err), errs.InvalidArgument)
+	}
+
+	return t, true, nil
+}
@@ -0,0 +1,127 @@
+package params
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/validation"
+)
+
+func TestTimeParser(t *testing.T) {
+	est := time.FixedZone("EST", -5*60*60)
+
+	tests := []struct {
+		name    string
+		parser  TimeParser
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{"rfc3339", DefaultTimeParser, "2024-03-01T10:00:00+02:00", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), ""},
+		{"date", DefaultTimeParser, "2024-03-01", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), ""},
+		{"unix seconds", DefaultTimeParser, "1709280000", time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), ""},
+		{"unix millis", DefaultTimeParser, "1709280000123", time.Date(2024, 3, 1, 8, 0, 0, 123e6, time.UTC), ""},
+		{
+			"millis only",
+			TimeParser{Formats: []TimeFormat{TimeUnixMilli}},
+			"1000",
+			time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC), "",
+		},
+		{
+			"location",
+			TimeParser{Formats: []TimeFormat{TimeDate}, Location: est},
+			"2024-03-01",
+			time.Date(2024, 3, 1, 0, 0, 0, 0, est), "",
+		},
+		{
+			"invalid", DefaultTimeParser, "yesterday",
+			time.Time{},
+			"must be a time in one of the formats: RFC 3339, date (YYYY-MM-DD), Unix seconds, Unix milliseconds",
+		},
+		{
+			"format priority",
+			TimeParser{Formats: []TimeFormat{TimeRFC3339}},
+			"2024-03-01",
+			time.Time{},
+			"must be a time in one of the formats: RFC 3339",
+		},
+		{
+			"min",
+			TimeParser{Formats: []TimeFormat{TimeDate}, Min: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			"2023-12-31",
+			time.Time{},
+			"must not be before 2024-01-01T00:00:00Z",
+		},
+		{
+			"max",
+			TimeParser{Formats: []TimeFormat{TimeDate}, Max: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			"2024-01-02",
+			time.Time{},
+			"must not be after 2024-01-01T00:00:00Z",
+		},
+		{
+			"range",
+			TimeParser{
+				Formats: []TimeFormat{TimeDate},
+				Min:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				Max:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+			"2024-03-01",
+			time.Time{},
+			"must be between 2024-01-01T00:00:00Z and 2024-02-01T00:00:00Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parser.Parse("since", tt.value)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.True(t, tt.want.Equal(got), "got %v", got)
+
+				return
+			}
+
+			var te *TimeError
+
+			require.ErrorAs(t, err, &te)
+			assert.Equal(t, "since", te.Param)
+			assert.Equal(t, tt.wantErr, te.UserError())
+		})
+	}
+}
+
+func TestGetTimeWith(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?since=2024-03-01&until=soon", nil)
+
+	got, ok, err := GetTimeWith(r, "since", true, DefaultTimeParser)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), got)
+
+	_, ok, err = GetTimeWith(r, "missing", false, DefaultTimeParser)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = GetTimeWith(r, "missing", true, DefaultTimeParser)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = GetTimeWith(r, "until", true, DefaultTimeParser)
+
+	var (
+		ee *validation.Errors
+		te *TimeError
+	)
+
+	require.ErrorAs(t, err, &ee)
+	assert.Contains(t, ee.Fields()["until"][0], "must be a time in one of the formats")
+	assert.True(t, errors.As(err, &te))
+	assert.Equal(t, `until: invalid time "soon": must be a time in one of the formats: RFC 3339, date (YYYY-MM-DD), `+
+		`Unix seconds, Unix milliseconds`, te.Error())
+}
@@ -0,0 +1,31 @@
+package params
+
+import (
+	"errors"
+
+	"github.com/bir/iken/validation"
+)
+
+// Unmarshaler is implemented by types parsing their own parameter values, e.g. Money or CountryCode.  It is honored
+// by every binder (Bind, BindQuery, BindPath, BindHeader, BindForm), Path, Slice and Opt, and takes precedence over
+// the built-in conversions and encoding.TextUnmarshaler.
+//
+// Errors implementing validation.UserError (e.g. validation.Error or validation.RuleError) are reported as is,
+// other errors are reported as "is invalid".
+type Unmarshaler interface {
+	UnmarshalParam(s string) error
+}
+
+func unmarshalParam(u Unmarshaler, s string) error {
+	err := u.UnmarshalParam(s)
+	if err == nil {
+		return nil
+	}
+
+	var ue validation.UserError
+	if errors.As(err, &ue) {
+		return err
+	}
+
+	return validation.Error{Message: "is invalid", Source: err}
+}
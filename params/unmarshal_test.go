@@ -0,0 +1,94 @@
+package params
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/validation"
+)
+
+// cents parses decimal amounts, e.g. "12.50".
+type cents int64
+
+func (c *cents) UnmarshalParam(s string) error {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 2 { //nolint:mnd
+		return validation.Error{Message: "must have at most 2 decimal places"}
+	}
+
+	n, err := strconv.ParseInt(whole+(frac + "00")[:2], 10, 64)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	*c = cents(n)
+
+	return nil
+}
+
+// country is a slice type, bound from a single value because it implements Unmarshaler.
+type country []byte
+
+var errCountry = errors.New("unknown country")
+
+func (c *country) UnmarshalParam(s string) error {
+	if len(s) != 2 { //nolint:mnd
+		return errCountry
+	}
+
+	*c = country(strings.ToUpper(s))
+
+	return nil
+}
+
+type ParamsWithUnmarshaler struct {
+	Price   cents      `query:"price"`
+	Prices  []cents    `query:"prices"`
+	Country country    `header:"X-Country"`
+	Max     Opt[cents] `param:"max"`
+	Min     *cents     `param:"min"`
+	Tax     cents      `path:"tax"`
+}
+
+func TestUnmarshaler(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?price=12.5&prices=1,2.25&max=3&min=0.01", nil)
+	r.Header.Set("X-Country", "us")
+
+	var p ParamsWithUnmarshaler
+
+	require.NoError(t, BindQuery(r, &p))
+	require.NoError(t, BindHeader(r, &p))
+	require.NoError(t, Bind(r, &p))
+	assert.Equal(t, cents(1250), p.Price)
+	assert.Equal(t, []cents{100, 225}, p.Prices)
+	assert.Equal(t, country("US"), p.Country)
+	assert.Equal(t, Some(cents(300)), p.Max)
+	require.NotNil(t, p.Min)
+	assert.Equal(t, cents(1), *p.Min)
+
+	tax, err := Path[cents](pathRequest("/tax/{tax}", "/tax/0.2"), "tax")
+	require.NoError(t, err)
+	assert.Equal(t, cents(20), tax)
+
+	r = httptest.NewRequest(http.MethodGet, "/?price=1.234&prices=x", nil)
+	r.Header.Set("X-Country", "usa")
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, BindQuery(r, &ParamsWithUnmarshaler{}), &ee)
+	assert.Equal(t, map[string][]string{
+		"price":     {"must have at most 2 decimal places"},
+		"prices[0]": {"is invalid"},
+	}, ee.Fields())
+
+	require.ErrorAs(t, BindHeader(r, &ParamsWithUnmarshaler{}), &ee)
+	assert.Equal(t, map[string][]string{"X-Country": {"is invalid"}}, ee.Fields())
+	assert.ErrorIs(t, ee, errCountry)
+}
@@ -0,0 +1,120 @@
+package params
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+// UUIDParser parses UUID parameters, optionally restricted to specific versions.
+type UUIDParser struct {
+	// Versions are the accepted UUID versions, empty accepts any.
+	Versions []uuid.Version
+}
+
+var (
+	// DefaultUUIDParser accepts any UUID version, used by Bind, Path and GetUUID.
+	DefaultUUIDParser = UUIDParser{}
+	// RandomUUIDParser accepts only random (v4) and time ordered (v7) UUIDs.
+	RandomUUIDParser = UUIDParser{Versions: []uuid.Version{4, 7}} //nolint:mnd
+)
+
+// UUIDError reports a UUID parameter that could not be parsed or has an unaccepted version.  UUIDError is coded
+// errs.InvalidArgument (400).
+type UUIDError struct {
+	Param    string
+	Value    string
+	Versions []uuid.Version
+	// WrongVersion is set if the value parsed, but its version is not one of Versions.
+	WrongVersion bool
+}
+
+func (e *UUIDError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("invalid UUID %q: %s", e.Value, e.UserError())
+	}
+
+	return fmt.Sprintf("%s: invalid UUID %q: %s", e.Param, e.Value, e.UserError())
+}
+
+// UserError describes the expected value.
+func (e *UUIDError) UserError() string {
+	if !e.WrongVersion || len(e.Versions) == 0 {
+		return "must be a valid UUID"
+	}
+
+	versions := make([]string, len(e.Versions))
+	for i, v := range e.Versions {
+		versions[i] = fmt.Sprint(int(v))
+	}
+
+	if len(versions) == 1 {
+		return "must be a version " + versions[0] + " UUID"
+	}
+
+	last := len(versions) - 1
+
+	return "must be a version " + strings.Join(versions[:last], ", ") + " or " + versions[last] + " UUID"
+}
+
+// Code classifies UUIDError as errs.InvalidArgument.
+func (e *UUIDError) Code() errs.Code {
+	return errs.InvalidArgument
+}
+
+// Parse converts the value s of the parameter name, returning *UUIDError on failure.  Any format accepted by
+// uuid.Parse is allowed, use uuid.UUID.String for the canonical lowercase form.
+func (p UUIDParser) Parse(name, s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.UUID{}, &UUIDError{Param: name, Value: s, Versions: p.Versions}
+	}
+
+	if len(p.Versions) == 0 {
+		return id, nil
+	}
+
+	for _, v := range p.Versions {
+		if id.Version() == v {
+			return id, nil
+		}
+	}
+
+	return uuid.UUID{}, &UUIDError{Param: name, Value: s, Versions: p.Versions, WrongVersion: true}
+}
+
+// GetUUIDWith returns the parameter name (see GetString) parsed by p.  Invalid values return *validation.Errors coded
+// errs.InvalidArgument, wrapping the *UUIDError.
+func GetUUIDWith(r *http.Request, name string, required bool, p UUIDParser) (uuid.UUID, bool, error) {
+	s, ok, err := GetString(r, name, required)
+	if err != nil || !ok {
+		return uuid.UUID{}, false, err
+	}
+
+	id, err := p.Parse(name, s)
+	if err != nil {
+		return uuid.UUID{}, false, errs.WithCode(validation.New(name, err), errs.InvalidArgument)
+	}
+
+	return id, true, nil
+}
+
+// PathUUIDWith returns the path value name parsed by p, see Path for the handling of missing and malformed values.
+func PathUUIDWith(r *http.Request, name string, p UUIDParser) (uuid.UUID, error) {
+	s := r.PathValue(name)
+	if s == "" {
+		return uuid.UUID{}, errs.WithCode(fmt.Errorf("%s: %w", name, ErrNotFound), errs.NotFound)
+	}
+
+	id, err := p.Parse(name, s)
+	if err != nil {
+		return uuid.UUID{}, malformedPath(name, err)
+	}
+
+	return id, nil
+}
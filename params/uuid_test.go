@@ -0,0 +1,102 @@
+package params
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/validation"
+)
+
+const (
+	testUUIDv1 = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	testUUIDv4 = "48ab873f-d4fc-4e2b-bf92-9440e431ff54"
+)
+
+func TestUUIDParser(t *testing.T) {
+	v7 := uuid.Must(uuid.NewV7())
+
+	tests := []struct {
+		name    string
+		parser  UUIDParser
+		value   string
+		want    string
+		wantErr string
+	}{
+		{"any", DefaultUUIDParser, testUUIDv1, testUUIDv1, ""},
+		{"uppercase", DefaultUUIDParser, "48AB873F-D4FC-4E2B-BF92-9440E431FF54", testUUIDv4, ""},
+		{"v4", RandomUUIDParser, testUUIDv4, testUUIDv4, ""},
+		{"v7", RandomUUIDParser, v7.String(), v7.String(), ""},
+		{"v1", RandomUUIDParser, testUUIDv1, "", "must be a version 4 or 7 UUID"},
+		{"single", UUIDParser{Versions: []uuid.Version{7}}, testUUIDv4, "", "must be a version 7 UUID"},
+		{"three", UUIDParser{Versions: []uuid.Version{1, 4, 7}}, v7.String(), v7.String(), ""},
+		{"invalid", RandomUUIDParser, "a123", "", "must be a valid UUID"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := test.parser.Parse("id", test.value)
+			if test.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.want, id.String())
+
+				return
+			}
+
+			var ue *UUIDError
+
+			require.ErrorAs(t, err, &ue)
+			assert.Equal(t, test.wantErr, ue.UserError())
+			assert.Equal(t, "id: invalid UUID "+`"`+test.value+`": `+test.wantErr, err.Error())
+
+			code, _ := errs.GetCode(err)
+			assert.Equal(t, errs.InvalidArgument, code)
+		})
+	}
+
+	versions := UUIDParser{Versions: []uuid.Version{1, 4, 7}}
+	_, err := versions.Parse("", "00000000-0000-2000-8000-000000000000")
+	assert.EqualError(t, err, `invalid UUID "00000000-0000-2000-8000-000000000000": must be a version 1, 4 or 7 UUID`)
+}
+
+func TestGetUUIDWith(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?a="+testUUIDv4+"&b="+testUUIDv1, nil)
+
+	id, ok, err := GetUUIDWith(r, "a", true, RandomUUIDParser)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, testUUIDv4, id.String())
+
+	_, ok, err = GetUUIDWith(r, "missing", false, RandomUUIDParser)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = GetUUIDWith(r, "missing", true, RandomUUIDParser)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	_, _, err = GetUUIDWith(r, "b", true, RandomUUIDParser)
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{"b": {"must be a version 4 or 7 UUID"}}, ee.Fields())
+}
+
+func TestPathUUIDWith(t *testing.T) {
+	id, err := PathUUIDWith(pathRequest("/users/{id}", "/users/"+testUUIDv4), "id", RandomUUIDParser)
+	require.NoError(t, err)
+	assert.Equal(t, testUUIDv4, id.String())
+
+	_, err = PathUUIDWith(pathRequest("/users/{id}", "/users/"+testUUIDv1), "id", RandomUUIDParser)
+
+	code, _ := errs.GetCode(err)
+	assert.Equal(t, errs.InvalidArgument, code)
+
+	_, err = PathUUIDWith(pathRequest("/users/{id}", "/users/"+testUUIDv4), "other", RandomUUIDParser)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,135 @@
+package pgxutil
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// DefaultCopyBatchSize is the number of rows per COPY when CopyOptions.BatchSize is 0.
+const DefaultCopyBatchSize = 10_000
+
+// CopyConn runs COPY FROM, implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx.
+type CopyConn interface {
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64,
+		error)
+}
+
+// CopyOptions configures CopyStructs and CopySeq.
+type CopyOptions struct {
+	// Columns are the copied columns, defaults to all the columns mapped by the struct (see ScanStruct).
+	Columns []string
+	// BatchSize is the number of rows per COPY, defaults to DefaultCopyBatchSize.
+	BatchSize int
+}
+
+// CopyStructs inserts rows into table (optionally schema qualified) with the COPY protocol, see CopySeq.
+func CopyStructs[T any](ctx context.Context, conn CopyConn, table string, rows []T, opts CopyOptions) (int64, error) {
+	return CopySeq(ctx, conn, table, slices.Values(rows), opts)
+}
+
+// CopySeq inserts the rows of seq into table (optionally schema qualified) with the COPY protocol, in batches of
+// BatchSize rows, logging the progress after each batch.  The struct fields map to columns as in ScanStruct.  Each
+// batch is a separate COPY, run conn in a transaction to insert all or none.  Returns the number of rows copied,
+// including those of the batches copied before an error.
+func CopySeq[T any](ctx context.Context, conn CopyConn, table string, seq iter.Seq[T], opts CopyOptions) (int64, error) {
+	var zero T
+
+	t := reflect.TypeOf(zero)
+	if t == nil || t.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("%w: %T", ErrScanType, zero)
+	}
+
+	columns, indexes, err := copyColumns(t, opts.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultCopyBatchSize
+	}
+
+	identifier := pgx.Identifier(strings.Split(table, "."))
+	batch := make([][]any, 0, batchSize)
+	start := time.Now()
+	log := zerolog.Ctx(ctx)
+
+	var total int64
+
+	flush := func() error {
+		n, err := conn.CopyFrom(ctx, identifier, columns, pgx.CopyFromRows(batch))
+		total += n
+
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", table, err)
+		}
+
+		log.Info().Str("db.table", table).Int64("db.row_count", total).Dur("duration", time.Since(start)).Msg("copy progress")
+
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for row := range seq {
+		v := reflect.ValueOf(row)
+		values := make([]any, len(indexes))
+
+		for i, index := range indexes {
+			values[i] = v.FieldByIndex(index).Interface()
+		}
+
+		batch = append(batch, values)
+
+		if len(batch) == batchSize {
+			if err = flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+
+	if len(batch) > 0 {
+		if err = flush(); err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// copyColumns returns the columns and field indexes of t for the names columns, all mapped columns if empty.
+func copyColumns(t reflect.Type, names []string) ([]string, [][]int, error) {
+	fields := structColumns(t)
+
+	if len(names) == 0 {
+		columns := make([]string, len(fields.fields))
+		indexes := make([][]int, len(fields.fields))
+
+		for i, f := range fields.fields {
+			columns[i], indexes[i] = f.column, f.index
+		}
+
+		return columns, indexes, nil
+	}
+
+	indexes := make([][]int, len(names))
+
+	for i, name := range names {
+		index, ok := fields.lookup(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %s from %s", ErrUnmappedColumn, name, t)
+		}
+
+		indexes[i] = index
+	}
+
+	return names, indexes, nil
+}
@@ -0,0 +1,108 @@
+package pgxutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxutil"
+)
+
+// copyConn records the rows of each COPY, failing the COPY number failAt (1 based).
+type copyConn struct {
+	table   pgx.Identifier
+	columns []string
+	batches [][][]any
+	failAt  int
+}
+
+var errCopy = errors.New("copy failed")
+
+func (c *copyConn) CopyFrom(_ context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource,
+) (int64, error) {
+	c.table, c.columns = table, columns
+
+	if len(c.batches)+1 == c.failAt {
+		return 0, errCopy
+	}
+
+	var rows [][]any
+
+	for src.Next() {
+		values, err := src.Values()
+		if err != nil {
+			return 0, err
+		}
+
+		rows = append(rows, values)
+	}
+
+	c.batches = append(c.batches, rows)
+
+	return int64(len(rows)), nil
+}
+
+type importRow struct {
+	timestamps
+	UserID  int
+	Name    string  `db:"full_name"`
+	Note    *string `db:"note"`
+	Home    address `db:"home,prefix"`
+	Ignored string  `db:"-"`
+}
+
+func TestCopyStructs(t *testing.T) {
+	var buf bytes.Buffer
+
+	ctx := zerolog.New(&buf).WithContext(context.Background())
+	conn := &copyConn{}
+	rows := []importRow{
+		{UserID: 1, Name: "bob", Home: address{Street: "1 Main St", City: "Springfield"}},
+		{UserID: 2, Name: "alice"},
+		{UserID: 3, Name: "carol"},
+	}
+
+	n, err := pgxutil.CopyStructs(ctx, conn, "app.users", rows, pgxutil.CopyOptions{BatchSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, pgx.Identifier{"app", "users"}, conn.table)
+	assert.Equal(t, []string{"user_id", "full_name", "note", "created_at", "home_street", "home_city"}, conn.columns)
+	require.Len(t, conn.batches, 2)
+	assert.Equal(t, []any{1, "bob", (*string)(nil), rows[0].CreatedAt, "1 Main St", "Springfield"}, conn.batches[0][0])
+	assert.Len(t, conn.batches[1], 1)
+	assert.Contains(t, buf.String(), `"db.table":"app.users","db.row_count":2,`)
+	assert.Contains(t, buf.String(), `"db.table":"app.users","db.row_count":3,`)
+}
+
+func TestCopySeq(t *testing.T) {
+	conn := &copyConn{failAt: 2}
+	seq := func(yield func(importRow) bool) {
+		for i := range 5 {
+			if !yield(importRow{UserID: i}) {
+				return
+			}
+		}
+	}
+
+	n, err := pgxutil.CopySeq(context.Background(), conn, "users", seq,
+		pgxutil.CopyOptions{Columns: []string{"user_id", "full_name"}, BatchSize: 2})
+	require.ErrorIs(t, err, errCopy)
+	require.EqualError(t, err, "copy users: copy failed")
+	assert.Equal(t, int64(2), n)
+	assert.Equal(t, []string{"user_id", "full_name"}, conn.columns)
+	assert.Equal(t, [][][]any{{{0, ""}, {1, ""}}}, conn.batches)
+
+	_, err = pgxutil.CopySeq(context.Background(), conn, "users", slices.Values([]importRow{}),
+		pgxutil.CopyOptions{Columns: []string{"ignored"}})
+	require.ErrorIs(t, err, pgxutil.ErrUnmappedColumn)
+
+	_, err = pgxutil.CopyStructs(context.Background(), conn, "users", []int{1}, pgxutil.CopyOptions{})
+	require.ErrorIs(t, err, pgxutil.ErrScanType)
+}
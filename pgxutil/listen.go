@@ -0,0 +1,209 @@
+package pgxutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/errs"
+)
+
+// ListenConn is the dedicated connection of a Listener, implemented by *pgx.Conn.
+type ListenConn interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+	Close(ctx context.Context) error
+}
+
+// NotifyHandler handles the notifications of a channel.
+type NotifyHandler func(ctx context.Context, n *pgconn.Notification) error
+
+const (
+	defaultListenRetryBase = 100 * time.Millisecond
+	defaultListenRetryMax  = 30 * time.Second
+)
+
+// Listener delivers the notifications of LISTEN channels to handlers, over a dedicated connection reconnected with
+// backoff (re-listening to all channels) when lost.  Handlers run sequentially, errors and panics are logged.
+type Listener struct {
+	// Connect opens the dedicated connection, e.g. func(ctx) (ListenConn, error) { return pgx.Connect(ctx, url) }.
+	Connect func(ctx context.Context) (ListenConn, error)
+	// Logger logs connection failures and handler errors.
+	Logger zerolog.Logger
+	// BaseDelay is the initial reconnect delay, doubled for each failed attempt, defaults to 100ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the reconnect delay, defaults to 30s.
+	MaxDelay time.Duration
+	// DedupeWindow drops notifications with the same DedupeID received within this long, 0 disables it.
+	DedupeWindow time.Duration
+	// DedupeID returns the ID of a notification for DedupeWindow, defaults to the channel and payload.
+	DedupeID func(n *pgconn.Notification) string
+
+	mu       sync.Mutex
+	handlers map[string][]NotifyHandler
+	seen     map[string]time.Time
+	pruned   time.Time
+}
+
+// NewListener returns a Listener using connect for its connection.
+func NewListener(connect func(ctx context.Context) (ListenConn, error), logger zerolog.Logger) *Listener {
+	return &Listener{Connect: connect, Logger: logger.With().Str("module", "listen").Logger()}
+}
+
+// Handle adds fn as a handler of channel, before Listen is called.
+func (l *Listener) Handle(channel string, fn NotifyHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.handlers == nil {
+		l.handlers = map[string][]NotifyHandler{}
+	}
+
+	l.handlers[channel] = append(l.handlers[channel], fn)
+}
+
+// Listen receives notifications until ctx is done, reconnecting on failures.  Returns ctx.Err().
+func (l *Listener) Listen(ctx context.Context) error {
+	base, maxDelay := l.BaseDelay, l.MaxDelay
+	if base <= 0 {
+		base = defaultListenRetryBase
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = defaultListenRetryMax
+	}
+
+	for attempt := 0; ; attempt++ {
+		connected, err := l.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err() //nolint:wrapcheck
+		}
+
+		if connected {
+			attempt = 0
+		}
+
+		delay := errs.RetryDelay(err, attempt, base, maxDelay)
+
+		l.Logger.Warn().Err(err).Int("attempt", attempt+1).Dur("delay", delay).Msg("listen reconnecting")
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err() //nolint:wrapcheck
+		case <-timer.C:
+		}
+	}
+}
+
+// session connects, listens to the channels and delivers notifications until the connection fails.  connected
+// reports whether the channels were listened to.
+func (l *Listener) session(ctx context.Context) (connected bool, err error) { //nolint:nonamedreturns
+	conn, err := l.Connect(ctx)
+	if err != nil {
+		return false, fmt.Errorf("listen connect: %w", err)
+	}
+
+	defer func() { _ = conn.Close(context.WithoutCancel(ctx)) }()
+
+	for _, channel := range l.channels() {
+		if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return false, fmt.Errorf("listen %s: %w", channel, err)
+		}
+	}
+
+	l.Logger.Debug().Strs("channels", l.channels()).Msg("listening")
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, fmt.Errorf("listen wait: %w", err)
+		}
+
+		if l.duplicate(n) {
+			continue
+		}
+
+		l.deliver(ctx, n)
+	}
+}
+
+func (l *Listener) channels() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]string, 0, len(l.handlers))
+	for channel := range l.handlers {
+		out = append(out, channel)
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+// duplicate reports whether the notification was received within DedupeWindow, recording it.
+func (l *Listener) duplicate(n *pgconn.Notification) bool {
+	if l.DedupeWindow <= 0 {
+		return false
+	}
+
+	id := n.Channel + "\n" + n.Payload
+	if l.DedupeID != nil {
+		id = l.DedupeID(n)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	if l.seen == nil {
+		l.seen = map[string]time.Time{}
+	}
+
+	if now.Sub(l.pruned) >= l.DedupeWindow {
+		for k, at := range l.seen {
+			if now.Sub(at) >= l.DedupeWindow {
+				delete(l.seen, k)
+			}
+		}
+
+		l.pruned = now
+	}
+
+	if at, ok := l.seen[id]; ok && now.Sub(at) < l.DedupeWindow {
+		return true
+	}
+
+	l.seen[id] = now
+
+	return false
+}
+
+func (l *Listener) deliver(ctx context.Context, n *pgconn.Notification) {
+	l.mu.Lock()
+	handlers := l.handlers[n.Channel]
+	l.mu.Unlock()
+
+	for _, fn := range handlers {
+		if err := l.call(ctx, fn, n); err != nil {
+			l.Logger.Error().Err(err).Str("channel", n.Channel).Str("payload", n.Payload).Msg("notification handler")
+		}
+	}
+}
+
+func (l *Listener) call(ctx context.Context, fn NotifyHandler, n *pgconn.Notification) (err error) { //nolint:nonamedreturns
+	defer errs.Recover(&err)
+
+	return fn(ctx, n)
+}
@@ -0,0 +1,162 @@
+package pgxutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxutil"
+)
+
+var errConnLost = errors.New("conn lost")
+
+// listenConn delivers its notifications, then fails as a lost connection.
+type listenConn struct {
+	mu       sync.Mutex
+	execs    []string
+	notifies []*pgconn.Notification
+	closed   bool
+}
+
+func (c *listenConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.execs = append(c.execs, sql)
+
+	return pgconn.NewCommandTag("LISTEN"), nil
+}
+
+func (c *listenConn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.notifies) == 0 {
+		return nil, errConnLost
+	}
+
+	n := c.notifies[0]
+	c.notifies = c.notifies[1:]
+
+	return n, nil
+}
+
+func (c *listenConn) Close(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	return nil
+}
+
+func notification(channel, payload string) *pgconn.Notification {
+	return &pgconn.Notification{Channel: channel, Payload: payload}
+}
+
+func TestListener(t *testing.T) {
+	first := &listenConn{notifies: []*pgconn.Notification{
+		notification("jobs", "1"),
+		notification("jobs", "1"),
+		notification("other", "x"),
+		notification("events", "boom"),
+	}}
+	second := &listenConn{notifies: []*pgconn.Notification{notification("jobs", "2")}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	connects := 0
+	connect := func(context.Context) (pgxutil.ListenConn, error) {
+		connects++
+
+		switch connects {
+		case 1:
+			return nil, errConnLost
+		case 2:
+			return first, nil
+		case 3:
+			return second, nil
+		}
+
+		cancel()
+
+		return nil, context.Canceled
+	}
+
+	var buf bytes.Buffer
+
+	l := pgxutil.NewListener(connect, zerolog.New(&buf))
+	l.BaseDelay = time.Millisecond
+	l.DedupeWindow = time.Minute
+
+	var payloads []string
+
+	l.Handle("jobs", func(_ context.Context, n *pgconn.Notification) error {
+		payloads = append(payloads, n.Payload)
+
+		return nil
+	})
+	l.Handle("events", func(context.Context, *pgconn.Notification) error {
+		panic("handler failed")
+	})
+
+	err := l.Listen(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, []string{"1", "2"}, payloads)
+	assert.Equal(t, 4, connects)
+	assert.Equal(t, []string{`LISTEN "events"`, `LISTEN "jobs"`}, first.execs)
+	assert.Equal(t, first.execs, second.execs, "re-listens after reconnecting")
+	assert.True(t, first.closed)
+	assert.True(t, second.closed)
+	assert.Contains(t, buf.String(), `"message":"listen reconnecting"`)
+	assert.Contains(t, buf.String(), `"channel":"events","payload":"boom","message":"notification handler"`)
+	assert.Contains(t, buf.String(), "handler failed")
+}
+
+func TestListenerDedupeID(t *testing.T) {
+	conn := &listenConn{notifies: []*pgconn.Notification{
+		notification("jobs", `{"id":1,"try":1}`),
+		notification("jobs", `{"id":1,"try":2}`),
+		notification("jobs", `{"id":2,"try":1}`),
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l := pgxutil.NewListener(func(context.Context) (pgxutil.ListenConn, error) {
+		if conn == nil {
+			cancel()
+
+			return nil, context.Canceled
+		}
+
+		c := conn
+		conn = nil
+
+		return c, nil
+	}, zerolog.Nop())
+	l.BaseDelay = time.Millisecond
+	l.DedupeWindow = time.Minute
+	l.DedupeID = func(n *pgconn.Notification) string { return n.Payload[:7] }
+
+	var payloads []string
+
+	l.Handle("jobs", func(_ context.Context, n *pgconn.Notification) error {
+		payloads = append(payloads, n.Payload)
+
+		return nil
+	})
+
+	require.ErrorIs(t, l.Listen(ctx), context.Canceled)
+	assert.Equal(t, []string{`{"id":1,"try":1}`, `{"id":2,"try":1}`}, payloads)
+}
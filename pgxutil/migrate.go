@@ -0,0 +1,383 @@
+package pgxutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// ErrMigrationName is returned for migration files not named VERSION_NAME.up.sql or VERSION_NAME.down.sql.
+	ErrMigrationName = errors.New("invalid migration file name")
+	// ErrMigrationDuplicate is returned for migration files of a version with different names, or more than one up or
+	// down file.
+	ErrMigrationDuplicate = errors.New("duplicate migration version")
+	// ErrMigrationMissing is returned when rolling back a migration without a down file.
+	ErrMigrationMissing = errors.New("missing migration")
+	// ErrMigrateCommand is returned by Migrator.Run for unknown commands.
+	ErrMigrateCommand = errors.New("unknown migrate command, use up, down [n] or status")
+)
+
+// DefaultMigrationTable is the table recording applied migrations when Migrator.Table is empty.
+const DefaultMigrationTable = "schema_migrations"
+
+// Migration is a schema change read from VERSION_NAME.up.sql and VERSION_NAME.down.sql files, e.g.
+// 0001_create_users.up.sql.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration is applied, see Migrator.Status.
+type MigrationStatus struct {
+	Migration
+	// Applied is when the migration was applied, zero if pending.
+	Applied time.Time
+}
+
+// MigrateConn is the single connection (e.g. *pgx.Conn or an acquired *pgxpool.Conn) running migrations, holding
+// the advisory lock.
+type MigrateConn interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// Migrator applies the SQL migrations of FS, e.g. an embed.FS, recording them in Table.  Each migration runs in a
+// transaction, and a session advisory lock serializes concurrent deploys.
+type Migrator struct {
+	FS fs.FS
+	// Dir is the directory of the migrations in FS, defaults to the root.
+	Dir string
+	// Table records the applied migrations, optionally schema qualified, defaults to DefaultMigrationTable.
+	Table string
+	// LockID is the advisory lock key, defaults to a hash of Table.
+	LockID int64
+	// Logger logs the progress.
+	Logger zerolog.Logger
+}
+
+// NewMigrator returns a Migrator of the migrations in dir of fsys.
+func NewMigrator(fsys fs.FS, dir string, logger zerolog.Logger) *Migrator {
+	return &Migrator{FS: fsys, Dir: dir, Logger: logger.With().Str("module", "migrate").Logger()}
+}
+
+// Migrations returns the migrations sorted by version.  Each version has one name, and at most one up and one down
+// file.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	dir := m.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	files, err := fs.Glob(m.FS, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("migrations: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	first := map[int64]string{} // version => first file
+	seen := map[string]bool{}   // version.direction
+
+	for _, file := range files {
+		base := path.Base(file)
+
+		name, direction, ok := strings.Cut(strings.TrimSuffix(base, ".sql"), ".")
+		version, title, found := strings.Cut(name, "_")
+		v, vErr := strconv.ParseInt(version, 10, 64)
+
+		if !ok || !found || vErr != nil || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("%w: %s", ErrMigrationName, base)
+		}
+
+		key := strconv.FormatInt(v, 10) + "." + direction
+		mig := byVersion[v]
+
+		switch {
+		case mig == nil:
+			mig = &Migration{Version: v, Name: title}
+			byVersion[v] = mig
+			first[v] = base
+		case mig.Name != title || seen[key]:
+			return nil, fmt.Errorf("%w: %s and %s", ErrMigrationDuplicate, first[v], base)
+		}
+
+		seen[key] = true
+
+		b, err := fs.ReadFile(m.FS, file)
+		if err != nil {
+			return nil, fmt.Errorf("migrations: %w", err)
+		}
+
+		if direction == "up" {
+			mig.Up = string(b)
+		} else {
+			mig.Down = string(b)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		out = append(out, *mig)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+
+	return out, nil
+}
+
+// Up applies the pending migrations, returning the number applied.
+func (m *Migrator) Up(ctx context.Context, conn MigrateConn) (int, error) {
+	return m.locked(ctx, conn, func(migrations []Migration, applied map[int64]time.Time) (int, error) {
+		n := 0
+
+		for _, mig := range migrations {
+			if _, ok := applied[mig.Version]; ok || mig.Up == "" {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, mig, mig.Up,
+				"INSERT INTO "+m.table()+" (version, name) VALUES ($1, $2)", mig.Version, mig.Name); err != nil {
+				return n, err
+			}
+
+			n++
+		}
+
+		m.Logger.Info().Int("count", n).Msg("migrations applied")
+
+		return n, nil
+	})
+}
+
+// Down rolls back the last steps applied migrations, returning the number rolled back.
+func (m *Migrator) Down(ctx context.Context, conn MigrateConn, steps int) (int, error) {
+	return m.locked(ctx, conn, func(migrations []Migration, applied map[int64]time.Time) (int, error) {
+		n := 0
+
+		for i := len(migrations) - 1; i >= 0 && n < steps; i-- {
+			mig := migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+
+			if mig.Down == "" {
+				return n, fmt.Errorf("%w: %d_%s.down.sql", ErrMigrationMissing, mig.Version, mig.Name)
+			}
+
+			if err := m.apply(ctx, conn, mig, mig.Down,
+				"DELETE FROM "+m.table()+" WHERE version = $1", mig.Version); err != nil {
+				return n, err
+			}
+
+			n++
+		}
+
+		m.Logger.Info().Int("count", n).Msg("migrations rolled back")
+
+		return n, nil
+	})
+}
+
+// Status returns the migrations with the time they were applied.
+func (m *Migrator) Status(ctx context.Context, conn MigrateConn) ([]MigrationStatus, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.createTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]MigrationStatus, len(migrations))
+	for i, mig := range migrations {
+		out[i] = MigrationStatus{Migration: mig, Applied: applied[mig.Version]}
+	}
+
+	return out, nil
+}
+
+// Run runs the command of args from main(): "up", "down" (one migration), "down N" or "status" (logged).
+func (m *Migrator) Run(ctx context.Context, conn MigrateConn, args []string) error {
+	if len(args) == 0 {
+		return ErrMigrateCommand
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		_, err := m.Up(ctx, conn)
+
+		return err
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("%w: down %q", ErrMigrateCommand, args[1])
+			}
+
+			steps = n
+		}
+
+		_, err := m.Down(ctx, conn, steps)
+
+		return err
+	case args[0] == "status" && len(args) == 1:
+		status, err := m.Status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range status {
+			e := m.Logger.Info().Int64("version", s.Version).Str("name", s.Name).Bool("applied", !s.Applied.IsZero())
+			if !s.Applied.IsZero() {
+				e = e.Time("applied_at", s.Applied)
+			}
+
+			e.Msg("migration")
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", ErrMigrateCommand, strings.Join(args, " "))
+}
+
+// locked runs fn with the migrations and the applied versions while holding the advisory lock.
+func (m *Migrator) locked(ctx context.Context, conn MigrateConn,
+	fn func(migrations []Migration, applied map[int64]time.Time) (int, error),
+) (int, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	m.Logger.Debug().Int64("lock", m.lockID()).Msg("waiting for migration lock")
+
+	if _, err = conn.Exec(ctx, "SELECT pg_advisory_lock($1)", m.lockID()); err != nil {
+		return 0, fmt.Errorf("migration lock: %w", err)
+	}
+
+	defer func() {
+		_, _ = conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", m.lockID())
+	}()
+
+	if err = m.createTable(ctx, conn); err != nil {
+		return 0, err
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	return fn(migrations, applied)
+}
+
+// apply runs the sql of mig and then record in a transaction.
+func (m *Migrator) apply(ctx context.Context, conn MigrateConn, mig Migration, sql, record string, args ...any) error {
+	start := time.Now()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("migration %d: %w", mig.Version, err)
+	}
+
+	defer func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }()
+
+	if _, err = tx.Exec(ctx, sql); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	if _, err = tx.Exec(ctx, record, args...); err != nil {
+		return fmt.Errorf("migration %d: %w", mig.Version, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("migration %d: %w", mig.Version, err)
+	}
+
+	m.Logger.Info().Int64("version", mig.Version).Str("name", mig.Name).Dur("duration", time.Since(start)).
+		Msg("migration")
+
+	return nil
+}
+
+func (m *Migrator) createTable(ctx context.Context, conn MigrateConn) error {
+	_, err := conn.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+m.table()+
+		" (version bigint PRIMARY KEY, name text NOT NULL, applied_at timestamptz NOT NULL DEFAULT now())")
+	if err != nil {
+		return fmt.Errorf("migration table: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context, conn MigrateConn) (map[int64]time.Time, error) {
+	rows, err := conn.Query(ctx, "SELECT version, applied_at FROM "+m.table())
+	if err != nil {
+		return nil, fmt.Errorf("applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	out := map[int64]time.Time{}
+
+	for rows.Next() {
+		var (
+			version int64
+			at      time.Time
+		)
+
+		if err = rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("applied migrations: %w", err)
+		}
+
+		out[version] = at
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("applied migrations: %w", err)
+	}
+
+	return out, nil
+}
+
+func (m *Migrator) table() string {
+	table := m.Table
+	if table == "" {
+		table = DefaultMigrationTable
+	}
+
+	return pgx.Identifier(strings.Split(table, ".")).Sanitize()
+}
+
+func (m *Migrator) lockID() int64 {
+	if m.LockID != 0 {
+		return m.LockID
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(m.table()))
+
+	return int64(h.Sum64()) //nolint:gosec // any key
+}
@@ -0,0 +1,203 @@
+package pgxutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxutil"
+)
+
+// migrateDB is an in-memory MigrateConn recording the statements run.
+type migrateDB struct {
+	applied map[int64]time.Time
+	log     []string
+}
+
+func (db *migrateDB) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	db.log = append(db.log, strings.Fields(sql)[0]+" "+strings.Fields(sql)[1])
+
+	return pgconn.CommandTag{}, nil
+}
+
+func (db *migrateDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	rows := &migrateRows{}
+	for v, at := range db.applied {
+		rows.versions = append(rows.versions, v)
+		rows.at = append(rows.at, at)
+	}
+
+	return rows, nil
+}
+
+func (db *migrateDB) Begin(context.Context) (pgx.Tx, error) {
+	return &migrateTx{db: db}, nil
+}
+
+type migrateTx struct {
+	pgx.Tx
+	db      *migrateDB
+	version int64
+	insert  bool
+}
+
+func (tx *migrateTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	if strings.Contains(sql, "FAIL") {
+		return pgconn.CommandTag{}, errors.New("syntax error")
+	}
+
+	switch {
+	case strings.HasPrefix(sql, `INSERT INTO "schema_migrations"`):
+		tx.version, tx.insert = args[0].(int64), true
+	case strings.HasPrefix(sql, `DELETE FROM "schema_migrations"`):
+		tx.version = args[0].(int64)
+	default:
+		tx.db.log = append(tx.db.log, strings.TrimSpace(sql))
+	}
+
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx *migrateTx) Commit(context.Context) error {
+	if tx.insert {
+		tx.db.applied[tx.version] = time.Now()
+	} else {
+		delete(tx.db.applied, tx.version)
+	}
+
+	return nil
+}
+
+func (tx *migrateTx) Rollback(context.Context) error { return nil }
+
+type migrateRows struct {
+	pgx.Rows
+	versions []int64
+	at       []time.Time
+	i        int
+}
+
+func (r *migrateRows) Next() bool { r.i++; return r.i <= len(r.versions) }
+func (r *migrateRows) Close()     {}
+func (r *migrateRows) Err() error { return nil }
+
+func (r *migrateRows) Scan(dest ...any) error {
+	*dest[0].(*int64) = r.versions[r.i-1]
+	*dest[1].(*time.Time) = r.at[r.i-1]
+
+	return nil
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/0001_users.up.sql":     {Data: []byte("CREATE TABLE users()")},
+		"migrations/0001_users.down.sql":   {Data: []byte("DROP TABLE users")},
+		"migrations/0002_orders.up.sql":    {Data: []byte("CREATE TABLE orders()")},
+		"migrations/0002_orders.down.sql":  {Data: []byte("DROP TABLE orders")},
+		"migrations/0010_indexes.up.sql":   {Data: []byte("CREATE INDEX i ON orders(id)")},
+		"migrations/0010_indexes.down.sql": {Data: []byte("DROP INDEX i")},
+		"migrations/README.md":             {Data: []byte("ignored")},
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	var buf bytes.Buffer
+
+	m := pgxutil.NewMigrator(testMigrations(), "migrations", zerolog.New(&buf))
+	db := &migrateDB{applied: map[int64]time.Time{}}
+	ctx := context.Background()
+
+	migrations, err := m.Migrations()
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, pgxutil.Migration{Version: 10, Name: "indexes", Up: "CREATE INDEX i ON orders(id)", Down: "DROP INDEX i"},
+		migrations[2])
+
+	n, err := m.Up(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []string{
+		"SELECT pg_advisory_lock($1)", "CREATE TABLE",
+		"CREATE TABLE users()", "CREATE TABLE orders()", "CREATE INDEX i ON orders(id)",
+		"SELECT pg_advisory_unlock($1)",
+	}, db.log)
+	assert.Contains(t, buf.String(), `"version":2,"name":"orders"`)
+
+	n, err = m.Up(ctx, db)
+	require.NoError(t, err)
+	assert.Zero(t, n, "already applied")
+
+	require.NoError(t, m.Run(ctx, db, []string{"down", "2"}))
+	assert.Len(t, db.applied, 1)
+	assert.Contains(t, db.applied, int64(1))
+
+	status, err := m.Status(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, status, 3)
+	assert.False(t, status[0].Applied.IsZero())
+	assert.True(t, status[1].Applied.IsZero())
+
+	buf.Reset()
+	require.NoError(t, m.Run(ctx, db, []string{"status"}))
+	assert.Equal(t, 3, strings.Count(buf.String(), `"message":"migration"`))
+
+	require.NoError(t, m.Run(ctx, db, []string{"down"}))
+	assert.Empty(t, db.applied)
+
+	for _, args := range [][]string{nil, {"sideways"}, {"down", "x"}, {"up", "1"}} {
+		assert.ErrorIs(t, m.Run(ctx, db, args), pgxutil.ErrMigrateCommand, args)
+	}
+}
+
+func TestMigratorErrors(t *testing.T) {
+	ctx := context.Background()
+	fsys := testMigrations()
+	fsys["migrations/0003_bad.up.sql"] = &fstest.MapFile{Data: []byte("FAIL")}
+
+	db := &migrateDB{applied: map[int64]time.Time{}}
+	m := pgxutil.NewMigrator(fsys, "migrations", zerolog.Nop())
+
+	n, err := m.Up(ctx, db)
+	require.EqualError(t, err, "migration 3_bad: syntax error")
+	assert.Equal(t, 2, n)
+	assert.Len(t, db.applied, 2, "the failed migration is rolled back")
+	assert.Equal(t, "SELECT pg_advisory_unlock($1)", db.log[len(db.log)-1], "unlocked")
+
+	delete(fsys, "migrations/0003_bad.up.sql")
+	fsys["migrations/0003_bad.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 1")}
+
+	_, err = m.Up(ctx, db)
+	require.NoError(t, err)
+
+	n, err = m.Down(ctx, db, 2)
+	require.ErrorIs(t, err, pgxutil.ErrMigrationMissing)
+	assert.Equal(t, 1, n, "10 rolled back, 3 has no down migration")
+
+	fsys["migrations/bad.sql"] = &fstest.MapFile{}
+
+	_, err = m.Migrations()
+	require.ErrorIs(t, err, pgxutil.ErrMigrationName)
+
+	delete(fsys, "migrations/bad.sql")
+	fsys["migrations/0003_other.down.sql"] = &fstest.MapFile{}
+
+	_, err = m.Migrations()
+	require.ErrorIs(t, err, pgxutil.ErrMigrationDuplicate)
+	assert.ErrorContains(t, err, "0003_bad.up.sql and 0003_other.down.sql")
+
+	delete(fsys, "migrations/0003_other.down.sql")
+	fsys["migrations/03_bad.up.sql"] = &fstest.MapFile{}
+
+	_, err = m.Migrations()
+	require.ErrorIs(t, err, pgxutil.ErrMigrationDuplicate, "second up file of version 3")
+}
@@ -0,0 +1,238 @@
+package pgxutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrNamedParam is returned by Named for parameters missing from the argument.
+	ErrNamedParam = errors.New("missing named parameter")
+	// ErrNamedArg is returned by Named for arguments that are not structs or string keyed maps.
+	ErrNamedArg = errors.New("named argument must be a struct or map[string]any")
+)
+
+// Querier runs queries, implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Named expands the :name parameters of sql into positional parameters, returning the args taken from arg: a
+// struct (fields named by the db tag, or matching the name case insensitively, embedded structs included) or a
+// map[string]any.  Repeated names share a parameter.  Casts (::int), string literals, quoted identifiers and
+// comments are left unchanged.
+func Named(sql string, arg any) (string, []any, error) {
+	lookup, err := namedLookup(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		sb    strings.Builder
+		args  []any
+		index = map[string]int{}
+	)
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		switch {
+		case c == '\'' || c == '"':
+			end := skipQuoted(sql, i, c)
+			sb.WriteString(sql[i:end])
+			i = end - 1
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+
+			sb.WriteString(sql[i : i+end])
+			i += end - 1
+		case c == ':' && i+1 < len(sql) && sql[i+1] == ':':
+			sb.WriteString("::")
+			i++
+		case c == ':' && i+1 < len(sql) && isNameStart(sql[i+1]):
+			end := i + 1
+			for end < len(sql) && isNamePart(sql[end]) {
+				end++
+			}
+
+			name := sql[i+1 : end]
+
+			n, ok := index[name]
+			if !ok {
+				v, found := lookup(name)
+				if !found {
+					return "", nil, fmt.Errorf("%w: %s", ErrNamedParam, name)
+				}
+
+				args = append(args, v)
+				n = len(args)
+				index[name] = n
+			}
+
+			sb.WriteString("$" + strconv.Itoa(n))
+			i = end - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return sb.String(), args, nil
+}
+
+// ExecNamed runs sql with the named parameters of arg, see Named.
+func ExecNamed(ctx context.Context, q Querier, sql string, arg any) (pgconn.CommandTag, error) {
+	sql, args, err := Named(sql, arg)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	return q.Exec(ctx, sql, args...) //nolint:wrapcheck
+}
+
+// QueryNamed runs sql with the named parameters of arg (see Named), scanning the rows into T by column name, see
+// pgx.RowToStructByName.
+func QueryNamed[T any](ctx context.Context, q Querier, sql string, arg any) ([]T, error) {
+	sql, args, err := Named(sql, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query:%w", err)
+	}
+
+	out, err := pgx.CollectRows(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return nil, fmt.Errorf("scan:%w", err)
+	}
+
+	return out, nil
+}
+
+// QueryNamedOne is QueryNamed for exactly one row, returning pgx.ErrNoRows if there is none.
+func QueryNamedOne[T any](ctx context.Context, q Querier, sql string, arg any) (T, error) {
+	var zero T
+
+	sql, args, err := Named(sql, arg)
+	if err != nil {
+		return zero, err
+	}
+
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return zero, fmt.Errorf("query:%w", err)
+	}
+
+	out, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[T])
+	if err != nil {
+		return zero, fmt.Errorf("scan:%w", err)
+	}
+
+	return out, nil
+}
+
+// namedLookup returns the lookup of parameter values of arg.
+func namedLookup(arg any) (func(name string) (any, bool), error) {
+	if m, ok := arg.(map[string]any); ok {
+		return func(name string) (any, bool) {
+			v, found := m[name]
+
+			return v, found
+		}, nil
+	}
+
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T", ErrNamedArg, arg)
+	}
+
+	return func(name string) (any, bool) {
+		f, found := namedField(v, name)
+		if !found {
+			return nil, false
+		}
+
+		return f.Interface(), true
+	}, nil
+}
+
+// namedField returns the exported field of v named name by its db tag or case insensitive name, searching embedded
+// structs after the fields of v.
+func namedField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+
+	var embedded []int
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag, hasTag := f.Tag.Lookup("db")
+		tag, _, _ = strings.Cut(tag, ",")
+
+		switch {
+		case tag == "-":
+			continue
+		case hasTag && tag != "":
+			if tag == name {
+				return v.Field(i), true
+			}
+		case f.Anonymous && f.Type.Kind() == reflect.Struct:
+			embedded = append(embedded, i)
+		case strings.EqualFold(f.Name, name) || strings.EqualFold(f.Name, strings.ReplaceAll(name, "_", "")):
+			return v.Field(i), true
+		}
+	}
+
+	for _, i := range embedded {
+		if f, found := namedField(v.Field(i), name); found {
+			return f, true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// skipQuoted returns the index after the literal or identifier quoted by q starting at start, doubled quotes are
+// escapes.
+func skipQuoted(sql string, start int, q byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] == q {
+			if i+1 < len(sql) && sql[i+1] == q {
+				i++
+
+				continue
+			}
+
+			return i + 1
+		}
+	}
+
+	return len(sql)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNamePart(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
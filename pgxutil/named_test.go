@@ -0,0 +1,159 @@
+package pgxutil_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxutil"
+)
+
+// fakeRows returns values by column, Scan assigns them by reflection.
+type fakeRows struct {
+	pgx.Rows
+	columns []string
+	values  [][]any
+	i       int
+}
+
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription {
+	out := make([]pgconn.FieldDescription, len(r.columns))
+	for i, c := range r.columns {
+		out[i].Name = c
+	}
+
+	return out
+}
+
+func (r *fakeRows) Next() bool { r.i++; return r.i <= len(r.values) }
+func (r *fakeRows) Close()     {}
+func (r *fakeRows) Err() error { return nil }
+
+func (r *fakeRows) Values() ([]any, error) { return r.values[r.i-1], nil }
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		v := r.values[r.i-1][i]
+		target := reflect.ValueOf(d).Elem()
+
+		switch {
+		case v == nil:
+			target.SetZero()
+		case target.Kind() == reflect.Pointer:
+			p := reflect.New(target.Type().Elem())
+			p.Elem().Set(reflect.ValueOf(v))
+			target.Set(p)
+		case target.Kind() == reflect.Interface:
+			target.Set(reflect.ValueOf(v))
+		default:
+			if !reflect.TypeOf(v).AssignableTo(target.Type()) {
+				return errors.New("cannot scan " + r.columns[i])
+			}
+
+			target.Set(reflect.ValueOf(v))
+		}
+	}
+
+	return nil
+}
+
+// fakeQuerier returns rows for any query, recording the last query.
+type fakeQuerier struct {
+	rows *fakeRows
+	sql  string
+	args []any
+}
+
+func (q *fakeQuerier) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.sql, q.args = sql, args
+
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (q *fakeQuerier) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.sql, q.args = sql, args
+
+	return q.rows, nil
+}
+
+type Audit struct {
+	UpdatedBy string
+}
+
+type userParams struct {
+	Audit
+	ID     int    `db:"user_id"`
+	Name   string `db:"name"`
+	Status string
+	secret string
+}
+
+func TestNamed(t *testing.T) {
+	p := userParams{ID: 42, Name: "bob", Status: "active", Audit: Audit{UpdatedBy: "admin"}}
+
+	sql, args, err := pgxutil.Named(`UPDATE users SET name = :name, status = :status::user_status, updated_by = :updated_by
+WHERE id = :user_id AND name <> :name -- :ignored
+AND note <> ':quoted' AND ":col" IS NOT NULL`, &p)
+	require.NoError(t, err)
+	assert.Equal(t, `UPDATE users SET name = $1, status = $2::user_status, updated_by = $3
+WHERE id = $4 AND name <> $1 -- :ignored
+AND note <> ':quoted' AND ":col" IS NOT NULL`, sql)
+	assert.Equal(t, []any{"bob", "active", "admin", 42}, args)
+
+	sql, args, err = pgxutil.Named("SELECT * FROM t WHERE a = :a AND b = :b", map[string]any{"a": 1, "b": nil})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM t WHERE a = $1 AND b = $2", sql)
+	assert.Equal(t, []any{1, nil}, args)
+
+	_, _, err = pgxutil.Named("SELECT :secret", p)
+	require.ErrorIs(t, err, pgxutil.ErrNamedParam)
+	require.EqualError(t, err, "missing named parameter: secret")
+
+	_, _, err = pgxutil.Named("SELECT :a", 1)
+	require.ErrorIs(t, err, pgxutil.ErrNamedArg)
+}
+
+type userRow struct {
+	ID   int     `db:"id"`
+	Name string  `db:"name"`
+	Bio  *string `db:"bio"`
+}
+
+func TestQueryNamed(t *testing.T) {
+	q := &fakeQuerier{rows: &fakeRows{
+		columns: []string{"id", "name", "bio"},
+		values:  [][]any{{1, "bob", nil}, {2, "alice", "hi"}},
+	}}
+
+	users, err := pgxutil.QueryNamed[userRow](context.Background(), q,
+		"SELECT id, name, bio FROM users WHERE status = :status", map[string]any{"status": "active"})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT id, name, bio FROM users WHERE status = $1", q.sql)
+	assert.Equal(t, []any{"active"}, q.args)
+	require.Len(t, users, 2)
+	assert.Equal(t, userRow{ID: 1, Name: "bob"}, users[0])
+	assert.Equal(t, "hi", *users[1].Bio)
+
+	q.rows = &fakeRows{columns: []string{"id", "name", "bio"}, values: [][]any{{3, "carol", nil}}}
+
+	u, err := pgxutil.QueryNamedOne[userRow](context.Background(), q, "SELECT * FROM users WHERE id = :id",
+		map[string]any{"id": 3})
+	require.NoError(t, err)
+	assert.Equal(t, "carol", u.Name)
+
+	q.rows = &fakeRows{columns: []string{"id"}}
+
+	_, err = pgxutil.QueryNamedOne[userRow](context.Background(), q, "SELECT 1", map[string]any{})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	tag, err := pgxutil.ExecNamed(context.Background(), q, "DELETE FROM users WHERE id = :user_id", userParams{ID: 7})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tag.RowsAffected())
+	assert.Equal(t, []any{7}, q.args)
+}
@@ -0,0 +1,42 @@
+package pgxutil
+
+import (
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/bir/iken/params"
+)
+
+// OrderBy returns the ORDER BY clause for fields, e.g. `ORDER BY "created_at" DESC, "name"`, or "" if fields is
+// empty.  columns maps sort field names to column names (dotted for qualified names, e.g. "u.created_at"), fields not
+// in columns use their name.  Identifiers are quoted, but fields must still be validated against an allowlist (see
+// params.Sort).
+func OrderBy(fields []params.SortField, columns map[string]string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("ORDER BY ")
+
+	for i, f := range fields {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		column, ok := columns[f.Name]
+		if !ok {
+			column = f.Name
+		}
+
+		sb.WriteString(pgx.Identifier(strings.Split(column, ".")).Sanitize())
+
+		if f.Desc {
+			sb.WriteString(" DESC")
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,21 @@
+package pgxutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/params"
+	"github.com/bir/iken/pgxutil"
+)
+
+func TestOrderBy(t *testing.T) {
+	assert.Equal(t, "", pgxutil.OrderBy(nil, nil))
+
+	fields := []params.SortField{{Name: "createdAt", Desc: true}, {Name: "name"}}
+
+	assert.Equal(t, `ORDER BY "createdAt" DESC, "name"`, pgxutil.OrderBy(fields, nil))
+	assert.Equal(t, `ORDER BY "u"."created_at" DESC, "name"`,
+		pgxutil.OrderBy(fields, map[string]string{"createdAt": "u.created_at"}))
+	assert.Equal(t, `ORDER BY "a""b"`, pgxutil.OrderBy([]params.SortField{{Name: `a"b`}}, nil))
+}
@@ -0,0 +1,189 @@
+package pgxutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/httputil"
+)
+
+// ErrPoolExhausted is returned by PoolMonitor.Check when every connection of the pool is acquired.
+var ErrPoolExhausted = errors.New("connection pool exhausted")
+
+// PoolMetricsPrefix prefixes the metric names written by PoolMonitor.
+var PoolMetricsPrefix = "db_pool_"
+
+// DefaultPoolInterval is the collection interval of PoolMonitor.Run when PoolMonitor.Interval is 0.
+const DefaultPoolInterval = 30 * time.Second
+
+// PoolStats is a snapshot of the statistics of a pool.
+type PoolStats struct {
+	Acquired     int32
+	Idle         int32
+	Constructing int32
+	Total        int32
+	Max          int32
+	// Acquires counts the successful acquires, EmptyAcquires those that waited for a connection, and
+	// CanceledAcquires those canceled by their context.
+	Acquires         int64
+	EmptyAcquires    int64
+	CanceledAcquires int64
+	// AcquireDuration is the total time spent in successful acquires.
+	AcquireDuration time.Duration
+}
+
+// NewPoolStats returns the PoolStats of s.
+func NewPoolStats(s *pgxpool.Stat) PoolStats {
+	return PoolStats{
+		Acquired:         s.AcquiredConns(),
+		Idle:             s.IdleConns(),
+		Constructing:     s.ConstructingConns(),
+		Total:            s.TotalConns(),
+		Max:              s.MaxConns(),
+		Acquires:         s.AcquireCount(),
+		EmptyAcquires:    s.EmptyAcquireCount(),
+		CanceledAcquires: s.CanceledAcquireCount(),
+		AcquireDuration:  s.AcquireDuration(),
+	}
+}
+
+// PoolMonitor periodically logs the statistics of a pool, exports them as metrics, and checks its health for
+// readiness endpoints.  Collections are logged at warn when the pool is exhausted or acquires were canceled since
+// the previous collection, otherwise at debug.
+type PoolMonitor struct {
+	// Stat returns the pool statistics.
+	Stat func() PoolStats
+	// Ping checks the database is reachable for Check, nil skips it.
+	Ping func(ctx context.Context) error
+	// Logger logs the collections.
+	Logger zerolog.Logger
+	// Interval is the collection interval of Run, defaults to DefaultPoolInterval.
+	Interval time.Duration
+
+	mu   sync.Mutex
+	last PoolStats
+}
+
+// NewPoolMonitor returns a PoolMonitor of pool.
+func NewPoolMonitor(pool *pgxpool.Pool, logger zerolog.Logger) *PoolMonitor {
+	return &PoolMonitor{
+		Stat:   func() PoolStats { return NewPoolStats(pool.Stat()) },
+		Ping:   pool.Ping,
+		Logger: logger.With().Str("module", "pool").Logger(),
+	}
+}
+
+// Run collects the statistics every Interval until ctx is done.
+func (m *PoolMonitor) Run(ctx context.Context) {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultPoolInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Collect()
+		}
+	}
+}
+
+// Collect logs the current statistics, with the acquires since the previous collection, and returns them.
+func (m *PoolMonitor) Collect() PoolStats {
+	s := m.Stat()
+
+	m.mu.Lock()
+	prev := m.last
+	m.last = s
+	m.mu.Unlock()
+
+	acquires := s.Acquires - prev.Acquires
+	canceled := s.CanceledAcquires - prev.CanceledAcquires
+
+	var wait time.Duration
+	if acquires > 0 {
+		wait = (s.AcquireDuration - prev.AcquireDuration) / time.Duration(acquires)
+	}
+
+	e := m.Logger.Debug()
+	if s.Acquired >= s.Max || canceled > 0 {
+		e = m.Logger.Warn()
+	}
+
+	e.Int32("db.pool.acquired", s.Acquired).
+		Int32("db.pool.idle", s.Idle).
+		Int32("db.pool.total", s.Total).
+		Int32("db.pool.max", s.Max).
+		Int64("db.pool.acquires", acquires).
+		Int64("db.pool.empty_acquires", s.EmptyAcquires-prev.EmptyAcquires).
+		Int64("db.pool.canceled_acquires", canceled).
+		Dur("db.pool.acquire_wait", wait).
+		Msg("pool stats")
+
+	return s
+}
+
+// Check returns an error if the database is unreachable, or ErrPoolExhausted if every connection is acquired.  Use it
+// as the readiness check, so exhausted instances stop receiving traffic.
+func (m *PoolMonitor) Check(ctx context.Context) error {
+	if m.Ping != nil {
+		if err := m.Ping(ctx); err != nil {
+			return fmt.Errorf("pool ping: %w", err)
+		}
+	}
+
+	if s := m.Stat(); s.Max > 0 && s.Acquired >= s.Max {
+		return fmt.Errorf("%w: %d/%d acquired", ErrPoolExhausted, s.Acquired, s.Max)
+	}
+
+	return nil
+}
+
+// WriteMetrics writes the current statistics in the Prometheus text exposition format, e.g.
+// `db_pool_acquired_conns 3`.
+func (m *PoolMonitor) WriteMetrics(w io.Writer) error {
+	s := m.Stat()
+
+	var out httputil.Metrics
+
+	for _, metric := range []struct {
+		name, kind, help string
+		value            any
+	}{
+		{"acquired_conns", "gauge", "Acquired connections.", s.Acquired},
+		{"idle_conns", "gauge", "Idle connections.", s.Idle},
+		{"constructing_conns", "gauge", "Connections being established.", s.Constructing},
+		{"total_conns", "gauge", "Open connections.", s.Total},
+		{"max_conns", "gauge", "Maximum connections.", s.Max},
+		{"acquires_total", "counter", "Successful acquires.", s.Acquires},
+		{"empty_acquires_total", "counter", "Acquires that waited for a connection.", s.EmptyAcquires},
+		{"canceled_acquires_total", "counter", "Acquires canceled by their context.", s.CanceledAcquires},
+		{"acquire_duration_seconds_total", "counter", "Time spent acquiring connections.", s.AcquireDuration.Seconds()},
+	} {
+		name := PoolMetricsPrefix + metric.name
+		out.Family(name, metric.kind, metric.help)
+		out.Sample(name, metric.value)
+	}
+
+	_, err := out.WriteTo(w)
+
+	return err //nolint:wrapcheck
+}
+
+// Handler serves WriteMetrics, for scraping by Prometheus.
+func (m *PoolMonitor) Handler() http.HandlerFunc {
+	return httputil.MetricsHandler(m.WriteMetrics)
+}
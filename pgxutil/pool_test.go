@@ -0,0 +1,117 @@
+package pgxutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxutil"
+)
+
+func TestPoolMonitorCollect(t *testing.T) {
+	stats := pgxutil.PoolStats{Acquired: 1, Idle: 2, Total: 3, Max: 4, Acquires: 10, AcquireDuration: time.Second}
+
+	var buf bytes.Buffer
+
+	m := &pgxutil.PoolMonitor{Stat: func() pgxutil.PoolStats { return stats }, Logger: zerolog.New(&buf)}
+
+	assert.Equal(t, stats, m.Collect())
+	assert.JSONEq(t, `{"level":"debug","db.pool.acquired":1,"db.pool.idle":2,"db.pool.total":3,"db.pool.max":4,
+		"db.pool.acquires":10,"db.pool.empty_acquires":0,"db.pool.canceled_acquires":0,"db.pool.acquire_wait":100,
+		"message":"pool stats"}`, buf.String())
+
+	buf.Reset()
+
+	stats.Acquired, stats.Idle, stats.Acquires, stats.EmptyAcquires, stats.CanceledAcquires = 4, 0, 14, 3, 1
+	stats.AcquireDuration += 2 * time.Second
+	m.Collect()
+
+	assert.JSONEq(t, `{"level":"warn","db.pool.acquired":4,"db.pool.idle":0,"db.pool.total":3,"db.pool.max":4,
+		"db.pool.acquires":4,"db.pool.empty_acquires":3,"db.pool.canceled_acquires":1,"db.pool.acquire_wait":500,
+		"message":"pool stats"}`, buf.String())
+}
+
+func TestPoolMonitorCheck(t *testing.T) {
+	stats := pgxutil.PoolStats{Acquired: 3, Max: 4}
+	errPing := errors.New("refused")
+
+	var pingErr error
+
+	m := &pgxutil.PoolMonitor{
+		Stat: func() pgxutil.PoolStats { return stats },
+		Ping: func(context.Context) error { return pingErr },
+	}
+
+	require.NoError(t, m.Check(context.Background()))
+
+	stats.Acquired = 4
+	require.ErrorIs(t, m.Check(context.Background()), pgxutil.ErrPoolExhausted)
+	require.EqualError(t, m.Check(context.Background()), "connection pool exhausted: 4/4 acquired")
+
+	pingErr = errPing
+	require.ErrorIs(t, m.Check(context.Background()), errPing)
+}
+
+func TestPoolMonitorRun(t *testing.T) {
+	collected := make(chan struct{}, 1)
+
+	m := &pgxutil.PoolMonitor{
+		Stat: func() pgxutil.PoolStats {
+			select {
+			case collected <- struct{}{}:
+			default:
+			}
+
+			return pgxutil.PoolStats{Max: 1}
+		},
+		Logger:   zerolog.Nop(),
+		Interval: time.Millisecond,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	<-collected
+	cancel()
+	<-done
+}
+
+func TestPoolMonitorHandler(t *testing.T) {
+	m := &pgxutil.PoolMonitor{Stat: func() pgxutil.PoolStats {
+		return pgxutil.PoolStats{Acquired: 2, Max: 5, Acquires: 7, AcquireDuration: 1500 * time.Millisecond}
+	}}
+
+	w := httptest.NewRecorder()
+	m.Handler()(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "# TYPE db_pool_acquired_conns gauge\ndb_pool_acquired_conns 2\n")
+	assert.Contains(t, w.Body.String(), "db_pool_max_conns 5\n")
+	assert.Contains(t, w.Body.String(), "# TYPE db_pool_acquires_total counter\ndb_pool_acquires_total 7\n")
+	assert.Contains(t, w.Body.String(), "db_pool_acquire_duration_seconds_total 1.5\n")
+}
+
+func TestNewPoolMonitor(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://localhost:1/test?pool_max_conns=3&connect_timeout=1")
+	require.NoError(t, err)
+
+	defer pool.Close()
+
+	m := pgxutil.NewPoolMonitor(pool, zerolog.Nop())
+
+	assert.Equal(t, pgxutil.PoolStats{Max: 3}, m.Collect())
+	require.Error(t, m.Check(context.Background()))
+}
@@ -0,0 +1,302 @@
+package pgxutil
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/httputil"
+)
+
+// RouterMetricsPrefix prefixes the metric names written by Router.
+var RouterMetricsPrefix = "db_route_"
+
+// DefaultCheckInterval is the replica health check interval of Router.Run when Router.CheckInterval is 0.
+const DefaultCheckInterval = 5 * time.Second
+
+// DB is a connection pool, implemented by *pgxpool.Pool.
+type DB interface {
+	Querier
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	TxBeginner
+	Begin(ctx context.Context) (pgx.Tx, error)
+	CopyConn
+	Ping(ctx context.Context) error
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a ctx routing the reads of Router to the primary, e.g. to read your own writes.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Router routes reads (Query, QueryRow of read only statements, see Reader) round-robin to the healthy replicas, and
+// writes (Exec, CopyFrom, other queries such as INSERT ... RETURNING) and transactions to the primary.  Reads use the
+// primary for contexts from WithPrimary, or when no replica is healthy.  Router is a DB counting the queries of each
+// route, create it with NewRouter.
+type Router struct {
+	Primary  DB
+	Replicas []DB
+	// Logger logs replica health changes.
+	Logger zerolog.Logger
+	// CheckInterval is the replica health check interval of Run, defaults to DefaultCheckInterval.
+	CheckInterval time.Duration
+
+	next      atomic.Uint64
+	unhealthy []atomic.Bool
+	reads     []atomic.Uint64 // by replica, the last for the primary
+	writes    atomic.Uint64
+	fallbacks atomic.Uint64
+}
+
+var _ DB = (*Router)(nil)
+
+// NewRouter returns a Router of primary and replicas.
+func NewRouter(primary DB, replicas []DB, logger zerolog.Logger) *Router {
+	return &Router{
+		Primary:   primary,
+		Replicas:  replicas,
+		Logger:    logger.With().Str("module", "router").Logger(),
+		unhealthy: make([]atomic.Bool, len(replicas)),
+		reads:     make([]atomic.Uint64, len(replicas)+1),
+	}
+}
+
+// Reader returns the DB for reads with ctx, e.g. to run a statement readOnly does not recognize on a replica.
+func (r *Router) Reader(ctx context.Context) DB {
+	if primary, _ := ctx.Value(primaryKey{}).(bool); !primary && len(r.Replicas) > 0 {
+		start := r.next.Add(1)
+
+		for i := range uint64(len(r.Replicas)) {
+			n := int((start + i) % uint64(len(r.Replicas)))
+			if !r.unhealthy[n].Load() {
+				r.reads[n].Add(1)
+
+				return r.Replicas[n]
+			}
+		}
+
+		r.fallbacks.Add(1)
+	}
+
+	r.reads[len(r.Replicas)].Add(1)
+
+	return r.Primary
+}
+
+// Writer returns the primary, counting a write.
+func (r *Router) Writer() DB {
+	r.writes.Add(1)
+
+	return r.Primary
+}
+
+func (r *Router) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return r.Writer().Exec(ctx, sql, args...) //nolint:wrapcheck
+}
+
+func (r *Router) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return r.route(ctx, sql).Query(ctx, sql, args...) //nolint:wrapcheck
+}
+
+func (r *Router) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return r.route(ctx, sql).QueryRow(ctx, sql, args...)
+}
+
+// route returns the Reader for read only statements (see readOnly), the Writer otherwise.
+func (r *Router) route(ctx context.Context, sql string) DB {
+	if readOnly(sql) {
+		return r.Reader(ctx)
+	}
+
+	return r.Writer()
+}
+
+// readOnlyStart lists the leading keywords of statements a replica may run.
+var readOnlyStart = []string{"SELECT", "WITH", "VALUES", "TABLE", "SHOW"}
+
+// writeKeywords marks statements writing or locking rows: data modifying CTEs, SELECT INTO and FOR UPDATE/SHARE.
+var writeKeywords = []string{"INSERT", "UPDATE", "DELETE", "MERGE", "INTO", "SHARE"}
+
+// readOnly reports whether sql starts with one of readOnlyStart and has none of writeKeywords, outside of string
+// literals, quoted identifiers and comments.  Functions with side effects are not detected, use WithPrimary or Writer.
+func readOnly(sql string) bool {
+	first := true
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(sql, i, c) - 1
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return !first
+			}
+
+			i += end
+		case strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i:], "*/")
+			if end < 0 {
+				return false
+			}
+
+			i += end + 1
+		case c == '$':
+			end, ok := skipDollarQuoted(sql, i)
+			if !ok {
+				return false
+			}
+
+			i = end - 1
+		case isNameStart(c):
+			end := i + 1
+			for end < len(sql) && (isNamePart(sql[end]) || sql[end] == '$') {
+				end++
+			}
+
+			word := strings.ToUpper(sql[i:end])
+			if (first && !slices.Contains(readOnlyStart, word)) || slices.Contains(writeKeywords, word) {
+				return false
+			}
+
+			first = false
+			i = end - 1
+		}
+	}
+
+	return !first
+}
+
+// skipDollarQuoted returns the end of the dollar quoted string ($$...$$ or $tag$...$tag$) at start, or start + 1 for
+// parameters ($1).  ok is false if the string is not terminated.
+func skipDollarQuoted(sql string, start int) (int, bool) {
+	end := start + 1
+	for end < len(sql) && isNamePart(sql[end]) {
+		end++
+	}
+
+	if end == len(sql) || sql[end] != '$' || end > start+1 && !isNameStart(sql[start+1]) {
+		return start + 1, true
+	}
+
+	tag := sql[start : end+1]
+
+	closing := strings.Index(sql[end+1:], tag)
+	if closing < 0 {
+		return 0, false
+	}
+
+	return end + 1 + closing + len(tag), true
+}
+
+func (r *Router) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.Writer().Begin(ctx) //nolint:wrapcheck
+}
+
+func (r *Router) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	return r.Writer().BeginTx(ctx, txOptions) //nolint:wrapcheck
+}
+
+func (r *Router) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string,
+	rowSrc pgx.CopyFromSource,
+) (int64, error) {
+	return r.Writer().CopyFrom(ctx, tableName, columnNames, rowSrc) //nolint:wrapcheck
+}
+
+// Ping pings the primary.
+func (r *Router) Ping(ctx context.Context) error {
+	return r.Primary.Ping(ctx) //nolint:wrapcheck
+}
+
+// CheckReplicas pings the replicas, routing reads away from those failing until they recover.
+func (r *Router) CheckReplicas(ctx context.Context) {
+	for i, replica := range r.Replicas {
+		err := replica.Ping(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if r.unhealthy[i].Swap(err != nil) == (err != nil) {
+			continue
+		}
+
+		if err != nil {
+			r.Logger.Warn().Err(err).Int("replica", i).Msg("replica unhealthy")
+		} else {
+			r.Logger.Info().Int("replica", i).Msg("replica healthy")
+		}
+	}
+}
+
+// Run checks the replicas every CheckInterval until ctx is done.
+func (r *Router) Run(ctx context.Context) {
+	interval := r.CheckInterval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.CheckReplicas(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// WriteMetrics writes the query counts by route in the Prometheus text exposition format, e.g.
+// `db_route_queries_total{route="replica",replica="0",kind="read"} 42`.
+func (r *Router) WriteMetrics(w io.Writer) error {
+	var out httputil.Metrics
+
+	name := RouterMetricsPrefix + "queries_total"
+	out.Family(name, "counter", "Database queries by route.")
+	out.Sample(name, r.reads[len(r.Replicas)].Load(), "route", "primary", "kind", "read")
+	out.Sample(name, r.writes.Load(), "route", "primary", "kind", "write")
+
+	for i := range r.Replicas {
+		out.Sample(name, r.reads[i].Load(), "route", "replica", "replica", strconv.Itoa(i), "kind", "read")
+	}
+
+	name = RouterMetricsPrefix + "fallbacks_total"
+	out.Family(name, "counter", "Reads routed to the primary without a healthy replica.")
+	out.Sample(name, r.fallbacks.Load())
+
+	name = RouterMetricsPrefix + "replica_healthy"
+	out.Family(name, "gauge", "Replica health, 1 if healthy.")
+
+	for i := range r.Replicas {
+		healthy := 1
+		if r.unhealthy[i].Load() {
+			healthy = 0
+		}
+
+		out.Sample(name, healthy, "replica", strconv.Itoa(i))
+	}
+
+	_, err := out.WriteTo(w)
+
+	return err //nolint:wrapcheck
+}
+
+// Handler serves WriteMetrics, for scraping by Prometheus.
+func (r *Router) Handler() http.HandlerFunc {
+	return httputil.MetricsHandler(r.WriteMetrics)
+}
@@ -0,0 +1,165 @@
+package pgxutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxutil"
+)
+
+var _ pgxutil.DB = (*pgxpool.Pool)(nil)
+
+// routeDB records the calls routed to it, other DB methods are not implemented.
+type routeDB struct {
+	pgxutil.DB
+	calls   []string
+	pingErr error
+}
+
+func (db *routeDB) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	db.calls = append(db.calls, "exec")
+
+	return pgconn.CommandTag{}, nil
+}
+
+func (db *routeDB) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	db.calls = append(db.calls, "query")
+
+	return nil, nil
+}
+
+func (db *routeDB) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	db.calls = append(db.calls, "begin")
+
+	return nil, nil
+}
+
+func (db *routeDB) Ping(context.Context) error { return db.pingErr }
+
+func TestRouter(t *testing.T) {
+	primary, r0, r1 := &routeDB{}, &routeDB{}, &routeDB{}
+
+	var buf bytes.Buffer
+
+	r := pgxutil.NewRouter(primary, []pgxutil.DB{r0, r1}, zerolog.New(&buf))
+	ctx := context.Background()
+
+	for range 4 {
+		_, _ = r.Query(ctx, "SELECT 1")
+	}
+
+	_, _ = r.Exec(ctx, "UPDATE t SET a = 1")
+	_, _ = r.BeginTx(ctx, pgx.TxOptions{})
+	_, _ = r.Query(pgxutil.WithPrimary(ctx), "SELECT 1")
+
+	assert.Equal(t, []string{"exec", "begin", "query"}, primary.calls)
+	assert.Equal(t, []string{"query", "query"}, r0.calls)
+	assert.Equal(t, []string{"query", "query"}, r1.calls)
+
+	r1.pingErr = errors.New("refused")
+	r.CheckReplicas(ctx)
+	r.CheckReplicas(ctx)
+
+	_, _ = r.Query(ctx, "SELECT 1")
+	_, _ = r.Query(ctx, "SELECT 1")
+	assert.Len(t, r0.calls, 4)
+
+	r0.pingErr = errors.New("refused")
+	r.CheckReplicas(ctx)
+
+	_, _ = r.Query(ctx, "SELECT 1")
+	assert.Len(t, primary.calls, 4)
+
+	r0.pingErr, r1.pingErr = nil, nil
+	r.CheckReplicas(ctx)
+
+	_, _ = r.Query(ctx, "SELECT 1")
+	_, _ = r.Query(ctx, "SELECT 1")
+	assert.Len(t, r0.calls, 5)
+	assert.Len(t, r1.calls, 3)
+
+	assert.Equal(t, `{"level":"warn","module":"router","error":"refused","replica":1,"message":"replica unhealthy"}
+{"level":"warn","module":"router","error":"refused","replica":0,"message":"replica unhealthy"}
+{"level":"info","module":"router","replica":0,"message":"replica healthy"}
+{"level":"info","module":"router","replica":1,"message":"replica healthy"}
+`, buf.String())
+
+	w := httptest.NewRecorder()
+	r.Handler()(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, `# HELP db_route_queries_total Database queries by route.
+# TYPE db_route_queries_total counter
+db_route_queries_total{route="primary",kind="read"} 2
+db_route_queries_total{route="primary",kind="write"} 2
+db_route_queries_total{route="replica",replica="0",kind="read"} 5
+db_route_queries_total{route="replica",replica="1",kind="read"} 3
+# HELP db_route_fallbacks_total Reads routed to the primary without a healthy replica.
+# TYPE db_route_fallbacks_total counter
+db_route_fallbacks_total 1
+# HELP db_route_replica_healthy Replica health, 1 if healthy.
+# TYPE db_route_replica_healthy gauge
+db_route_replica_healthy{replica="0"} 1
+db_route_replica_healthy{replica="1"} 1
+`, w.Body.String())
+}
+
+func TestRouterReadOnly(t *testing.T) {
+	tests := []struct {
+		sql     string
+		replica bool
+	}{
+		{"SELECT 1", true},
+		{"  select * from t where a = $1", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"-- comment\nSELECT 1", true},
+		{"/* delete */ SELECT 'insert', \"update\" FROM t", true},
+		{"SELECT $$update$$, $tag$ delete $tag$", true},
+		{"WITH a AS (SELECT 1) SELECT * FROM a", true},
+		{"VALUES (1)", true},
+		{"TABLE t", true},
+		{"SHOW search_path", true},
+		{"INSERT INTO t VALUES (1) RETURNING id", false},
+		{"UPDATE t SET a = 1 RETURNING a", false},
+		{"DELETE FROM t RETURNING id", false},
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", false},
+		{"SELECT * FROM t FOR UPDATE", false},
+		{"SELECT * FROM t FOR KEY SHARE", false},
+		{"SELECT * INTO copy FROM t", false},
+		{"/* unterminated SELECT", false},
+		{"SELECT $$unterminated", false},
+		{"", false},
+		{"CALL proc()", false},
+	}
+
+	for _, test := range tests {
+		primary, replica := &routeDB{}, &routeDB{}
+		r := pgxutil.NewRouter(primary, []pgxutil.DB{replica}, zerolog.Nop())
+
+		_, _ = r.Query(context.Background(), test.sql)
+		assert.Equal(t, test.replica, len(replica.calls) == 1, test.sql)
+		assert.Equal(t, !test.replica, len(primary.calls) == 1, test.sql)
+	}
+}
+
+func TestRouterNoReplicas(t *testing.T) {
+	primary := &routeDB{}
+	r := pgxutil.NewRouter(primary, nil, zerolog.Nop())
+
+	_, _ = r.Query(context.Background(), "SELECT 1")
+	require.NoError(t, r.Ping(context.Background()))
+	assert.Equal(t, []string{"query"}, primary.calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Run(ctx)
+}
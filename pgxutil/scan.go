@@ -1,11 +1,23 @@
 package pgxutil
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/jackc/pgx/v5"
 )
 
+var (
+	// ErrUnmappedColumn is returned when scanning a column without a matching struct field.
+	ErrUnmappedColumn = errors.New("unmapped column")
+	// ErrScanType is returned when scanning rows into types other than structs.
+	ErrScanType = errors.New("scan type must be a struct")
+)
+
 func ToArray[T comparable](rows pgx.Rows, err error) ([]T, error) {
 	if err != nil {
 		return nil, fmt.Errorf("query:%w", err)
@@ -26,3 +38,182 @@ func ToArray[T comparable](rows pgx.Rows, err error) ([]T, error) {
 
 	return out, nil
 }
+
+// ScanAll scans rows into a slice of T, see ScanStruct.
+func ScanAll[T any](rows pgx.Rows) ([]T, error) {
+	out, err := pgx.CollectRows(rows, ScanStruct[T])
+	if err != nil {
+		return nil, fmt.Errorf("scan:%w", err)
+	}
+
+	return out, nil
+}
+
+// ScanOne scans exactly one row into T (see ScanStruct), returning pgx.ErrNoRows if there is none and
+// pgx.ErrTooManyRows if there are more.
+func ScanOne[T any](rows pgx.Rows) (T, error) {
+	out, err := pgx.CollectExactlyOneRow(rows, ScanStruct[T])
+	if err != nil {
+		return out, fmt.Errorf("scan:%w", err)
+	}
+
+	return out, nil
+}
+
+// ScanStruct is a pgx.RowToFunc scanning the columns of row into the exported fields of the struct T, named by the db
+// tag or matching the column case insensitively ignoring underscores (CreatedAt for created_at).  Embedded structs
+// are flattened, and struct fields with the prefix option (`db:"home,prefix"`) map the columns prefixed by their name
+// and an underscore (home_street).  Nullable columns scan into pointer fields, nil for NULL.  Fields without columns
+// are left unchanged, columns without fields fail with ErrUnmappedColumn.
+func ScanStruct[T any](row pgx.CollectableRow) (T, error) {
+	var out T
+
+	v := reflect.ValueOf(&out).Elem()
+	if v.Kind() != reflect.Struct {
+		return out, fmt.Errorf("%w: %T", ErrScanType, out)
+	}
+
+	fields := structColumns(v.Type())
+	columns := row.FieldDescriptions()
+	dest := make([]any, len(columns))
+
+	for i, c := range columns {
+		index, ok := fields.lookup(c.Name)
+		if !ok {
+			return out, fmt.Errorf("%w: %s into %T", ErrUnmappedColumn, c.Name, out)
+		}
+
+		dest[i] = v.FieldByIndex(index).Addr().Interface()
+	}
+
+	if err := row.Scan(dest...); err != nil {
+		return out, fmt.Errorf("scan %T: %w", out, err)
+	}
+
+	return out, nil
+}
+
+// columnField is a struct field mapped to a column.
+type columnField struct {
+	column string
+	index  []int
+}
+
+// columnFields are the mapped fields of a struct in order, with their indexes by tagged column name, and by
+// normalized name for untagged fields.
+type columnFields struct {
+	fields []columnField
+	tagged map[string][]int
+	names  map[string][]int
+}
+
+func (f *columnFields) lookup(column string) ([]int, bool) {
+	if index, ok := f.tagged[column]; ok {
+		return index, true
+	}
+
+	index, ok := f.names[normalizeColumn(column)]
+
+	return index, ok
+}
+
+// add maps the field at index to column, unless an outer field already maps it.
+func (f *columnFields) add(column string, index []int, tagged bool) {
+	names, key := f.names, normalizeColumn(column)
+	if tagged {
+		names, key = f.tagged, column
+	}
+
+	if _, ok := names[key]; ok {
+		return
+	}
+
+	names[key] = index
+	f.fields = append(f.fields, columnField{column, index})
+}
+
+var structColumnsCache sync.Map // reflect.Type => *columnFields
+
+func structColumns(t reflect.Type) *columnFields {
+	if f, ok := structColumnsCache.Load(t); ok {
+		return f.(*columnFields) //nolint:forcetypeassert
+	}
+
+	f := &columnFields{tagged: map[string][]int{}, names: map[string][]int{}}
+	addStructColumns(f, t, nil, "")
+
+	structColumnsCache.Store(t, f)
+
+	return f
+}
+
+// addStructColumns adds the fields of t, at index within the root struct, to f with their columns prefixed by
+// prefix.  Untagged fields map to their snake case name (created_at for CreatedAt).  Outer fields take precedence over
+// those of embedded structs.
+func addStructColumns(f *columnFields, t reflect.Type, index []int, prefix string) {
+	type nested struct {
+		t      reflect.Type
+		index  []int
+		prefix string
+	}
+
+	var structs []nested
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("db")
+		name, opts, _ := strings.Cut(tag, ",")
+		embedded := field.Anonymous && field.Type.Kind() == reflect.Struct && !hasTag
+		fieldIndex := append(slices.Clone(index), i)
+
+		switch {
+		case name == "-" || !field.IsExported() && !embedded:
+			continue
+		case field.Type.Kind() == reflect.Struct && slices.Contains(strings.Split(opts, ","), "prefix"):
+			if name == "" {
+				name = snakeCase(field.Name)
+			}
+
+			structs = append(structs, nested{field.Type, fieldIndex, prefix + name + "_"})
+		case embedded:
+			structs = append(structs, nested{field.Type, fieldIndex, prefix})
+		case name != "":
+			f.add(prefix+name, fieldIndex, true)
+		default:
+			f.add(prefix+snakeCase(field.Name), fieldIndex, false)
+		}
+	}
+
+	for _, s := range structs {
+		addStructColumns(f, s.t, s.index, s.prefix)
+	}
+}
+
+func normalizeColumn(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// snakeCase converts a Go name to snake case, keeping acronyms together (UserID => user_id).
+func snakeCase(s string) string {
+	var b strings.Builder
+
+	for i, r := range s {
+		upper := 'A' <= r && r <= 'Z'
+		if upper && i > 0 {
+			prevLower := 'a' <= s[i-1] && s[i-1] <= 'z' || '0' <= s[i-1] && s[i-1] <= '9'
+			nextLower := i+1 < len(s) && 'a' <= s[i+1] && s[i+1] <= 'z'
+
+			if prevLower || nextLower && 'A' <= s[i-1] && s[i-1] <= 'Z' {
+				b.WriteByte('_')
+			}
+		}
+
+		if upper {
+			r += 'a' - 'A'
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package pgxutil_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxutil"
+)
+
+type address struct {
+	Street string
+	City   string `db:"city"`
+}
+
+type timestamps struct {
+	CreatedAt time.Time
+}
+
+type customer struct {
+	timestamps
+	ID       int     `db:"id"`
+	Name     string  // name
+	Nickname *string `db:"nick"`
+	Home     address `db:"home,prefix"`
+	Work     address `db:",prefix"`
+	Skipped  string  `db:"-"`
+}
+
+func TestScanAll(t *testing.T) {
+	now := time.Now()
+	rows := &fakeRows{
+		columns: []string{"id", "name", "nick", "home_street", "home_city", "work_street", "created_at"},
+		values: [][]any{
+			{1, "bob", nil, "1 Main St", "Springfield", "2 Work Rd", now},
+			{2, "alice", "ali", "3 Elm St", "Shelbyville", nil, now},
+		},
+	}
+
+	got, err := pgxutil.ScanAll[customer](rows)
+	require.NoError(t, err)
+
+	nick := "ali"
+
+	assert.Equal(t, []customer{
+		{
+			timestamps: timestamps{CreatedAt: now}, ID: 1, Name: "bob",
+			Home: address{Street: "1 Main St", City: "Springfield"}, Work: address{Street: "2 Work Rd"},
+		},
+		{
+			timestamps: timestamps{CreatedAt: now}, ID: 2, Name: "alice", Nickname: &nick,
+			Home: address{Street: "3 Elm St", City: "Shelbyville"},
+		},
+	}, got)
+}
+
+func TestScanAllErrors(t *testing.T) {
+	_, err := pgxutil.ScanAll[customer](&fakeRows{columns: []string{"id", "skipped"}, values: [][]any{{1, "x"}}})
+	require.ErrorIs(t, err, pgxutil.ErrUnmappedColumn)
+	require.EqualError(t, err, "scan:unmapped column: skipped into pgxutil_test.customer")
+
+	_, err = pgxutil.ScanAll[customer](&fakeRows{columns: []string{"id", "name"}, values: [][]any{{1, nil}, {2, 3}}})
+	require.EqualError(t, err, "scan:scan pgxutil_test.customer: cannot scan name")
+
+	_, err = pgxutil.ScanAll[int](&fakeRows{columns: []string{"id"}, values: [][]any{{1}}})
+	require.ErrorIs(t, err, pgxutil.ErrScanType)
+}
+
+func TestScanOne(t *testing.T) {
+	got, err := pgxutil.ScanOne[userRow](&fakeRows{columns: []string{"id", "name"}, values: [][]any{{1, "bob"}}})
+	require.NoError(t, err)
+	assert.Equal(t, userRow{ID: 1, Name: "bob"}, got)
+
+	_, err = pgxutil.ScanOne[userRow](&fakeRows{columns: []string{"id"}})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = pgxutil.ScanOne[userRow](&fakeRows{columns: []string{"id"}, values: [][]any{{1}, {2}}})
+	require.ErrorIs(t, err, pgx.ErrTooManyRows)
+}
+
+func TestToArray(t *testing.T) {
+	got, err := pgxutil.ToArray[int](&fakeRows{columns: []string{"id"}, values: [][]any{{1}, {2}}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, got)
+
+	_, err = pgxutil.ToArray[int](nil, pgx.ErrNoRows)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -0,0 +1,185 @@
+package pgxutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/httputil"
+)
+
+var (
+	// ErrQueryTimeout wraps the errors of queries exceeding their statement timeout or context deadline, a 504.
+	ErrQueryTimeout = errs.Sentinel("ErrQueryTimeout", errs.DeadlineExceeded).WithMessage("query timeout")
+	// ErrQueryCanceled wraps the errors of queries canceled by the server for other reasons, e.g. pg_cancel_backend,
+	// a 500.
+	ErrQueryCanceled = errs.Sentinel("ErrQueryCanceled", errs.Internal).WithMessage("query canceled")
+)
+
+// TimeoutMetricsPrefix prefixes the metric names written by StatementTimeout.
+var TimeoutMetricsPrefix = "db_"
+
+// sqlStateQueryCanceled is the SQLSTATE of canceled statements, including statement timeouts.
+const sqlStateQueryCanceled = "57014"
+
+type serverTimeoutKey struct{}
+
+// WithServerTimeout returns a ctx whose queries run under a server statement_timeout, so TimeoutError classifies their
+// cancellations as timeouts.  RunTx sets it for fn.
+func WithServerTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, serverTimeoutKey{}, true)
+}
+
+// TimeoutError returns err wrapped by ErrQueryTimeout if the query of ctx timed out, or by ErrQueryCanceled if the
+// server canceled it.  Server cancellations of a ctx from WithServerTimeout are timeouts, the SQLSTATE is the same
+// and the message depends on lc_messages.  Other errors, including those of contexts canceled by the client, are
+// returned unchanged.
+func TimeoutError(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, ErrQueryTimeout) || errors.Is(err, ErrQueryCanceled) {
+		return err
+	}
+
+	var s interface {
+		error
+		SQLState() string
+	}
+
+	canceled := errors.As(err, &s) && s.SQLState() == sqlStateQueryCanceled
+	serverTimeout, _ := ctx.Value(serverTimeoutKey{}).(bool)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded),
+		canceled && serverTimeout:
+		return ErrQueryTimeout.Wrap(err)
+	case canceled:
+		return ErrQueryCanceled.Wrap(err)
+	}
+
+	return err
+}
+
+// StatementTimeout runs queries with a timeout, returning ErrQueryTimeout and ErrQueryCanceled errors (see
+// TimeoutError) and counting them by query name.  StatementTimeout is safe for concurrent use.
+type StatementTimeout struct {
+	// Timeout is the timeout of each call, 0 disables it.
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	timeouts map[string]uint64
+	canceled map[string]uint64
+}
+
+// NewStatementTimeout returns a StatementTimeout of timeout.
+func NewStatementTimeout(timeout time.Duration) *StatementTimeout {
+	return &StatementTimeout{Timeout: timeout}
+}
+
+// Run runs fn with a ctx deadline of Timeout, name identifies the query in the metrics.
+func (s *StatementTimeout) Run(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	return s.observe(name, TimeoutError(ctx, fn(ctx)))
+}
+
+// RunTx runs fn in tx after SET LOCAL statement_timeout to Timeout, enforced by the server for each statement until
+// the end of the transaction, name identifies the query in the metrics.  With a Timeout, all server cancellations of
+// fn count as timeouts (see WithServerTimeout).
+func (s *StatementTimeout) RunTx(ctx context.Context, tx pgx.Tx, name string,
+	fn func(ctx context.Context, tx pgx.Tx) error,
+) error {
+	if s.Timeout > 0 {
+		ms := max(s.Timeout.Milliseconds(), 1)
+
+		if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = "+strconv.FormatInt(ms, 10)); err != nil {
+			return fmt.Errorf("statement timeout: %w", err)
+		}
+
+		ctx = WithServerTimeout(ctx)
+	}
+
+	return s.observe(name, TimeoutError(ctx, fn(ctx, tx)))
+}
+
+// Stats returns the number of timeouts and cancellations of the query name.
+func (s *StatementTimeout) Stats(name string) (timeouts, canceled uint64) { //nolint:nonamedreturns
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.timeouts[name], s.canceled[name]
+}
+
+func (s *StatementTimeout) observe(name string, err error) error {
+	timeout, canceled := errors.Is(err, ErrQueryTimeout), errors.Is(err, ErrQueryCanceled)
+	if !timeout && !canceled {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timeouts == nil {
+		s.timeouts, s.canceled = map[string]uint64{}, map[string]uint64{}
+	}
+
+	if timeout {
+		s.timeouts[name]++
+	} else {
+		s.canceled[name]++
+	}
+
+	return err
+}
+
+// WriteMetrics writes the counters in the Prometheus text exposition format, e.g.
+// `db_query_timeouts_total{query="GetUser"} 3`.
+func (s *StatementTimeout) WriteMetrics(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out httputil.Metrics
+
+	for _, metric := range []struct {
+		name, help string
+		counts     map[string]uint64
+	}{
+		{"query_timeouts_total", "Database queries timed out.", s.timeouts},
+		{"query_cancels_total", "Database queries canceled by the server.", s.canceled},
+	} {
+		name := TimeoutMetricsPrefix + metric.name
+		out.Family(name, "counter", metric.help)
+
+		queries := make([]string, 0, len(metric.counts))
+		for q := range metric.counts {
+			queries = append(queries, q)
+		}
+
+		sort.Strings(queries)
+
+		for _, q := range queries {
+			out.Sample(name, metric.counts[q], "query", q)
+		}
+	}
+
+	_, err := out.WriteTo(w)
+
+	return err //nolint:wrapcheck
+}
+
+// Handler serves WriteMetrics, for scraping by Prometheus.
+func (s *StatementTimeout) Handler() http.HandlerFunc {
+	return httputil.MetricsHandler(s.WriteMetrics)
+}
@@ -0,0 +1,110 @@
+package pgxutil_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/pgxutil"
+)
+
+var (
+	errStatementTimeout = &pgconn.PgError{Code: "57014", Message: "Abbruch der Anweisung wegen Zeitüberschreitung"}
+	errUserCancel       = &pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}
+)
+
+func TestTimeoutError(t *testing.T) {
+	ctx := context.Background()
+	expired, cancel := context.WithDeadline(ctx, time.Now())
+
+	defer cancel()
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	errOther := errors.New("other")
+
+	require.NoError(t, pgxutil.TimeoutError(ctx, nil))
+	assert.Equal(t, errOther, pgxutil.TimeoutError(ctx, errOther))
+	assert.Equal(t, context.Canceled, pgxutil.TimeoutError(canceled, context.Canceled), "client cancel")
+
+	for _, err := range []error{
+		pgxutil.TimeoutError(pgxutil.WithServerTimeout(ctx), errStatementTimeout),
+		pgxutil.TimeoutError(expired, errUserCancel),
+		pgxutil.TimeoutError(ctx, context.DeadlineExceeded),
+	} {
+		require.ErrorIs(t, err, pgxutil.ErrQueryTimeout)
+		assert.Equal(t, http.StatusGatewayTimeout, errs.Status(err))
+	}
+
+	require.ErrorIs(t, pgxutil.TimeoutError(ctx, errStatementTimeout), pgxutil.ErrQueryCanceled,
+		"cancellations are only timeouts under a server timeout")
+
+	err := pgxutil.TimeoutError(ctx, errUserCancel)
+	require.ErrorIs(t, err, pgxutil.ErrQueryCanceled)
+	require.ErrorIs(t, err, errUserCancel)
+	assert.Equal(t, http.StatusInternalServerError, errs.Status(err))
+	assert.Equal(t, err, pgxutil.TimeoutError(ctx, err), "already classified")
+}
+
+// timeoutTx records the statements it runs, other pgx.Tx methods are not implemented.
+type timeoutTx struct {
+	pgx.Tx
+	execs []string
+}
+
+func (tx *timeoutTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	tx.execs = append(tx.execs, sql)
+
+	return pgconn.NewCommandTag("SET"), nil
+}
+
+func TestStatementTimeout(t *testing.T) {
+	s := pgxutil.NewStatementTimeout(time.Millisecond)
+
+	err := s.Run(context.Background(), "SlowQuery", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+	require.ErrorIs(t, err, pgxutil.ErrQueryTimeout)
+
+	require.NoError(t, s.Run(context.Background(), "FastQuery", func(context.Context) error { return nil }))
+
+	tx := &timeoutTx{}
+	s.Timeout = 1500 * time.Millisecond
+
+	err = s.RunTx(context.Background(), tx, "Report", func(context.Context, pgx.Tx) error { return errStatementTimeout })
+	require.ErrorIs(t, err, pgxutil.ErrQueryTimeout)
+
+	s.Timeout = 0
+
+	err = s.RunTx(context.Background(), tx, "Report", func(context.Context, pgx.Tx) error { return errUserCancel })
+	require.ErrorIs(t, err, pgxutil.ErrQueryCanceled)
+	assert.Equal(t, []string{"SET LOCAL statement_timeout = 1500"}, tx.execs)
+
+	timeouts, canceled := s.Stats("SlowQuery")
+	assert.Equal(t, uint64(1), timeouts)
+	assert.Zero(t, canceled)
+
+	w := httptest.NewRecorder()
+	s.Handler()(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, `# HELP db_query_timeouts_total Database queries timed out.
+# TYPE db_query_timeouts_total counter
+db_query_timeouts_total{query="Report"} 1
+db_query_timeouts_total{query="SlowQuery"} 1
+# HELP db_query_cancels_total Database queries canceled by the server.
+# TYPE db_query_cancels_total counter
+db_query_cancels_total{query="Report"} 1
+`, w.Body.String())
+}
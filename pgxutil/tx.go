@@ -0,0 +1,135 @@
+package pgxutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/httplog"
+	"github.com/bir/iken/logctx"
+)
+
+// TxBeginner starts transactions, implemented by *pgxpool.Pool, *pgx.Conn and pgx.Tx (for savepoints).
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// TxRetryStates are the SQLSTATEs retried by InTx: serialization_failure and deadlock_detected.
+var TxRetryStates = []string{"40001", "40P01"}
+
+const (
+	// DefaultTxAttempts is the number of attempts of InTx when TxOptions.MaxAttempts is 0.
+	DefaultTxAttempts     = 3
+	defaultTxRetryBase    = 10 * time.Millisecond
+	defaultTxRetryMaxWait = time.Second
+)
+
+// TxOptions configures InTx.
+type TxOptions struct {
+	pgx.TxOptions
+	// MaxAttempts is the total number of attempts, including the first, defaults to DefaultTxAttempts.
+	MaxAttempts int
+	// BaseDelay is the initial backoff delay, doubled for each attempt, defaults to 10ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay, defaults to 1s.
+	MaxDelay time.Duration
+}
+
+// InTx runs fn in a transaction of db, committed if fn returns nil and rolled back otherwise.  Transactions failing
+// with a TxRetryStates or errs.IsRetryable error (from fn or Commit) are retried with exponential backoff, or after
+// the errs.RetryAfter delay, up to MaxAttempts and while the ctx deadline allows, so fn must be safe to run again.  The returned error is translated with errs.Translate.
+func InTx(ctx context.Context, db TxBeginner, opts TxOptions, fn func(ctx context.Context, tx pgx.Tx) error) error {
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultTxAttempts
+	}
+
+	base, maxDelay := opts.BaseDelay, opts.MaxDelay
+	if base <= 0 {
+		base = defaultTxRetryBase
+	}
+
+	if maxDelay <= 0 {
+		maxDelay = defaultTxRetryMaxWait
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := runTx(ctx, db, opts.TxOptions, fn)
+		if err == nil || !(retryableTx(err) || errs.IsRetryable(err)) || attempt+1 >= maxAttempts {
+			return errs.Translate(err)
+		}
+
+		delay := errs.RetryDelay(err, attempt, base, maxDelay)
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return errs.Translate(err)
+		}
+
+		e := zerolog.Ctx(ctx).Warn().Err(err)
+		if id := logctx.GetID(ctx); id != "" {
+			e = e.Str(httplog.RequestID, id)
+		}
+
+		e.Int("attempt", attempt+1).
+			Dur("delay", delay).
+			Msg("retrying transaction")
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return errs.Translate(fmt.Errorf("retry tx: %w", errors.Join(ctx.Err(), err)))
+		case <-timer.C:
+		}
+	}
+}
+
+func runTx(ctx context.Context, db TxBeginner, opts pgx.TxOptions, fn func(ctx context.Context, tx pgx.Tx) error,
+) (err error) { //nolint:nonamedreturns
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+
+			panic(r)
+		}
+	}()
+
+	if err = fn(ctx, tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	return nil
+}
+
+// retryableTx reports whether err is a TxRetryStates error.
+func retryableTx(err error) bool {
+	var s interface {
+		error
+		SQLState() string
+	}
+
+	return errors.As(err, &s) && slices.Contains(TxRetryStates, s.SQLState())
+}
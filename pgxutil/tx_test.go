@@ -0,0 +1,139 @@
+package pgxutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/logctx"
+	"github.com/bir/iken/pgxutil"
+)
+
+// fakeTx records the outcome of a transaction, other pgx.Tx methods are not implemented.
+type fakeTx struct {
+	pgx.Tx
+	db *fakeDB
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	t.db.commits++
+
+	return t.db.commitErr
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	t.db.rollbacks++
+
+	return nil
+}
+
+type fakeDB struct {
+	begins, commits, rollbacks int
+	commitErr                  error
+	opts                       pgx.TxOptions
+}
+
+func (db *fakeDB) BeginTx(_ context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
+	db.begins++
+	db.opts = opts
+
+	return &fakeTx{db: db}, nil
+}
+
+func TestInTx(t *testing.T) {
+	db := &fakeDB{}
+	opts := pgxutil.TxOptions{TxOptions: pgx.TxOptions{IsoLevel: pgx.Serializable}, BaseDelay: time.Millisecond}
+
+	var buf bytes.Buffer
+
+	ctx := zerolog.New(&buf).WithContext(logctx.SetID(context.Background(), "req-1"))
+	calls := 0
+
+	err := pgxutil.InTx(ctx, db, opts, func(context.Context, pgx.Tx) error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("update: %w", &pgconn.PgError{Code: "40001"})
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, db.begins)
+	assert.Equal(t, 2, db.rollbacks)
+	assert.Equal(t, 1, db.commits)
+	assert.Equal(t, pgx.Serializable, db.opts.IsoLevel)
+	assert.Contains(t, buf.String(), `"http.request_id":"req-1","attempt":2`)
+
+	db = &fakeDB{commitErr: &pgconn.PgError{Code: "40P01"}}
+	err = pgxutil.InTx(ctx, db, opts, func(context.Context, pgx.Tx) error { return nil })
+
+	var pgErr *pgconn.PgError
+
+	require.ErrorAs(t, err, &pgErr)
+	assert.Equal(t, pgxutil.DefaultTxAttempts, db.commits, "deadlocks on commit are retried up to MaxAttempts")
+
+	db = &fakeDB{}
+	calls = 0
+	err = pgxutil.InTx(ctx, db, opts, func(context.Context, pgx.Tx) error {
+		calls++
+		if calls < 2 {
+			return errs.MarkRetryable(errors.New("unavailable"), time.Millisecond)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, db.begins, "errors marked retryable are retried")
+
+	db = &fakeDB{}
+	boom := errors.New("boom")
+	err = pgxutil.InTx(ctx, db, opts, func(context.Context, pgx.Tx) error { return boom })
+	require.ErrorIs(t, err, boom)
+	assert.Equal(t, 1, db.begins, "other errors are not retried")
+	assert.Equal(t, 1, db.rollbacks)
+}
+
+func TestInTxTranslate(t *testing.T) {
+	errs.DefaultTranslator.RegisterSQLState("23505", errs.AlreadyExists, "already exists")
+
+	err := pgxutil.InTx(context.Background(), &fakeDB{}, pgxutil.TxOptions{}, func(context.Context, pgx.Tx) error {
+		return &pgconn.PgError{Code: "23505"}
+	})
+
+	code, ok := errs.GetCode(err)
+	require.True(t, ok)
+	assert.Equal(t, errs.AlreadyExists, code)
+}
+
+func TestInTxDeadline(t *testing.T) {
+	db := &fakeDB{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+
+	err := pgxutil.InTx(ctx, db, pgxutil.TxOptions{MaxAttempts: 10, BaseDelay: time.Second},
+		func(context.Context, pgx.Tx) error { return &pgconn.PgError{Code: "40001"} })
+	require.Error(t, err)
+	assert.Equal(t, 1, db.begins, "the backoff would exceed the deadline")
+}
+
+func TestInTxPanic(t *testing.T) {
+	db := &fakeDB{}
+
+	assert.Panics(t, func() {
+		_ = pgxutil.InTx(context.Background(), db, pgxutil.TxOptions{}, func(context.Context, pgx.Tx) error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t, 1, db.rollbacks)
+}
@@ -0,0 +1,140 @@
+package pgxzero
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// DefaultExplainTimeout bounds the EXPLAIN of slow queries when Explain.Timeout is 0.
+const DefaultExplainTimeout = 5 * time.Second
+
+// ExplainDB runs the EXPLAIN statements, implemented by *pgxpool.Pool and *pgx.Conn.
+type ExplainDB interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Explain configures logging the plans of slow queries as DBPlan, see Tracer.Explain.
+type Explain struct {
+	// Sample is the fraction of slow queries explained, from 0 (none) to 1 (all).
+	Sample float64
+	// Analyze runs EXPLAIN ANALYZE for SELECT statements, executing them again, other statements are only planned.
+	Analyze bool
+	// Timeout bounds each EXPLAIN, defaults to DefaultExplainTimeout.
+	Timeout time.Duration
+	// DB runs the EXPLAIN in the background on its own connection, e.g. the pool, logging the plan in a separate "Plan"
+	// event.  One plan is explained at a time, slow queries ending meanwhile are skipped.  Nil explains on the
+	// connection of the query, before its caller continues, and only outside of transactions.
+	DB ExplainDB
+
+	busy atomic.Bool
+}
+
+// explainable are the first keywords of the statements accepted by EXPLAIN.
+var explainable = map[string]bool{
+	"DELETE": true, "INSERT": true, "MERGE": true, "SELECT": true, "TABLE": true, "UPDATE": true, "VALUES": true,
+	"WITH": true,
+}
+
+type explainKey struct{}
+
+// ExplainStatement returns the EXPLAIN (FORMAT JSON) statement of sql, with ANALYZE if analyze is set and sql is a
+// SELECT.  Returns false for statements EXPLAIN does not accept, e.g. DDL.
+func ExplainStatement(sql string, analyze bool) (string, bool) {
+	s := strings.TrimSpace(sql)
+	for strings.HasPrefix(s, "--") {
+		_, s, _ = strings.Cut(s, "\n")
+		s = strings.TrimSpace(s)
+	}
+
+	end := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(s)
+	}
+
+	keyword := strings.ToUpper(s[:end])
+
+	if !explainable[keyword] {
+		return "", false
+	}
+
+	if analyze && keyword == "SELECT" {
+		return "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) " + sql, true
+	}
+
+	return "EXPLAIN (FORMAT JSON) " + sql, true
+}
+
+// explains reports whether a successful query taking d is explained.
+func (t *Tracer) explains(err error, d time.Duration) bool {
+	return t.Explain != nil && err == nil && t.slow(d) && rand.Float64() < t.Explain.Sample //nolint:gosec
+}
+
+// idle reports whether conn is idle outside of a transaction, so an EXPLAIN cannot change the transaction state.
+func idle(conn *pgx.Conn) bool {
+	return conn != nil && conn.PgConn() != nil && conn.PgConn().TxStatus() == 'I'
+}
+
+// explainBackground explains sql on Explain.DB in a new goroutine, logging the plan with e.
+func (t *Tracer) explainBackground(ctx context.Context, e *zerolog.Event, sql string, args []any) {
+	if !t.Explain.busy.CompareAndSwap(false, true) {
+		e.Discard()
+
+		return
+	}
+
+	go func() {
+		defer t.Explain.busy.Store(false)
+
+		plan, err := t.explain(ctx, t.Explain.DB, sql, args)
+		withPlan(e, plan, err).Msg("Plan")
+	}()
+}
+
+// withPlan adds the plan or its error to e.
+func withPlan(e *zerolog.Event, plan []byte, err error) *zerolog.Event {
+	switch {
+	case err != nil:
+		return e.AnErr(DBPlanError, err)
+	case plan != nil:
+		return e.RawJSON(DBPlan, plan)
+	}
+
+	return e
+}
+
+// explain returns the JSON plan of sql, run on db.
+func (t *Tracer) explain(ctx context.Context, db ExplainDB, sql string, args []any) ([]byte, error) {
+	stmt, ok := ExplainStatement(sql, t.Explain.Analyze)
+	if !ok {
+		return nil, nil
+	}
+
+	timeout := t.Explain.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExplainTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithValue(context.WithoutCancel(ctx), explainKey{}, true), timeout)
+	defer cancel()
+
+	var plan []byte
+
+	if err := db.QueryRow(ctx, stmt, args...).Scan(&plan); err != nil {
+		return nil, fmt.Errorf("explain: %w", err)
+	}
+
+	return plan, nil
+}
+
+// explaining reports whether ctx is the context of an explain, which is not traced.
+func explaining(ctx context.Context) bool {
+	return ctx.Value(explainKey{}) != nil
+}
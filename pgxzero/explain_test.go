@@ -0,0 +1,136 @@
+package pgxzero_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/httplog"
+	"github.com/bir/iken/logctx"
+	"github.com/bir/iken/pgxzero"
+)
+
+func TestExplainStatement(t *testing.T) {
+	tests := []struct {
+		sql     string
+		analyze bool
+		want    string
+		ok      bool
+	}{
+		{"select 1", false, "EXPLAIN (FORMAT JSON) select 1", true},
+		{"select 1", true, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) select 1", true},
+		{
+			"-- name: GetUser :one\n  SELECT * FROM users", true,
+			"EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) -- name: GetUser :one\n  SELECT * FROM users", true,
+		},
+		{"UPDATE users SET a = 1", true, "EXPLAIN (FORMAT JSON) UPDATE users SET a = 1", true},
+		{
+			"with x as (delete from t returning *) select * from x", true,
+			"EXPLAIN (FORMAT JSON) with x as (delete from t returning *) select * from x", true,
+		},
+		{"CREATE TABLE t (id int)", false, "", false},
+		{"BEGIN", false, "", false},
+		{"", false, "", false},
+	}
+
+	for _, test := range tests {
+		got, ok := pgxzero.ExplainStatement(test.sql, test.analyze)
+		assert.Equal(t, test.want, got, test.sql)
+		assert.Equal(t, test.ok, ok, test.sql)
+	}
+}
+
+func TestTracerExplainWithoutConn(t *testing.T) {
+	var buf bytes.Buffer
+
+	tr := pgxzero.NewTracer(zerolog.New(&buf))
+	tr.SlowThreshold = time.Nanosecond
+	tr.Explain = &pgxzero.Explain{Sample: 1}
+
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	time.Sleep(time.Millisecond)
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	lines := logged(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, true, lines[0][pgxzero.DBSlow])
+	assert.NotContains(t, lines[0], pgxzero.DBPlan)
+	assert.NotContains(t, lines[0], pgxzero.DBPlanError)
+}
+
+// planDB returns plan for any query, recording the statements.
+type planDB struct {
+	mu    sync.Mutex
+	plan  string
+	stmts []string
+}
+
+func (db *planDB) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.stmts = append(db.stmts, sql)
+
+	return planRow(db.plan)
+}
+
+type planRow string
+
+func (r planRow) Scan(dest ...any) error {
+	*dest[0].(*[]byte) = []byte(r) //nolint:forcetypeassert
+
+	return nil
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p) //nolint:wrapcheck
+}
+
+func (b *lockedBuffer) lines() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return bytes.Count(b.buf.Bytes(), []byte("\n"))
+}
+
+func TestTracerExplainBackground(t *testing.T) {
+	var buf lockedBuffer
+
+	db := &planDB{plan: `[{"Plan":{"Node Type":"Result"}}]`}
+
+	tr := pgxzero.NewTracer(zerolog.New(&buf))
+	tr.SlowThreshold = time.Nanosecond
+	tr.Explain = &pgxzero.Explain{Sample: 1, Analyze: true, DB: db}
+
+	ctx := logctx.SetID(context.Background(), "req-1")
+	ctx = tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	time.Sleep(time.Millisecond)
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	require.Eventually(t, func() bool { return buf.lines() == 2 }, time.Second, time.Millisecond)
+
+	lines := logged(t, &buf.buf)
+	assert.Equal(t, "Query", lines[0]["message"])
+	assert.NotContains(t, lines[0], pgxzero.DBPlan)
+	assert.Equal(t, "Plan", lines[1]["message"])
+	assert.Equal(t, "select 1", lines[1][pgxzero.DBStatement])
+	assert.Equal(t, "req-1", lines[1][httplog.RequestID])
+	assert.Equal(t, []any{map[string]any{"Plan": map[string]any{"Node Type": "Result"}}}, lines[1][pgxzero.DBPlan])
+	assert.Equal(t, []string{"EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) select 1"}, db.stmts)
+}
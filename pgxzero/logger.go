@@ -15,8 +15,9 @@ type LevelMapper = func(tracelog.LogLevel, string) zerolog.Level
 
 // Logger manages mapping pgx error messages to Zerolog.
 type Logger struct {
-	logger zerolog.Logger
-	mapper LevelMapper
+	logger   zerolog.Logger
+	mapper   LevelMapper
+	redactor *Redactor
 }
 
 func defaultMapper(level tracelog.LogLevel, _ string) zerolog.Level {
@@ -52,6 +53,13 @@ func (l *Logger) WithMapper(m LevelMapper) *Logger {
 	return l
 }
 
+// WithRedactor redacts the logged query arguments with r.
+func (l *Logger) WithRedactor(r *Redactor) *Logger {
+	l.redactor = r
+
+	return l
+}
+
 // Log is the pgx Logger interface contract.
 func (l *Logger) Log(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
 	if ctx != nil && (data == nil || data["request_id"] == nil) {
@@ -65,5 +73,20 @@ func (l *Logger) Log(ctx context.Context, level tracelog.LogLevel, msg string, d
 		}
 	}
 
+	if ctx != nil && data[httplog.Operation] == nil {
+		if op := logctx.GetOperation(ctx); op != "" {
+			if data == nil {
+				data = make(map[string]any)
+			}
+
+			data[httplog.Operation] = op
+		}
+	}
+
+	if args, ok := data["args"].([]any); ok && l.redactor != nil {
+		sql, _ := data["sql"].(string)
+		data["args"] = l.redactor.Args(sql, args)
+	}
+
 	l.logger.WithLevel(l.mapper(level, msg)).Fields(data).Msg(msg)
 }
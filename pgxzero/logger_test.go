@@ -17,6 +17,7 @@ func TestLogger_Log(t *testing.T) {
 	dataWithoutRequest := map[string]any{"other": 123}
 
 	ctx := logctx.SetID(context.Background(), "121")
+	opCtx := logctx.SetOperation(ctx, "getUser")
 	tests := []struct {
 		name  string
 		ctx   context.Context
@@ -35,6 +36,7 @@ func TestLogger_Log(t *testing.T) {
 		{"withID in Data", ctx, tracelog.LogLevelWarn, "ctx", dataWithRequest, "{\"level\":\"warn\",\"module\":\"tracelog\",\"request_id\":123,\"message\":\"ctx\"}\n"},
 		{"withID in Ctx", ctx, tracelog.LogLevelWarn, "ctx", dataWithoutRequest, "{\"level\":\"warn\",\"module\":\"tracelog\",\"http.request_id\":\"121\",\"other\":123,\"message\":\"ctx\"}\n"},
 		{"withID in Ctx no data", ctx, tracelog.LogLevelWarn, "ctx", nil, "{\"level\":\"warn\",\"module\":\"tracelog\",\"http.request_id\":\"121\",\"message\":\"ctx\"}\n"},
+		{"withOperation in Ctx", opCtx, tracelog.LogLevelWarn, "ctx", nil, "{\"level\":\"warn\",\"module\":\"tracelog\",\"http.request_id\":\"121\",\"op\":\"getUser\",\"message\":\"ctx\"}\n"},
 	}
 
 	var logBuf bytes.Buffer
@@ -0,0 +1,222 @@
+package pgxzero
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/bir/iken/httputil"
+)
+
+// MetricsPrefix prefixes the metric names written by Metrics.
+var MetricsPrefix = "db_"
+
+// DefaultBuckets are the upper bounds in seconds of the query duration histograms when Metrics.Buckets is nil.
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MaxFingerprint truncates query fingerprints, bounding the size of metric labels.
+var MaxFingerprint = 200
+
+// Metrics counts queries and their durations by QueryName, and errors by query and SQLSTATE, set it as
+// Tracer.Metrics.  Metrics is safe for concurrent use.
+type Metrics struct {
+	// Buckets are the sorted upper bounds in seconds of the duration histograms, defaults to DefaultBuckets.  Set
+	// before the first Observe.
+	Buckets []float64
+
+	mu      sync.Mutex
+	queries map[string]*histogram
+	errors  map[[2]string]uint64
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    time.Duration
+	count  uint64
+}
+
+// QueryStats are the counters of a query, see Metrics.Stats.
+type QueryStats struct {
+	Count    uint64
+	Duration time.Duration
+	Errors   map[string]uint64 // by SQLSTATE, "" for errors from outside the database
+}
+
+// NewMetrics returns empty Metrics, the zero value is also ready to use.
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+var (
+	// reName matches the sqlc style name comment, e.g. "-- name: GetUser :one".
+	reName = regexp.MustCompile(`^\s*--\s*name:\s*(\S+)`)
+	// reLiteral matches string and numeric literals.
+	reLiteral = regexp.MustCompile(`'(?:[^']|'')*'|(^|[^$\w.])\d+(?:\.\d+)?\b`)
+	// reParams matches lists of parameters, e.g. "($1, $2, $3)".
+	reParams = regexp.MustCompile(`\(\s*\$\d+(?:\s*,\s*\$\d+)+\s*\)`)
+)
+
+// QueryName returns the name of sql from a "-- name: Name" comment, or its fingerprint: the statement with
+// literals replaced by ?, parameter lists by (...), and whitespace collapsed, truncated to MaxFingerprint.
+func QueryName(sql string) string {
+	if m := reName.FindStringSubmatch(sql); m != nil {
+		return m[1]
+	}
+
+	s := reParams.ReplaceAllString(sql, "(...)")
+	s = reLiteral.ReplaceAllString(s, "${1}?")
+	s = strings.Join(strings.Fields(s), " ")
+
+	if len(s) > MaxFingerprint {
+		s = s[:MaxFingerprint]
+	}
+
+	return s
+}
+
+// SQLState returns the SQLSTATE of err, "" if it is not a database error.
+func SQLState(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+
+	return ""
+}
+
+// Observe records a query taking d, failing with err if not nil.
+func (m *Metrics) Observe(query string, d time.Duration, err error) {
+	buckets := m.buckets()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.queries == nil {
+		m.queries, m.errors = map[string]*histogram{}, map[[2]string]uint64{}
+	}
+
+	h := m.queries[query]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(buckets))}
+		m.queries[query] = h
+	}
+
+	h.count++
+	h.sum += d
+
+	if i := sort.SearchFloat64s(buckets, d.Seconds()); i < len(buckets) {
+		h.counts[i]++
+	}
+
+	if err != nil {
+		m.errors[[2]string{query, SQLState(err)}]++
+	}
+}
+
+// Stats returns the counters of query.
+func (m *Metrics) Stats(query string) QueryStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var s QueryStats
+
+	if h := m.queries[query]; h != nil {
+		s.Count = h.count
+		s.Duration = h.sum
+	}
+
+	for k, n := range m.errors {
+		if k[0] == query {
+			if s.Errors == nil {
+				s.Errors = map[string]uint64{}
+			}
+
+			s.Errors[k[1]] = n
+		}
+	}
+
+	return s
+}
+
+func (m *Metrics) buckets() []float64 {
+	if m.Buckets == nil {
+		return DefaultBuckets
+	}
+
+	return m.Buckets
+}
+
+// WriteMetrics writes the metrics in the Prometheus text exposition format, e.g.
+// `db_queries_total{query="GetUser"} 42`.
+func (m *Metrics) WriteMetrics(w io.Writer) error {
+	buckets := m.buckets()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queries := make([]string, 0, len(m.queries))
+	for q := range m.queries {
+		queries = append(queries, q)
+	}
+
+	sort.Strings(queries)
+
+	var out httputil.Metrics
+
+	name := MetricsPrefix + "queries_total"
+	out.Family(name, "counter", "Database queries.")
+
+	for _, q := range queries {
+		out.Sample(name, m.queries[q].count, "query", q)
+	}
+
+	name = MetricsPrefix + "query_duration_seconds"
+	out.Family(name, "histogram", "Database query durations.")
+
+	for _, q := range queries {
+		h := m.queries[q]
+
+		var cumulative uint64
+
+		for i, le := range buckets {
+			cumulative += h.counts[i]
+			out.Sample(name+"_bucket", cumulative, "query", q, "le", httputil.MetricValue(le))
+		}
+
+		out.Sample(name+"_bucket", h.count, "query", q, "le", "+Inf")
+		out.Sample(name+"_sum", h.sum.Seconds(), "query", q)
+		out.Sample(name+"_count", h.count, "query", q)
+	}
+
+	keys := make([][2]string, 0, len(m.errors))
+	for k := range m.errors {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1]
+	})
+
+	name = MetricsPrefix + "query_errors_total"
+	out.Family(name, "counter", "Database query errors by SQLSTATE.")
+
+	for _, k := range keys {
+		out.Sample(name, m.errors[k], "query", k[0], "sqlstate", k[1])
+	}
+
+	_, err := out.WriteTo(w)
+
+	return err //nolint:wrapcheck
+}
+
+// Handler serves WriteMetrics, for scraping by Prometheus.
+func (m *Metrics) Handler() http.HandlerFunc {
+	return httputil.MetricsHandler(m.WriteMetrics)
+}
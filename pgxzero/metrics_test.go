@@ -0,0 +1,99 @@
+package pgxzero_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxzero"
+)
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"-- name: GetUser :one\nSELECT * FROM users WHERE id = $1", "GetUser"},
+		{"SELECT *\n  FROM users\n WHERE id = $1 AND name = 'bob''s' LIMIT 10", "SELECT * FROM users WHERE id = $1 AND name = ? LIMIT ?"},
+		{"SELECT * FROM users WHERE id IN ($1, $2, $3)", "SELECT * FROM users WHERE id IN (...)"},
+		{"SELECT * FROM t2 WHERE x = 1.5", "SELECT * FROM t2 WHERE x = ?"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, pgxzero.QueryName(test.sql), test.sql)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := &pgxzero.Metrics{Buckets: []float64{0.01, 0.1}}
+
+	m.Observe("GetUser", 5*time.Millisecond, nil)
+	m.Observe("GetUser", 50*time.Millisecond, &pgconn.PgError{Code: "40001"})
+	m.Observe("GetUser", time.Second, fmt.Errorf("query: %w", &pgconn.PgError{Code: "40001"}))
+	m.Observe("Other", 0, errors.New("conn closed"))
+
+	assert.Equal(t, pgxzero.QueryStats{
+		Count: 3, Duration: 1055 * time.Millisecond, Errors: map[string]uint64{"40001": 2},
+	}, m.Stats("GetUser"))
+
+	w := httptest.NewRecorder()
+	m.Handler()(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, `# HELP db_queries_total Database queries.
+# TYPE db_queries_total counter
+db_queries_total{query="GetUser"} 3
+db_queries_total{query="Other"} 1
+# HELP db_query_duration_seconds Database query durations.
+# TYPE db_query_duration_seconds histogram
+db_query_duration_seconds_bucket{query="GetUser",le="0.01"} 1
+db_query_duration_seconds_bucket{query="GetUser",le="0.1"} 2
+db_query_duration_seconds_bucket{query="GetUser",le="+Inf"} 3
+db_query_duration_seconds_sum{query="GetUser"} 1.055
+db_query_duration_seconds_count{query="GetUser"} 3
+db_query_duration_seconds_bucket{query="Other",le="0.01"} 1
+db_query_duration_seconds_bucket{query="Other",le="0.1"} 1
+db_query_duration_seconds_bucket{query="Other",le="+Inf"} 1
+db_query_duration_seconds_sum{query="Other"} 0
+db_query_duration_seconds_count{query="Other"} 1
+# HELP db_query_errors_total Database query errors by SQLSTATE.
+# TYPE db_query_errors_total counter
+db_query_errors_total{query="GetUser",sqlstate="40001"} 2
+db_query_errors_total{query="Other",sqlstate=""} 1
+`, w.Body.String())
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	m := &pgxzero.Metrics{Buckets: []float64{}}
+	m.Observe(`SELECT "a\b"`+"\u00a0FROM t", 0, nil)
+
+	var b strings.Builder
+
+	require.NoError(t, m.WriteMetrics(&b))
+	assert.Contains(t, b.String(), `db_queries_total{query="SELECT \"a\\b\"`+"\u00a0"+`FROM t"} 1`,
+		"exposition escapes, not Go quoting")
+}
+
+func TestTracerMetrics(t *testing.T) {
+	tr := pgxzero.NewTracer(zerolog.Nop())
+	tr.Metrics = pgxzero.NewMetrics()
+
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "-- name: Ping\nselect 1"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	ctx = tr.TraceCopyFromStart(context.Background(), nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"users"}})
+	tr.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{Err: &pgconn.PgError{Code: "23505"}})
+
+	assert.Equal(t, uint64(1), tr.Metrics.Stats("Ping").Count)
+	assert.Equal(t, map[string]uint64{"23505": 1}, tr.Metrics.Stats(`COPY "users"`).Errors)
+}
@@ -0,0 +1,172 @@
+package pgxzero
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// RedactMode is how a Redactor logs an argument.
+type RedactMode int
+
+// Redact modes, from the least to the most strict.
+const (
+	// RedactNone logs the argument as is.
+	RedactNone RedactMode = iota
+	// RedactTruncate keeps the first TruncateLen characters of the argument.
+	RedactTruncate
+	// RedactHash replaces the argument with a short SHA-256 hash, so equal values remain identifiable.
+	RedactHash
+	// RedactOmit replaces the argument with Omitted.
+	RedactOmit
+)
+
+// Omitted replaces arguments redacted with RedactOmit.
+var Omitted = "[omitted]"
+
+// DefaultTruncateLen is the number of characters kept by RedactTruncate when TruncateLen is 0.
+const DefaultTruncateLen = 4
+
+// Redactor redacts the bound arguments of logged queries.  The mode of an argument is the first of: Index by
+// parameter number ($1 is 1), Names by the patterns (path.Match syntax, e.g. "*email*") matching the lower case
+// column compared to or inserted from the parameter (or the name of pgx.NamedArgs), the strictest if several match,
+// and Default.
+type Redactor struct {
+	Default     RedactMode
+	Index       map[int]RedactMode
+	Names       map[string]RedactMode
+	TruncateLen int
+}
+
+var (
+	// reCompare matches a column compared to a parameter, e.g. `u.email = $1` or `name ILIKE $2`.
+	reCompare = regexp.MustCompile(
+		`(?i)"?([a-z_][a-z0-9_]*)"?\s*(?:=|<>|!=|<=|>=|<|>|\s(?:i?like|in)\s*\(?|=\s*any\s*\()\s*\$(\d+)\b`)
+	// reInsert matches the columns and values of an insert.
+	reInsert = regexp.MustCompile(`(?is)insert\s+into\s+[^(]+\(([^)]*)\)\s*values\s*\(([^)]*)\)`)
+)
+
+// Args returns the args of sql as logged.
+func (r *Redactor) Args(sql string, args []any) []any {
+	if len(args) == 1 {
+		switch named := args[0].(type) {
+		case pgx.NamedArgs:
+			return []any{pgx.NamedArgs(r.named(named))}
+		case pgx.StrictNamedArgs:
+			return []any{pgx.StrictNamedArgs(r.named(named))}
+		}
+	}
+
+	var names map[int]string
+	if len(r.Names) > 0 {
+		names = paramNames(sql)
+	}
+
+	out := make([]any, len(args))
+
+	for i, v := range args {
+		mode, ok := r.Index[i+1]
+		if !ok {
+			mode = r.nameMode(names[i+1])
+		}
+
+		out[i] = r.redact(mode, v)
+	}
+
+	return out
+}
+
+func (r *Redactor) named(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = r.redact(r.nameMode(k), v)
+	}
+
+	return out
+}
+
+// nameMode returns the mode of a parameter named name, "" if unknown.
+func (r *Redactor) nameMode(name string) RedactMode {
+	if name == "" {
+		return r.Default
+	}
+
+	name = strings.ToLower(name)
+	found := false
+	out := RedactNone
+
+	for pattern, mode := range r.Names {
+		if ok, _ := path.Match(pattern, name); ok {
+			found = true
+			out = max(out, mode)
+		}
+	}
+
+	if !found {
+		return r.Default
+	}
+
+	return out
+}
+
+func (r *Redactor) redact(mode RedactMode, v any) any {
+	if v == nil {
+		return nil
+	}
+
+	switch mode {
+	case RedactNone:
+		return v
+	case RedactOmit:
+		return Omitted
+	case RedactHash:
+		sum := sha256.Sum256([]byte(fmt.Sprint(v)))
+
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	case RedactTruncate:
+		n := r.TruncateLen
+		if n <= 0 {
+			n = DefaultTruncateLen
+		}
+
+		if s := []rune(fmt.Sprint(v)); len(s) > n {
+			return string(s[:n]) + "..."
+		}
+
+		return fmt.Sprint(v)
+	}
+
+	return Omitted
+}
+
+// paramNames returns the column names of the parameters of sql, by parameter number.
+func paramNames(sql string) map[int]string {
+	names := map[int]string{}
+
+	for _, m := range reInsert.FindAllStringSubmatch(sql, -1) {
+		columns, values := strings.Split(m[1], ","), strings.Split(m[2], ",")
+
+		for i := range min(len(columns), len(values)) {
+			value := strings.TrimSpace(values[i])
+			if n, err := strconv.Atoi(strings.TrimPrefix(value, "$")); err == nil && strings.HasPrefix(value, "$") {
+				names[n] = strings.Trim(strings.TrimSpace(columns[i]), `"`)
+			}
+		}
+	}
+
+	for _, m := range reCompare.FindAllStringSubmatch(sql, -1) {
+		if n, err := strconv.Atoi(m[2]); err == nil {
+			if _, ok := names[n]; !ok {
+				names[n] = m[1]
+			}
+		}
+	}
+
+	return names
+}
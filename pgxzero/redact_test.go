@@ -0,0 +1,85 @@
+package pgxzero_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/tracelog"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/pgxzero"
+)
+
+func TestRedactor(t *testing.T) {
+	r := &pgxzero.Redactor{
+		Index: map[int]pgxzero.RedactMode{3: pgxzero.RedactNone},
+		Names: map[string]pgxzero.RedactMode{
+			"*email*": pgxzero.RedactHash, "password": pgxzero.RedactOmit, "name": pgxzero.RedactTruncate,
+			"*": pgxzero.RedactNone,
+		},
+		Default: pgxzero.RedactOmit,
+	}
+
+	tests := []struct {
+		name string
+		sql  string
+		args []any
+		want []any
+	}{
+		{
+			"compare",
+			`SELECT * FROM users u WHERE u.email = $1 AND "password" = $2 AND id = $3 AND name ILIKE $4`,
+			[]any{"bob@example.com", "secret", 42, "Robert"},
+			[]any{"sha256:5ff860bf1190596c", pgxzero.Omitted, 42, "Robe..."},
+		},
+		{
+			"insert",
+			`INSERT INTO users (id, "email", password) VALUES ($1, $2, $3) RETURNING id`,
+			[]any{7, "bob@example.com", nil},
+			[]any{7, "sha256:5ff860bf1190596c", nil},
+		},
+		{"unknown", "SELECT f($1, $2, $3)", []any{1, 2, 3}, []any{pgxzero.Omitted, pgxzero.Omitted, 3}},
+		{
+			"named",
+			"SELECT * FROM users WHERE email = @email AND id = @id",
+			[]any{pgx.NamedArgs{"email": "bob@example.com", "id": 1}},
+			[]any{pgx.NamedArgs{"email": "sha256:5ff860bf1190596c", "id": 1}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, r.Args(test.sql, test.args))
+		})
+	}
+
+	global := &pgxzero.Redactor{Default: pgxzero.RedactTruncate, TruncateLen: 2}
+	assert.Equal(t, []any{"12...", "ab"}, global.Args("", []any{12345, "ab"}))
+}
+
+func TestRedactorLogging(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := &pgxzero.Redactor{Default: pgxzero.RedactOmit}
+
+	tr := pgxzero.NewTracer(zerolog.New(&buf))
+	tr.Redactor = r
+
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select $1", Args: []any{"x"}})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	lines := logged(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, []any{pgxzero.Omitted}, lines[0][pgxzero.DBArgs])
+
+	pgxzero.New(zerolog.New(&buf)).WithRedactor(r).Log(context.Background(), tracelog.LogLevelInfo, "Query",
+		map[string]any{"sql": "select $1", "args": []any{"x"}})
+
+	lines = logged(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, []any{pgxzero.Omitted}, lines[0]["args"])
+}
@@ -0,0 +1,290 @@
+package pgxzero
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/bir/iken/httplog"
+	"github.com/bir/iken/logctx"
+)
+
+// Reference: https://docs.datadoghq.com/standard-attributes/?product=log&search=db.
+const (
+	DBAlreadyPrepared = "db.already_prepared"
+	DBArgs            = "db.args"
+	DBBatchSize       = "db.batch_size"
+	DBColumns         = "db.columns"
+	DBInstance        = "db.instance"
+	DBPID             = "db.pid"
+	DBPlan            = "db.plan"
+	DBPlanError       = "db.plan_error"
+	DBRowCount        = "db.row_count"
+	DBSlow            = "db.slow"
+	DBStatement       = "db.statement"
+	DBStatementName   = "db.statement_name"
+	DBTable           = "db.table"
+	DBUser            = "db.user"
+	NetworkHost       = "network.destination.host"
+	NetworkPort       = "network.destination.port"
+)
+
+// Tracer logs pgx v5 query, batch, copy, prepare and connect events to zerolog, set it as pgx.ConnConfig.Tracer.
+// Successful events are logged at Level, slow events at warn and failures at error with the error.
+type Tracer struct {
+	Logger zerolog.Logger
+	// Level of successful events, defaults to debug.
+	Level zerolog.Level
+	// SlowThreshold logs events taking at least this long at warn with DBSlow and the full statement, 0 disables it.
+	SlowThreshold time.Duration
+	// Sampler samples the successful events below SlowThreshold, nil logs all of them.
+	Sampler zerolog.Sampler
+	// MaxStatementLog truncates the statements of events below SlowThreshold to this many bytes, 0 is unlimited.
+	MaxStatementLog int
+	// Redactor redacts the logged arguments, nil logs them as is.
+	Redactor *Redactor
+	// Metrics records queries by QueryName, copies as "COPY table" and batches as "batch", nil disables them.
+	Metrics *Metrics
+	// Explain logs the plans of a sample of the successful queries over SlowThreshold, nil disables it.
+	Explain *Explain
+}
+
+// NewTracer returns a Tracer logging to logger at debug level.
+func NewTracer(logger zerolog.Logger) *Tracer {
+	return &Tracer{
+		Logger: logger.With().Str("module", "pgx").Logger(),
+		Level:  zerolog.DebugLevel,
+	}
+}
+
+var (
+	_ pgx.QueryTracer    = (*Tracer)(nil)
+	_ pgx.BatchTracer    = (*Tracer)(nil)
+	_ pgx.CopyFromTracer = (*Tracer)(nil)
+	_ pgx.PrepareTracer  = (*Tracer)(nil)
+	_ pgx.ConnectTracer  = (*Tracer)(nil)
+)
+
+type traceKey struct{}
+
+// trace is the state of an event between its start and end, stored in the context.
+type trace struct {
+	start  time.Time
+	sql    string
+	args   []any
+	size   int
+	table  pgx.Identifier
+	cols   []string
+	name   string
+	config *pgx.ConnConfig
+}
+
+func startTrace(ctx context.Context, t *trace) context.Context {
+	t.start = time.Now()
+
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+func getTrace(ctx context.Context) *trace {
+	if t, ok := ctx.Value(traceKey{}).(*trace); ok {
+		return t
+	}
+
+	return &trace{start: time.Now()}
+}
+
+// TraceQueryStart is the pgx.QueryTracer contract.
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if explaining(ctx) {
+		return ctx
+	}
+
+	return startTrace(ctx, &trace{sql: data.SQL, args: data.Args})
+}
+
+// TraceQueryEnd is the pgx.QueryTracer contract.
+func (t *Tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	if explaining(ctx) {
+		return
+	}
+
+	tr := getTrace(ctx)
+
+	d := time.Since(tr.start)
+	t.observe(QueryName(tr.sql), d, data.Err)
+
+	e := t.event(ctx, conn, data.Err, d).
+		Str(DBStatement, t.statement(tr.sql, d)).
+		Interface(DBArgs, t.args(tr.sql, tr.args)).
+		Int64(DBRowCount, data.CommandTag.RowsAffected()).
+		Dur(httplog.Duration, d)
+
+	if t.explains(data.Err, d) {
+		switch {
+		case t.Explain.DB != nil:
+			t.explainBackground(ctx, t.event(ctx, conn, nil, d).Str(DBStatement, tr.sql), tr.sql, tr.args)
+		case idle(conn):
+			plan, err := t.explain(ctx, conn, tr.sql, tr.args)
+			e = withPlan(e, plan, err)
+		}
+	}
+
+	e.Msg("Query")
+}
+
+// TraceBatchStart is the pgx.BatchTracer contract.
+func (t *Tracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+
+	return startTrace(ctx, &trace{size: size})
+}
+
+// TraceBatchQuery is the pgx.BatchTracer contract.
+func (t *Tracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	t.event(ctx, conn, data.Err, 0).
+		Str(DBStatement, t.statement(data.SQL, 0)).
+		Interface(DBArgs, t.args(data.SQL, data.Args)).
+		Int64(DBRowCount, data.CommandTag.RowsAffected()).
+		Msg("BatchQuery")
+}
+
+// TraceBatchEnd is the pgx.BatchTracer contract.
+func (t *Tracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	tr := getTrace(ctx)
+
+	d := time.Since(tr.start)
+	t.observe("batch", d, data.Err)
+
+	t.event(ctx, conn, data.Err, d).
+		Int(DBBatchSize, tr.size).
+		Dur(httplog.Duration, d).
+		Msg("BatchClose")
+}
+
+// TraceCopyFromStart is the pgx.CopyFromTracer contract.
+func (t *Tracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData,
+) context.Context {
+	return startTrace(ctx, &trace{table: data.TableName, cols: data.ColumnNames})
+}
+
+// TraceCopyFromEnd is the pgx.CopyFromTracer contract.
+func (t *Tracer) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	tr := getTrace(ctx)
+
+	d := time.Since(tr.start)
+	t.observe("COPY "+tr.table.Sanitize(), d, data.Err)
+
+	t.event(ctx, conn, data.Err, d).
+		Str(DBTable, tr.table.Sanitize()).
+		Strs(DBColumns, tr.cols).
+		Int64(DBRowCount, data.CommandTag.RowsAffected()).
+		Dur(httplog.Duration, d).
+		Msg("CopyFrom")
+}
+
+// TracePrepareStart is the pgx.PrepareTracer contract.
+func (t *Tracer) TracePrepareStart(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareStartData,
+) context.Context {
+	return startTrace(ctx, &trace{name: data.Name, sql: data.SQL})
+}
+
+// TracePrepareEnd is the pgx.PrepareTracer contract.
+func (t *Tracer) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	tr := getTrace(ctx)
+
+	d := time.Since(tr.start)
+
+	t.event(ctx, conn, data.Err, d).
+		Str(DBStatementName, tr.name).
+		Str(DBStatement, t.statement(tr.sql, d)).
+		Bool(DBAlreadyPrepared, data.AlreadyPrepared).
+		Dur(httplog.Duration, d).
+		Msg("Prepare")
+}
+
+// TraceConnectStart is the pgx.ConnectTracer contract.
+func (t *Tracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	return startTrace(ctx, &trace{config: data.ConnConfig})
+}
+
+// TraceConnectEnd is the pgx.ConnectTracer contract.
+func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	tr := getTrace(ctx)
+
+	d := time.Since(tr.start)
+	e := t.event(ctx, data.Conn, data.Err, d)
+
+	if tr.config != nil {
+		e = e.Str(NetworkHost, tr.config.Host).
+			Uint16(NetworkPort, tr.config.Port).
+			Str(DBInstance, tr.config.Database).
+			Str(DBUser, tr.config.User)
+	}
+
+	e.Dur(httplog.Duration, d).Msg("Connect")
+}
+
+func (t *Tracer) observe(query string, d time.Duration, err error) {
+	if t.Metrics != nil {
+		t.Metrics.Observe(query, d, err)
+	}
+}
+
+// args returns the logged args of sql.
+func (t *Tracer) args(sql string, args []any) []any {
+	if t.Redactor == nil {
+		return args
+	}
+
+	return t.Redactor.Args(sql, args)
+}
+
+// slow reports whether an event taking d is logged as slow.
+func (t *Tracer) slow(d time.Duration) bool {
+	return t.SlowThreshold > 0 && d >= t.SlowThreshold
+}
+
+// statement returns sql truncated to MaxStatementLog unless the event is slow.
+func (t *Tracer) statement(sql string, d time.Duration) string {
+	if t.MaxStatementLog > 0 && len(sql) > t.MaxStatementLog && !t.slow(d) {
+		return sql[:t.MaxStatementLog] + "..."
+	}
+
+	return sql
+}
+
+// event starts an event of duration d: error with err, warn if slow, else Level if sampled, with the request ID and
+// operation of ctx.  Returns nil (discarding the fields) for events not sampled.
+func (t *Tracer) event(ctx context.Context, conn *pgx.Conn, err error, d time.Duration) *zerolog.Event {
+	var e *zerolog.Event
+
+	switch {
+	case err != nil:
+		e = t.Logger.Error().Err(err)
+	case t.slow(d):
+		e = t.Logger.Warn().Bool(DBSlow, true)
+	case t.Sampler != nil && !t.Sampler.Sample(t.Level):
+		return nil
+	default:
+		e = t.Logger.WithLevel(t.Level)
+	}
+
+	if conn != nil && conn.PgConn() != nil {
+		e = e.Uint32(DBPID, conn.PgConn().PID())
+	}
+
+	if id := logctx.GetID(ctx); id != "" {
+		e = e.Str(httplog.RequestID, id)
+	}
+
+	if op := logctx.GetOperation(ctx); op != "" {
+		e = e.Str(httplog.Operation, op)
+	}
+
+	return e
+}
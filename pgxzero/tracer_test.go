@@ -0,0 +1,172 @@
+package pgxzero_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/httplog"
+	"github.com/bir/iken/logctx"
+	"github.com/bir/iken/pgxzero"
+)
+
+// logged decodes the log lines of buf, removing the variable duration.
+func logged(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var out []map[string]any
+
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var m map[string]any
+
+		require.NoError(t, dec.Decode(&m))
+
+		delete(m, httplog.Duration)
+
+		out = append(out, m)
+	}
+
+	buf.Reset()
+
+	return out
+}
+
+func TestTracer(t *testing.T) {
+	var buf bytes.Buffer
+
+	tr := pgxzero.NewTracer(zerolog.New(&buf))
+	ctx := context.Background()
+
+	qctx := tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "select $1", Args: []any{1}})
+	tr.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	qctx = tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "bad"})
+	tr.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{Err: errors.New("syntax error")})
+
+	assert.Equal(t, []map[string]any{
+		{
+			"level": "debug", "module": "pgx", "message": "Query",
+			pgxzero.DBStatement: "select $1", pgxzero.DBArgs: []any{float64(1)}, pgxzero.DBRowCount: float64(1),
+		},
+		{
+			"level": "error", "module": "pgx", "message": "Query", "error": "syntax error",
+			pgxzero.DBStatement: "bad", pgxzero.DBArgs: nil, pgxzero.DBRowCount: float64(0),
+		},
+	}, logged(t, &buf))
+
+	b := &pgx.Batch{}
+	b.Queue("insert 1")
+	b.Queue("insert 2")
+
+	bctx := tr.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{Batch: b})
+	tr.TraceBatchQuery(bctx, nil, pgx.TraceBatchQueryData{SQL: "insert 1", CommandTag: pgconn.NewCommandTag("INSERT 0 1")})
+	tr.TraceBatchEnd(bctx, nil, pgx.TraceBatchEndData{})
+
+	lines := logged(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "BatchQuery", lines[0]["message"])
+	assert.Equal(t, "insert 1", lines[0][pgxzero.DBStatement])
+	assert.Equal(t, "BatchClose", lines[1]["message"])
+	assert.InDelta(t, 2, lines[1][pgxzero.DBBatchSize], 0)
+
+	cctx := tr.TraceCopyFromStart(ctx, nil, pgx.TraceCopyFromStartData{
+		TableName: pgx.Identifier{"public", "users"}, ColumnNames: []string{"id", "name"},
+	})
+	tr.TraceCopyFromEnd(cctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 3")})
+
+	assert.Equal(t, []map[string]any{{
+		"level": "debug", "module": "pgx", "message": "CopyFrom",
+		pgxzero.DBTable: `"public"."users"`, pgxzero.DBColumns: []any{"id", "name"}, pgxzero.DBRowCount: float64(3),
+	}}, logged(t, &buf))
+
+	pctx := tr.TracePrepareStart(ctx, nil, pgx.TracePrepareStartData{Name: "q1", SQL: "select 1"})
+	tr.TracePrepareEnd(pctx, nil, pgx.TracePrepareEndData{AlreadyPrepared: true})
+
+	assert.Equal(t, []map[string]any{{
+		"level": "debug", "module": "pgx", "message": "Prepare",
+		pgxzero.DBStatementName: "q1", pgxzero.DBStatement: "select 1", pgxzero.DBAlreadyPrepared: true,
+	}}, logged(t, &buf))
+
+	config, err := pgx.ParseConfig("postgres://bob@db:5433/app")
+	require.NoError(t, err)
+
+	tr.Level = zerolog.InfoLevel
+
+	connCtx := tr.TraceConnectStart(ctx, pgx.TraceConnectStartData{ConnConfig: config})
+	tr.TraceConnectEnd(connCtx, pgx.TraceConnectEndData{})
+
+	assert.Equal(t, []map[string]any{{
+		"level": "info", "module": "pgx", "message": "Connect",
+		pgxzero.NetworkHost: "db", pgxzero.NetworkPort: float64(5433), pgxzero.DBInstance: "app", pgxzero.DBUser: "bob",
+	}}, logged(t, &buf))
+}
+
+func TestTracerRequestID(t *testing.T) {
+	var buf bytes.Buffer
+
+	tr := pgxzero.NewTracer(zerolog.New(&buf))
+	ctx := logctx.SetOperation(logctx.SetID(context.Background(), "req-1"), "getUser")
+
+	qctx := tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	tr.TraceQueryEnd(qctx, nil, pgx.TraceQueryEndData{})
+
+	bctx := tr.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{Batch: &pgx.Batch{}})
+	tr.TraceBatchQuery(bctx, nil, pgx.TraceBatchQueryData{SQL: "select 2"})
+
+	lines := logged(t, &buf)
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		assert.Equal(t, "req-1", line[httplog.RequestID])
+		assert.Equal(t, "getUser", line[httplog.Operation])
+	}
+}
+
+func TestTracerSlow(t *testing.T) {
+	var buf bytes.Buffer
+
+	tr := pgxzero.NewTracer(zerolog.New(&buf))
+	tr.SlowThreshold = time.Millisecond
+	tr.MaxStatementLog = 6
+
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	ctx = tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select pg_sleep(1)"})
+	time.Sleep(2 * time.Millisecond)
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	lines := logged(t, &buf)
+	require.Len(t, lines, 2)
+	assert.Equal(t, "debug", lines[0]["level"])
+	assert.Equal(t, "select...", lines[0][pgxzero.DBStatement], "truncated")
+	assert.NotContains(t, lines[0], pgxzero.DBSlow)
+	assert.Equal(t, "warn", lines[1]["level"])
+	assert.Equal(t, true, lines[1][pgxzero.DBSlow])
+	assert.Equal(t, "select pg_sleep(1)", lines[1][pgxzero.DBStatement], "full statement")
+
+	tr.SlowThreshold = time.Hour
+	tr.Sampler = &zerolog.BasicSampler{N: 3}
+
+	for range 6 {
+		ctx = tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "select 1"})
+		tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	}
+
+	ctx = tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "bad"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("boom")})
+
+	lines = logged(t, &buf)
+	require.Len(t, lines, 3, "fast queries sampled, errors always logged")
+	assert.Equal(t, "error", lines[2]["level"])
+}
@@ -0,0 +1,199 @@
+package strutil
+
+import (
+	"iter"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/width"
+)
+
+// Ellipsis is the suffix marking truncated strings.
+const Ellipsis = "…"
+
+const (
+	zwj          = '\u200d'
+	emojiVariant = '\ufe0f'
+)
+
+// TruncateRunes returns s truncated to at most n runes, ending with ellipsis (which counts towards n) if truncated.
+// The ellipsis is dropped if it does not fit.  Invalid UTF-8 is replaced with utf8.RuneError, so the result is always
+// valid UTF-8.
+func TruncateRunes(s string, n int, ellipsis string) string {
+	s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	ellipsis = strings.ToValidUTF8(ellipsis, string(utf8.RuneError))
+
+	keep := n - utf8.RuneCountInString(ellipsis)
+	if keep < 0 {
+		keep, ellipsis = max(n, 0), ""
+	}
+
+	i := 0
+	for range keep {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+
+	return s[:i] + ellipsis
+}
+
+// TruncateWidth returns s truncated to at most w terminal columns (see Width), ending with ellipsis (which counts
+// towards w) if truncated.  Grapheme clusters (see Graphemes) are never split, and the ellipsis is dropped if it does
+// not fit.  Invalid UTF-8 is replaced with utf8.RuneError, so the result is always valid UTF-8.
+func TruncateWidth(s string, w int, ellipsis string) string {
+	s = strings.ToValidUTF8(s, string(utf8.RuneError))
+	if Width(s) <= w {
+		return s
+	}
+
+	ellipsis = strings.ToValidUTF8(ellipsis, string(utf8.RuneError))
+
+	keep := w - Width(ellipsis)
+	if keep < 0 {
+		keep, ellipsis = w, ""
+	}
+
+	i := 0
+
+	for g := range Graphemes(s) {
+		gw := graphemeWidth(g)
+		if gw > keep {
+			break
+		}
+
+		keep -= gw
+		i += len(g)
+	}
+
+	return s[:i] + ellipsis
+}
+
+// TruncateBytes returns the longest prefix of b of at most n bytes that does not split a UTF-8 encoded rune.  Invalid
+// UTF-8 (e.g. binary data) is kept as is, only a valid rune crossing n is dropped.
+func TruncateBytes(b []byte, n int) []byte {
+	if len(b) <= n {
+		return b
+	}
+
+	n = max(n, 0)
+
+	for i := n - 1; i >= 0 && i > n-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+
+		if r, size := utf8.DecodeRune(b[i:]); i+size > n && (r != utf8.RuneError || size > 1) {
+			return b[:i]
+		}
+
+		break
+	}
+
+	return b[:n]
+}
+
+// Width returns the display width of s in terminal columns: 2 for East Asian wide and fullwidth characters and emoji,
+// 0 for control and format characters and combining marks, 1 otherwise.  Each grapheme cluster counts once.
+func Width(s string) int {
+	w := 0
+
+	for g := range Graphemes(s) {
+		w += graphemeWidth(g)
+	}
+
+	return w
+}
+
+// Graphemes returns the user perceived characters of s, approximating the extended grapheme clusters of Unicode
+// UAX #29: a rune with its combining marks, variation selectors, emoji modifiers and tags, runes joined by ZWJ,
+// regional indicator pairs (flags) and CRLF are not split.
+func Graphemes(s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for s != "" {
+			n := graphemeLen(s)
+			if !yield(s[:n]) {
+				return
+			}
+
+			s = s[n:]
+		}
+	}
+}
+
+// graphemeLen returns the byte length of the grapheme cluster at the start of s.
+func graphemeLen(s string) int {
+	r, i := utf8.DecodeRuneInString(s)
+
+	switch {
+	case r == '\r':
+		if strings.HasPrefix(s[i:], "\n") {
+			i++
+		}
+
+		return i
+	case isControl(r):
+		return i
+	case isRegionalIndicator(r):
+		if next, size := utf8.DecodeRuneInString(s[i:]); isRegionalIndicator(next) {
+			i += size
+		}
+	}
+
+	for i < len(s) {
+		next, size := utf8.DecodeRuneInString(s[i:])
+
+		switch {
+		case r == zwj && !isControl(next):
+		case isExtend(next):
+		default:
+			return i
+		}
+
+		r = next
+		i += size
+	}
+
+	return i
+}
+
+func graphemeWidth(g string) int {
+	r, size := utf8.DecodeRuneInString(g)
+
+	switch {
+	case isControl(r) || isExtend(r):
+		return 0
+	case isRegionalIndicator(r) || strings.ContainsRune(g[size:], emojiVariant):
+		return 2
+	}
+
+	switch width.LookupRune(r).Kind() { //nolint:exhaustive
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func isControl(r rune) bool {
+	return r != zwj && (unicode.IsControl(r) || unicode.Is(unicode.Cf, r) || r == '\u2028' || r == '\u2029')
+}
+
+// isExtend reports whether r extends the preceding rune: combining marks, ZWJ, variation selectors, emoji modifiers,
+// tags and Hangul medial vowels and final consonants.
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Variation_Selector) ||
+		r == zwj ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) ||
+		(r >= 0xe0020 && r <= 0xe007f) ||
+		(r >= 0x1160 && r <= 0x11ff) ||
+		(r >= 0xd7b0 && r <= 0xd7ff)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
@@ -0,0 +1,169 @@
+package strutil
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		n        int
+		ellipsis string
+		want     string
+	}{
+		{name: "short", s: "abc", n: 3, ellipsis: Ellipsis, want: "abc"},
+		{name: "ascii", s: "abcdef", n: 4, ellipsis: Ellipsis, want: "abc…"},
+		{name: "multibyte", s: "héllo wörld", n: 6, ellipsis: "...", want: "hél..."},
+		{name: "no ellipsis", s: "日本語テキスト", n: 3, ellipsis: "", want: "日本語"},
+		{name: "ellipsis too long", s: "abcdef", n: 2, ellipsis: "...", want: "ab"},
+		{name: "zero", s: "abc", n: 0, ellipsis: Ellipsis, want: ""},
+		{name: "negative", s: "abc", n: -1, ellipsis: Ellipsis, want: ""},
+		{name: "invalid", s: "ab\xffcd", n: 4, ellipsis: "", want: "ab�c"},
+		{name: "invalid short", s: "a\xff", n: 4, ellipsis: "", want: "a�"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := TruncateRunes(test.s, test.n, test.ellipsis)
+			if got != test.want {
+				t.Errorf("TruncateRunes(%q, %d, %q) = %q, want %q", test.s, test.n, test.ellipsis, got, test.want)
+			}
+
+			if !utf8.ValidString(got) {
+				t.Errorf("TruncateRunes(%q) = %q is not valid UTF-8", test.s, got)
+			}
+		})
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		w        int
+		ellipsis string
+		want     string
+	}{
+		{name: "short", s: "abc", w: 3, ellipsis: Ellipsis, want: "abc"},
+		{name: "ascii", s: "abcdef", w: 4, ellipsis: Ellipsis, want: "abc…"},
+		{name: "wide", s: "日本語テキスト", w: 7, ellipsis: Ellipsis, want: "日本語…"},
+		{name: "wide boundary", s: "日本語テキスト", w: 6, ellipsis: Ellipsis, want: "日本…"},
+		{name: "combining", s: "e\u0301e\u0301e\u0301e\u0301", w: 3, ellipsis: Ellipsis, want: "e\u0301e\u0301…"},
+		{name: "zwj family", s: "👨\u200d👩\u200d👧 family", w: 5, ellipsis: Ellipsis, want: "👨\u200d👩\u200d👧 f…"},
+		{name: "flags", s: "🇺🇸🇫🇷🇯🇵", w: 5, ellipsis: "", want: "🇺🇸🇫🇷"},
+		{name: "skin tone", s: "👍🏽👍🏽👍🏽", w: 4, ellipsis: "", want: "👍🏽👍🏽"},
+		{name: "ellipsis too long", s: "abcdef", w: 2, ellipsis: "...", want: "ab"},
+		{name: "zero", s: "abc", w: 0, ellipsis: Ellipsis, want: ""},
+		{name: "invalid", s: "ab\xff\xfecdef", w: 4, ellipsis: "", want: "ab�c"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := TruncateWidth(test.s, test.w, test.ellipsis)
+			if got != test.want {
+				t.Errorf("TruncateWidth(%q, %d, %q) = %q, want %q", test.s, test.w, test.ellipsis, got, test.want)
+			}
+
+			if !utf8.ValidString(got) {
+				t.Errorf("TruncateWidth(%q) = %q is not valid UTF-8", test.s, got)
+			}
+
+			if w := Width(got); w > max(test.w, 0) {
+				t.Errorf("Width(%q) = %d, want <= %d", got, w, test.w)
+			}
+		})
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{s: "", want: 0},
+		{s: "abc", want: 3},
+		{s: "日本", want: 4},
+		{s: "ｈｉ", want: 4},
+		{s: "e\u0301", want: 1},
+		{s: "\u0301", want: 0},
+		{s: "a\tb\r\n", want: 2},
+		{s: "😀", want: 2},
+		{s: "\u2764\ufe0f", want: 2},
+		{s: "👨\u200d👩\u200d👧", want: 2},
+		{s: "🇺🇸", want: 2},
+		{s: "한국어", want: 6},
+		{s: "각", want: 2},
+	}
+
+	for _, test := range tests {
+		if got := Width(test.s); got != test.want {
+			t.Errorf("Width(%q) = %d, want %d", test.s, got, test.want)
+		}
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{s: "", want: nil},
+		{s: "ab", want: []string{"a", "b"}},
+		{s: "e\u0301x", want: []string{"e\u0301", "x"}},
+		{s: "a\r\nb", want: []string{"a", "\r\n", "b"}},
+		{s: "👨\u200d👩\u200d👧!", want: []string{"👨\u200d👩\u200d👧", "!"}},
+		{s: "🇺🇸🇫", want: []string{"🇺🇸", "🇫"}},
+		{s: "🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f", want: []string{
+			"🏴\U000e0067\U000e0062\U000e0073\U000e0063\U000e0074\U000e007f",
+		}},
+		{s: "\u0301\u0301a", want: []string{"\u0301\u0301", "a"}},
+		{s: "\x00\u0301", want: []string{"\x00", "\u0301"}},
+	}
+
+	for _, test := range tests {
+		if got := slices.Collect(Graphemes(test.s)); !slices.Equal(got, test.want) {
+			t.Errorf("Graphemes(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+
+	for g := range Graphemes("abc") {
+		if g != "a" {
+			t.Errorf("first grapheme = %q, want %q", g, "a")
+		}
+
+		break
+	}
+}
+
+func TestTruncateBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		b    string
+		n    int
+		want string
+	}{
+		{name: "short", b: "abc", n: 5, want: "abc"},
+		{name: "ascii", b: "abcdef", n: 3, want: "abc"},
+		{name: "boundary", b: "aé", n: 3, want: "aé"},
+		{name: "split 2 byte", b: "aéb", n: 2, want: "a"},
+		{name: "split 3 byte", b: "a日本", n: 3, want: "a"},
+		{name: "split 4 byte", b: "a😀", n: 4, want: "a"},
+		{name: "binary", b: "a\xff\xfe\xfd\xfc", n: 3, want: "a\xff\xfe"},
+		{name: "partial invalid", b: "a\xe6\x97", n: 2, want: "a\xe6"},
+		{name: "zero", b: "日本", n: 0, want: ""},
+		{name: "negative", b: "abc", n: -1, want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := TruncateBytes([]byte(test.b), test.n)
+			if !bytes.Equal(got, []byte(test.want)) {
+				t.Errorf("TruncateBytes(%q, %d) = %q, want %q", test.b, test.n, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+)
+
+// Catalog maps language tags (e.g. "fr", "pt-BR") to message templates keyed by RuleError.Key (falling back to
+// RuleError.Rule).  Templates reference the rule parameter as {param} or by rule name:
+//
+//	validation.Catalog{
+//		"fr": {
+//			"required":   "est obligatoire",
+//			"min.string": "doit contenir au moins {min} caractères",
+//			"oneof":      "doit être l'une des valeurs : {oneof}",
+//		},
+//	}
+type Catalog map[string]map[string]string
+
+// Message returns the message of re in the first supported language of langs, ordered by preference (see
+// httputil.AcceptLanguages).  Region specific tags fall back to the base language ("fr-CA" => "fr").  ok is false if
+// no template is available.
+func (c Catalog) Message(re RuleError, langs ...string) (string, bool) {
+	for _, tag := range langs {
+		if tmpl, ok := c.lookup(tag, re); ok {
+			return interpolate(tmpl, re), true
+		}
+	}
+
+	return "", false
+}
+
+// Localize returns err with the RuleError messages translated, see Message.  err may be a RuleError (e.g. from Var)
+// or *Errors, which is copied.  Messages without a translation and other errors are returned unchanged.
+func (c Catalog) Localize(err error, langs ...string) error {
+	if _, ok := err.(RuleError); ok { //nolint:errorlint // only direct rule errors are translated
+		return c.localize(err, langs)
+	}
+
+	var ee *Errors
+	if !errors.As(err, &ee) || ee == nil {
+		return err
+	}
+
+	out := make(Errors, len(*ee))
+
+	for field, msgs := range *ee {
+		localized := make(Messages, len(msgs))
+
+		for i, msg := range msgs {
+			localized[i] = c.localize(msg, langs)
+		}
+
+		out[field] = localized
+	}
+
+	return &out
+}
+
+func (c Catalog) localize(err error, langs []string) error {
+	re, ok := err.(RuleError) //nolint:errorlint // only direct rule errors are translated
+	if !ok {
+		return err
+	}
+
+	if msg, ok := c.Message(re, langs...); ok {
+		re.Message = msg
+	}
+
+	return re
+}
+
+func (c Catalog) lookup(tag string, re RuleError) (string, bool) {
+	if tmpl, ok := c.lookupKey(tag, re); ok {
+		return tmpl, true
+	}
+
+	if base, _, found := strings.Cut(tag, "-"); found {
+		return c.lookupKey(base, re)
+	}
+
+	return "", false
+}
+
+func (c Catalog) lookupKey(tag string, re RuleError) (string, bool) {
+	messages, ok := c[tag]
+	if !ok {
+		return "", false
+	}
+
+	if re.Key != "" {
+		if tmpl, ok := messages[re.Key]; ok {
+			return tmpl, true
+		}
+	}
+
+	tmpl, ok := messages[re.Rule]
+
+	return tmpl, ok
+}
+
+func interpolate(tmpl string, re RuleError) string {
+	param := re.Param
+	if re.Rule == "oneof" {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+
+	return strings.NewReplacer("{param}", param, "{"+re.Rule+"}", param).Replace(tmpl)
+}
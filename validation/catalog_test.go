@@ -0,0 +1,82 @@
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/validation"
+)
+
+func TestCatalog(t *testing.T) {
+	catalog := validation.Catalog{
+		"fr": {
+			"required":   "est obligatoire",
+			"min.string": "doit contenir au moins {min} caractères",
+			"min":        "doit être au moins {param}",
+			"oneof":      "doit être l'une des valeurs : {oneof}",
+		},
+		"de": {"required": "ist erforderlich"},
+	}
+
+	type Form struct {
+		Name  string `json:"name" validate:"required"`
+		Alias string `json:"alias" validate:"omitempty,min=3"`
+		Age   int    `json:"age" validate:"min=18"`
+		Role  string `json:"role" validate:"oneof=admin member"`
+		Code  string `json:"code" validate:"omitempty,len=2"`
+	}
+
+	err := validation.Struct(Form{Alias: "a", Role: "x", Code: "abc"})
+
+	tests := []struct {
+		name  string
+		langs []string
+		want  map[string][]string
+	}{
+		{"default", nil, map[string][]string{
+			"name":  {"is required"},
+			"alias": {"must be at least 3 characters"},
+			"age":   {"must be at least 18"},
+			"role":  {"must be one of: admin, member"},
+			"code":  {"must be exactly 2 characters"},
+		}},
+		{"region fallback", []string{"fr-CA"}, map[string][]string{
+			"name":  {"est obligatoire"},
+			"alias": {"doit contenir au moins 3 caractères"},
+			"age":   {"doit être au moins 18"},
+			"role":  {"doit être l'une des valeurs : admin, member"},
+			"code":  {"must be exactly 2 characters"},
+		}},
+		{"preference per message", []string{"de", "fr"}, map[string][]string{
+			"name":  {"ist erforderlich"},
+			"alias": {"doit contenir au moins 3 caractères"},
+			"age":   {"doit être au moins 18"},
+			"role":  {"doit être l'une des valeurs : admin, member"},
+			"code":  {"must be exactly 2 characters"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ee *validation.Errors
+
+			assert.ErrorAs(t, catalog.Localize(err, tt.langs...), &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+		})
+	}
+
+	// Original is unchanged
+	var ee *validation.Errors
+
+	assert.ErrorAs(t, err, &ee)
+	assert.Equal(t, "is required", (*ee)["name"][0].Error())
+
+	varErr := catalog.Localize(validation.Var(context.Background(), "", "required"), "fr")
+	assert.EqualError(t, varErr, "est obligatoire")
+
+	other := errors.New("other")
+	assert.Equal(t, other, catalog.Localize(other, "fr"))
+}
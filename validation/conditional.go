@@ -0,0 +1,176 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Conditional rules evaluate sibling fields of the struct containing the field.  Fields are referenced by Go field
+// name.
+const (
+	// ruleRequiredIf requires the field if all the field/value pairs match, e.g. `required_if=Type card`.
+	ruleRequiredIf = "required_if"
+	// ruleRequiredUnless requires the field unless all the field/value pairs match, e.g. `required_unless=Type cash`.
+	ruleRequiredUnless = "required_unless"
+	// ruleExcludedWith requires the field to be empty if any of the fields are set, e.g. `excluded_with=Token`.
+	ruleExcludedWith = "excluded_with"
+	// ruleRequiredWhen requires the field if the registered Condition holds, e.g. `required_when=is_card`.
+	ruleRequiredWhen = "required_when"
+	// ruleExcludedWhen requires the field to be empty if the registered Condition holds.
+	ruleExcludedWhen = "excluded_when"
+)
+
+// Condition reports if a conditional rule (required_when, excluded_when) applies.  parent is the struct containing
+// the validated field.
+type Condition func(ctx context.Context, parent any) bool
+
+// RegisterCondition adds the named Condition to DefaultValidator, see Validator.RegisterCondition.
+func RegisterCondition(name string, cond Condition) {
+	DefaultValidator.RegisterCondition(name, cond)
+}
+
+// RegisterCondition adds the named Condition, referenced by the `required_when` and `excluded_when` rules.  Use for
+// conditions that can not be expressed with required_if/required_unless/excluded_with:
+//
+//	validation.RegisterCondition("is_card", func(_ context.Context, parent any) bool {
+//		p, ok := parent.(Payment)
+//
+//		return ok && p.Type == "card" && p.Amount > 0
+//	})
+//
+//	type Payment struct {
+//		Type       string `json:"type" validate:"oneof=card cash"`
+//		Amount     int    `json:"amount"`
+//		CardNumber string `json:"card_number" validate:"required_when=is_card,len=16"`
+//	}
+//
+// Conditions should be registered at startup, before validation.
+func (v *Validator) RegisterCondition(name string, cond Condition) *Validator {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.conditions[name] = cond
+
+	return v
+}
+
+func isConditional(name string) bool {
+	switch name {
+	case ruleRequiredIf, ruleRequiredUnless, ruleExcludedWith, ruleRequiredWhen, ruleExcludedWhen:
+		return true
+	}
+
+	return false
+}
+
+// checkConditionParam validates the parameters of conditional rules.
+func (v *Validator) checkConditionParam(name, param string) error {
+	fields := strings.Fields(param)
+
+	switch name {
+	case ruleRequiredIf, ruleRequiredUnless:
+		if len(fields) == 0 || len(fields)%2 != 0 {
+			return fmt.Errorf("%w: %s=%q requires field value pairs", ErrInvalidRule, name, param)
+		}
+	case ruleExcludedWith:
+		if len(fields) == 0 {
+			return fmt.Errorf("%w: %s requires fields", ErrInvalidRule, name)
+		}
+	case ruleRequiredWhen, ruleExcludedWhen:
+		v.mu.RLock()
+		_, ok := v.conditions[param]
+		v.mu.RUnlock()
+
+		if !ok {
+			return fmt.Errorf("%w: %s unknown condition %q", ErrInvalidRule, name, param)
+		}
+	}
+
+	return nil
+}
+
+// checkFields verifies the fields referenced by conditional and cross field rules exist in t.
+func checkFields(t reflect.Type, set *ruleSet) error {
+	for ; set != nil; set = set.dive {
+		for _, r := range set.rules {
+			fields := strings.Fields(r.param)
+
+			switch r.name {
+			case ruleRequiredIf, ruleRequiredUnless:
+				for i := 0; i < len(fields); i += 2 {
+					if _, ok := t.FieldByName(fields[i]); !ok {
+						return fmt.Errorf("%w: %s references unknown field %q", ErrInvalidRule, r.name, fields[i])
+					}
+				}
+			case ruleExcludedWith:
+				for _, f := range fields {
+					if _, ok := t.FieldByName(f); !ok {
+						return fmt.Errorf("%w: %s references unknown field %q", ErrInvalidRule, r.name, f)
+					}
+				}
+			default:
+				if !isCrossField(r.name) {
+					continue
+				}
+
+				for _, f := range fields {
+					if _, ok := t.FieldByName(f); !ok {
+						return fmt.Errorf("%w: %s references unknown field %q", ErrInvalidRule, r.name, f)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// condition reports if the conditional rule applies to the field, given the struct containing the field.
+func (v *Validator) condition(ctx context.Context, parent reflect.Value, r rule) (bool, error) {
+	if parent.Kind() != reflect.Struct {
+		return false, fmt.Errorf("%w: %s requires a struct field", ErrInvalidRule, r.name)
+	}
+
+	fields := strings.Fields(r.param)
+
+	switch r.name {
+	case ruleRequiredIf:
+		return fieldsMatch(parent, fields), nil
+	case ruleRequiredUnless:
+		return !fieldsMatch(parent, fields), nil
+	case ruleExcludedWith:
+		for _, f := range fields {
+			if !parent.FieldByName(f).IsZero() {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	v.mu.RLock()
+	cond := v.conditions[r.param]
+	v.mu.RUnlock()
+
+	return cond(ctx, parent.Interface()), nil
+}
+
+// fieldsMatch reports if all the field/value pairs match the formatted field values, nil pointers match "".
+func fieldsMatch(parent reflect.Value, pairs []string) bool {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		f := reflect.Indirect(parent.FieldByName(pairs[i]))
+
+		got := ""
+		if f.IsValid() {
+			got = fmt.Sprint(f.Interface())
+		}
+
+		if got != pairs[i+1] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,106 @@
+package validation_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/validation"
+)
+
+type Payment struct {
+	Type       string  `json:"type" validate:"oneof=card cash invoice"`
+	Amount     int     `json:"amount"`
+	CardNumber string  `json:"card_number" validate:"required_if=Type card,len=16"`
+	Reference  *string `json:"reference" validate:"required_unless=Type cash"`
+	Change     int     `json:"change" validate:"excluded_with=CardNumber"`
+	PIN        string  `json:"pin" validate:"required_when=large_card,len=4"`
+	Note       string  `json:"note" validate:"excluded_when=large_card"`
+}
+
+func TestConditional(t *testing.T) {
+	v := validation.NewValidator().RegisterCondition("large_card", func(_ context.Context, parent any) bool {
+		p, ok := parent.(Payment)
+
+		return ok && p.Type == "card" && p.Amount > 100
+	})
+
+	card := "4111111111111111"
+
+	tests := []struct {
+		name string
+		p    Payment
+		want map[string][]string
+	}{
+		{"cash", Payment{Type: "cash", Change: 5}, nil},
+		{"card", Payment{Type: "card", CardNumber: card, Reference: strPtr("")}, nil},
+		{"card required", Payment{Type: "card", Reference: strPtr("r")}, map[string][]string{
+			"card_number": {"is required"},
+		}},
+		{"card rules", Payment{Type: "card", CardNumber: "1", Reference: strPtr("r")}, map[string][]string{
+			"card_number": {"must be exactly 16 characters"},
+		}},
+		{"required unless", Payment{Type: "invoice"}, map[string][]string{"reference": {"is required"}}},
+		{
+			"excluded with",
+			Payment{Type: "card", CardNumber: card, Reference: strPtr("r"), Change: 1},
+			map[string][]string{"change": {"must be empty"}},
+		},
+		{
+			"condition",
+			Payment{Type: "card", Amount: 500, CardNumber: card, Reference: strPtr("r"), Note: "x"},
+			map[string][]string{"pin": {"is required"}, "note": {"must be empty"}},
+		},
+		{
+			"condition rules",
+			Payment{Type: "card", Amount: 500, CardNumber: card, Reference: strPtr("r"), PIN: "1"},
+			map[string][]string{"pin": {"must be exactly 4 characters"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Struct(tt.p)
+			if tt.want == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var ee *validation.Errors
+
+			assert.ErrorAs(t, err, &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+		})
+	}
+}
+
+func TestConditionalInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"pairs", struct {
+			A string `validate:"required_if=B"`
+			B string
+		}{}},
+		{"unknown field", struct {
+			A string `validate:"required_unless=C x"`
+		}{}},
+		{"no fields", struct {
+			A string `validate:"excluded_with="`
+		}{}},
+		{"unknown condition", struct {
+			A string `validate:"required_when=nope"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, validation.Struct(tt.v), validation.ErrInvalidRule)
+		})
+	}
+
+	assert.ErrorIs(t, validation.Var(context.Background(), "", "required_if=A b"), validation.ErrInvalidRule)
+}
@@ -0,0 +1,139 @@
+package validation
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Cross field rules compare the field with a sibling field of the struct, referenced by Go field name, e.g.
+// `validate:"eqfield=Password"` or `validate:"gtfield=StartDate"`.  Numbers, strings and time.Time values are
+// supported.
+var crossFieldRules = map[string]struct {
+	accept  func(c int) bool
+	message string
+}{
+	"eqfield":  {func(c int) bool { return c == 0 }, "must match %s"},
+	"nefield":  {func(c int) bool { return c != 0 }, "must not match %s"},
+	"gtfield":  {func(c int) bool { return c > 0 }, "must be greater than %s"},
+	"gtefield": {func(c int) bool { return c >= 0 }, "must be greater than or equal to %s"},
+	"ltfield":  {func(c int) bool { return c < 0 }, "must be less than %s"},
+	"ltefield": {func(c int) bool { return c <= 0 }, "must be less than or equal to %s"},
+}
+
+func isCrossField(name string) bool {
+	_, ok := crossFieldRules[name]
+
+	return ok
+}
+
+// Invariants is implemented by structs with rules spanning multiple fields.  Invariants is called after the field
+// rules of the struct, failures are added to ee keyed by the path relative to the struct (prefixed with the path of
+// the struct when nested):
+//
+//	func (b Booking) Invariants(_ context.Context, ee *validation.Errors) error {
+//		if b.Guests > b.Room.Capacity {
+//			ee.Add("guests", "exceeds the room capacity")
+//		}
+//
+//		return nil
+//	}
+//
+// A non nil error aborts validation, as with CtxFunc.
+type Invariants interface {
+	Invariants(ctx context.Context, ee *Errors) error
+}
+
+var invariantsType = reflect.TypeOf((*Invariants)(nil)).Elem()
+
+// checkInvariants applies the Invariants of val, if implemented by the value or a pointer to it.
+func checkInvariants(ctx context.Context, val reflect.Value, prefix string, ee *Errors) error {
+	var inv Invariants
+
+	switch {
+	case val.Type().Implements(invariantsType):
+		inv, _ = val.Interface().(Invariants)
+	case val.CanAddr() && reflect.PointerTo(val.Type()).Implements(invariantsType):
+		inv, _ = val.Addr().Interface().(Invariants)
+	case reflect.PointerTo(val.Type()).Implements(invariantsType):
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		inv, _ = ptr.Interface().(Invariants)
+	default:
+		return nil
+	}
+
+	var local Errors
+
+	if err := inv.Invariants(ctx, &local); err != nil {
+		return fmt.Errorf("%s: %w", joinPath(prefix, "invariants"), err)
+	}
+
+	for key, msgs := range local {
+		path := joinPath(prefix, key)
+		if key == "" {
+			path = prefix
+		}
+
+		for _, msg := range msgs {
+			ee.Add(path, msg)
+		}
+	}
+
+	return nil
+}
+
+// compareField applies the cross field rule r to fv.
+func (v *Validator) compareField(parent, fv reflect.Value, r rule) error {
+	if parent.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %s requires a struct field", ErrInvalidRule, r.name)
+	}
+
+	sf, _ := parent.Type().FieldByName(r.param)
+	other := reflect.Indirect(parent.FieldByName(r.param))
+	fv = reflect.Indirect(fv)
+
+	if !other.IsValid() || !fv.IsValid() {
+		// Nil pointers are not compared
+		return nil
+	}
+
+	c, err := compareValues(fv, other)
+	if err != nil {
+		return fmt.Errorf("%s=%s: %w", r.name, r.param, err)
+	}
+
+	def := crossFieldRules[r.name]
+	if def.accept(c) {
+		return nil
+	}
+
+	name, _ := v.fieldName(sf)
+
+	return RuleError{Rule: r.name, Param: r.param, Message: fmt.Sprintf(def.message, name)}
+}
+
+// compareValues returns -1, 0 or 1 if a is less than, equal to, or greater than b.
+func compareValues(a, b reflect.Value) (int, error) {
+	if a.Type() == reflect.TypeOf(time.Time{}) && b.Type() == a.Type() {
+		at, _ := a.Interface().(time.Time)
+		bt, _ := b.Interface().(time.Time)
+
+		return at.Compare(bt), nil
+	}
+
+	if a.Kind() == reflect.String && b.Kind() == reflect.String {
+		return cmp.Compare(a.String(), b.String()), nil
+	}
+
+	af, aUnit, aok := measure(a)
+	bf, bUnit, bok := measure(b)
+
+	if !aok || !bok || aUnit != "" || bUnit != "" {
+		return 0, fmt.Errorf("%w: can not compare %s with %s", ErrInvalidRule, a.Type(), b.Type())
+	}
+
+	return cmp.Compare(af, bf), nil
+}
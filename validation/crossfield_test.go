@@ -0,0 +1,136 @@
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/validation"
+)
+
+type Room struct {
+	Capacity int `json:"capacity" validate:"min=1"`
+}
+
+type Booking struct {
+	Password string     `json:"password" validate:"required"`
+	Confirm  string     `json:"confirm" validate:"eqfield=Password"`
+	Start    time.Time  `json:"start"`
+	End      time.Time  `json:"end" validate:"gtfield=Start"`
+	Checkout *time.Time `json:"checkout" validate:"gtefield=End"`
+	Guests   int        `json:"guests" validate:"ltefield=MaxGuests"`
+	Children int        `json:"children" validate:"ltfield=Guests"`
+	Previous string     `json:"previous" validate:"omitempty,nefield=Password"`
+	Room     Room       `json:"room"`
+	Rooms    []Room     `json:"rooms"`
+
+	MaxGuests int `json:"max_guests"`
+}
+
+func (b Booking) Invariants(_ context.Context, ee *validation.Errors) error {
+	if b.Guests > b.Room.Capacity*2 {
+		ee.Add("guests", "exceeds the room capacity")
+	}
+
+	return nil
+}
+
+func (r *Room) Invariants(_ context.Context, ee *validation.Errors) error {
+	if r.Capacity > 10 {
+		ee.Add("capacity", "rooms are limited to 10 guests")
+	}
+
+	return nil
+}
+
+func TestCrossField(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid := func() Booking {
+		return Booking{
+			Password: "secret", Confirm: "secret",
+			Start: start, End: start.Add(time.Hour),
+			Guests: 2, Children: 1, MaxGuests: 4,
+			Room: Room{Capacity: 2},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(b *Booking)
+		want   map[string][]string
+	}{
+		{"valid", func(*Booking) {}, nil},
+		{"eqfield", func(b *Booking) { b.Confirm = "secrets" }, map[string][]string{"confirm": {"must match password"}}},
+		{"gtfield time", func(b *Booking) { b.End = start }, map[string][]string{"end": {"must be greater than start"}}},
+		{"gtefield pointer", func(b *Booking) { b.Checkout = &start }, map[string][]string{
+			"checkout": {"must be greater than or equal to end"},
+		}},
+		{"ltefield", func(b *Booking) { b.MaxGuests = 1 }, map[string][]string{
+			"guests": {"must be less than or equal to max_guests"},
+		}},
+		{"ltfield", func(b *Booking) { b.Children = 2 }, map[string][]string{"children": {"must be less than guests"}}},
+		{"nefield", func(b *Booking) { b.Previous = "secret" }, map[string][]string{"previous": {"must not match password"}}},
+		{"invariants", func(b *Booking) { b.Guests, b.MaxGuests = 5, 5; b.Children = 0 }, map[string][]string{
+			"guests": {"exceeds the room capacity"},
+		}},
+		{"nested invariants", func(b *Booking) { b.Room.Capacity = 11; b.Rooms = []Room{{Capacity: 12}} }, map[string][]string{
+			"room.capacity":     {"rooms are limited to 10 guests"},
+			"rooms[0].capacity": {"rooms are limited to 10 guests"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := valid()
+			tt.modify(&b)
+
+			err := validation.Struct(b)
+			if tt.want == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var ee *validation.Errors
+
+			require.ErrorAs(t, err, &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+		})
+	}
+}
+
+type failingInvariants struct{}
+
+func (failingInvariants) Invariants(context.Context, *validation.Errors) error {
+	return errors.New("lookup failed")
+}
+
+func TestCrossFieldInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"missing field", struct {
+			A string `validate:"eqfield="`
+		}{}},
+		{"unknown field", struct {
+			A string `validate:"eqfield=B"`
+		}{}},
+		{"incomparable", struct {
+			A string `validate:"gtfield=B"`
+			B int
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, validation.Struct(tt.v), validation.ErrInvalidRule)
+		})
+	}
+
+	assert.EqualError(t, validation.Struct(failingInvariants{}), "invariants: lookup failed")
+}
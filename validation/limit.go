@@ -0,0 +1,88 @@
+package validation
+
+import "errors"
+
+// errStop aborts validation once the first failure is found in fail fast mode.
+var errStop = errors.New("validation stopped")
+
+// WithFailFast stops validation at the first failure, for cheap rejection of invalid payloads.  The Errors returned
+// contain a single failure.  Intended for use at setup, before validation.
+func (v *Validator) WithFailFast(failFast bool) *Validator {
+	v.failFast = failFast
+
+	return v
+}
+
+// WithMaxErrors caps the failures reported by StructCtx at n messages (ordered by path), 0 reports all.  Validation
+// stops once more than n failures are found, bounding the work spent on adversarial payloads, and returns a
+// LimitError.  Intended for use at setup, before validation.
+func (v *Validator) WithMaxErrors(n int) *Validator {
+	v.maxErrors = n
+
+	return v
+}
+
+// LimitError is returned instead of *Errors when failures were omitted due to WithMaxErrors.  Validation stopped
+// past the cap, so the number of omitted failures is unknown.  errors.As with *Errors matches the reported failures.
+type LimitError struct {
+	Errors *Errors
+}
+
+// Unwrap provides compatibility for Go 1.13 error chains.
+func (e *LimitError) Unwrap() error {
+	return e.Errors
+}
+
+// Error returns the error string of the reported Errors, noting the omitted failures.
+func (e *LimitError) Error() string {
+	return e.Errors.Error() + " (more omitted)"
+}
+
+// stopped reports if validation must stop, at the first failure in fail fast mode or past the max errors.
+func (v *Validator) stopped(ee *Errors) bool {
+	switch {
+	case v.failFast:
+		return len(*ee) > 0
+	case v.maxErrors > 0:
+		return messageCount(ee) > v.maxErrors
+	default:
+		return false
+	}
+}
+
+func messageCount(ee *Errors) int {
+	n := 0
+
+	for _, messages := range *ee {
+		n += len(messages)
+	}
+
+	return n
+}
+
+// limit applies the max errors setting to ee.
+func (v *Validator) limit(ee *Errors) error {
+	if v.maxErrors <= 0 || len(*ee) == 0 {
+		return ee.GetErr()
+	}
+
+	if messageCount(ee) <= v.maxErrors {
+		return ee
+	}
+
+	var (
+		kept  Errors
+		count int
+	)
+
+	for _, key := range ee.Keys() {
+		for _, msg := range (*ee)[key] {
+			if count < v.maxErrors {
+				kept.Add(key, msg)
+				count++
+			}
+		}
+	}
+
+	return &LimitError{Errors: &kept}
+}
@@ -0,0 +1,82 @@
+package validation_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/validation"
+)
+
+type Garbage struct {
+	A     string   `json:"a" validate:"required"`
+	B     string   `json:"b" validate:"required,email"`
+	C     int      `json:"c" validate:"min=1"`
+	Items []Item   `json:"items"`
+	Tags  []string `json:"tags" validate:"dive,len=2"`
+}
+
+func garbage() Garbage {
+	return Garbage{Items: []Item{{}, {}}, Tags: []string{"a", "b"}}
+}
+
+func TestFailFast(t *testing.T) {
+	var ee *validation.Errors
+
+	err := validation.NewValidator().WithFailFast(true).Struct(garbage())
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{"a": {"is required"}}, ee.Fields())
+
+	err = validation.NewValidator().WithFailFast(true).Struct(Garbage{A: "a", B: "b@example.com", C: 1, Items: []Item{{SKU: "x"}, {}}})
+	require.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{"items[0].quantity": {"must be at least 1"}}, ee.Fields())
+
+	assert.NoError(t, validation.NewValidator().WithFailFast(true).Struct(Garbage{A: "a", B: "b@example.com", C: 1}))
+}
+
+func TestMaxErrors(t *testing.T) {
+	err := validation.NewValidator().WithMaxErrors(3).Struct(garbage())
+
+	var limitErr *validation.LimitError
+
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, map[string][]string{
+		"a": {"is required"},
+		"b": {"is required"},
+		"c": {"must be at least 1"},
+	}, limitErr.Errors.Fields())
+	assert.EqualError(t, err, "a: is required; b: is required; c: must be at least 1. (more omitted)")
+
+	var ee *validation.Errors
+
+	require.True(t, errors.As(err, &ee))
+	assert.Len(t, *ee, 3)
+
+	// Within the budget, Errors are returned directly
+	err = validation.NewValidator().WithMaxErrors(9).Struct(garbage())
+	require.False(t, errors.As(err, &limitErr))
+	require.ErrorAs(t, err, &ee)
+	assert.Len(t, *ee, 9)
+}
+
+func TestMaxErrorsStopsEvaluation(t *testing.T) {
+	calls := 0
+
+	v := validation.NewValidator().WithMaxErrors(2).Register("counted", "is counted", func(any, string) bool {
+		calls++
+
+		return false
+	})
+
+	err := v.Struct(struct {
+		Values []string `json:"values" validate:"dive,counted"`
+	}{Values: make([]string, 1000)})
+
+	var limitErr *validation.LimitError
+
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, limitErr.Errors.Fields(), 2)
+}
@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FieldError is the failures of a field, identified by both the dotted path used as the Errors key and the
+// equivalent RFC 6901 JSON Pointer.
+type FieldError struct {
+	Path     string   `json:"path"`
+	Pointer  string   `json:"pointer"`
+	Messages []string `json:"messages"`
+}
+
+// Details returns the failures sorted by path, each with the JSON Pointer of the field, e.g. "items[3].quantity"
+// => "/items/3/quantity".
+func (ee *Errors) Details() []FieldError {
+	fields := ee.Fields()
+	out := make([]FieldError, 0, len(fields))
+
+	for _, key := range ee.Keys() {
+		out = append(out, FieldError{Path: key, Pointer: Pointer(key), Messages: fields[key]})
+	}
+
+	return out
+}
+
+// Pointers returns the messages keyed by JSON Pointer instead of dotted path, see Pointer.
+func (ee *Errors) Pointers() map[string][]string {
+	out := make(map[string][]string, len(*ee))
+
+	for key, messages := range ee.Fields() {
+		p := Pointer(key)
+		out[p] = append(out[p], messages...)
+	}
+
+	return out
+}
+
+// Pointer converts a dotted error path (e.g. `items[3].quantity`, `attributes["color"]`) to an RFC 6901 JSON Pointer
+// (`/items/3/quantity`, `/attributes/color`).  The empty path is the document root, "".  Malformed paths never fail:
+// an unterminated `[` starts a literal key running to the end of the path.
+func Pointer(path string) string {
+	var b strings.Builder
+
+	for path != "" {
+		var token string
+
+		switch {
+		case path[0] == '.':
+			path = path[1:]
+
+			continue
+		case strings.HasPrefix(path, `["`):
+			token, path = quotedKey(path[1:])
+		case path[0] == '[':
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				token, path = path, ""
+
+				break
+			}
+
+			token, path = path[1:end], path[end+1:]
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+
+			token, path = path[:end], path[end:]
+		}
+
+		b.WriteByte('/')
+		b.WriteString(escapePointer(token))
+	}
+
+	return b.String()
+}
+
+// quotedKey consumes a quoted map key followed by `]`, returning the unquoted key and the remainder.
+func quotedKey(s string) (string, string) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			key, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				key = s[1:i]
+			}
+
+			return key, strings.TrimPrefix(s[i+1:], "]")
+		}
+	}
+
+	return s, ""
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
@@ -0,0 +1,72 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/validation"
+)
+
+func TestPointer(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{"name", "/name"},
+		{"shipping.address.zip", "/shipping/address/zip"},
+		{"items[3].quantity", "/items/3/quantity"},
+		{`attributes["color"]`, "/attributes/color"},
+		{`attributes["a.b/c~d"].x`, "/attributes/a.b~1c~0d/x"},
+		{`attributes["say \"hi\""]`, `/attributes/say "hi"`},
+		{"matrix[1][2]", "/matrix/1/2"},
+		{"a[", "/a/["},
+		{"[", "/["},
+		{"a[b", "/a/[b"},
+		{"a]", "/a]"},
+		{"a[]", "/a/"},
+		{`a["`, `/a/"`},
+		{`a["b`, `/a/"b`},
+		{`a["b\`, `/a/"b\`},
+		{"..a..", "/a"},
+		{"a[1][", "/a/1/["},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, validation.Pointer(tt.path))
+		})
+	}
+}
+
+func FuzzPointer(f *testing.F) {
+	for _, seed := range []string{"", "a.b", "items[3].quantity", `attributes["a.b"]`, "a[", `a["b`, "[[]]", `["\"]`} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, path string) {
+		p := validation.Pointer(path)
+		if p != "" && p[0] != '/' {
+			t.Errorf("Pointer(%q) = %q, want a leading /", path, p)
+		}
+	})
+}
+
+func TestErrorsDetails(t *testing.T) {
+	var ee validation.Errors
+
+	ee.Add("items[1].sku", "is required")
+	ee.Add(`attributes["color"]`, "is required")
+	ee.Add("items[1].sku", "is too short")
+
+	assert.Equal(t, []validation.FieldError{
+		{Path: `attributes["color"]`, Pointer: "/attributes/color", Messages: []string{"is required"}},
+		{Path: "items[1].sku", Pointer: "/items/1/sku", Messages: []string{"is required", "is too short"}},
+	}, ee.Details())
+
+	assert.Equal(t, map[string][]string{
+		"/attributes/color": {"is required"},
+		"/items/1/sku":      {"is required", "is too short"},
+	}, ee.Pointers())
+}
@@ -0,0 +1,92 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Func is a custom rule, reporting if value is valid for the rule param.  value is the field value with pointers
+// dereferenced, nil pointers are not validated.
+type Func func(value any, param string) bool
+
+// CtxFunc is a context aware custom rule, e.g. checking uniqueness against a repository.  A non nil error aborts
+// validation and is returned by StructCtx/Var, it is intended for infrastructure failures.
+type CtxFunc func(ctx context.Context, value any, param string) (bool, error)
+
+// Register adds the named rule to DefaultValidator, see Validator.Register.
+func Register(name, message string, fn Func) {
+	DefaultValidator.Register(name, message, fn)
+}
+
+// RegisterCtx adds the named context aware rule to DefaultValidator, see Validator.RegisterCtx.
+func RegisterCtx(name, message string, fn CtxFunc) {
+	DefaultValidator.RegisterCtx(name, message, fn)
+}
+
+// Register adds the named rule, replacing any existing rule of the same name.  message is reported for invalid
+// values.  Rules should be registered at startup, before validation.
+//
+//	validation.Register("iban", "must be a valid IBAN", func(value any, _ string) bool { ... })
+func (v *Validator) Register(name, message string, fn Func) *Validator {
+	return v.RegisterCtx(name, message, func(_ context.Context, value any, param string) (bool, error) {
+		return fn(value, param), nil
+	})
+}
+
+// RegisterCtx adds the named context aware rule, see Register.
+func (v *Validator) RegisterCtx(name, message string, fn CtxFunc) *Validator {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.rules[name] = func(ctx context.Context, val reflect.Value, param string) error {
+		ok, err := fn(ctx, val.Interface(), param)
+		if err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+
+		if !ok {
+			return RuleError{Rule: name, Param: param, Message: message}
+		}
+
+		return nil
+	}
+
+	// Parsed rules reference the previous definitions
+	v.cache.Clear()
+
+	return v
+}
+
+// Var validates a single value with DefaultValidator, see Validator.Var.
+func Var(ctx context.Context, value any, rules string) error {
+	return DefaultValidator.Var(ctx, value, rules)
+}
+
+// Var validates value against rules using the struct tag syntax (e.g. "required,iban").  Returns the RuleError of
+// the first failing rule, nil if valid, or an error for invalid rules and failing context aware rules.
+func (v *Validator) Var(ctx context.Context, value any, rules string) error {
+	parsed, err := v.parseTag(rules)
+	if err != nil {
+		return err
+	}
+
+	var ee Errors
+
+	fv := reflect.ValueOf(value)
+	if !fv.IsValid() {
+		// untyped nil is treated as a nil pointer
+		fv = reflect.ValueOf((*struct{})(nil))
+	}
+
+	if err := v.validateValue(ctx, reflect.Value{}, fv, "", parsed, &ee); err != nil && !errors.Is(err, errStop) {
+		return err
+	}
+
+	if msgs := ee[""]; len(msgs) > 0 {
+		return msgs[0]
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/validation"
+)
+
+type userRepo map[string]bool
+
+func (r userRepo) exists(_ context.Context, name string) (bool, error) {
+	if name == "fail" {
+		return false, errors.New("db down")
+	}
+
+	return r[name], nil
+}
+
+type Signup struct {
+	Username string  `json:"username" validate:"required,unique_username"`
+	IBAN     *string `json:"iban" validate:"iban"`
+	Prefix   string  `json:"prefix" validate:"prefix=ACME"`
+}
+
+func TestRegister(t *testing.T) {
+	repo := userRepo{"taken": true}
+
+	v := validation.NewValidator().
+		Register("iban", "must be a valid IBAN", func(value any, _ string) bool {
+			s, _ := value.(string)
+
+			return len(s) > 4 && s[:2] == strings.ToUpper(s[:2])
+		}).
+		Register("prefix", "must start with the prefix", func(value any, param string) bool {
+			return strings.HasPrefix(value.(string), param)
+		}).
+		RegisterCtx("unique_username", "is already taken", func(ctx context.Context, value any, _ string) (bool, error) {
+			found, err := repo.exists(ctx, value.(string))
+
+			return !found, err
+		})
+
+	assert.NoError(t, v.Struct(Signup{Username: "bob", Prefix: "ACME-1"}))
+
+	err := v.Struct(Signup{Username: "taken", IBAN: strPtr("de"), Prefix: "X"})
+
+	var ee *validation.Errors
+
+	assert.ErrorAs(t, err, &ee)
+	assert.Equal(t, map[string][]string{
+		"username": {"is already taken"},
+		"iban":     {"must be a valid IBAN"},
+		"prefix":   {"must start with the prefix"},
+	}, ee.Fields())
+
+	var re validation.RuleError
+
+	assert.ErrorAs(t, (*ee)["prefix"][0], &re)
+	assert.Equal(t, "ACME", re.Param)
+
+	err = v.StructCtx(context.Background(), Signup{Username: "fail", Prefix: "ACME"})
+	assert.EqualError(t, err, "username: rule unique_username: db down")
+	assert.False(t, errors.As(err, &ee))
+
+	// Unregistered in the default validator
+	assert.ErrorIs(t, validation.Struct(Signup{}), validation.ErrInvalidRule)
+}
+
+func TestVar(t *testing.T) {
+	validation.Register("even", "must be even", func(value any, _ string) bool {
+		return value.(int)%2 == 0
+	})
+
+	ctx := context.Background()
+
+	assert.NoError(t, validation.Var(ctx, 4, "required,even"))
+	assert.EqualError(t, validation.Var(ctx, 3, "required,even"), "must be even")
+	assert.EqualError(t, validation.Var(ctx, 0, "required,even"), "is required")
+	assert.EqualError(t, validation.Var(ctx, "bob", "email"), "must be a valid email address")
+	assert.NoError(t, validation.Var(ctx, "", "omitempty,email"))
+	assert.NoError(t, validation.Var(ctx, nil, "min=3"))
+	assert.EqualError(t, validation.Var(ctx, nil, "required"), "is required")
+	assert.ErrorIs(t, validation.Var(ctx, 1, "bogus"), validation.ErrInvalidRule)
+	assert.ErrorIs(t, validation.Var(ctx, 1, "email"), validation.ErrInvalidRule)
+}
@@ -0,0 +1,266 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// RuleError is the failure of a single validation rule.
+type RuleError struct {
+	// Rule is the name of the failed rule, e.g. "min".
+	Rule string
+	// Param is the rule parameter, e.g. "3" for `min=3`.
+	Param string
+	// Message is the client facing description of the failure.
+	Message string
+	// Key identifies the message template in a Catalog, Rule is used if empty.  Length rules qualify the key by the
+	// measured type, e.g. "min.string" or "max.collection".
+	Key string
+}
+
+func (e RuleError) Error() string {
+	return e.Message
+}
+
+// ruleFunc validates v against param, returning a RuleError on failure, or ErrInvalidRule if the rule does not
+// support the type of v.  Any other error aborts validation.
+type ruleFunc func(ctx context.Context, v reflect.Value, param string) error
+
+// builtin adapts the context free built-in rules.
+func builtin(fn func(v reflect.Value, param string) error) ruleFunc {
+	return func(_ context.Context, v reflect.Value, param string) error {
+		return fn(v, param)
+	}
+}
+
+func builtinRules() map[string]ruleFunc {
+	// Placeholder for rules applied by validateField
+	applied := builtin(func(reflect.Value, string) error { return nil })
+
+	return map[string]ruleFunc{
+		"omitempty":        applied,
+		"required":         builtin(ruleRequired),
+		"min":              builtin(ruleMin),
+		"max":              builtin(ruleMax),
+		"len":              builtin(ruleLen),
+		"email":            builtin(ruleEmail),
+		"url":              builtin(ruleURL),
+		"uuid":             builtin(ruleUUID),
+		"oneof":            builtin(ruleOneOf),
+		"regexp":           builtin(ruleRegexp),
+		ruleRequiredIf:     applied,
+		ruleRequiredUnless: applied,
+		ruleExcludedWith:   applied,
+		ruleRequiredWhen:   applied,
+		ruleExcludedWhen:   applied,
+		"eqfield":          applied,
+		"nefield":          applied,
+		"gtfield":          applied,
+		"gtefield":         applied,
+		"ltfield":          applied,
+		"ltefield":         applied,
+	}
+}
+
+// checkParam validates rule parameters when tags are parsed, so bad tags fail on first use rather than on
+// specific input.
+func checkParam(name, param string) error {
+	switch name {
+	case "min", "max", "len":
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return fmt.Errorf("%w: %s=%q must be a number", ErrInvalidRule, name, param)
+		}
+	case "oneof":
+		if strings.TrimSpace(param) == "" {
+			return fmt.Errorf("%w: oneof requires values", ErrInvalidRule)
+		}
+	case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+		if strings.TrimSpace(param) == "" {
+			return fmt.Errorf("%w: %s requires a field", ErrInvalidRule, name)
+		}
+	case "regexp":
+		if _, err := compileRegexp(param); err != nil {
+			return fmt.Errorf("%w: regexp=%q: %w", ErrInvalidRule, param, err)
+		}
+	}
+
+	return nil
+}
+
+func unsupported(name string, v reflect.Value) error {
+	return fmt.Errorf("%w: %s does not support %s", ErrInvalidRule, name, v.Kind())
+}
+
+func ruleRequired(v reflect.Value, _ string) error {
+	if !v.IsValid() || v.IsZero() {
+		return RuleError{Rule: "required", Message: "is required"}
+	}
+
+	return nil
+}
+
+// measure returns the value compared by min/max/len: rune count for strings, length for collections and the numeric
+// value for numbers.  unit describes the measure for messages.
+func measure(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+
+	return 0, "", false
+}
+
+func compare(name string, v reflect.Value, param string, fails func(got, want float64) bool, format string) error {
+	got, unit, ok := measure(v)
+	if !ok {
+		return unsupported(name, v)
+	}
+
+	want, _ := strconv.ParseFloat(param, 64)
+	if fails(got, want) {
+		verb := "be"
+		if unit == " items" {
+			verb = "contain"
+		}
+
+		return RuleError{
+			Rule:    name,
+			Param:   param,
+			Message: fmt.Sprintf(format, verb, param, unit),
+			Key:     measureKey(name, unit),
+		}
+	}
+
+	return nil
+}
+
+func measureKey(name, unit string) string {
+	switch unit {
+	case " characters":
+		return name + ".string"
+	case " items":
+		return name + ".collection"
+	}
+
+	return name
+}
+
+func ruleMin(v reflect.Value, param string) error {
+	return compare("min", v, param, func(got, want float64) bool { return got < want }, "must %s at least %s%s")
+}
+
+func ruleMax(v reflect.Value, param string) error {
+	return compare("max", v, param, func(got, want float64) bool { return got > want }, "must %s at most %s%s")
+}
+
+func ruleLen(v reflect.Value, param string) error {
+	if _, unit, ok := measure(v); !ok || unit == "" {
+		return unsupported("len", v)
+	}
+
+	return compare("len", v, param, func(got, want float64) bool { return got != want }, "must %s exactly %s%s")
+}
+
+func stringRule(name string, v reflect.Value, valid func(string) bool, message string) error {
+	if v.Kind() != reflect.String {
+		return unsupported(name, v)
+	}
+
+	if !valid(v.String()) {
+		return RuleError{Rule: name, Message: message}
+	}
+
+	return nil
+}
+
+func ruleEmail(v reflect.Value, _ string) error {
+	return stringRule("email", v, func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+
+		return err == nil && addr.Address == s
+	}, "must be a valid email address")
+}
+
+func ruleURL(v reflect.Value, _ string) error {
+	return stringRule("url", v, func(s string) bool {
+		u, err := url.ParseRequestURI(s)
+
+		return err == nil && u.Scheme != "" && u.Host != ""
+	}, "must be a valid URL")
+}
+
+func ruleUUID(v reflect.Value, _ string) error {
+	if v.Type() == reflect.TypeOf(uuid.UUID{}) {
+		return nil
+	}
+
+	return stringRule("uuid", v, func(s string) bool {
+		return uuid.Validate(s) == nil
+	}, "must be a valid UUID")
+}
+
+func ruleOneOf(v reflect.Value, param string) error {
+	var s string
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = fmt.Sprint(v.Interface())
+	default:
+		return unsupported("oneof", v)
+	}
+
+	allowed := strings.Fields(param)
+
+	for _, a := range allowed {
+		if a == s {
+			return nil
+		}
+	}
+
+	return RuleError{Rule: "oneof", Param: param, Message: "must be one of: " + strings.Join(allowed, ", ")}
+}
+
+var regexpCache sync.Map // pattern => *regexp.Regexp
+
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil //nolint:forcetypeassert
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("compile: %w", err)
+	}
+
+	regexpCache.Store(pattern, re)
+
+	return re, nil
+}
+
+func ruleRegexp(v reflect.Value, param string) error {
+	re, err := compileRegexp(param)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRule, err)
+	}
+
+	return stringRule("regexp", v, re.MatchString, "has an invalid format")
+}
@@ -0,0 +1,730 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSchema is returned when a schema can not be compiled.
+var ErrInvalidSchema = errors.New("invalid schema")
+
+// Schema is a compiled JSON Schema, supporting the subset used by OpenAPI 3 request schemas:
+//
+//   - type (including type arrays and OpenAPI `nullable`), enum
+//   - properties, required, additionalProperties
+//   - items, minItems, maxItems, uniqueItems
+//   - minLength, maxLength, pattern, format (email, uri, uuid, date, date-time)
+//   - minimum, maximum, exclusiveMinimum, exclusiveMaximum (boolean and numeric forms)
+//   - allOf, anyOf, oneOf and local $ref
+//
+// Unknown keywords and formats are ignored.  Failures are reported as Errors using the same paths and messages as
+// Struct, RuleError.Key matches the equivalent tag rule so a Catalog applies to both.
+type Schema struct {
+	types      []string
+	nullable   bool
+	never      bool
+	enum       []any
+	properties map[string]*Schema
+	required   []string
+	additional *Schema
+	items      *Schema
+	minItems   *float64
+	maxItems   *float64
+	unique     bool
+	minLength  *float64
+	maxLength  *float64
+	pattern    *regexp.Regexp
+	format     string
+	minimum    *float64
+	maximum    *float64
+	exclMin    *float64
+	exclMax    *float64
+	allOf      []*Schema
+	anyOf      []*Schema
+	oneOf      []*Schema
+}
+
+// CompileSchema compiles the JSON Schema document data, $ref is resolved within the document.
+func CompileSchema(data []byte) (*Schema, error) {
+	return CompileSchemaRef(data, "#")
+}
+
+// CompileSchemaRef compiles the schema at ref (a JSON pointer fragment) within the document data, e.g. an OpenAPI
+// document and "#/components/schemas/User".  $ref is resolved within the document.
+func CompileSchemaRef(data []byte, ref string) (*Schema, error) {
+	var root any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	c := compiler{root: root, refs: map[string]*Schema{}}
+
+	return c.ref(ref)
+}
+
+type compiler struct {
+	root any
+	refs map[string]*Schema
+}
+
+func (c *compiler) ref(ref string) (*Schema, error) {
+	if s, ok := c.refs[ref]; ok {
+		return s, nil
+	}
+
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("%w: only local references are supported: %q", ErrInvalidSchema, ref)
+	}
+
+	node, err := resolvePointer(c.root, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %w", ErrInvalidSchema, ref, err)
+	}
+
+	// Registered before compiling to support recursive schemas
+	s := &Schema{}
+	c.refs[ref] = s
+
+	if err := c.compileInto(s, node, ref); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func resolvePointer(node any, pointer string) (any, error) {
+	if pointer == "" {
+		return node, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("invalid pointer")
+	}
+
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token, _ = url.PathUnescape(token)
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		switch n := node.(type) {
+		case map[string]any:
+			next, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", token)
+			}
+
+			node = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, fmt.Errorf("invalid index %q", token)
+			}
+
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("%q not found", token)
+		}
+	}
+
+	return node, nil
+}
+
+func (c *compiler) compile(node any, path string) (*Schema, error) {
+	s := &Schema{}
+
+	return s, c.compileInto(s, node, path)
+}
+
+func (c *compiler) compileList(node any, path string) ([]*Schema, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	list, ok := node.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be an array", ErrInvalidSchema, path)
+	}
+
+	out := make([]*Schema, len(list))
+
+	for i, item := range list {
+		s, err := c.compile(item, fmt.Sprintf("%s/%d", path, i))
+		if err != nil {
+			return nil, err
+		}
+
+		out[i] = s
+	}
+
+	return out, nil
+}
+
+//nolint:cyclop,funlen // keyword mapping
+func (c *compiler) compileInto(s *Schema, node any, path string) error {
+	if b, ok := node.(bool); ok {
+		s.never = !b
+
+		return nil
+	}
+
+	m, ok := node.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: %s must be an object", ErrInvalidSchema, path)
+	}
+
+	if ref, ok := m["$ref"].(string); ok {
+		target, err := c.ref(ref)
+		if err != nil {
+			return err
+		}
+
+		s.allOf = []*Schema{target}
+
+		return nil
+	}
+
+	switch t := m["type"].(type) {
+	case string:
+		s.types = []string{t}
+	case []any:
+		for _, v := range t {
+			if name, ok := v.(string); ok {
+				s.types = append(s.types, name)
+			}
+		}
+	}
+
+	s.nullable, _ = m["nullable"].(bool)
+	s.unique, _ = m["uniqueItems"].(bool)
+	s.format, _ = m["format"].(string)
+
+	if enum, ok := m["enum"].([]any); ok {
+		s.enum = enum
+	}
+
+	s.minItems = number(m["minItems"])
+	s.maxItems = number(m["maxItems"])
+	s.minLength = number(m["minLength"])
+	s.maxLength = number(m["maxLength"])
+	s.minimum = number(m["minimum"])
+	s.maximum = number(m["maximum"])
+
+	// OpenAPI 3.0 uses boolean exclusive flags, JSON Schema 2019+ numeric bounds
+	if excl, ok := m["exclusiveMinimum"].(bool); ok && excl {
+		s.exclMin, s.minimum = s.minimum, nil
+	} else {
+		s.exclMin = number(m["exclusiveMinimum"])
+	}
+
+	if excl, ok := m["exclusiveMaximum"].(bool); ok && excl {
+		s.exclMax, s.maximum = s.maximum, nil
+	} else {
+		s.exclMax = number(m["exclusiveMaximum"])
+	}
+
+	if pattern, ok := m["pattern"].(string); ok {
+		re, err := compileRegexp(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: %s/pattern: %w", ErrInvalidSchema, path, err)
+		}
+
+		s.pattern = re
+	}
+
+	if required, ok := m["required"].([]any); ok {
+		for _, v := range required {
+			if name, ok := v.(string); ok {
+				s.required = append(s.required, name)
+			}
+		}
+	}
+
+	if props, ok := m["properties"].(map[string]any); ok {
+		s.properties = make(map[string]*Schema, len(props))
+
+		for name, prop := range props {
+			ps, err := c.compile(prop, path+"/properties/"+name)
+			if err != nil {
+				return err
+			}
+
+			s.properties[name] = ps
+		}
+	}
+
+	var err error
+
+	if additional, ok := m["additionalProperties"]; ok {
+		if s.additional, err = c.compile(additional, path+"/additionalProperties"); err != nil {
+			return err
+		}
+	}
+
+	if items, ok := m["items"]; ok {
+		if s.items, err = c.compile(items, path+"/items"); err != nil {
+			return err
+		}
+	}
+
+	if s.allOf, err = c.compileList(m["allOf"], path+"/allOf"); err != nil {
+		return err
+	}
+
+	if s.anyOf, err = c.compileList(m["anyOf"], path+"/anyOf"); err != nil {
+		return err
+	}
+
+	if s.oneOf, err = c.compileList(m["oneOf"], path+"/oneOf"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func number(v any) *float64 {
+	f, ok := v.(float64)
+	if !ok {
+		return nil
+	}
+
+	return &f
+}
+
+// Validate decodes the JSON document data and validates it, see ValidateValue.  Malformed JSON returns the decoding
+// error.
+func (s *Schema) Validate(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return s.ValidateValue(v)
+}
+
+// ValidateValue validates a decoded JSON value, as produced by encoding/json decoding into any.  Returns *Errors if
+// the value is invalid, failures of the document root are reported with an empty path.
+func (s *Schema) ValidateValue(v any) error {
+	var ee Errors
+
+	s.validate(v, "", &ee)
+
+	return ee.GetErr()
+}
+
+// ValidateValues validates query or form parameters against an object schema.  Values are converted to the type of
+// the property schema, array properties receive every value, other properties the first.
+func (s *Schema) ValidateValues(values url.Values) error {
+	obj := make(map[string]any, len(values))
+
+	for name, vv := range values {
+		if len(vv) == 0 {
+			continue
+		}
+
+		prop := s.property(name)
+
+		if prop != nil && prop.hasType("array") {
+			items := make([]any, len(vv))
+			for i, v := range vv {
+				items[i] = prop.items.coerce(v)
+			}
+
+			obj[name] = items
+
+			continue
+		}
+
+		obj[name] = prop.coerce(vv[0])
+	}
+
+	return s.ValidateValue(obj)
+}
+
+func (s *Schema) property(name string) *Schema {
+	if s == nil {
+		return nil
+	}
+
+	if p, ok := s.properties[name]; ok {
+		return p
+	}
+
+	for _, sub := range s.allOf {
+		if p := sub.property(name); p != nil {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) hasType(name string) bool {
+	if s == nil {
+		return false
+	}
+
+	for _, t := range s.types {
+		if t == name {
+			return true
+		}
+	}
+
+	for _, sub := range s.allOf {
+		if sub.hasType(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// coerce converts a parameter to the schema type, values that do not convert are kept as strings.
+func (s *Schema) coerce(v string) any {
+	switch {
+	case s.hasType("integer"), s.hasType("number"):
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return json.Number(v)
+		}
+	case s.hasType("boolean"):
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+
+	return v
+}
+
+func schemaError(ee *Errors, path, rule, key, param, message string) {
+	ee.Add(path, RuleError{Rule: rule, Param: param, Message: message, Key: key})
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+//nolint:cyclop // keyword dispatch
+func (s *Schema) validate(v any, path string, ee *Errors) {
+	if s.never {
+		schemaError(ee, path, "false", "excluded", "", "is not allowed")
+
+		return
+	}
+
+	if v == nil && (s.nullable || s.hasType("null")) {
+		return
+	}
+
+	if !s.validateType(v, path, ee) {
+		return
+	}
+
+	if len(s.enum) > 0 && !s.inEnum(v) {
+		values := make([]string, len(s.enum))
+		for i, e := range s.enum {
+			values[i] = fmt.Sprint(e)
+		}
+
+		schemaError(ee, path, "enum", "oneof", strings.Join(values, " "),
+			"must be one of: "+strings.Join(values, ", "))
+	}
+
+	switch t := v.(type) {
+	case string:
+		s.validateString(t, path, ee)
+	case json.Number, float64:
+		f, _ := toFloat(t)
+		s.validateNumber(f, path, ee)
+	case []any:
+		s.validateArray(t, path, ee)
+	case map[string]any:
+		s.validateObject(t, path, ee)
+	}
+
+	for _, sub := range s.allOf {
+		sub.validate(v, path, ee)
+	}
+
+	if len(s.anyOf) > 0 && s.matches(v, s.anyOf) == 0 {
+		schemaError(ee, path, "anyOf", "anyOf", "", "must match at least one schema")
+	}
+
+	if len(s.oneOf) > 0 && s.matches(v, s.oneOf) != 1 {
+		schemaError(ee, path, "oneOf", "oneOf", "", "must match exactly one schema")
+	}
+}
+
+func (s *Schema) matches(v any, schemas []*Schema) int {
+	n := 0
+
+	for _, sub := range schemas {
+		var subErrors Errors
+
+		sub.validate(v, "", &subErrors)
+
+		if len(subErrors) == 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+func jsonType(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number, float64:
+		f, _ := toFloat(t)
+		if f == float64(int64(f)) {
+			return "integer"
+		}
+
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+
+	return ""
+}
+
+func (s *Schema) validateType(v any, path string, ee *Errors) bool {
+	if len(s.types) == 0 {
+		return true
+	}
+
+	got := jsonType(v)
+
+	for _, t := range s.types {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+
+	message := "must be one of types: " + strings.Join(s.types, ", ")
+	if len(s.types) == 1 {
+		article := "a "
+		if strings.ContainsRune("aeiou", rune(s.types[0][0])) {
+			article = "an "
+		}
+
+		message = "must be " + article + s.types[0]
+	}
+
+	schemaError(ee, path, "type", "type", strings.Join(s.types, " "), message)
+
+	return false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case json.Number:
+		f, err := t.Float64()
+
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// normalize converts numbers to float64, so decoded values compare with enum values.
+func normalize(v any) any {
+	if f, ok := toFloat(v); ok {
+		return f
+	}
+
+	return v
+}
+
+func (s *Schema) inEnum(v any) bool {
+	for _, e := range s.enum {
+		if reflect.DeepEqual(normalize(e), normalize(v)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Schema) validateString(v, path string, ee *Errors) {
+	n := float64(utf8.RuneCountInString(v))
+
+	if s.minLength != nil && n < *s.minLength {
+		schemaError(ee, path, "minLength", "min.string", formatNumber(*s.minLength),
+			"must be at least "+formatNumber(*s.minLength)+" characters")
+	}
+
+	if s.maxLength != nil && n > *s.maxLength {
+		schemaError(ee, path, "maxLength", "max.string", formatNumber(*s.maxLength),
+			"must be at most "+formatNumber(*s.maxLength)+" characters")
+	}
+
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		schemaError(ee, path, "pattern", "regexp", s.pattern.String(), "has an invalid format")
+	}
+
+	s.validateFormat(v, path, ee)
+}
+
+func (s *Schema) validateFormat(v, path string, ee *Errors) {
+	var (
+		valid   bool
+		key     string
+		message string
+	)
+
+	switch s.format {
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		valid, key, message = err == nil && addr.Address == v, "email", "must be a valid email address"
+	case "uri", "url":
+		u, err := url.ParseRequestURI(v)
+		valid, key, message = err == nil && u.Scheme != "" && u.Host != "", "url", "must be a valid URL"
+	case "uuid":
+		valid, key, message = uuid.Validate(v) == nil, "uuid", "must be a valid UUID"
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		valid, key, message = err == nil, "date", "must be a valid date"
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		valid, key, message = err == nil, "datetime", "must be a valid date-time"
+	default:
+		return
+	}
+
+	if !valid {
+		schemaError(ee, path, "format", key, s.format, message)
+	}
+}
+
+func (s *Schema) validateNumber(v float64, path string, ee *Errors) {
+	if s.minimum != nil && v < *s.minimum {
+		schemaError(ee, path, "minimum", "min", formatNumber(*s.minimum), "must be at least "+formatNumber(*s.minimum))
+	}
+
+	if s.maximum != nil && v > *s.maximum {
+		schemaError(ee, path, "maximum", "max", formatNumber(*s.maximum), "must be at most "+formatNumber(*s.maximum))
+	}
+
+	if s.exclMin != nil && v <= *s.exclMin {
+		schemaError(ee, path, "exclusiveMinimum", "gt", formatNumber(*s.exclMin),
+			"must be greater than "+formatNumber(*s.exclMin))
+	}
+
+	if s.exclMax != nil && v >= *s.exclMax {
+		schemaError(ee, path, "exclusiveMaximum", "lt", formatNumber(*s.exclMax),
+			"must be less than "+formatNumber(*s.exclMax))
+	}
+}
+
+func (s *Schema) validateArray(v []any, path string, ee *Errors) {
+	n := float64(len(v))
+
+	if s.minItems != nil && n < *s.minItems {
+		schemaError(ee, path, "minItems", "min.collection", formatNumber(*s.minItems),
+			"must contain at least "+formatNumber(*s.minItems)+" items")
+	}
+
+	if s.maxItems != nil && n > *s.maxItems {
+		schemaError(ee, path, "maxItems", "max.collection", formatNumber(*s.maxItems),
+			"must contain at most "+formatNumber(*s.maxItems)+" items")
+	}
+
+	if s.unique && !unique(v) {
+		schemaError(ee, path, "uniqueItems", "unique", "", "must contain unique items")
+	}
+
+	if s.items != nil {
+		for i, item := range v {
+			s.items.validate(item, fmt.Sprintf("%s[%d]", path, i), ee)
+		}
+	}
+}
+
+func unique(v []any) bool {
+	for i := range v {
+		for j := i + 1; j < len(v); j++ {
+			if reflect.DeepEqual(normalize(v[i]), normalize(v[j])) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// propertyPath appends the payload key name to path, quoted (e.g. attributes["a.b"]) unless it is an identifier, so
+// keys containing separators keep a path that Pointer parses back to the key.
+func propertyPath(path, name string) string {
+	if !isIdentifier(name) {
+		return path + "[" + strconv.Quote(name) + "]"
+	}
+
+	return joinPath(path, name)
+}
+
+// isIdentifier reports whether name is non-empty and only letters, digits, '_' and '-'.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, r := range name {
+		if r != '_' && r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Schema) validateObject(v map[string]any, path string, ee *Errors) {
+	for _, name := range s.required {
+		if _, ok := v[name]; !ok {
+			schemaError(ee, propertyPath(path, name), "required", "required", "", "is required")
+		}
+	}
+
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if prop, ok := s.properties[name]; ok {
+			prop.validate(v[name], propertyPath(path, name), ee)
+		} else if s.additional != nil {
+			if s.additional.never {
+				schemaError(ee, propertyPath(path, name), "additionalProperties", "excluded", "", "is not allowed")
+			} else {
+				s.additional.validate(v[name], propertyPath(path, name), ee)
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package validation_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/validation"
+)
+
+const openAPIDoc = `{
+  "openapi": "3.0.3",
+  "components": {
+    "schemas": {
+      "Item": {
+        "type": "object",
+        "required": ["sku", "quantity"],
+        "properties": {
+          "sku": {"type": "string", "minLength": 2, "pattern": "^[A-Z0-9-]+$"},
+          "quantity": {"type": "integer", "minimum": 1, "maximum": 99}
+        }
+      },
+      "Order": {
+        "type": "object",
+        "required": ["email", "items"],
+        "additionalProperties": false,
+        "properties": {
+          "id": {"type": "string", "format": "uuid"},
+          "email": {"type": "string", "format": "email"},
+          "status": {"type": "string", "enum": ["new", "paid"]},
+          "note": {"type": "string", "maxLength": 5, "nullable": true},
+          "total": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+          "due": {"type": "string", "format": "date"},
+          "tags": {"type": "array", "maxItems": 2, "uniqueItems": true, "items": {"type": "string"}},
+          "items": {"type": "array", "minItems": 1, "items": {"$ref": "#/components/schemas/Item"}},
+          "attributes": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 3}},
+          "payment": {"oneOf": [
+            {"type": "object", "required": ["card"], "properties": {"card": {"type": "string"}}},
+            {"type": "object", "required": ["iban"], "properties": {"iban": {"type": "string"}}}
+          ]}
+        }
+      }
+    }
+  }
+}`
+
+func TestSchema(t *testing.T) {
+	s, err := validation.CompileSchemaRef([]byte(openAPIDoc), "#/components/schemas/Order")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		body string
+		want map[string][]string
+	}{
+		{"valid", `{"email":"bob@example.com","items":[{"sku":"A-1","quantity":2}],"note":null,"total":1.5}`, nil},
+		{"required", `{}`, map[string][]string{"email": {"is required"}, "items": {"is required"}}},
+		{"root type", `[]`, map[string][]string{"": {"must be an object"}}},
+		{"types", `{"email":1,"items":{},"tags":[1]}`, map[string][]string{
+			"email":   {"must be a string"},
+			"items":   {"must be an array"},
+			"tags[0]": {"must be a string"},
+		}},
+		{
+			"elements", `{"email":"bob@example.com","items":[{"sku":"A-1","quantity":1},{"sku":"a","quantity":1.5}]}`,
+			map[string][]string{
+				"items[1].sku":      {"must be at least 2 characters", "has an invalid format"},
+				"items[1].quantity": {"must be an integer"},
+			},
+		},
+		{"rules", `{"id":"x","email":"bob","status":"old","note":"toolong","total":0,"due":"2024-13-01",` +
+			`"tags":["a","a","b"],"items":[],"extra":true,"attributes":{"color":"blue"}}`, map[string][]string{
+			"id":               {"must be a valid UUID"},
+			"email":            {"must be a valid email address"},
+			"status":           {"must be one of: new, paid"},
+			"note":             {"must be at most 5 characters"},
+			"total":            {"must be greater than 0"},
+			"due":              {"must be a valid date"},
+			"tags":             {"must contain at most 2 items", "must contain unique items"},
+			"items":            {"must contain at least 1 items"},
+			"extra":            {"is not allowed"},
+			`attributes.color`: {"must be at most 3 characters"},
+		}},
+		{
+			"quoted keys", `{"email":"bob@example.com","items":[{"sku":"AB","quantity":1}],` +
+				`"attributes":{"a.b":"long","c[0]":"long","":"long","q\"":"long"},"x]y":1}`,
+			map[string][]string{
+				`attributes[""]`:     {"must be at most 3 characters"},
+				`attributes["a.b"]`:  {"must be at most 3 characters"},
+				`attributes["c[0]"]`: {"must be at most 3 characters"},
+				`attributes["q\""]`:  {"must be at most 3 characters"},
+				`["x]y"]`:            {"is not allowed"},
+			},
+		},
+		{
+			"one of", `{"email":"bob@example.com","items":[{"sku":"AB","quantity":1}],"payment":{"card":"x","iban":"y"}}`,
+			map[string][]string{"payment": {"must match exactly one schema"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate([]byte(tt.body))
+			if tt.want == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var ee *validation.Errors
+
+			require.ErrorAs(t, err, &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+		})
+	}
+
+	assert.Error(t, s.Validate([]byte(`{`)))
+}
+
+func TestSchemaValues(t *testing.T) {
+	s, err := validation.CompileSchema([]byte(`{
+		"type": "object",
+		"required": ["limit"],
+		"properties": {
+			"limit": {"type": "integer", "maximum": 100},
+			"active": {"type": "boolean"},
+			"ids": {"type": "array", "items": {"type": "integer"}}
+		}
+	}`))
+	require.NoError(t, err)
+
+	assert.NoError(t, s.ValidateValues(url.Values{"limit": {"10"}, "active": {"true"}, "ids": {"1", "2"}}))
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, s.ValidateValues(url.Values{"limit": {"x"}, "active": {"maybe"}, "ids": {"1", "b"}}), &ee)
+	assert.Equal(t, map[string][]string{
+		"limit":  {"must be an integer"},
+		"active": {"must be a boolean"},
+		"ids[1]": {"must be an integer"},
+	}, ee.Fields())
+
+	require.ErrorAs(t, s.ValidateValues(url.Values{"limit": {"101"}}), &ee)
+	assert.Equal(t, map[string][]string{"limit": {"must be at most 100"}}, ee.Fields())
+}
+
+func TestCompileSchemaInvalid(t *testing.T) {
+	for _, doc := range []string{
+		`{`, `"x"`, `{"pattern":"("}`, `{"$ref":"#/missing"}`, `{"$ref":"other.json"}`,
+		`{"allOf":{}}`,
+	} {
+		_, err := validation.CompileSchema([]byte(doc))
+		assert.ErrorIs(t, err, validation.ErrInvalidSchema, doc)
+	}
+
+	// Recursive references
+	s, err := validation.CompileSchema([]byte(`{"type":"object","properties":{"child":{"$ref":"#"}}}`))
+	require.NoError(t, err)
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, s.Validate([]byte(`{"child":{"child":1}}`)), &ee)
+	assert.Equal(t, map[string][]string{"child.child": {"must be an object"}}, ee.Fields())
+}
@@ -0,0 +1,439 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrInvalidRule is returned when a struct tag references an unknown rule or has an invalid parameter.
+	ErrInvalidRule = errors.New("invalid validation rule")
+	// ErrInvalidTarget is returned when the value validated is not a struct or pointer to a struct.
+	ErrInvalidTarget = errors.New("validation target must be a struct")
+)
+
+// Validator validates structs using declarative rules in struct tags:
+//
+//	type CreateUser struct {
+//		Name  string `json:"name" validate:"required,min=2,max=64"`
+//		Email string `json:"email" validate:"required,email"`
+//		Role  string `json:"role" validate:"omitempty,oneof=admin member"`
+//	}
+//
+// Rules are comma separated, parameters follow `=`.  `regexp` consumes the remainder of the tag so the
+// expression may contain commas, it must be the last rule.  Nested structs are validated recursively, including the
+// elements of slices and maps.  Rules preceding `dive` apply to the collection itself, rules following it apply to
+// each element (e.g. `validate:"max=10,dive,min=3"`), dive may be repeated for nested collections.
+//
+// Failures are reported as Errors keyed by the dotted path of the field (e.g. "shipping.address.zip",
+// "items[3].quantity", `attributes["color"]`), every message is a RuleError.  Path elements use the field's json name
+// by default (falling back to the Go field name), see WithNameTag.  Embedded structs without an explicit name are
+// flattened into the parent, matching encoding/json.
+type Validator struct {
+	tagName    string
+	nameTag    string
+	mu         sync.RWMutex
+	rules      map[string]ruleFunc
+	conditions map[string]Condition
+	cache      sync.Map // reflect.Type => []fieldRules
+	failFast   bool
+	maxErrors  int
+}
+
+// TagName is the default struct tag used to declare rules.
+const TagName = "validate"
+
+// NewValidator creates a Validator with the built-in rules.
+func NewValidator() *Validator {
+	return &Validator{
+		tagName:    TagName,
+		nameTag:    "json",
+		rules:      builtinRules(),
+		conditions: map[string]Condition{},
+	}
+}
+
+// WithNameTag sets the struct tag used to name fields in error paths (e.g. "json", "form"), empty uses the Go field
+// name.  Intended for use at setup, before validation.
+func (v *Validator) WithNameTag(tag string) *Validator {
+	v.nameTag = tag
+	v.cache.Clear()
+
+	return v
+}
+
+// DefaultValidator is used by Struct.
+var DefaultValidator = NewValidator()
+
+// Struct validates s with DefaultValidator.
+func Struct(s any) error {
+	return DefaultValidator.Struct(s)
+}
+
+// StructCtx validates s with DefaultValidator.
+func StructCtx(ctx context.Context, s any) error {
+	return DefaultValidator.StructCtx(ctx, s)
+}
+
+// Struct validates s, see StructCtx.
+func (v *Validator) Struct(s any) error {
+	return v.StructCtx(context.Background(), s)
+}
+
+// StructCtx validates s, which must be a struct or pointer to a struct.  ctx is passed to context aware rules (see
+// RegisterCtx).  Returns *Errors if any rule fails (or *LimitError, see WithMaxErrors), nil if valid,
+// ErrInvalidRule/ErrInvalidTarget for programming errors, or the error of a failing context aware rule.
+func (v *Validator) StructCtx(ctx context.Context, s any) error {
+	val := reflect.ValueOf(s)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return ErrInvalidTarget
+		}
+
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return ErrInvalidTarget
+	}
+
+	var ee Errors
+
+	if err := v.validateStruct(ctx, val, "", &ee); err != nil && !errors.Is(err, errStop) {
+		return err
+	}
+
+	return v.limit(&ee)
+}
+
+// rule is a parsed tag element.
+type rule struct {
+	name  string
+	param string
+	fn    ruleFunc
+}
+
+// ruleSet is the parsed tag, dive holds the rules applied to each element of a collection.
+type ruleSet struct {
+	rules []rule
+	dive  *ruleSet
+}
+
+type fieldRules struct {
+	index    int
+	name     string
+	rules    ruleSet
+	embedded bool
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+func (v *Validator) validateStruct(ctx context.Context, val reflect.Value, prefix string, ee *Errors) error {
+	fields, err := v.typeRules(val.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		path := prefix
+		if !f.embedded {
+			path = joinPath(prefix, f.name)
+		}
+
+		if err := v.validateValue(ctx, val, val.Field(f.index), path, f.rules, ee); err != nil {
+			return err
+		}
+	}
+
+	if v.stopped(ee) {
+		return errStop
+	}
+
+	return checkInvariants(ctx, val, prefix, ee)
+}
+
+// validateValue applies the rules to fv, then validates the elements of collections with the dive rules, and
+// nested structs.  parent is the struct containing the field, used by conditional rules.
+func (v *Validator) validateValue(ctx context.Context, parent, fv reflect.Value, path string, set ruleSet,
+	ee *Errors,
+) error {
+	if v.stopped(ee) {
+		return errStop
+	}
+
+	ok, err := v.validateField(ctx, parent, fv, path, set.rules, ee)
+	if err != nil || !ok {
+		return err
+	}
+
+	fv = reflect.Indirect(fv)
+
+	switch fv.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		return v.validateStruct(ctx, fv, path, ee)
+	case reflect.Slice, reflect.Array:
+		if set.dive == nil && !mayNest(fv.Type().Elem()) {
+			return nil
+		}
+
+		for i := 0; i < fv.Len(); i++ {
+			if err := v.validateValue(ctx, parent, fv.Index(i), fmt.Sprintf("%s[%d]", path, i), diveRules(set), ee); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if set.dive == nil && !mayNest(fv.Type().Elem()) {
+			return nil
+		}
+
+		iter := fv.MapRange()
+		for iter.Next() {
+			if err := v.validateValue(ctx, parent, iter.Value(), mapPath(path, iter.Key()), diveRules(set), ee); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func diveRules(set ruleSet) ruleSet {
+	if set.dive == nil {
+		return ruleSet{}
+	}
+
+	return *set.dive
+}
+
+// mapPath formats string keys quoted, e.g. attributes["color"], other keys using their default format.
+func mapPath(path string, key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return path + "[" + strconv.Quote(key.String()) + "]"
+	}
+
+	return fmt.Sprintf("%s[%v]", path, key.Interface())
+}
+
+// mayNest reports if values of t may contain fields with rules.
+func mayNest(t reflect.Type) bool {
+	switch derefType(t).Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return mayNest(derefType(t).Elem())
+	}
+
+	return false
+}
+
+// validateField applies the field's rules, returning false if validation of the field failed or was skipped.
+// Rules applied to unsupported types return ErrInvalidRule.
+func (v *Validator) validateField(ctx context.Context, parent, fv reflect.Value, name string, rules []rule,
+	ee *Errors,
+) (bool, error) {
+	for _, r := range rules {
+		target := reflect.Indirect(fv)
+
+		switch {
+		case r.name == "omitempty":
+			if fv.IsZero() {
+				return false, nil
+			}
+
+			continue
+		case isConditional(r.name):
+			applies, err := v.condition(ctx, parent, r)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", name, err)
+			}
+
+			required := r.name == ruleRequiredIf || r.name == ruleRequiredUnless || r.name == ruleRequiredWhen
+
+			switch {
+			case applies && required && fv.IsZero():
+				ee.Add(name, RuleError{Rule: r.name, Param: r.param, Message: "is required", Key: "required"})
+
+				return false, nil
+			case applies && !required && !fv.IsZero():
+				ee.Add(name, RuleError{Rule: r.name, Param: r.param, Message: "must be empty", Key: "excluded"})
+
+				return false, nil
+			case fv.IsZero():
+				// Optional or excluded, remaining rules are skipped as with omitempty
+				return false, nil
+			}
+
+			continue
+		case isCrossField(r.name):
+			if fv.Kind() == reflect.Pointer && fv.IsNil() {
+				return false, nil
+			}
+
+			if err := v.compareField(parent, fv, r); err != nil {
+				var re RuleError
+				if !errors.As(err, &re) {
+					return false, fmt.Errorf("%s: %w", name, err)
+				}
+
+				ee.Add(name, err)
+
+				return false, nil
+			}
+
+			continue
+		case r.name == "required":
+			// Pointers are required to be non nil, the value pointed to may be zero
+			target = fv
+		default:
+			// Nothing to check for nil pointers, unless required
+			if fv.Kind() == reflect.Pointer && fv.IsNil() {
+				return false, nil
+			}
+		}
+
+		if err := r.fn(ctx, target, r.param); err != nil {
+			var re RuleError
+			if !errors.As(err, &re) {
+				return false, fmt.Errorf("%s: %w", name, err)
+			}
+
+			ee.Add(name, err)
+
+			return false, nil
+		}
+	}
+
+	return !(fv.Kind() == reflect.Pointer && fv.IsNil()), nil
+}
+
+func (v *Validator) typeRules(t reflect.Type) ([]fieldRules, error) {
+	if cached, ok := v.cache.Load(t); ok {
+		return cached.([]fieldRules), nil //nolint:forcetypeassert
+	}
+
+	out := make([]fieldRules, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get(v.tagName)
+		if tag == "-" {
+			continue
+		}
+
+		rules, err := v.parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), sf.Name, err)
+		}
+
+		if err := checkFields(t, &rules); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", t.Name(), sf.Name, err)
+		}
+
+		if len(rules.rules) == 0 && rules.dive == nil && !mayNest(sf.Type) {
+			continue
+		}
+
+		name, named := v.fieldName(sf)
+
+		out = append(out, fieldRules{
+			index:    i,
+			name:     name,
+			rules:    rules,
+			embedded: sf.Anonymous && !named && derefType(sf.Type).Kind() == reflect.Struct,
+		})
+	}
+
+	v.cache.Store(t, out)
+
+	return out, nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// parseTag parses the rules of a tag, rules following `dive` apply to the elements of a collection.
+func (v *Validator) parseTag(tag string) (ruleSet, error) {
+	var (
+		out     ruleSet
+		current = &out
+	)
+
+	for tag != "" {
+		var item string
+
+		tag = strings.TrimLeft(tag, " ")
+
+		if strings.HasPrefix(tag, "regexp=") {
+			item, tag = tag, ""
+		} else {
+			item, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, param, _ := strings.Cut(strings.TrimSpace(item), "=")
+
+		switch name {
+		case "":
+			continue
+		case "dive":
+			current.dive = &ruleSet{}
+			current = current.dive
+
+			continue
+		}
+
+		v.mu.RLock()
+		fn, ok := v.rules[name]
+		v.mu.RUnlock()
+
+		if !ok {
+			return ruleSet{}, fmt.Errorf("%w: %q", ErrInvalidRule, name)
+		}
+
+		if err := checkParam(name, param); err != nil {
+			return ruleSet{}, err
+		}
+
+		if err := v.checkConditionParam(name, param); err != nil {
+			return ruleSet{}, err
+		}
+
+		current.rules = append(current.rules, rule{name: name, param: param, fn: fn})
+	}
+
+	return out, nil
+}
+
+// fieldName uses the name tag of the field, falling back to the field name.  named reports if the tag defined
+// the name.
+func (v *Validator) fieldName(sf reflect.StructField) (string, bool) {
+	if v.nameTag == "" {
+		return sf.Name, false
+	}
+
+	name, _, _ := strings.Cut(sf.Tag.Get(v.nameTag), ",")
+	if name == "" || name == "-" {
+		return sf.Name, false
+	}
+
+	return name, true
+}
@@ -0,0 +1,270 @@
+package validation_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/validation"
+)
+
+type Address struct {
+	Zip string `json:"zip" validate:"required,len=5"`
+}
+
+type Base struct {
+	ID string `json:"id" validate:"omitempty,uuid"`
+}
+
+type CreateUser struct {
+	Base
+	Name     string    `json:"name" validate:"required,min=2,max=8"`
+	Email    string    `json:"email" validate:"required,email"`
+	Website  string    `json:"website,omitempty" validate:"omitempty,url"`
+	Role     string    `json:"role" validate:"oneof=admin member"`
+	Age      int       `json:"age" validate:"min=18,max=130"`
+	Tags     []string  `json:"tags" validate:"max=2"`
+	Code     string    `validate:"omitempty,regexp=^[A-Z]{2,3}$"`
+	Nickname *string   `json:"nickname" validate:"min=3"`
+	Manager  *string   `json:"manager" validate:"required"`
+	Token    uuid.UUID `json:"token" validate:"uuid"`
+	Address  Address   `json:"address"`
+	Billing  *Address  `json:"billing"`
+	Ignored  string    `json:"-" validate:"-"`
+	private  string    //nolint:unused
+}
+
+func strPtr(s string) *string { return &s }
+
+func validUser() CreateUser {
+	return CreateUser{
+		Name:    "bob",
+		Email:   "bob@example.com",
+		Role:    "admin",
+		Age:     30,
+		Manager: strPtr(""),
+		Address: Address{Zip: "12345"},
+	}
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(u *CreateUser)
+		want   string
+	}{
+		{"valid", func(_ *CreateUser) {}, `null`},
+		{"required", func(u *CreateUser) { u.Name = ""; u.Email = ""; u.Manager = nil }, `{"email":["is required"],"manager":["is required"],"name":["is required"]}`},
+		{"min max string", func(u *CreateUser) { u.Name = "b" }, `{"name":["must be at least 2 characters"]}`},
+		{"max runes", func(u *CreateUser) { u.Name = "żółćżółćż" }, `{"name":["must be at most 8 characters"]}`},
+		{"email", func(u *CreateUser) { u.Email = "Bob <bob@example.com>" }, `{"email":["must be a valid email address"]}`},
+		{"url", func(u *CreateUser) { u.Website = "example.com" }, `{"website":["must be a valid URL"]}`},
+		{"oneof", func(u *CreateUser) { u.Role = "root" }, `{"role":["must be one of: admin, member"]}`},
+		{"numbers", func(u *CreateUser) { u.Age = 12 }, `{"age":["must be at least 18"]}`},
+		{"collection", func(u *CreateUser) { u.Tags = []string{"a", "b", "c"} }, `{"tags":["must contain at most 2 items"]}`},
+		{"regexp", func(u *CreateUser) { u.Code = "abc" }, `{"Code":["has an invalid format"]}`},
+		{"pointer", func(u *CreateUser) { u.Nickname = strPtr("ab") }, `{"nickname":["must be at least 3 characters"]}`},
+		{"embedded", func(u *CreateUser) { u.ID = "nope" }, `{"id":["must be a valid UUID"]}`},
+		{"nested", func(u *CreateUser) { u.Address.Zip = "1" }, `{"address.zip":["must be exactly 5 characters"]}`},
+		{"nested pointer", func(u *CreateUser) { u.Billing = &Address{} }, `{"billing.zip":["is required"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := validUser()
+			tt.modify(&u)
+
+			err := validation.Struct(&u)
+
+			var fields map[string][]string
+
+			var ee *validation.Errors
+			if errors.As(err, &ee) {
+				fields = ee.Fields()
+			} else {
+				assert.NoError(t, err)
+			}
+
+			b, _ := json.Marshal(fields)
+			assert.Equal(t, tt.want, string(b))
+		})
+	}
+}
+
+func TestStructRuleError(t *testing.T) {
+	u := validUser()
+	u.Name = "b"
+
+	var ee *validation.Errors
+
+	assert.ErrorAs(t, validation.Struct(u), &ee)
+
+	var re validation.RuleError
+
+	assert.ErrorAs(t, (*ee)["name"][0], &re)
+	assert.Equal(t, validation.RuleError{Rule: "min", Param: "2", Message: "must be at least 2 characters", Key: "min.string"}, re)
+}
+
+func TestStructInvalid(t *testing.T) {
+	var nilUser *CreateUser
+
+	assert.ErrorIs(t, validation.Struct(nil), validation.ErrInvalidTarget)
+	assert.ErrorIs(t, validation.Struct(nilUser), validation.ErrInvalidTarget)
+	assert.ErrorIs(t, validation.Struct("string"), validation.ErrInvalidTarget)
+
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"unknown", struct {
+			A string `validate:"bogus"`
+		}{}},
+		{"bad param", struct {
+			A string `validate:"min=x"`
+		}{}},
+		{"bad oneof", struct {
+			A string `validate:"oneof="`
+		}{}},
+		{"bad regexp", struct {
+			A string `validate:"regexp=("`
+		}{}},
+		{"unsupported type", struct {
+			A int `validate:"email"`
+		}{}},
+		{"unsupported len", struct {
+			A int `validate:"len=1"`
+		}{}},
+		{"unsupported min", struct {
+			A bool `validate:"min=1"`
+		}{}},
+		{"unsupported oneof", struct {
+			A float64 `validate:"oneof=1"`
+		}{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, validation.Struct(tt.v), validation.ErrInvalidRule)
+		})
+	}
+}
+
+type Shipping struct {
+	Address Address `json:"address" form:"addr"`
+}
+
+type Order struct {
+	Shipping *Shipping `json:"shipping" form:"ship"`
+	Base     `json:"base"`
+	Note     string `validate:"required"`
+}
+
+func TestStructPaths(t *testing.T) {
+	order := Order{Shipping: &Shipping{}, Base: Base{ID: "x"}}
+
+	tests := []struct {
+		name    string
+		nameTag string
+		want    map[string][]string
+	}{
+		{"json", "json", map[string][]string{
+			"shipping.address.zip": {"is required"},
+			"base.id":              {"must be a valid UUID"},
+			"Note":                 {"is required"},
+		}},
+		{"form", "form", map[string][]string{
+			"ship.addr.Zip": {"is required"},
+			"ID":            {"must be a valid UUID"},
+			"Note":          {"is required"},
+		}},
+		{"field names", "", map[string][]string{
+			"Shipping.Address.Zip": {"is required"},
+			"ID":                   {"must be a valid UUID"},
+			"Note":                 {"is required"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.NewValidator().WithNameTag(tt.nameTag).Struct(order)
+
+			var ee *validation.Errors
+
+			assert.ErrorAs(t, err, &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+		})
+	}
+}
+
+type Item struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+type Cart struct {
+	Items      []Item            `json:"items" validate:"min=1,max=3"`
+	Coupons    []string          `json:"coupons" validate:"max=2,dive,len=4"`
+	Attributes map[string]string `json:"attributes" validate:"dive,required,max=5"`
+	Matrix     [][]int           `json:"matrix" validate:"dive,dive,max=9"`
+	Saved      map[int]*Item     `json:"saved"`
+}
+
+func TestStructCollections(t *testing.T) {
+	tests := []struct {
+		name string
+		cart Cart
+		want map[string][]string
+	}{
+		{"valid", Cart{Items: []Item{{SKU: "a", Quantity: 1}}}, nil},
+		{"collection min", Cart{}, map[string][]string{"items": {"must contain at least 1 items"}}},
+		{"elements", Cart{Items: []Item{{SKU: "a", Quantity: 1}, {Quantity: 0}}}, map[string][]string{
+			"items[1].sku":      {"is required"},
+			"items[1].quantity": {"must be at least 1"},
+		}},
+		{"dive", Cart{Items: []Item{{SKU: "a", Quantity: 1}}, Coupons: []string{"ABCD", "XY"}}, map[string][]string{
+			"coupons[1]": {"must be exactly 4 characters"},
+		}},
+		{
+			"collection and dive",
+			Cart{Items: []Item{{SKU: "a", Quantity: 1}}, Coupons: []string{"A", "ABCD", "ABCD"}},
+			map[string][]string{"coupons": {"must contain at most 2 items"}},
+		},
+		{
+			"map",
+			Cart{Items: []Item{{SKU: "a", Quantity: 1}}, Attributes: map[string]string{"color": "", "size": "medium"}},
+			map[string][]string{
+				`attributes["color"]`: {"is required"},
+				`attributes["size"]`:  {"must be at most 5 characters"},
+			},
+		},
+		{
+			"nested dive",
+			Cart{Items: []Item{{SKU: "a", Quantity: 1}}, Matrix: [][]int{{1}, {2, 10}}},
+			map[string][]string{"matrix[1][1]": {"must be at most 9"}},
+		},
+		{
+			"map of structs",
+			Cart{Items: []Item{{SKU: "a", Quantity: 1}}, Saved: map[int]*Item{7: {Quantity: 1}, 8: nil}},
+			map[string][]string{"saved[7].sku": {"is required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.Struct(tt.cart)
+			if tt.want == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var ee *validation.Errors
+
+			assert.ErrorAs(t, err, &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ParamRules maps parameter names to rules for Values and Header.  Rules use the struct tag syntax, optionally
+// prefixed by the parameter type used to convert the value: string (default), int, uint, float, bool, time (RFC3339)
+// or uuid.  List types (e.g. []int) accept repeated and comma separated values, rules following `dive` apply to each
+// element:
+//
+//	validation.ParamRules{
+//		"limit":  "int,min=1,max=100",
+//		"status": "omitempty,oneof=open closed",
+//		"ids":    "[]int,max=10,dive,min=1",
+//	}
+//
+// Absent parameters are nil, failing `required` and skipping other rules.
+type ParamRules map[string]string
+
+// Values validates query or form parameters with DefaultValidator, see Validator.Values.
+func Values(ctx context.Context, values url.Values, rules ParamRules) error {
+	return DefaultValidator.Values(ctx, values, rules)
+}
+
+// Header validates request headers with DefaultValidator, see Validator.Header.
+func Header(ctx context.Context, h http.Header, rules ParamRules) error {
+	return DefaultValidator.Header(ctx, h, rules)
+}
+
+// Values validates values against rules, returning *Errors keyed by parameter name (list elements by index, e.g.
+// "ids[2]") with the same messages as Struct.  Conversion failures are reported as "type" RuleErrors.
+func (v *Validator) Values(ctx context.Context, values url.Values, rules ParamRules) error {
+	return v.validateParams(ctx, rules, func(name string) []string { return values[name] })
+}
+
+// Header validates h against rules, see Values.  Rule names are canonicalized for the lookup, failures use the name
+// as given in rules.
+func (v *Validator) Header(ctx context.Context, h http.Header, rules ParamRules) error {
+	return v.validateParams(ctx, rules, func(name string) []string {
+		return h.Values(textproto.CanonicalMIMEHeaderKey(name))
+	})
+}
+
+func (v *Validator) validateParams(ctx context.Context, rules ParamRules, lookup func(string) []string) error {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var ee Errors
+
+	for _, name := range names {
+		if v.stopped(&ee) {
+			break
+		}
+
+		typ, tag := paramType(rules[name])
+
+		set, err := v.parseTag(tag)
+		if err != nil {
+			return err
+		}
+
+		val, ok := coerceParam(typ, lookup(name))
+		if !ok {
+			ee.Add(name, RuleError{Rule: "type", Param: typ, Message: typeMessage(typ), Key: "type"})
+
+			continue
+		}
+
+		err = v.validateValue(ctx, reflect.Value{}, val, name, set, &ee)
+		if err != nil && !errors.Is(err, errStop) {
+			return err
+		}
+	}
+
+	return v.limit(&ee)
+}
+
+var paramTypes = map[string]reflect.Type{
+	"string": reflect.TypeOf(""),
+	"int":    reflect.TypeOf(int64(0)),
+	"uint":   reflect.TypeOf(uint64(0)),
+	"float":  reflect.TypeOf(float64(0)),
+	"bool":   reflect.TypeOf(false),
+	"time":   reflect.TypeOf(time.Time{}),
+	"uuid":   reflect.TypeOf(uuid.UUID{}),
+}
+
+// paramType splits the optional type prefix from the rules.
+func paramType(rules string) (string, string) {
+	first, rest, _ := strings.Cut(rules, ",")
+	first = strings.TrimSpace(first)
+
+	if _, ok := paramTypes[strings.TrimPrefix(first, "[]")]; ok {
+		return first, rest
+	}
+
+	return "string", rules
+}
+
+func typeMessage(typ string) string {
+	switch strings.TrimPrefix(typ, "[]") {
+	case "int":
+		return "must be an integer"
+	case "uint":
+		return "must be a positive integer"
+	case "float":
+		return "must be a number"
+	case "bool":
+		return "must be a boolean"
+	case "time":
+		return "must be an RFC3339 date"
+	case "uuid":
+		return "must be a valid UUID"
+	}
+
+	return "is invalid"
+}
+
+// coerceParam converts the parameter values to typ, absent values are a nil pointer to the type.
+func coerceParam(typ string, values []string) (reflect.Value, bool) {
+	elem, isList := strings.CutPrefix(typ, "[]")
+	t := paramTypes[elem]
+
+	if !isList {
+		if len(values) == 0 {
+			return reflect.Zero(reflect.PointerTo(t)), true
+		}
+
+		return convertParam(elem, values[0])
+	}
+
+	if len(values) == 0 {
+		return reflect.Zero(reflect.PointerTo(reflect.SliceOf(t))), true
+	}
+
+	out := reflect.MakeSlice(reflect.SliceOf(t), 0, len(values))
+
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			item, ok := convertParam(elem, part)
+			if !ok {
+				return reflect.Value{}, false
+			}
+
+			out = reflect.Append(out, item)
+		}
+	}
+
+	return out, true
+}
+
+func convertParam(typ, s string) (reflect.Value, bool) {
+	var (
+		out any = s
+		err error
+	)
+
+	switch typ {
+	case "int":
+		out, err = strconv.ParseInt(s, 10, 64)
+	case "uint":
+		out, err = strconv.ParseUint(s, 10, 64)
+	case "float":
+		out, err = strconv.ParseFloat(s, 64)
+	case "bool":
+		out, err = strconv.ParseBool(s)
+	case "time":
+		out, err = time.Parse(time.RFC3339, s)
+	case "uuid":
+		out, err = uuid.Parse(s)
+	}
+
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	return reflect.ValueOf(out), true
+}
@@ -0,0 +1,93 @@
+package validation_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bir/iken/validation"
+)
+
+func TestValues(t *testing.T) {
+	rules := validation.ParamRules{
+		"limit":  "int,min=1,max=100",
+		"status": "omitempty,oneof=open closed",
+		"ids":    "[]int,max=3,dive,min=1",
+		"since":  "time",
+		"q":      "required,min=2",
+	}
+
+	tests := []struct {
+		name   string
+		values url.Values
+		want   map[string][]string
+	}{
+		{"valid", url.Values{"limit": {"10"}, "status": {"open"}, "ids": {"1,2", "3"}, "q": {"shoes"}}, nil},
+		{"absent", url.Values{}, map[string][]string{"q": {"is required"}}},
+		{"rules", url.Values{"limit": {"0"}, "status": {"x"}, "ids": {"1", "0"}, "q": {"a"}}, map[string][]string{
+			"limit":  {"must be at least 1"},
+			"status": {"must be one of: open, closed"},
+			"ids[1]": {"must be at least 1"},
+			"q":      {"must be at least 2 characters"},
+		}},
+		{"collection", url.Values{"ids": {"1,2,3,4"}, "q": {"ab"}}, map[string][]string{
+			"ids": {"must contain at most 3 items"},
+		}},
+		{"types", url.Values{"limit": {"ten"}, "ids": {"1,x"}, "since": {"today"}, "q": {"ab"}}, map[string][]string{
+			"limit": {"must be an integer"},
+			"ids":   {"must be an integer"},
+			"since": {"must be an RFC3339 date"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validation.Values(context.Background(), tt.values, rules)
+			if tt.want == nil {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var ee *validation.Errors
+
+			require.ErrorAs(t, err, &ee)
+			assert.Equal(t, tt.want, ee.Fields())
+		})
+	}
+
+	assert.ErrorIs(t, validation.Values(context.Background(), nil, validation.ParamRules{"a": "bogus"}),
+		validation.ErrInvalidRule)
+}
+
+func TestHeader(t *testing.T) {
+	rules := validation.ParamRules{
+		"X-Tenant-Id":    "required,uuid",
+		"x-client-build": "uint,omitempty,max=500",
+		"X-Debug":        "bool",
+	}
+
+	h := http.Header{}
+	h.Set("X-Tenant-Id", "8a3e2a36-7d0c-4d35-9d3e-2a6d7e1f0c11")
+	h.Set("X-Client-Build", "42")
+	h.Set("X-Debug", "true")
+
+	assert.NoError(t, validation.Header(context.Background(), h, rules))
+
+	h = http.Header{}
+	h.Set("X-Client-Build", "900")
+	h.Set("X-Debug", "sure")
+
+	var ee *validation.Errors
+
+	require.ErrorAs(t, validation.Header(context.Background(), h, rules), &ee)
+	assert.Equal(t, map[string][]string{
+		"X-Tenant-Id":    {"is required"},
+		"x-client-build": {"must be at most 500"},
+		"X-Debug":        {"must be a boolean"},
+	}, ee.Fields())
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/bir/iken/errs"
+)
+
+// ErrorProcessorFunc is a processor that reports failures.
+type ErrorProcessorFunc[I any] func(I) error
+
+// Retry converts p to a ProcessorFunc that retries inputs failing with a retryable error (see errs.IsRetryable),
+// up to maxAttempts total attempts.  The delay between attempts honors errs.RetryAfter, otherwise backs off
+// exponentially from base to maxDelay.  onFailure, if not nil, receives the input and final error of failed inputs.
+func Retry[I any](p ErrorProcessorFunc[I], maxAttempts int, base, maxDelay time.Duration,
+	onFailure func(I, error),
+) ProcessorFunc[I] {
+	return func(input I) {
+		for attempt := 0; ; attempt++ {
+			err := p(input)
+			if err == nil {
+				return
+			}
+
+			if !errs.IsRetryable(err) || attempt+1 >= maxAttempts {
+				if onFailure != nil {
+					onFailure(input, err)
+				}
+
+				return
+			}
+
+			time.Sleep(errs.RetryDelay(err, attempt, base, maxDelay))
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package worker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bir/iken/errs"
+	"github.com/bir/iken/worker"
+)
+
+func TestRetry(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		attempts  int
+		wantCalls int
+		wantErr   error
+	}{
+		{"success", []error{nil}, 3, 1, nil},
+		{"retry success", []error{errs.MarkRetryable(errBoom, 0), nil}, 3, 2, nil},
+		{"permanent", []error{errBoom, nil}, 3, 1, errBoom},
+		{"exhausted", []error{errs.MarkRetryable(errBoom, 0), errs.MarkRetryable(errBoom, 0)}, 2, 2, errBoom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+
+			var failed error
+
+			p := worker.Retry(func(i int) error {
+				assert.Equal(t, 42, i)
+				calls++
+
+				return tt.errs[calls-1]
+			}, tt.attempts, time.Millisecond, time.Millisecond, func(_ int, err error) {
+				failed = err
+			})
+
+			p(42)
+
+			assert.Equal(t, tt.wantCalls, calls)
+
+			if tt.wantErr == nil {
+				assert.Nil(t, failed)
+			} else {
+				assert.ErrorIs(t, failed, tt.wantErr)
+			}
+		})
+	}
+}